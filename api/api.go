@@ -53,8 +53,13 @@ func (s Server) Serve(ctx context.Context) (err error) {
 	}
 
 	server := &http.Server{}
-	mux := NewServerRoutes(s)
-	server.Handler = mux
+	mux := newServerRoutes(s)
+	server.Handler = mux.ServeMux
+	defer func() {
+		if err := mux.limits.Close(); err != nil {
+			log.Errorf("closing access log: %s", err)
+		}
+	}()
 
 	go s.ServeRPC(ctx)
 	go s.ServeWebsocket(ctx)
@@ -179,82 +184,122 @@ func HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
 
 // NewServerRoutes returns a Muxer that has all API routes
 func NewServerRoutes(s Server) *http.ServeMux {
+	return newServerRoutes(s).ServeMux
+}
+
+// routeMux registers handlers, keeping a list of registered paths
+type routeMux struct {
+	*http.ServeMux
+	s      Server
+	limits *requestLimits
+	paths  []string
+}
+
+// handle registers a handler for a path in the Routes table, wrapping it in
+// access logging, middleware, rate limits & request validation
+func (m *routeMux) handle(path string, handler http.HandlerFunc) {
+	m.paths = append(m.paths, path)
+	m.Handle(path, m.limits.logAccess(path, m.s.middleware(m.limits.rateLimit(validateRequest(path, handler)))))
+}
+
+func newServerRoutes(s Server) *routeMux {
 	cfg := s.Config()
 
-	m := http.NewServeMux()
+	m := &routeMux{ServeMux: http.NewServeMux(), s: s, limits: newRequestLimits(cfg.API)}
 
-	m.Handle("/health", s.middleware(HealthCheckHandler))
-	m.Handle("/ipfs/", s.middleware(s.HandleIPFSPath))
-	m.Handle("/ipns/", s.middleware(s.HandleIPNSPath))
+	m.handle("/health", HealthCheckHandler)
+	m.handle("/ipfs/", s.HandleIPFSPath)
+	m.handle("/ipns/", s.HandleIPNSPath)
 
 	proh := NewProfileHandlers(s.Instance, cfg.API.ReadOnly)
-	m.Handle("/me", s.middleware(proh.ProfileHandler))
-	m.Handle("/profile", s.middleware(proh.ProfileHandler))
-	m.Handle("/profile/photo", s.middleware(proh.ProfilePhotoHandler))
-	m.Handle("/profile/poster", s.middleware(proh.PosterHandler))
+	m.handle("/me", proh.ProfileHandler)
+	m.handle("/profile", proh.ProfileHandler)
+	m.handle("/profile/photo", proh.ProfilePhotoHandler)
+	m.handle("/profile/poster", proh.PosterHandler)
 
 	ph := NewPeerHandlers(s.Instance, cfg.API.ReadOnly)
-	m.Handle("/peers", s.middleware(ph.PeersHandler))
-	m.Handle("/peers/", s.middleware(ph.PeerHandler))
-	m.Handle("/connect/", s.middleware(ph.ConnectToPeerHandler))
-	m.Handle("/connections", s.middleware(ph.ConnectionsHandler))
+	m.handle("/peers", ph.PeersHandler)
+	m.handle("/peers/", ph.PeerHandler)
+	m.handle("/connect/", ph.ConnectToPeerHandler)
+	m.handle("/connections", ph.ConnectionsHandler)
 
 	if cfg.Remote != nil && cfg.Remote.Enabled {
 		log.Info("running in `remote` mode")
 
 		remh := NewRemoteHandlers(s.Instance)
-		m.Handle("/remote/dsync", s.middleware(remh.DsyncHandler))
-		m.Handle("/remote/logsync", s.middleware(remh.LogsyncHandler))
-		m.Handle("/remote/refs", s.middleware(remh.RefsHandler))
+		m.handle("/remote/dsync", remh.DsyncHandler)
+		m.handle("/remote/logsync", remh.LogsyncHandler)
+		m.handle("/remote/refs", remh.RefsHandler)
 	}
 
 	dsh := NewDatasetHandlers(s.Instance, cfg.API.ReadOnly)
-	m.Handle("/list", s.middleware(dsh.ListHandler))
-	m.Handle("/list/", s.middleware(dsh.PeerListHandler))
-	m.Handle("/save", s.middleware(dsh.SaveHandler))
-	m.Handle("/save/", s.middleware(dsh.SaveHandler))
-	m.Handle("/remove/", s.middleware(dsh.RemoveHandler))
-	m.Handle("/get/", s.middleware(dsh.GetHandler))
-	m.Handle("/rename", s.middleware(dsh.RenameHandler))
-	m.Handle("/diff", s.middleware(dsh.DiffHandler))
-	// Deprecated, use /get/username/name?component=body or /get/username/name/body.csv
-	m.Handle("/body/", s.middleware(dsh.BodyHandler))
-	m.Handle("/stats/", s.middleware(dsh.StatsHandler))
-	m.Handle("/unpack/", s.middleware(dsh.UnpackHandler))
+	m.handle("/list", dsh.ListHandler)
+	m.handle("/list/", dsh.PeerListHandler)
+	m.handle("/save", dsh.SaveHandler)
+	m.handle("/save/", dsh.SaveHandler)
+	m.handle("/remove/", dsh.RemoveHandler)
+	m.handle("/get/", dsh.GetHandler)
+	m.handle("/rename", dsh.RenameHandler)
+	m.handle("/diff", dsh.DiffHandler)
+	// streams raw bodies, use /get/username/name?component=body for a paginated body
+	// in a json response
+	m.handle("/body/", dsh.BodyHandler)
+	m.handle("/stats/", dsh.StatsHandler)
+	m.handle("/unpack/", dsh.UnpackHandler)
 
 	remClientH := NewRemoteClientHandlers(s.Instance, cfg.API.ReadOnly)
-	m.Handle("/push/", s.middleware(remClientH.PushHandler))
-	m.Handle("/pull/", s.middleware(dsh.PullHandler))
-	m.Handle("/feeds", s.middleware(remClientH.FeedsHandler))
-	m.Handle("/preview/", s.middleware(remClientH.DatasetPreviewHandler))
+	m.handle("/push/", remClientH.PushHandler)
+	m.handle("/pull/", dsh.PullHandler)
+	m.handle("/feeds", remClientH.FeedsHandler)
+	m.handle("/preview/", remClientH.DatasetPreviewHandler)
 
 	fsih := NewFSIHandlers(s.Instance, cfg.API.ReadOnly)
-	m.Handle("/status/", s.middleware(fsih.StatusHandler("/status")))
-	m.Handle("/whatchanged/", s.middleware(fsih.WhatChangedHandler("/whatchanged")))
-	m.Handle("/init/", s.middleware(fsih.InitHandler("/init")))
-	m.Handle("/checkout/", s.middleware(fsih.CheckoutHandler("/checkout")))
-	m.Handle("/restore/", s.middleware(fsih.RestoreHandler("/restore")))
-	m.Handle("/fsi/write/", s.middleware(fsih.WriteHandler("/fsi/write")))
+	m.handle("/status/", fsih.StatusHandler("/status"))
+	m.handle("/whatchanged/", fsih.WhatChangedHandler("/whatchanged"))
+	m.handle("/init/", fsih.InitHandler("/init"))
+	m.handle("/checkout/", fsih.CheckoutHandler("/checkout"))
+	m.handle("/restore/", fsih.RestoreHandler("/restore"))
+	m.handle("/fsi/write/", fsih.WriteHandler("/fsi/write"))
 
 	renderh := NewRenderHandlers(s.Instance)
-	m.Handle("/render", s.middleware(renderh.RenderHandler))
-	m.Handle("/render/", s.middleware(renderh.RenderHandler))
+	m.handle("/render", renderh.RenderHandler)
+	m.handle("/render/", renderh.RenderHandler)
 
 	lh := NewLogHandlers(s.Instance)
-	m.Handle("/history/", s.middleware(lh.LogHandler))
+	m.handle("/history/", lh.LogHandler)
+
+	evh := NewEventHandlers(s.Instance, cfg.API.ReadOnly)
+	m.handle("/events", evh.EventsHandler)
+
+	fh := NewFollowHandlers(s.Instance, cfg.API.ReadOnly)
+	m.handle("/follow", fh.FollowHandler)
+	m.handle("/follow/feed", fh.FeedHandler)
+
+	oh := NewOrgHandlers(s.Instance, cfg.API.ReadOnly)
+	m.handle("/orgs", oh.OrgsHandler)
+	m.handle("/orgs/members", oh.MembersHandler)
+
+	uh := NewUploadHandlers(s.Instance)
+	m.handle("/upload", uh.UploadHandler)
+	m.handle("/upload/", uh.UploadSessionHandler)
 
 	rch := NewRegistryClientHandlers(s.Instance, cfg.API.ReadOnly)
-	m.Handle("/registry/profile/new", s.middleware(rch.CreateProfileHandler))
-	m.Handle("/registry/profile/prove", s.middleware(rch.ProveProfileKeyHandler))
+	m.handle("/registry/profile/new", rch.CreateProfileHandler)
+	m.handle("/registry/profile/prove", rch.ProveProfileKeyHandler)
 
 	sh := NewSearchHandlers(s.Instance)
-	m.Handle("/search", s.middleware(sh.SearchHandler))
+	m.handle("/search", sh.SearchHandler)
 
 	sqlh := NewSQLHandlers(s.Instance, cfg.API.ReadOnly)
-	m.Handle("/sql", s.middleware(sqlh.QueryHandler("/sql")))
+	m.handle("/sql", sqlh.QueryHandler("/sql"))
+
+	if cfg.API.EnableGraphQL {
+		gqlh := NewGraphQLHandlers(s.Instance, cfg.API.ReadOnly)
+		m.handle("/graphql", gqlh.GraphQLHandler)
+	}
 
 	if !cfg.API.DisableWebui {
-		m.Handle("/webui", s.middleware(WebuiHandler))
+		m.handle("/webui", WebuiHandler)
 	}
 
 	return m
@@ -246,6 +246,7 @@ func TestServerReadOnlyRoutes(t *testing.T) {
 		{"GET", "/checkout", 403},
 		{"GET", "/status", 403},
 		{"GET", "/init", 403},
+		{"GET", "/events", 403},
 
 		// active endpoints:
 		{"GET", "/health", 200},
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/base/archive"
+	"github.com/qri-io/qri/lib"
+)
+
+// rowRangeUnit is the unit of Range headers for dataset bodies. Ranges select
+// entries by index, eg: "Range: rows=0-99" requests the first 100 entries
+const rowRangeUnit = "rows"
+
+// streamBody writes a dataset body directly to the response, without loading
+// the body into memory. Responses carry an ETag derived from the dataset path
+// & the requested representation, which clients can send back with
+// If-None-Match to skip downloading a body they already have
+func (h *DatasetHandlers) streamBody(w http.ResponseWriter, r *http.Request, args *GetReqArgs) {
+	params := &args.Params
+
+	rng, err := parseRowRange(r.Header.Get("Range"))
+	if err != nil {
+		w.Header().Set("Content-Range", fmt.Sprintf("%s */*", rowRangeUnit))
+		util.WriteErrResponse(w, http.StatusRequestedRangeNotSatisfiable, err)
+		return
+	}
+	if rng != nil {
+		params.Offset = rng.offset
+		params.Limit = rng.limit
+		// open-ended ranges read every entry from the offset on
+		params.All = rng.limit < 0
+	}
+
+	res, err := h.OpenBody(r.Context(), params)
+	if err != nil {
+		util.RespondWithError(w, err)
+		return
+	}
+	defer func() {
+		if err := h.CloseBody(res); err != nil {
+			log.Debugf("closing body of %s: %s", res.Ref, err)
+		}
+	}()
+
+	format := params.Format
+	if format == "" && res.Dataset.Structure != nil {
+		format = res.Dataset.Structure.Format
+	}
+	if format != base.NDJSONFormat {
+		if _, err := dataset.ParseDataFormatString(format); err != nil {
+			util.WriteErrResponse(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	filename, err := archive.GenerateFilename(res.Dataset, format)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// bodies in linked working directories can change without the path
+	// changing, so they don't get an ETag
+	if res.FSIPath == "" && res.Ref.Path != "" {
+		etag := bodyETag(res.Ref.Path, params)
+		w.Header().Set("ETag", etag)
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	w.Header().Set("Accept-Ranges", rowRangeUnit)
+	w.Header().Set("Content-Type", extensionToMimeType("."+format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	if rng != nil {
+		w.Header().Set("Content-Range", rng.contentRange())
+		w.WriteHeader(http.StatusPartialContent)
+	}
+
+	if err := h.WriteBody(params, res, w); err != nil {
+		// headers have already been sent, the best we can do is cut the
+		// response short
+		log.Errorf("streaming body of %s: %s", res.Ref, err)
+	}
+}
+
+// rowRange is a parsed Range header
+type rowRange struct {
+	offset, limit int
+}
+
+func (rr rowRange) contentRange() string {
+	if rr.limit < 0 {
+		return fmt.Sprintf("%s %d-*/*", rowRangeUnit, rr.offset)
+	}
+	return fmt.Sprintf("%s %d-%d/*", rowRangeUnit, rr.offset, rr.offset+rr.limit-1)
+}
+
+// parseRowRange reads a Range header in the form "rows=first-last", where
+// last is optional. Ranges are inclusive. Suffix ranges ("rows=-10") and
+// multiple ranges aren't supported, since body length isn't known up front.
+// An empty header returns a nil range
+func parseRowRange(header string) (*rowRange, error) {
+	if header == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(header, rowRangeUnit+"=") {
+		return nil, fmt.Errorf("unsupported range unit, only %q ranges are supported", rowRangeUnit)
+	}
+	spec := strings.TrimPrefix(header, rowRangeUnit+"=")
+	if strings.Contains(spec, ",") {
+		return nil, fmt.Errorf("multiple ranges aren't supported")
+	}
+
+	parts := strings.Split(strings.TrimSpace(spec), "-")
+	if len(parts) != 2 || parts[0] == "" {
+		return nil, fmt.Errorf("invalid range %q", header)
+	}
+	first, err := strconv.Atoi(parts[0])
+	if err != nil || first < 0 {
+		return nil, fmt.Errorf("invalid range %q", header)
+	}
+	if parts[1] == "" {
+		return &rowRange{offset: first, limit: -1}, nil
+	}
+	last, err := strconv.Atoi(parts[1])
+	if err != nil || last < first {
+		return nil, fmt.Errorf("invalid range %q", header)
+	}
+	return &rowRange{offset: first, limit: last - first + 1}, nil
+}
+
+// bodyETag creates a strong entity tag for a body representation. Dataset
+// paths are content-addressed, so the same path, format & selection always
+// produce the same bytes
+func bodyETag(path string, p *lib.GetParams) string {
+	sel := fmt.Sprintf("%d-all", p.Offset)
+	if !p.All {
+		sel = fmt.Sprintf("%d-%d", p.Offset, p.Limit)
+	}
+	fcfg := ""
+	if p.FormatConfig != nil {
+		fcfg = fmt.Sprintf("%v", p.FormatConfig.Map())
+	}
+	sum := sha256.Sum256([]byte(strings.Join([]string{path, p.Format, sel, fcfg}, "\n")))
+	return `"` + strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(sum[:20])) + `"`
+}
+
+// etagMatches checks an If-None-Match header against an ETag, using weak
+// comparison
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
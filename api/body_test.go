@@ -0,0 +1,122 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStreamBody(t *testing.T) {
+	run := NewAPITestRunner(t)
+	defer run.Delete()
+
+	ds := run.BuildDataset("test_ds")
+	run.SaveDataset(ds, "testdata/cities/data.csv")
+	dsHandler := NewDatasetHandlers(run.Inst, false)
+
+	call := func(h http.HandlerFunc, url string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", url, nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		h(w, req)
+		return w
+	}
+
+	w := call(dsHandler.BodyHandler, "/body/peer/test_ds?download=true&format=csv&all=true", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	expect := "city,pop,avg_age,in_usa\ntoronto,40000000,55.5,false\nnew york,8500000,44.4,true\nchicago,300000,44.4,true\nchatham,35000,65.25,true\nraleigh,250000,50.65,true\n"
+	if diff := cmp.Diff(expect, w.Body.String()); diff != "" {
+		t.Errorf("csv body mismatch (-want +got):\n%s", diff)
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected response to have an ETag")
+	}
+
+	// /get/ raw downloads stream the same body
+	w = call(dsHandler.GetHandler, "/get/peer/test_ds/body.csv", nil)
+	if diff := cmp.Diff(expect, w.Body.String()); diff != "" {
+		t.Errorf("/get/ body.csv mismatch (-want +got):\n%s", diff)
+	}
+	if w.Header().Get("ETag") != etag {
+		t.Errorf("expected the same representation to have the same ETag. want: %s, got: %s", etag, w.Header().Get("ETag"))
+	}
+
+	w = call(dsHandler.BodyHandler, "/body/peer/test_ds?download=true&format=csv&all=true", map[string]string{"If-None-Match": etag})
+	if w.Code != http.StatusNotModified {
+		t.Errorf("expected matching If-None-Match to return 304, got %d", w.Code)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("expected 304 response to have no body, got: %q", w.Body.String())
+	}
+
+	w = call(dsHandler.BodyHandler, "/body/peer/test_ds", map[string]string{"Accept": "application/x-ndjson", "Range": "rows=1-2"})
+	if w.Code != http.StatusPartialContent {
+		t.Fatalf("expected status 206, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Range"); got != "rows 1-2/*" {
+		t.Errorf("content range mismatch. want: %q, got: %q", "rows 1-2/*", got)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/x-ndjson" {
+		t.Errorf("content type mismatch. want: %q, got: %q", "application/x-ndjson", got)
+	}
+	expect = "[\"new york\",8500000,44.4,true]\n[\"chicago\",300000,44.4,true]\n"
+	if diff := cmp.Diff(expect, w.Body.String()); diff != "" {
+		t.Errorf("ndjson range mismatch (-want +got):\n%s", diff)
+	}
+	if w.Header().Get("ETag") == etag {
+		t.Error("expected a different range to have a different ETag")
+	}
+
+	w = call(dsHandler.BodyHandler, "/body/peer/test_ds", map[string]string{"Accept": "application/x-ndjson", "Range": "rows=2-"})
+	if w.Code != http.StatusPartialContent {
+		t.Fatalf("expected open-ended range status 206, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Range"); got != "rows 2-*/*" {
+		t.Errorf("content range mismatch. want: %q, got: %q", "rows 2-*/*", got)
+	}
+	expect = "[\"chicago\",300000,44.4,true]\n[\"chatham\",35000,65.25,true]\n[\"raleigh\",250000,50.65,true]\n"
+	if diff := cmp.Diff(expect, w.Body.String()); diff != "" {
+		t.Errorf("ndjson open-ended range mismatch (-want +got):\n%s", diff)
+	}
+
+	w = call(dsHandler.BodyHandler, "/body/peer/test_ds", map[string]string{"Range": "bytes=0-100"})
+	if w.Code != http.StatusRequestedRangeNotSatisfiable {
+		t.Errorf("expected byte range to return 416, got %d", w.Code)
+	}
+}
+
+func TestParseRowRange(t *testing.T) {
+	good := []struct {
+		header string
+		expect *rowRange
+	}{
+		{"", nil},
+		{"rows=0-9", &rowRange{offset: 0, limit: 10}},
+		{"rows=5-5", &rowRange{offset: 5, limit: 1}},
+		{"rows=20-", &rowRange{offset: 20, limit: -1}},
+	}
+	for _, c := range good {
+		got, err := parseRowRange(c.header)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", c.header, err)
+			continue
+		}
+		if diff := cmp.Diff(c.expect, got, cmp.AllowUnexported(rowRange{})); diff != "" {
+			t.Errorf("%q: result mismatch (-want +got):\n%s", c.header, diff)
+		}
+	}
+
+	bad := []string{"bytes=0-9", "rows=-10", "rows=0-1,5-9", "rows=9-0", "rows=a-b"}
+	for _, header := range bad {
+		if _, err := parseRowRange(header); err == nil {
+			t.Errorf("%q: expected error", header)
+		}
+	}
+}
@@ -194,6 +194,8 @@ func extensionToMimeType(ext string) string {
 		return "text/csv"
 	case ".json":
 		return "application/json"
+	case ".ndjson":
+		return "application/x-ndjson"
 	case ".yaml":
 		return "application/x-yaml"
 	case ".xlsx":
@@ -210,6 +212,8 @@ func (h *DatasetHandlers) listHandler(w http.ResponseWriter, r *http.Request) {
 	args.OrderBy = "created"
 
 	args.Term = r.FormValue("term")
+	page := args.Page()
+	args.Limit = page.FetchLimit()
 
 	res := []dsref.VersionInfo{}
 	if err := h.List(&args, &res); err != nil {
@@ -217,11 +221,35 @@ func (h *DatasetHandlers) listHandler(w http.ResponseWriter, r *http.Request) {
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
-	if err := util.WritePageResponse(w, res, r, args.Page()); err != nil {
+
+	page, res = versionInfosPage(page, res)
+	// the number of refs is only the total when no filters are applied
+	if args.Term == "" && page.NextCursor != "" {
+		if count, err := h.repo.RefCount(); err == nil {
+			page.ResultCount = count
+		}
+	}
+	if err := util.WritePageResponse(w, res, r, page); err != nil {
 		log.Infof("error list datasests response: %s", err.Error())
 	}
 }
 
+// versionInfosPage sets the next cursor of a page of dataset list results,
+// keyed by dataset alias. res is read up to the page fetch limit & trimmed to
+// the page size
+func versionInfosPage(p util.Page, res []dsref.VersionInfo) (util.Page, []dsref.VersionInfo) {
+	n := len(res)
+	if n > p.Size {
+		res = res[:p.Size]
+	}
+	lastKey := ""
+	if len(res) > 0 {
+		last := res[len(res)-1]
+		lastKey = last.Username + "/" + last.Name
+	}
+	return p.WithResults(n, lastKey), res
+}
+
 func (h *DatasetHandlers) getHandler(w http.ResponseWriter, r *http.Request) {
 	args, err := parseGetReqArgs(r, strings.TrimPrefix(r.URL.Path, "/get/"))
 	if err != nil {
@@ -229,6 +257,12 @@ func (h *DatasetHandlers) getHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// raw downloads are always the body
+	if args.RawDownload && args.Params.Format != "zip" {
+		h.streamBody(w, r, args)
+		return
+	}
+
 	params := &args.Params
 	result := &lib.GetResult{}
 	err = h.Get(params, result)
@@ -256,6 +290,15 @@ func (h DatasetHandlers) bodyHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	params.Selector = "body"
 
+	// row ranges can only be requested for raw bodies
+	if r.Header.Get("Range") != "" {
+		args.RawDownload = true
+	}
+	if args.RawDownload && params.Format != "zip" {
+		h.streamBody(w, r, args)
+		return
+	}
+
 	result := &lib.GetResult{}
 	err = h.Get(params, result)
 	if err != nil {
@@ -379,6 +422,8 @@ func (h *DatasetHandlers) peerListHandler(w http.ResponseWriter, r *http.Request
 		}
 		p.Peername = ref.Peername
 	}
+	page := p.Page()
+	p.Limit = page.FetchLimit()
 
 	res := []dsref.VersionInfo{}
 	if err := h.List(&p, &res); err != nil {
@@ -386,7 +431,8 @@ func (h *DatasetHandlers) peerListHandler(w http.ResponseWriter, r *http.Request
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
-	if err := util.WritePageResponse(w, res, r, p.Page()); err != nil {
+	page, res = versionInfosPage(page, res)
+	if err := util.WritePageResponse(w, res, r, page); err != nil {
 		log.Infof("error list datasests response: %s", err.Error())
 	}
 }
@@ -468,6 +514,7 @@ func (h *DatasetHandlers) saveHandler(w http.ResponseWriter, r *http.Request) {
 		ShouldRender: !(r.FormValue("no_render") == "true"),
 		NewName:      r.FormValue("new") == "true",
 		BodyPath:     r.FormValue("bodypath"),
+		UploadID:     r.FormValue("upload"),
 		Recall:       r.FormValue("recall"),
 		Drop:         r.FormValue("drop"),
 
@@ -487,6 +534,10 @@ func (h *DatasetHandlers) saveHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.Save(p, res); err != nil {
+		if p.UploadID != "" && isUploadError(err) {
+			writeUploadError(w, err)
+			return
+		}
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -635,6 +686,15 @@ func parseGetReqArgs(r *http.Request, reqPath string) (*GetReqArgs, error) {
 		rawDownload = true
 	}
 
+	// Newline-delimited JSON is only available as a raw download
+	if arrayContains(r.Header["Accept"], "application/x-ndjson") {
+		if format != "" && format != "ndjson" {
+			return nil, util.NewAPIError(http.StatusBadRequest, fmt.Sprintf("format %q conflicts with header \"Accept: application/x-ndjson\"", format))
+		}
+		format = "ndjson"
+		rawDownload = true
+	}
+
 	// The body.csv suffix is a convenience feature to get the entire body as a csv
 	if hasBodyCsvSuffix {
 		format = "csv"
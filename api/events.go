@@ -0,0 +1,75 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/journal"
+	"github.com/qri-io/qri/lib"
+)
+
+// EventHandlers wraps EventMethods with http.HandlerFuncs
+type EventHandlers struct {
+	lib.EventMethods
+	readOnly bool
+}
+
+// NewEventHandlers allocates an EventHandlers pointer
+func NewEventHandlers(inst *lib.Instance, readOnly bool) *EventHandlers {
+	req := lib.NewEventMethods(inst)
+	return &EventHandlers{*req, readOnly}
+}
+
+// EventsHandler is the endpoint for paging through the event journal
+func (h *EventHandlers) EventsHandler(w http.ResponseWriter, r *http.Request) {
+	if h.readOnly {
+		readOnlyResponse(w, "/events")
+		return
+	}
+
+	switch r.Method {
+	case "OPTIONS":
+		util.EmptyOkHandler(w, r)
+	case "GET":
+		h.eventsHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+func (h *EventHandlers) eventsHandler(w http.ResponseWriter, r *http.Request) {
+	since, err := lib.ParseEventsSince(r.FormValue("since"))
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	r.ParseForm()
+	p := &lib.EventsParams{
+		ListParams: lib.ListParamsFromRequest(r),
+		Since:      since,
+		Types:      r.Form["type"],
+	}
+	page := p.Page()
+	p.Limit = page.FetchLimit()
+
+	res := []journal.Entry{}
+	if err := h.List(p, &res); err != nil {
+		if err == lib.ErrNoEventJournal {
+			util.WriteErrResponse(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+		util.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// journal entries are ordered oldest first, offsets are stable as events
+	// are added
+	n := len(res)
+	if n > page.Size {
+		res = res[:page.Size]
+	}
+	if err := util.WritePageResponse(w, res, r, page.WithResults(n, "")); err != nil {
+		log.Infof("error writing events response: %s", err.Error())
+	}
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/journal"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/logbook"
+)
+
+func TestEventsHandler(t *testing.T) {
+	ctx, done := context.WithCancel(context.Background())
+	defer done()
+
+	tmpDir, err := ioutil.TempDir("", "api_events_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// seed the journal with a few entries
+	ts := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	lines := ""
+	for i, et := range []string{"dataset:Init", "dataset:CommitChange", "dataset:CommitChange", "dataset:CommitChange"} {
+		lines += fmt.Sprintf(`{"seq":%d,"type":%q,"timestamp":%q}`+"\n", i+1, et, ts)
+	}
+	if err := ioutil.WriteFile(filepath.Join(tmpDir, journal.Filename), []byte(lines), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfigForTesting()
+	cfg.Repo.Type = "mem"
+	cfg.Repo.EventJournal = true
+	cfg.Filesystems = []qfs.Config{{Type: "mem"}}
+	inst, err := lib.NewInstance(ctx, tmpDir, lib.OptConfig(cfg), lib.OptLogbook(&logbook.Book{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewEventHandlers(inst, false)
+	get := func(url string) (int, []byte) {
+		w := httptest.NewRecorder()
+		h.EventsHandler(w, httptest.NewRequest("GET", url, nil))
+		body, _ := ioutil.ReadAll(w.Result().Body)
+		return w.Result().StatusCode, body
+	}
+
+	status, body := get("/events?type=dataset:CommitChange&pageSize=2&page=2")
+	if status != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", status, body)
+	}
+	res := struct {
+		Data       []journal.Entry
+		Pagination struct {
+			PrevURL string `json:"prevUrl"`
+		}
+	}{}
+	if err := json.Unmarshal(body, &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Data) != 1 || res.Data[0].Seq != 4 {
+		t.Errorf("expected second page to contain entry 4. got: %#v", res.Data)
+	}
+	if !strings.Contains(res.Pagination.PrevURL, "page=1") {
+		t.Errorf("expected a previous page url, got %q", res.Pagination.PrevURL)
+	}
+
+	// a page that holds exactly the remaining entries has no next page
+	page := struct {
+		Data       []journal.Entry
+		Pagination struct {
+			NextURL    string `json:"nextUrl"`
+			NextCursor string `json:"nextCursor"`
+		}
+	}{}
+	status, body = get("/events?type=dataset:CommitChange&pageSize=3")
+	if status != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", status, body)
+	}
+	if err := json.Unmarshal(body, &page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Data) != 3 {
+		t.Errorf("expected a full page of 3 entries, got %d", len(page.Data))
+	}
+	if page.Pagination.NextURL != "" || page.Pagination.NextCursor != "" {
+		t.Errorf("expected the last page to have no next page, got url %q & cursor %q", page.Pagination.NextURL, page.Pagination.NextCursor)
+	}
+
+	status, body = get("/events?type=dataset:CommitChange&pageSize=2")
+	if status != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", status, body)
+	}
+	page.Pagination.NextCursor = ""
+	if err := json.Unmarshal(body, &page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Data) != 2 || page.Pagination.NextCursor == "" {
+		t.Errorf("expected a page of 2 entries with a next cursor, got %d entries & cursor %q", len(page.Data), page.Pagination.NextCursor)
+	}
+
+	if status, body = get("/events?since=10m&type=dataset:CommitChange"); status != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", status, body)
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Data) != 0 {
+		t.Errorf("expected no events in the last 10 minutes, got %d", len(res.Data))
+	}
+
+	if status, _ = get("/events?since=yesterday"); status != http.StatusBadRequest {
+		t.Errorf("expected invalid since to be a bad request, got %d", status)
+	}
+}
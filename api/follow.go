@@ -0,0 +1,127 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/follow"
+	"github.com/qri-io/qri/lib"
+)
+
+// FollowHandlers wraps FollowMethods with http.HandlerFuncs
+type FollowHandlers struct {
+	lib.FollowMethods
+	readOnly bool
+}
+
+// NewFollowHandlers allocates a FollowHandlers pointer
+func NewFollowHandlers(inst *lib.Instance, readOnly bool) *FollowHandlers {
+	req := lib.NewFollowMethods(inst)
+	return &FollowHandlers{*req, readOnly}
+}
+
+// FollowHandler is the endpoint for listing, adding & removing follows
+func (h *FollowHandlers) FollowHandler(w http.ResponseWriter, r *http.Request) {
+	if h.readOnly {
+		readOnlyResponse(w, "/follow")
+		return
+	}
+
+	switch r.Method {
+	case "OPTIONS":
+		util.EmptyOkHandler(w, r)
+	case "GET":
+		h.followingHandler(w, r)
+	case "POST":
+		h.followHandler(w, r)
+	case "DELETE":
+		h.unfollowHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+// FeedHandler is the endpoint for listing new versions announced by follows
+func (h *FollowHandlers) FeedHandler(w http.ResponseWriter, r *http.Request) {
+	if h.readOnly {
+		readOnlyResponse(w, "/follow/feed")
+		return
+	}
+
+	switch r.Method {
+	case "OPTIONS":
+		util.EmptyOkHandler(w, r)
+	case "GET":
+		h.feedHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+func (h *FollowHandlers) followingHandler(w http.ResponseWriter, r *http.Request) {
+	args := lib.ListParamsFromRequest(r)
+	page := args.Page()
+	args.Limit = page.FetchLimit()
+	res := []follow.Follow{}
+	if err := h.Following(&args, &res); err != nil {
+		util.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	n := len(res)
+	if n > page.Size {
+		res = res[:page.Size]
+	}
+	util.WritePageResponse(w, res, r, page.WithResults(n, ""))
+}
+
+func (h *FollowHandlers) followHandler(w http.ResponseWriter, r *http.Request) {
+	p := &lib.FollowParams{}
+	if r.Header.Get("Content-Type") == "application/json" {
+		if err := json.NewDecoder(r.Body).Decode(p); err != nil {
+			util.WriteErrResponse(w, http.StatusBadRequest, err)
+			return
+		}
+	} else {
+		p.Ref = r.FormValue("ref")
+		p.AutoPull = util.ReqParamBool(r, "autopull", false)
+	}
+
+	res := &follow.Follow{}
+	if err := h.Follow(p, res); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+func (h *FollowHandlers) unfollowHandler(w http.ResponseWriter, r *http.Request) {
+	p := &lib.UnfollowParams{Ref: r.FormValue("ref")}
+	res := &follow.Follow{}
+	if err := h.Unfollow(p, res); err != nil {
+		if errors.Is(err, follow.ErrNotFound) {
+			util.WriteErrResponse(w, http.StatusNotFound, err)
+			return
+		}
+		util.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+func (h *FollowHandlers) feedHandler(w http.ResponseWriter, r *http.Request) {
+	args := lib.ListParamsFromRequest(r)
+	page := args.Page()
+	args.Limit = page.FetchLimit()
+	res := []follow.Item{}
+	if err := h.Feed(&args, &res); err != nil {
+		util.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	n := len(res)
+	if n > page.Size {
+		res = res[:page.Size]
+	}
+	util.WritePageResponse(w, res, r, page.WithResults(n, ""))
+}
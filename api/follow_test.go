@@ -0,0 +1,60 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qri-io/qri/follow"
+)
+
+func TestFollowHandlers(t *testing.T) {
+	run := NewAPITestRunner(t)
+	defer run.Delete()
+
+	fh := NewFollowHandlers(run.Inst, false)
+	call := func(h http.HandlerFunc, method, url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest(method, url, nil))
+		return w
+	}
+
+	w := call(fh.FollowHandler, "POST", "/follow?ref=peer/movies&autopull=true")
+	if w.Code != http.StatusOK {
+		t.Fatalf("follow: expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	res := struct{ Data follow.Follow }{}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Data.Alias() != "peer/movies" || !res.Data.AutoPull || res.Data.InitID == "" {
+		t.Errorf("unexpected follow: %#v", res.Data)
+	}
+
+	w = call(fh.FollowHandler, "GET", "/follow")
+	list := struct{ Data []follow.Follow }{}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Data) != 1 {
+		t.Errorf("expected 1 follow, got %d", len(list.Data))
+	}
+
+	w = call(fh.FeedHandler, "GET", "/follow/feed")
+	if w.Code != http.StatusOK {
+		t.Errorf("feed: expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w = call(fh.FollowHandler, "DELETE", "/follow?ref=peer/movies"); w.Code != http.StatusOK {
+		t.Errorf("unfollow: expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w = call(fh.FollowHandler, "DELETE", "/follow?ref=peer/movies"); w.Code != http.StatusNotFound {
+		t.Errorf("unfollowing twice: expected status 404, got %d", w.Code)
+	}
+
+	ro := NewFollowHandlers(run.Inst, true)
+	if w = call(ro.FollowHandler, "POST", "/follow?ref=peer/movies"); w.Code != http.StatusForbidden {
+		t.Errorf("read-only: expected status 403, got %d", w.Code)
+	}
+}
@@ -0,0 +1,564 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/lib"
+)
+
+// graphQLSchema describes the data available from the /graphql endpoint.
+// Every resolver calls lib methods, the same methods that back the rest of
+// the API
+const graphQLSchema = `
+schema {
+	query: Query
+}
+
+scalar JSON
+scalar Time
+
+type Query {
+	# a dataset, by reference. references with a path select a version
+	dataset(ref: String!): Dataset
+	# datasets in this node's collection
+	datasets(term: String, offset: Int, limit: Int): [Dataset!]!
+	# the profile of this node
+	me: Profile!
+	# a peer, by peername
+	peer(peername: String!): Profile
+	# peers this node knows about
+	peers(offset: Int, limit: Int, cached: Boolean): [Profile!]!
+	# remotes this node can push to & pull from
+	remotes: [Remote!]!
+}
+
+type Dataset {
+	ref: String!
+	username: String!
+	name: String!
+	profileID: String
+	path: String
+	commit: Commit
+	meta: JSON
+	structure: JSON
+	readme: String
+	transform: JSON
+	viz: JSON
+	# entries of the body, as JSON
+	body(offset: Int, limit: Int, all: Boolean): JSON
+	# statistics about the body
+	stats: JSON
+	# versions of the dataset, newest first
+	history(offset: Int, limit: Int): [Version!]!
+}
+
+type Commit {
+	title: String!
+	message: String
+	timestamp: Time
+	path: String
+}
+
+type Version {
+	ref: String!
+	username: String!
+	name: String!
+	profileID: String
+	path: String
+	commitTime: Time
+	commitTitle: String
+	commitMessage: String
+	bodySize: Int
+	bodyRows: Int
+	# the dataset at this version
+	dataset: Dataset!
+}
+
+type Profile {
+	id: String!
+	peername: String!
+	name: String
+	description: String
+	homeUrl: String
+	type: String
+	color: String
+	photo: String
+	thumb: String
+	twitter: String
+	online: Boolean!
+}
+
+type Remote {
+	name: String!
+	address: String!
+}
+`
+
+// graphQLMaxDepth limits how deeply queries can nest, stopping queries that
+// recurse through dataset history from doing unbounded work
+const graphQLMaxDepth = 8
+
+// GraphQLHandlers serves GraphQL queries
+type GraphQLHandlers struct {
+	schema *graphql.Schema
+}
+
+// NewGraphQLHandlers allocates a GraphQLHandlers pointer
+func NewGraphQLHandlers(inst *lib.Instance, readOnly bool) *GraphQLHandlers {
+	root := &queryResolver{
+		datasets: lib.NewDatasetMethods(inst),
+		log:      lib.NewLogMethods(inst),
+		peers:    lib.NewPeerMethods(inst),
+		profile:  lib.NewProfileMethods(inst),
+		config:   lib.NewConfigMethods(inst),
+		readOnly: readOnly,
+	}
+	schema := graphql.MustParseSchema(graphQLSchema, root, graphql.MaxDepth(graphQLMaxDepth))
+	return &GraphQLHandlers{schema: schema}
+}
+
+// graphQLRequest is the body of a GraphQL request
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// GraphQLHandler is the endpoint for GraphQL queries. Responses follow the
+// GraphQL spec instead of the API response envelope
+func (h *GraphQLHandlers) GraphQLHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "OPTIONS":
+		util.EmptyOkHandler(w, r)
+	case "GET", "POST":
+		h.graphQLHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+func (h *GraphQLHandlers) graphQLHandler(w http.ResponseWriter, r *http.Request) {
+	req := graphQLRequest{}
+	if r.Method == "POST" {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			util.WriteErrResponse(w, http.StatusBadRequest, err)
+			return
+		}
+	} else {
+		req.Query = r.FormValue("query")
+		req.OperationName = r.FormValue("operationName")
+		if vars := r.FormValue("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("parsing variables: %w", err))
+				return
+			}
+		}
+	}
+	if req.Query == "" {
+		util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("query is required"))
+		return
+	}
+
+	res := h.schema.Exec(r.Context(), req.Query, req.OperationName, req.Variables)
+	data, err := json.Marshal(res)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// JSON is a GraphQL scalar for values without a fixed shape, like dataset
+// components & bodies
+type JSON struct {
+	Value interface{}
+}
+
+// ImplementsGraphQLType maps JSON to the schema's JSON scalar
+func (JSON) ImplementsGraphQLType(name string) bool { return name == "JSON" }
+
+// UnmarshalGraphQL accepts any input value
+func (j *JSON) UnmarshalGraphQL(input interface{}) error {
+	j.Value = input
+	return nil
+}
+
+// MarshalJSON encodes the wrapped value
+func (j JSON) MarshalJSON() ([]byte, error) {
+	return json.Marshal(j.Value)
+}
+
+func jsonValue(v interface{}) *JSON {
+	if v == nil {
+		return nil
+	}
+	return &JSON{Value: v}
+}
+
+func intArg(arg *int32, def int) int {
+	if arg == nil {
+		return def
+	}
+	return int(*arg)
+}
+
+func timeValue(t interface{ IsZero() bool }, v graphql.Time) *graphql.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &v
+}
+
+func stringValue(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+type queryResolver struct {
+	datasets *lib.DatasetMethods
+	log      *lib.LogMethods
+	peers    *lib.PeerMethods
+	profile  *lib.ProfileMethods
+	config   *lib.ConfigMethods
+	readOnly bool
+}
+
+func (q *queryResolver) Dataset(args struct{ Ref string }) (*datasetResolver, error) {
+	ref, err := dsref.Parse(args.Ref)
+	if err != nil {
+		return nil, err
+	}
+	d := q.newDatasetResolver(dsref.NewVersionInfoFromRef(ref))
+	// load eagerly, so missing datasets resolve to an error instead of a
+	// dataset with empty fields
+	if _, err := d.load(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (q *queryResolver) Datasets(args struct {
+	Term          *string
+	Offset, Limit *int32
+}) ([]*datasetResolver, error) {
+	if q.readOnly {
+		return nil, readOnlyErr("datasets")
+	}
+	p := &lib.ListParams{
+		Offset: intArg(args.Offset, 0),
+		Limit:  intArg(args.Limit, lib.DefaultPageSize),
+	}
+	if args.Term != nil {
+		p.Term = *args.Term
+	}
+	res := []dsref.VersionInfo{}
+	if err := q.datasets.List(p, &res); err != nil {
+		return nil, err
+	}
+
+	ds := make([]*datasetResolver, len(res))
+	for i, vi := range res {
+		// listings describe the latest version, load it by name
+		vi.Path = ""
+		ds[i] = q.newDatasetResolver(vi)
+	}
+	return ds, nil
+}
+
+func (q *queryResolver) Me() (*profileResolver, error) {
+	in := true
+	res := &config.ProfilePod{}
+	if err := q.profile.GetProfile(&in, res); err != nil {
+		return nil, err
+	}
+	return &profileResolver{res}, nil
+}
+
+func (q *queryResolver) Peer(args struct{ Peername string }) (*profileResolver, error) {
+	if q.readOnly {
+		return nil, readOnlyErr("peer")
+	}
+	res := &config.ProfilePod{}
+	if err := q.peers.Info(&lib.PeerInfoParams{Peername: args.Peername}, res); err != nil {
+		return nil, err
+	}
+	return &profileResolver{res}, nil
+}
+
+func (q *queryResolver) Peers(args struct {
+	Offset, Limit *int32
+	Cached        *bool
+}) ([]*profileResolver, error) {
+	if q.readOnly {
+		return nil, readOnlyErr("peers")
+	}
+	p := &lib.PeerListParams{
+		Offset: intArg(args.Offset, 0),
+		Limit:  intArg(args.Limit, lib.DefaultPageSize),
+		Cached: true,
+	}
+	if args.Cached != nil {
+		p.Cached = *args.Cached
+	}
+	res := []*config.ProfilePod{}
+	if err := q.peers.List(p, &res); err != nil {
+		return nil, err
+	}
+
+	pros := make([]*profileResolver, len(res))
+	for i, pro := range res {
+		pros[i] = &profileResolver{pro}
+	}
+	return pros, nil
+}
+
+func (q *queryResolver) Remotes() ([]*remoteResolver, error) {
+	data := []byte{}
+	if err := q.config.GetConfig(&lib.GetConfigParams{Field: "remotes", Format: "json"}, &data); err != nil {
+		return nil, err
+	}
+	remotes := map[string]string{}
+	if err := json.Unmarshal(data, &remotes); err != nil {
+		return nil, err
+	}
+
+	res := make([]*remoteResolver, 0, len(remotes))
+	for name, addr := range remotes {
+		res = append(res, &remoteResolver{name: name, address: addr})
+	}
+	return res, nil
+}
+
+// readOnlyErr is the error resolvers return for fields that read-only servers
+// don't serve, matching the endpoints read-only mode blocks
+func readOnlyErr(field string) error {
+	return fmt.Errorf("qri server is in read-only mode, access to '%s' is forbidden", field)
+}
+
+func (q *queryResolver) newDatasetResolver(vi dsref.VersionInfo) *datasetResolver {
+	return &datasetResolver{q: q, ref: dsref.Ref{Username: vi.Username, Name: vi.Name, Path: vi.Path}}
+}
+
+// datasetResolver loads a dataset once, the first time a field that isn't
+// part of the reference is requested
+type datasetResolver struct {
+	q   *queryResolver
+	ref dsref.Ref
+
+	once     sync.Once
+	ds       *dataset.Dataset
+	resolved dsref.Ref
+	loadErr  error
+}
+
+func (d *datasetResolver) load() (*dataset.Dataset, error) {
+	d.once.Do(func() {
+		res := &lib.GetResult{}
+		if err := d.q.datasets.Get(&lib.GetParams{Refstr: d.ref.String()}, res); err != nil {
+			d.loadErr = err
+			return
+		}
+		d.ds = res.Dataset
+		if res.Ref != nil {
+			d.resolved = *res.Ref
+		}
+	})
+	return d.ds, d.loadErr
+}
+
+func (d *datasetResolver) getBytes(p lib.GetParams) ([]byte, error) {
+	p.Refstr = d.ref.String()
+	res := &lib.GetResult{}
+	if err := d.q.datasets.Get(&p, res); err != nil {
+		return nil, err
+	}
+	return res.Bytes, nil
+}
+
+func (d *datasetResolver) Ref() string      { return d.ref.String() }
+func (d *datasetResolver) Username() string { return d.ref.Username }
+func (d *datasetResolver) Name() string     { return d.ref.Name }
+
+func (d *datasetResolver) ProfileID() (*string, error) {
+	if _, err := d.load(); err != nil {
+		return nil, err
+	}
+	return stringValue(d.resolved.ProfileID), nil
+}
+
+func (d *datasetResolver) Path() (*string, error) {
+	if _, err := d.load(); err != nil {
+		return nil, err
+	}
+	return stringValue(d.resolved.Path), nil
+}
+
+func (d *datasetResolver) Commit() (*commitResolver, error) {
+	ds, err := d.load()
+	if err != nil || ds.Commit == nil {
+		return nil, err
+	}
+	return &commitResolver{ds.Commit}, nil
+}
+
+func (d *datasetResolver) Meta() (*JSON, error) {
+	ds, err := d.load()
+	if err != nil || ds.Meta == nil {
+		return nil, err
+	}
+	return jsonValue(ds.Meta), nil
+}
+
+func (d *datasetResolver) Structure() (*JSON, error) {
+	ds, err := d.load()
+	if err != nil || ds.Structure == nil {
+		return nil, err
+	}
+	return jsonValue(ds.Structure), nil
+}
+
+func (d *datasetResolver) Readme() (*string, error) {
+	ds, err := d.load()
+	if err != nil || ds.Readme == nil {
+		return nil, err
+	}
+	data, err := d.getBytes(lib.GetParams{Selector: "readme.script"})
+	if err != nil {
+		return nil, err
+	}
+	s := string(data)
+	return &s, nil
+}
+
+func (d *datasetResolver) Transform() (*JSON, error) {
+	ds, err := d.load()
+	if err != nil || ds.Transform == nil {
+		return nil, err
+	}
+	return jsonValue(ds.Transform), nil
+}
+
+func (d *datasetResolver) Viz() (*JSON, error) {
+	ds, err := d.load()
+	if err != nil || ds.Viz == nil {
+		return nil, err
+	}
+	return jsonValue(ds.Viz), nil
+}
+
+func (d *datasetResolver) Body(args struct {
+	Offset, Limit *int32
+	All           *bool
+}) (*JSON, error) {
+	if d.q.readOnly {
+		return nil, readOnlyErr("body")
+	}
+	p := lib.GetParams{
+		Selector: "body",
+		Format:   "json",
+		Offset:   intArg(args.Offset, 0),
+		Limit:    intArg(args.Limit, util.DefaultPageSize),
+		All:      args.All != nil && *args.All,
+	}
+	data, err := d.getBytes(p)
+	if err != nil {
+		return nil, err
+	}
+	return jsonValue(json.RawMessage(data)), nil
+}
+
+func (d *datasetResolver) Stats() (*JSON, error) {
+	data, err := d.getBytes(lib.GetParams{Selector: "stats"})
+	if err != nil {
+		return nil, err
+	}
+	return jsonValue(json.RawMessage(data)), nil
+}
+
+func (d *datasetResolver) History(args struct{ Offset, Limit *int32 }) ([]*versionResolver, error) {
+	p := &lib.LogParams{
+		Ref: dsref.Ref{Username: d.ref.Username, Name: d.ref.Name}.String(),
+		ListParams: lib.ListParams{
+			Offset: intArg(args.Offset, 0),
+			Limit:  intArg(args.Limit, lib.DefaultPageSize),
+		},
+	}
+	res := []lib.DatasetLogItem{}
+	if err := d.q.log.Log(p, &res); err != nil {
+		return nil, err
+	}
+
+	vs := make([]*versionResolver, len(res))
+	for i, item := range res {
+		vs[i] = &versionResolver{q: d.q, item: item}
+	}
+	return vs, nil
+}
+
+type commitResolver struct {
+	c *dataset.Commit
+}
+
+func (c *commitResolver) Title() string    { return c.c.Title }
+func (c *commitResolver) Message() *string { return stringValue(c.c.Message) }
+func (c *commitResolver) Path() *string    { return stringValue(c.c.Path) }
+func (c *commitResolver) Timestamp() *graphql.Time {
+	return timeValue(c.c.Timestamp, graphql.Time{Time: c.c.Timestamp})
+}
+
+type versionResolver struct {
+	q    *queryResolver
+	item lib.DatasetLogItem
+}
+
+func (v *versionResolver) Ref() string {
+	return dsref.Ref{Username: v.item.Username, Name: v.item.Name, Path: v.item.Path}.String()
+}
+func (v *versionResolver) Username() string       { return v.item.Username }
+func (v *versionResolver) Name() string           { return v.item.Name }
+func (v *versionResolver) ProfileID() *string     { return stringValue(v.item.ProfileID) }
+func (v *versionResolver) Path() *string          { return stringValue(v.item.Path) }
+func (v *versionResolver) CommitTitle() *string   { return stringValue(v.item.CommitTitle) }
+func (v *versionResolver) CommitMessage() *string { return stringValue(v.item.CommitMessage) }
+func (v *versionResolver) BodySize() *int32       { n := int32(v.item.BodySize); return &n }
+func (v *versionResolver) BodyRows() *int32       { n := int32(v.item.BodyRows); return &n }
+func (v *versionResolver) Dataset() *datasetResolver {
+	return v.q.newDatasetResolver(v.item.VersionInfo)
+}
+func (v *versionResolver) CommitTime() *graphql.Time {
+	return timeValue(v.item.CommitTime, graphql.Time{Time: v.item.CommitTime})
+}
+
+type profileResolver struct {
+	p *config.ProfilePod
+}
+
+func (p *profileResolver) ID() string           { return p.p.ID }
+func (p *profileResolver) Peername() string     { return p.p.Peername }
+func (p *profileResolver) Name() *string        { return stringValue(p.p.Name) }
+func (p *profileResolver) Description() *string { return stringValue(p.p.Description) }
+func (p *profileResolver) HomeUrl() *string     { return stringValue(p.p.HomeURL) }
+func (p *profileResolver) Type() *string        { return stringValue(p.p.Type) }
+func (p *profileResolver) Color() *string       { return stringValue(p.p.Color) }
+func (p *profileResolver) Photo() *string       { return stringValue(p.p.Photo) }
+func (p *profileResolver) Thumb() *string       { return stringValue(p.p.Thumb) }
+func (p *profileResolver) Twitter() *string     { return stringValue(p.p.Twitter) }
+func (p *profileResolver) Online() bool         { return p.p.Online }
+
+type remoteResolver struct {
+	name, address string
+}
+
+func (r *remoteResolver) Name() string    { return r.name }
+func (r *remoteResolver) Address() string { return r.address }
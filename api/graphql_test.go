@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestGraphQLHandler(t *testing.T) {
+	run := NewAPITestRunner(t)
+	defer run.Delete()
+
+	ds := run.BuildDataset("test_ds")
+	run.SaveDataset(ds, "testdata/cities/data.csv")
+	h := NewGraphQLHandlers(run.Inst, false)
+
+	query := func(req *http.Request) map[string]interface{} {
+		w := httptest.NewRecorder()
+		h.GraphQLHandler(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		res := map[string]interface{}{}
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("decoding response %q: %s", w.Body.String(), err)
+		}
+		if res["errors"] != nil {
+			t.Fatalf("unexpected errors: %v", res["errors"])
+		}
+		return res["data"].(map[string]interface{})
+	}
+
+	body := `{"query":"query List($limit: Int) { me { peername } datasets(limit: $limit) { ref body(limit: 2) history { commitTitle bodyRows } } }","variables":{"limit":10}}`
+	data := query(httptest.NewRequest("POST", "/graphql", strings.NewReader(body)))
+
+	if peername := data["me"].(map[string]interface{})["peername"]; peername != "peer" {
+		t.Errorf("expected me.peername to be %q, got %v", "peer", peername)
+	}
+	var d map[string]interface{}
+	for _, item := range data["datasets"].([]interface{}) {
+		if item.(map[string]interface{})["ref"] == "peer/test_ds" {
+			d = item.(map[string]interface{})
+		}
+	}
+	if d == nil {
+		t.Fatalf("expected datasets to include peer/test_ds, got: %v", data["datasets"])
+	}
+	if rows := d["body"].([]interface{}); len(rows) != 2 {
+		t.Errorf("expected 2 body rows, got %d", len(rows))
+	}
+	history := d["history"].([]interface{})
+	if len(history) != 1 {
+		t.Fatalf("expected 1 version, got %d", len(history))
+	}
+	if title := history[0].(map[string]interface{})["commitTitle"]; title == nil || title == "" {
+		t.Errorf("expected version to have a commit title, got %v", title)
+	}
+
+	// GET requests read the query from the URL
+	q := url.Values{"query": {`{ dataset(ref: "peer/test_ds") { name structure } }`}}
+	data = query(httptest.NewRequest("GET", "/graphql?"+q.Encode(), nil))
+	dsData := data["dataset"].(map[string]interface{})
+	if dsData["name"] != "test_ds" {
+		t.Errorf("expected name %q, got %v", "test_ds", dsData["name"])
+	}
+	if st, ok := dsData["structure"].(map[string]interface{}); !ok || st["format"] != "csv" {
+		t.Errorf("expected a csv structure, got %v", dsData["structure"])
+	}
+
+	// read-only instances don't serve bodies
+	ro := NewGraphQLHandlers(run.Inst, true)
+	w := httptest.NewRecorder()
+	ro.GraphQLHandler(w, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query":"{ dataset(ref: \"peer/test_ds\") { body } }"}`)))
+	if !strings.Contains(w.Body.String(), "read-only") {
+		t.Errorf("expected read-only body error, got: %s", w.Body.String())
+	}
+	for _, field := range []string{"datasets { name }", "peers { peername }", `peer(peername: \"peer\") { peername }`} {
+		w = httptest.NewRecorder()
+		ro.GraphQLHandler(w, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query":"{ `+field+` }"}`)))
+		if !strings.Contains(w.Body.String(), "read-only") {
+			t.Errorf("expected read-only error querying %s, got: %s", field, w.Body.String())
+		}
+	}
+
+	w = httptest.NewRecorder()
+	h.GraphQLHandler(w, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected missing query to return 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/limits"
+	"github.com/qri-io/qri/remote"
+)
+
+// requestLimits rate limits API requests & writes access logs, configured by
+// the api section of config
+type requestLimits struct {
+	ip      *limits.Limiter
+	profile *limits.Limiter
+
+	logLk     sync.Mutex
+	accessLog io.Writer
+	// logFile is the access log file, nil when logging to stdout or stderr
+	logFile *os.File
+}
+
+func newRequestLimits(cfg *config.API) *requestLimits {
+	rl := &requestLimits{}
+	if cfg == nil {
+		return rl
+	}
+	rl.ip = limits.NewLimiter(cfg.RateLimit, cfg.RateLimitBurst)
+	rl.profile = limits.NewLimiter(cfg.ProfileRateLimit, cfg.RateLimitBurst)
+
+	switch cfg.AccessLog {
+	case "":
+	case "stdout":
+		rl.accessLog = os.Stdout
+	case "stderr":
+		rl.accessLog = os.Stderr
+	default:
+		// the file stays open until the limits are closed
+		f, err := os.OpenFile(cfg.AccessLog, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			log.Errorf("opening access log: %s", err)
+			break
+		}
+		rl.accessLog = f
+		rl.logFile = f
+	}
+	return rl
+}
+
+// Close closes the access log file. Requests handled after closing aren't
+// logged
+func (rl *requestLimits) Close() error {
+	rl.logLk.Lock()
+	defer rl.logLk.Unlock()
+	if rl.logFile == nil {
+		return nil
+	}
+	err := rl.logFile.Close()
+	rl.accessLog = ioutil.Discard
+	rl.logFile = nil
+	return err
+}
+
+// requestIP is the address a request came from. Forwarding headers aren't
+// trusted, clients could set them to dodge rate limits
+func requestIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// requestProfileID is the verified profile making a remote request. Remote
+// clients sign requests with their profile key, dsync in query params & other
+// requests in headers. Requests without a valid signature return an empty ID,
+// leaving them to IP limits, so clients can't spend another profile's limits
+func requestProfileID(r *http.Request) string {
+	params := map[string]string{"path": r.URL.Path}
+	for _, key := range []string{"pid", "pubkey", "timestamp", "signature"} {
+		params[key] = r.Header.Get(key)
+	}
+	if params["signature"] == "" {
+		// dsync signs the path of the dataset being synced
+		q := r.URL.Query()
+		for key := range params {
+			params[key] = q.Get(key)
+		}
+	}
+	if params["signature"] == "" {
+		return ""
+	}
+	pid, err := remote.VerifiedProfileID(params)
+	if err != nil {
+		log.Debugf("verifying request profile: %s", err)
+		return ""
+	}
+	return pid.String()
+}
+
+// rateLimit responds with 429 Too Many Requests to clients over their limit
+func (rl *requestLimits) rateLimit(handler http.HandlerFunc) http.HandlerFunc {
+	if rl.ip == nil && rl.profile == nil {
+		return handler
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		ok, wait := rl.ip.Allow(requestIP(r))
+		if pid := requestProfileID(r); ok && pid != "" {
+			ok, wait = rl.profile.Allow(pid)
+		}
+		if !ok {
+			w.Header().Set("Retry-After", fmt.Sprintf("%d", int(math.Ceil(wait.Seconds()))))
+			util.WriteErrResponse(w, http.StatusTooManyRequests, fmt.Errorf("rate limit exceeded, retry in %s", wait.Round(time.Second)))
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// accessLogEntry is a line of the access log
+type accessLogEntry struct {
+	Time      time.Time `json:"time"`
+	Method    string    `json:"method"`
+	Route     string    `json:"route"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	BytesIn   int64     `json:"bytesIn"`
+	BytesOut  int64     `json:"bytesOut"`
+	LatencyMs float64   `json:"latencyMs"`
+	IP        string    `json:"ip"`
+	ProfileID string    `json:"profileID,omitempty"`
+}
+
+// logAccess writes an access log entry for each request to route
+func (rl *requestLimits) logAccess(route string, handler http.HandlerFunc) http.HandlerFunc {
+	if rl.accessLog == nil {
+		return handler
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rw := &countingResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		body := &countingReader{r: r.Body}
+		if r.Body != nil {
+			r.Body = body
+		}
+
+		handler(rw, r)
+
+		rl.writeEntry(accessLogEntry{
+			Time:      start.UTC(),
+			Method:    r.Method,
+			Route:     route,
+			Path:      r.URL.Path,
+			Status:    rw.status,
+			BytesIn:   body.n,
+			BytesOut:  rw.n,
+			LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+			IP:        requestIP(r),
+			ProfileID: requestProfileID(r),
+		})
+	}
+}
+
+func (rl *requestLimits) writeEntry(e accessLogEntry) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		log.Errorf("encoding access log entry: %s", err)
+		return
+	}
+	rl.logLk.Lock()
+	defer rl.logLk.Unlock()
+	if _, err := rl.accessLog.Write(append(data, '\n')); err != nil {
+		log.Errorf("writing access log: %s", err)
+	}
+}
+
+// countingResponseWriter records the status & number of bytes of a response
+type countingResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	n           int64
+}
+
+func (w *countingResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *countingResponseWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// Flush passes flushes through, streaming endpoints rely on them
+func (w *countingResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// countingReader records the number of bytes read from a request body
+type countingReader struct {
+	r io.ReadCloser
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingReader) Close() error {
+	return c.r.Close()
+}
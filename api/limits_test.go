@@ -0,0 +1,143 @@
+package api
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/qri-io/qri/config"
+	testPeers "github.com/qri-io/qri/config/test"
+	"github.com/qri-io/qri/identity"
+)
+
+func TestRequestLimits(t *testing.T) {
+	dir, err := ioutil.TempDir("", "api_request_limits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	logPath := filepath.Join(dir, "access.log")
+
+	rl := newRequestLimits(&config.API{
+		RateLimit:        60,
+		ProfileRateLimit: 60,
+		RateLimitBurst:   1,
+		AccessLog:        logPath,
+	})
+	h := rl.logAccess("/get/", rl.rateLimit(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		w.Write([]byte("ok"))
+	}))
+
+	pk := testPeers.GetTestPeerInfo(0).PrivKey
+	pid, err := identity.KeyIDFromPriv(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	call := func(ip, url, body string, signer crypto.PrivKey) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", url, strings.NewReader(body))
+		req.RemoteAddr = ip + ":1234"
+		if signer != nil {
+			signRequest(t, req, signer)
+		}
+		w := httptest.NewRecorder()
+		h(w, req)
+		return w
+	}
+
+	if w := call("10.0.0.1", "/get/peer/ds", "hello", nil); w.Code != http.StatusOK {
+		t.Errorf("expected first request to be allowed, got status %d", w.Code)
+	}
+	w := call("10.0.0.1", "/get/peer/ds", "", nil)
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected second request from the same ip to be limited, got status %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "1" {
+		t.Errorf("expected Retry-After of 1 second, got %q", w.Header().Get("Retry-After"))
+	}
+
+	// profiles are limited across addresses
+	if w := call("10.0.0.2", "/get/peer/ds", "", pk); w.Code != http.StatusOK {
+		t.Errorf("expected first request from a profile to be allowed, got status %d", w.Code)
+	}
+	if w := call("10.0.0.3", "/get/peer/ds", "", pk); w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected second request from the same profile to be limited, got status %d", w.Code)
+	}
+	// unsigned claims to be a profile only count against the address
+	if w := call("10.0.0.4", "/get/peer/ds?pid="+pid, "", nil); w.Code != http.StatusOK {
+		t.Errorf("expected an unverified profile claim not to be limited by profile, got status %d", w.Code)
+	}
+
+	if err := rl.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if w := call("10.0.0.5", "/get/peer/ds", "", nil); w.Code != http.StatusOK {
+		t.Errorf("expected requests after closing to be allowed, got status %d", w.Code)
+	}
+
+	f, err := os.Open(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	entries := []accessLogEntry{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		e := accessLogEntry{}
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatalf("decoding access log line %q: %s", sc.Text(), err)
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) != 5 {
+		t.Fatalf("expected 5 access log entries, got %d", len(entries))
+	}
+	first := entries[0]
+	if first.Route != "/get/" || first.Path != "/get/peer/ds" || first.Method != "POST" {
+		t.Errorf("unexpected route details: %+v", first)
+	}
+	if first.Status != http.StatusOK || first.BytesIn != 5 || first.BytesOut != 2 || first.IP != "10.0.0.1" {
+		t.Errorf("unexpected request details: %+v", first)
+	}
+	if entries[1].Status != http.StatusTooManyRequests {
+		t.Errorf("expected limited request to be logged with status 429, got %d", entries[1].Status)
+	}
+	if entries[3].ProfileID != pid {
+		t.Errorf("expected profile ID to be logged, got %q", entries[3].ProfileID)
+	}
+	if entries[4].ProfileID != "" {
+		t.Errorf("expected unverified profile ID not to be logged, got %q", entries[4].ProfileID)
+	}
+}
+
+// signRequest signs a request in headers, the way remote clients do
+func signRequest(t *testing.T, r *http.Request, pk crypto.PrivKey) {
+	pid, err := identity.KeyIDFromPriv(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := pk.GetPublic().Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := fmt.Sprintf("%d", time.Now().Unix())
+	sig, err := pk.Sign([]byte(fmt.Sprintf("%s.%s.%s", now, pid, r.URL.Path)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("timestamp", now)
+	r.Header.Set("pid", pid)
+	r.Header.Set("signature", base64.StdEncoding.EncodeToString(sig))
+	r.Header.Set("pubkey", base64.StdEncoding.EncodeToString(pub))
+}
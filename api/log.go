@@ -54,6 +54,8 @@ func (h *LogHandlers) logHandler(w http.ResponseWriter, r *http.Request) {
 
 	lp := lib.ListParamsFromRequest(r)
 	lp.Peername = args.Peername
+	page := lp.Page()
+	lp.Limit = page.FetchLimit()
 
 	local := r.FormValue("local") == "true"
 	remoteName := r.FormValue("remote")
@@ -89,7 +91,15 @@ func (h *LogHandlers) logHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if err := util.WritePageResponse(w, res, r, params.Page()); err != nil {
+	n := len(res)
+	if n > page.Size {
+		res = res[:page.Size]
+	}
+	lastKey := ""
+	if len(res) > 0 {
+		lastKey = res[len(res)-1].Path
+	}
+	if err := util.WritePageResponse(w, res, r, page.WithResults(n, lastKey)); err != nil {
 		log.Infof("error list dataset history response: %s", err.Error())
 	}
 	return
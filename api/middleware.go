@@ -38,8 +38,9 @@ func (s *Server) addCORSHeaders(w http.ResponseWriter, r *http.Request) {
 	for _, o := range s.Config().API.AllowedOrigins {
 		if origin == o {
 			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Access-Control-Allow-Methods", "GET, PUT, POST, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type,Authorization")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, PUT, PATCH, POST, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type,Authorization,"+uploadOffsetHeader+","+uploadChecksumHeader)
+			w.Header().Set("Access-Control-Expose-Headers", uploadOffsetHeader+","+uploadChecksumHeader)
 			w.Header().Set("Access-Control-Allow-Credentials", "true")
 			return
 		}
@@ -0,0 +1,437 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/jsonschema"
+	apiutil "github.com/qri-io/qri/api/util"
+	"gopkg.in/yaml.v2"
+)
+
+// OpenAPISpecFilename is the name of the generated OpenAPI spec file, stored
+// in the api package directory
+const OpenAPISpecFilename = "open_api_3.yaml"
+
+// OpenAPISpec generates an OpenAPI 3 document describing Routes
+func OpenAPISpec() ([]byte, error) {
+	doc := openAPIDoc{
+		OpenAPI: "3.0.0",
+		Info: openAPIInfo{
+			Title:       "Qri API",
+			Description: "Qri API used to communicate with a Qri node. This file is generated from the api route table, do not edit by hand. Regenerate with: go run ./cmd/generate openapi",
+			Version:     APIVersion,
+		},
+		Paths: map[string]map[string]openAPIOperation{},
+		Components: map[string]interface{}{
+			"schemas": map[string]interface{}{
+				"Meta": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"code":    map[string]interface{}{"type": "integer"},
+						"error":   map[string]interface{}{"type": "string"},
+						"message": map[string]interface{}{"type": "string"},
+					},
+				},
+				"Pagination": JSONSchema(apiutil.Page{}),
+			},
+			"responses": map[string]interface{}{
+				"Error": map[string]interface{}{
+					"description": "error",
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{
+							"schema": map[string]interface{}{
+								"type": "object",
+								"properties": map[string]interface{}{
+									"meta": map[string]interface{}{"$ref": "#/components/schemas/Meta"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, r := range Routes {
+		path := r.Path
+		if r.PathParams != "" {
+			path = r.Path + r.PathParams
+		}
+		if _, ok := doc.Paths[path]; ok {
+			return nil, fmt.Errorf("duplicate route path %q", path)
+		}
+
+		ops := map[string]openAPIOperation{}
+		for _, method := range r.Methods {
+			ops[strings.ToLower(method)] = r.operation(method, path)
+		}
+		doc.Paths[path] = ops
+	}
+
+	return yaml.Marshal(doc)
+}
+
+type openAPIDoc struct {
+	OpenAPI    string                                 `yaml:"openapi"`
+	Info       openAPIInfo                            `yaml:"info"`
+	Paths      map[string]map[string]openAPIOperation `yaml:"paths"`
+	Components map[string]interface{}                 `yaml:"components"`
+}
+
+type openAPIInfo struct {
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	Version     string `yaml:"version"`
+}
+
+type openAPIOperation struct {
+	Summary     string                 `yaml:"summary"`
+	OperationID string                 `yaml:"operationId"`
+	Deprecated  bool                   `yaml:"deprecated,omitempty"`
+	Parameters  []interface{}          `yaml:"parameters,omitempty"`
+	RequestBody interface{}            `yaml:"requestBody,omitempty"`
+	Responses   map[string]interface{} `yaml:"responses"`
+}
+
+func (r Route) operation(method, path string) openAPIOperation {
+	op := openAPIOperation{
+		Summary:     r.Summary,
+		OperationID: operationID(method, path),
+		Deprecated:  r.Deprecated,
+		Responses: map[string]interface{}{
+			"default": map[string]interface{}{"$ref": "#/components/responses/Error"},
+		},
+	}
+
+	for _, name := range pathParamNames(path) {
+		op.Parameters = append(op.Parameters, map[string]interface{}{
+			"name":     name,
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]interface{}{"type": "string"},
+		})
+	}
+
+	if r.Paginated {
+		op.Parameters = append(op.Parameters,
+			map[string]interface{}{"name": "cursor", "in": "query", "description": "opaque cursor for the next page, from pagination.nextCursor", "schema": map[string]interface{}{"type": "string"}},
+			map[string]interface{}{"name": "page", "in": "query", "schema": map[string]interface{}{"type": "integer"}},
+			map[string]interface{}{"name": "pageSize", "in": "query", "schema": map[string]interface{}{"type": "integer"}},
+		)
+	}
+
+	// GET requests don't have bodies in OpenAPI
+	if r.Params != nil && method != http.MethodGet {
+		op.RequestBody = map[string]interface{}{
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": JSONSchema(r.Params),
+				},
+			},
+		}
+	}
+
+	ok := map[string]interface{}{"description": "OK"}
+	if r.Result != nil {
+		props := map[string]interface{}{
+			"meta": map[string]interface{}{"$ref": "#/components/schemas/Meta"},
+			"data": JSONSchema(r.Result),
+		}
+		if r.Paginated {
+			props["pagination"] = map[string]interface{}{"$ref": "#/components/schemas/Pagination"}
+		}
+		ok["content"] = map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": map[string]interface{}{
+					"type":       "object",
+					"properties": props,
+				},
+			},
+		}
+	}
+	op.Responses["200"] = ok
+
+	return op
+}
+
+// operationID creates an identifier from a method and path, for example:
+// POST /save/{username}/{name} becomes postSaveUsernameName
+func operationID(method, path string) string {
+	id := strings.ToLower(method)
+	for _, seg := range strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == '{' || r == '}'
+	}) {
+		id += strings.Title(seg)
+	}
+	return id
+}
+
+func pathParamNames(path string) (names []string) {
+	for _, seg := range strings.Split(path, "/") {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			names = append(names, seg[1:len(seg)-1])
+		}
+	}
+	return names
+}
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// schemaOverride creates the schema of a type that can't be read from its
+// struct fields. fields is the schema of the type's fields, nil for types that
+// aren't structs
+type schemaOverride func(fields map[string]interface{}) map[string]interface{}
+
+// schemaOverrides describes types that decode themselves with UnmarshalJSON.
+// Unmarshalers without an override are described by an empty schema, which
+// accepts any value
+var schemaOverrides = map[reflect.Type]schemaOverride{
+	// dataset components decode either a path string or an object
+	reflect.TypeOf(dataset.Dataset{}):           pathOrStructSchema,
+	reflect.TypeOf(dataset.Commit{}):            pathOrStructSchema,
+	reflect.TypeOf(dataset.Meta{}):              pathOrStructSchema,
+	reflect.TypeOf(dataset.Readme{}):            pathOrStructSchema,
+	reflect.TypeOf(dataset.Structure{}):         pathOrStructSchema,
+	reflect.TypeOf(dataset.Transform{}):         pathOrStructSchema,
+	reflect.TypeOf(dataset.TransformResource{}): pathOrStructSchema,
+	reflect.TypeOf(dataset.Viz{}):               pathOrStructSchema,
+
+	reflect.TypeOf(dataset.DataFormat(0)): stringSchema,
+	reflect.TypeOf(dataset.Kind("")):      stringSchema,
+}
+
+func pathOrStructSchema(fields map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"oneOf": []interface{}{
+			map[string]interface{}{"type": "string", "description": "path"},
+			fields,
+		},
+	}
+}
+
+func stringSchema(map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": "string"}
+}
+
+// JSONSchema generates a JSON schema describing the JSON encoding of a value,
+// following the rules of the encoding/json package. Schemas are compatible
+// with OpenAPI 3
+func JSONSchema(v interface{}) map[string]interface{} {
+	return schemaForType(reflect.TypeOf(v), map[reflect.Type]bool{})
+}
+
+func schemaForType(t reflect.Type, seen map[reflect.Type]bool) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	if override, ok := schemaOverrides[t]; ok {
+		var fields map[string]interface{}
+		if t.Kind() == reflect.Struct {
+			fields = structSchema(t, seen)
+		}
+		return override(fields)
+	}
+	// types that decode themselves may accept more than one shape, don't
+	// constrain them
+	if t.Implements(unmarshalerType) || reflect.PtrTo(t).Implements(unmarshalerType) {
+		return map[string]interface{}{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// byte slices are base64 encoded
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": schemaForType(t.Elem(), seen)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaForType(t.Elem(), seen)}
+	case reflect.Struct:
+		return structSchema(t, seen)
+	}
+
+	// interfaces & anything else can be any value
+	return map[string]interface{}{}
+}
+
+// structSchema describes the fields of a struct type
+func structSchema(t reflect.Type, seen map[reflect.Type]bool) map[string]interface{} {
+	if seen[t] {
+		// recursive types aren't described past the first level
+		return map[string]interface{}{"type": "object"}
+	}
+	seen[t] = true
+	defer delete(seen, t)
+
+	props := map[string]interface{}{}
+	addStructProperties(t, props, seen)
+	s := map[string]interface{}{"type": "object"}
+	if len(props) > 0 {
+		s["properties"] = props
+	}
+	return s
+}
+
+func addStructProperties(t reflect.Type, props map[string]interface{}, seen map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if idx := strings.Index(tag, ","); idx != -1 {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		// untagged embedded structs have their fields promoted
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			addStructProperties(ft, props, seen)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		if strings.Contains(opts, "string") {
+			props[name] = map[string]interface{}{"type": "string"}
+			continue
+		}
+		props[name] = schemaForType(f.Type, seen)
+	}
+}
+
+// validateRequest rejects requests to paginated routes with invalid cursors,
+// and requests with JSON bodies that don't match the schema of the route's
+// params
+func validateRequest(path string, handler http.HandlerFunc) http.HandlerFunc {
+	r, ok := route(path)
+	if !ok {
+		return handler
+	}
+	if r.Paginated {
+		handler = validateCursor(handler)
+	}
+	if r.Params == nil {
+		return handler
+	}
+
+	data, err := json.Marshal(JSONSchema(r.Params))
+	if err != nil {
+		log.Errorf("encoding %s request schema: %s", path, err)
+		return handler
+	}
+	schema := &jsonschema.Schema{}
+	if err := json.Unmarshal(data, schema); err != nil {
+		log.Errorf("compiling %s request schema: %s", path, err)
+		return handler
+	}
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Body == nil || !strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+			handler(w, req)
+			return
+		}
+
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			apiutil.WriteErrResponse(w, http.StatusBadRequest, err)
+			return
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		if err := validateJSON(req.Context(), schema, body); err != nil {
+			apiutil.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+		handler(w, req)
+	}
+}
+
+func validateCursor(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, err := apiutil.CursorFromRequest(r); err != nil {
+			apiutil.WriteErrResponse(w, http.StatusBadRequest, err)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// validateJSON checks a JSON document against a schema. null values are
+// ignored, matching how encoding/json leaves fields unset
+func validateJSON(ctx context.Context, schema *jsonschema.Schema, data []byte) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	doc = dropNulls(doc)
+	if doc == nil {
+		return nil
+	}
+
+	keyErrs := schema.Validate(ctx, doc).Errs
+	if keyErrs == nil || len(*keyErrs) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(*keyErrs))
+	for _, e := range *keyErrs {
+		msgs = append(msgs, e.Error())
+	}
+	sort.Strings(msgs)
+	return fmt.Errorf("%s", strings.Join(msgs, ", "))
+}
+
+func dropNulls(v interface{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for key, val := range x {
+			if val == nil {
+				delete(x, key)
+				continue
+			}
+			x[key] = dropNulls(val)
+		}
+	case []interface{}:
+		for i, val := range x {
+			x[i] = dropNulls(val)
+		}
+	}
+	return v
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOpenAPISpecUpToDate(t *testing.T) {
+	prev := APIVersion
+	APIVersion = "test_version"
+	defer func() { APIVersion = prev }()
+
+	got, err := OpenAPISpec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect, err := ioutil.ReadFile(OpenAPISpecFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the spec is regenerated on release, a version bump alone doesn't make it
+	// out of date
+	if diff := cmp.Diff(withoutInfoVersion(string(expect)), withoutInfoVersion(string(got))); diff != "" {
+		t.Errorf("%s is out of date, regenerate it with `go run ./cmd/generate openapi` (-want +got):\n%s", OpenAPISpecFilename, diff)
+	}
+}
+
+// withoutInfoVersion drops the info.version line from an OpenAPI spec
+func withoutInfoVersion(spec string) string {
+	lines := strings.Split(spec, "\n")
+	inInfo := false
+	for i, line := range lines {
+		if !strings.HasPrefix(line, " ") {
+			inInfo = line == "info:"
+			continue
+		}
+		if inInfo && strings.HasPrefix(line, "  version:") {
+			return strings.Join(append(lines[:i:i], lines[i+1:]...), "\n")
+		}
+	}
+	return spec
+}
+
+func TestRoutesDocumented(t *testing.T) {
+	run := NewAPITestRunner(t)
+	defer run.Delete()
+
+	m := newServerRoutes(New(run.Inst))
+	if len(m.paths) == 0 {
+		t.Fatal("expected routes to be registered")
+	}
+	for _, path := range m.paths {
+		if _, ok := route(path); !ok {
+			t.Errorf("route %q isn't documented. add it to api.Routes", path)
+		}
+	}
+
+	for _, r := range Routes {
+		if r.Summary == "" || len(r.Methods) == 0 {
+			t.Errorf("route %q must have a summary and methods", r.Path)
+		}
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	h := validateRequest("/rename", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	cases := []struct {
+		contentType string
+		body        string
+		status      int
+	}{
+		{"application/json", `{"Current":"me/a","Next":"me/b"}`, http.StatusOK},
+		{"application/json", `{"Current":null}`, http.StatusOK},
+		{"application/json", ``, http.StatusOK},
+		{"application/json", `{"Current":5}`, http.StatusBadRequest},
+		{"application/json", `not json`, http.StatusBadRequest},
+		// only JSON bodies are validated
+		{"application/x-www-form-urlencoded", `current=me/a`, http.StatusOK},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest("POST", "/rename", strings.NewReader(c.body))
+		req.Header.Set("Content-Type", c.contentType)
+		w := httptest.NewRecorder()
+		h(w, req)
+		if w.Code != c.status {
+			t.Errorf("%s %q: expected status %d, got %d: %s", c.contentType, c.body, c.status, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestSaveRequestSchema(t *testing.T) {
+	r, ok := route("/save")
+	if !ok {
+		t.Fatal("expected /save to be documented")
+	}
+	if schema := JSONSchema(r.Params); len(schema) == 0 {
+		t.Fatal("expected /save request schema to describe a dataset, got an empty schema")
+	}
+
+	h := validateRequest("/save", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	cases := []struct {
+		body   string
+		status int
+	}{
+		{`{"name":"ds","meta":{"title":"title","keywords":["a"]},"body":[[1,2]]}`, http.StatusOK},
+		{`{"meta":"/ipfs/QmMeta","structure":{"format":"csv"}}`, http.StatusOK},
+		{`"/ipfs/QmDataset"`, http.StatusOK},
+		{`{"name":5}`, http.StatusBadRequest},
+		{`{"meta":{"keywords":"a"}}`, http.StatusBadRequest},
+		{`{"structure":5}`, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest("POST", "/save", strings.NewReader(c.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		h(w, req)
+		if w.Code != c.status {
+			t.Errorf("%q: expected status %d, got %d: %s", c.body, c.status, w.Code, w.Body.String())
+		}
+	}
+}
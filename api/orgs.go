@@ -0,0 +1,160 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/logbook"
+)
+
+// OrgHandlers wraps OrgMethods with http.HandlerFuncs
+type OrgHandlers struct {
+	lib.OrgMethods
+	readOnly bool
+}
+
+// NewOrgHandlers allocates an OrgHandlers pointer
+func NewOrgHandlers(inst *lib.Instance, readOnly bool) *OrgHandlers {
+	req := lib.NewOrgMethods(inst)
+	return &OrgHandlers{*req, readOnly}
+}
+
+// OrgsHandler is the endpoint for listing & creating organizations
+func (h *OrgHandlers) OrgsHandler(w http.ResponseWriter, r *http.Request) {
+	if h.readOnly {
+		readOnlyResponse(w, "/orgs")
+		return
+	}
+
+	switch r.Method {
+	case "OPTIONS":
+		util.EmptyOkHandler(w, r)
+	case "GET":
+		h.listHandler(w, r)
+	case "POST":
+		h.createHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+// MembersHandler is the endpoint for showing, adding & removing members of an
+// organization
+func (h *OrgHandlers) MembersHandler(w http.ResponseWriter, r *http.Request) {
+	if h.readOnly {
+		readOnlyResponse(w, "/orgs/members")
+		return
+	}
+
+	switch r.Method {
+	case "OPTIONS":
+		util.EmptyOkHandler(w, r)
+	case "GET":
+		h.getHandler(w, r)
+	case "POST":
+		h.addMemberHandler(w, r)
+	case "DELETE":
+		h.removeMemberHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+func (h *OrgHandlers) listHandler(w http.ResponseWriter, r *http.Request) {
+	args := lib.ListParamsFromRequest(r)
+	page := args.Page()
+	args.Limit = page.FetchLimit()
+	res := []logbook.Org{}
+	if err := h.List(&args, &res); err != nil {
+		util.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	n := len(res)
+	if n > page.Size {
+		res = res[:page.Size]
+	}
+	util.WritePageResponse(w, res, r, page.WithResults(n, ""))
+}
+
+func (h *OrgHandlers) createHandler(w http.ResponseWriter, r *http.Request) {
+	p := &lib.OrgParams{}
+	if r.Header.Get("Content-Type") == "application/json" {
+		if err := json.NewDecoder(r.Body).Decode(p); err != nil {
+			util.WriteErrResponse(w, http.StatusBadRequest, err)
+			return
+		}
+	} else {
+		p.Name = r.FormValue("name")
+	}
+
+	res := &logbook.Org{}
+	if err := h.Create(p, res); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+func (h *OrgHandlers) getHandler(w http.ResponseWriter, r *http.Request) {
+	p := &lib.OrgParams{Name: r.FormValue("org")}
+	res := &logbook.Org{}
+	if err := h.Get(p, res); err != nil {
+		writeOrgErrResponse(w, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+func orgMemberParamsFromRequest(r *http.Request) (*lib.OrgMemberParams, error) {
+	p := &lib.OrgMemberParams{}
+	if r.Header.Get("Content-Type") == "application/json" {
+		err := json.NewDecoder(r.Body).Decode(p)
+		return p, err
+	}
+	p.Org = r.FormValue("org")
+	p.Username = r.FormValue("username")
+	p.Role = r.FormValue("role")
+	return p, nil
+}
+
+func (h *OrgHandlers) addMemberHandler(w http.ResponseWriter, r *http.Request) {
+	p, err := orgMemberParamsFromRequest(r)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	res := &logbook.Org{}
+	if err := h.AddMember(p, res); err != nil {
+		writeOrgErrResponse(w, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+func (h *OrgHandlers) removeMemberHandler(w http.ResponseWriter, r *http.Request) {
+	p, err := orgMemberParamsFromRequest(r)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	res := &logbook.Org{}
+	if err := h.RemoveMember(p, res); err != nil {
+		writeOrgErrResponse(w, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+func writeOrgErrResponse(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, logbook.ErrNotFound):
+		util.WriteErrResponse(w, http.StatusNotFound, err)
+	case errors.Is(err, logbook.ErrAccessDenied):
+		util.WriteErrResponse(w, http.StatusForbidden, err)
+	default:
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+	}
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qri-io/qri/logbook"
+)
+
+func TestOrgHandlers(t *testing.T) {
+	run := NewAPITestRunner(t)
+	defer run.Delete()
+
+	oh := NewOrgHandlers(run.Inst, false)
+	call := func(h http.HandlerFunc, method, url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest(method, url, nil))
+		return w
+	}
+
+	w := call(oh.OrgsHandler, "POST", "/orgs?name=climate")
+	if w.Code != http.StatusOK {
+		t.Fatalf("create: expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	res := struct{ Data logbook.Org }{}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Data.Name != "climate" || len(res.Data.Members) != 1 {
+		t.Errorf("unexpected org: %#v", res.Data)
+	}
+
+	w = call(oh.OrgsHandler, "GET", "/orgs")
+	list := struct{ Data []logbook.Org }{}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Data) != 1 {
+		t.Errorf("expected 1 org, got %d", len(list.Data))
+	}
+
+	if w = call(oh.MembersHandler, "GET", "/orgs/members?org=climate"); w.Code != http.StatusOK {
+		t.Errorf("members: expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w = call(oh.MembersHandler, "GET", "/orgs/members?org=nope"); w.Code != http.StatusNotFound {
+		t.Errorf("members of unknown org: expected status 404, got %d", w.Code)
+	}
+	if w = call(oh.MembersHandler, "POST", "/orgs/members?org=climate&username=nobody"); w.Code != http.StatusBadRequest {
+		t.Errorf("adding unknown peer: expected status 400, got %d", w.Code)
+	}
+
+	ro := NewOrgHandlers(run.Inst, true)
+	if w = call(ro.OrgsHandler, "POST", "/orgs?name=weather"); w.Code != http.StatusForbidden {
+		t.Errorf("read-only: expected status 403, got %d", w.Code)
+	}
+}
@@ -88,9 +88,11 @@ func (h *PeerHandlers) listPeersHandler(w http.ResponseWriter, r *http.Request)
 	// args.OrderBy = "created"
 	cached := util.ReqParamBool(r, "cached", false)
 
+	page := args.Page()
 	p := &lib.PeerListParams{
-		Limit:  args.Limit,
+		Limit:  page.FetchLimit(),
 		Offset: args.Offset,
+		After:  args.After,
 		Cached: cached,
 	}
 	res := []*config.ProfilePod{}
@@ -99,7 +101,16 @@ func (h *PeerHandlers) listPeersHandler(w http.ResponseWriter, r *http.Request)
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
-	util.WritePageResponse(w, res, r, args.Page())
+
+	n := len(res)
+	if n > page.Size {
+		res = res[:page.Size]
+	}
+	lastKey := ""
+	if len(res) > 0 {
+		lastKey = res[len(res)-1].ID
+	}
+	util.WritePageResponse(w, res, r, page.WithResults(n, lastKey))
 }
 
 func (h *PeerHandlers) listConnectionsHandler(w http.ResponseWriter, r *http.Request) {
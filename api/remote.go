@@ -52,6 +52,7 @@ func (h *RemoteClientHandlers) PushHandler(w http.ResponseWriter, r *http.Reques
 	p := &lib.PushParams{
 		Ref:        ref.String(),
 		RemoteName: r.FormValue("remote"),
+		Catalog:    r.FormValue("catalog") == "true",
 	}
 
 	var res dsref.Ref
@@ -96,7 +97,34 @@ func (h *RemoteClientHandlers) feedsHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	util.WriteResponse(w, res)
+	page := util.PageFromRequest(r)
+	n := pageFeeds(res, page)
+	if err := util.WritePageResponse(w, res, r, page.WithResults(n, "")); err != nil {
+		log.Infof("error feeds response: %s", err.Error())
+	}
+}
+
+// pageFeeds slices each feed in an index of feeds to the same page, returning
+// the number of items from the page offset in the longest feed, up to the page
+// fetch limit
+func pageFeeds(feeds map[string][]dsref.VersionInfo, p util.Page) (n int) {
+	for name, feed := range feeds {
+		start, end := p.Offset(), p.Offset()+p.Limit()
+		if start > len(feed) {
+			start = len(feed)
+		}
+		if len(feed)-start > n {
+			n = len(feed) - start
+		}
+		if end > len(feed) {
+			end = len(feed)
+		}
+		feeds[name] = feed[start:end]
+	}
+	if n > p.FetchLimit() {
+		n = p.FetchLimit()
+	}
+	return n
 }
 
 // DatasetPreviewHandler fetches a dataset preview from the registry
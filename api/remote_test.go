@@ -4,6 +4,9 @@ import (
 	// "math/rand"
 	"context"
 	"testing"
+
+	"github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/dsref"
 )
 
 func TestRemoteClientHandlers(t *testing.T) {
@@ -34,3 +37,22 @@ func TestRemoteClientHandlers(t *testing.T) {
 	}
 	runHandlerTestCases(t, "fetch", l.LogHandler, fetchCases, true)
 }
+
+func TestPageFeeds(t *testing.T) {
+	feeds := map[string][]dsref.VersionInfo{
+		"recent":   make([]dsref.VersionInfo, 5),
+		"featured": make([]dsref.VersionInfo, 2),
+	}
+	// one more item than the page size means the longest feed has another page
+	if n := pageFeeds(feeds, util.NewPage(2, 2)); n != 3 {
+		t.Errorf("expected longest feed to have 3 items from the page offset, got %d", n)
+	}
+	if len(feeds["recent"]) != 2 || len(feeds["featured"]) != 0 {
+		t.Errorf("expected each feed to be sliced to the page, got %d recent & %d featured", len(feeds["recent"]), len(feeds["featured"]))
+	}
+
+	feeds["recent"] = make([]dsref.VersionInfo, 5)
+	if n := pageFeeds(feeds, util.NewPage(3, 2)); n != 1 {
+		t.Errorf("expected the last page to have 1 item, got %d", n)
+	}
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/follow"
+	"github.com/qri-io/qri/journal"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/logbook"
+	reporef "github.com/qri-io/qri/repo/ref"
+	"github.com/qri-io/qri/upload"
+)
+
+// Route describes an API endpoint. Every path NewServerRoutes registers must
+// have an entry in Routes, which is the source of the OpenAPI spec and request
+// validation
+type Route struct {
+	// Path is the pattern a handler is registered with. Paths that end in a
+	// slash match any path with the same prefix
+	Path string
+	// PathParams describes the portion of the URL that follows a prefix path,
+	// eg: "{username}/{name}"
+	PathParams string
+	// Methods lists the HTTP methods the endpoint responds to
+	Methods []string
+	// Summary is a short description of the endpoint
+	Summary string
+	// Deprecated endpoints are kept for backwards compatibility
+	Deprecated bool
+	// Params is the type of JSON request bodies, nil for endpoints that
+	// don't accept JSON. Requests with a JSON body are validated against the
+	// schema generated from this type
+	Params interface{}
+	// Result is the type of the "data" field in the response envelope, nil
+	// for endpoints that don't respond with a JSON envelope
+	Result interface{}
+	// Paginated responses include a "pagination" field
+	Paginated bool
+}
+
+// Routes is the table of all API endpoints
+var Routes = []Route{
+	{Path: "/health", Methods: []string{"GET"}, Summary: "check if the node is running"},
+	{Path: "/ipfs/", PathParams: "{path}", Methods: []string{"GET"}, Summary: "fetch raw data from the content-addressed store"},
+	{Path: "/ipns/", PathParams: "{name}", Methods: []string{"GET"}, Summary: "resolve an IPNS name & fetch the data it points to"},
+
+	{Path: "/me", Methods: []string{"GET", "POST"}, Summary: "get or update this peer's profile", Params: config.ProfilePod{}, Result: config.ProfilePod{}},
+	{Path: "/profile", Methods: []string{"GET", "POST"}, Summary: "get or update this peer's profile", Params: config.ProfilePod{}, Result: config.ProfilePod{}},
+	{Path: "/profile/photo", Methods: []string{"GET", "PUT", "POST"}, Summary: "get or set this peer's profile photo", Params: lib.FileParams{}, Result: config.ProfilePod{}},
+	{Path: "/profile/poster", Methods: []string{"GET", "PUT", "POST"}, Summary: "get or set this peer's poster image", Params: lib.FileParams{}, Result: config.ProfilePod{}},
+
+	{Path: "/peers", Methods: []string{"GET"}, Summary: "list known peers", Result: []*config.ProfilePod{}, Paginated: true},
+	{Path: "/peers/", PathParams: "{profileID}", Methods: []string{"GET"}, Summary: "get info about a peer", Result: config.ProfilePod{}},
+	{Path: "/connect/", PathParams: "{multiaddr}", Methods: []string{"GET"}, Summary: "connect to a peer", Result: config.ProfilePod{}},
+	{Path: "/connections", Methods: []string{"GET"}, Summary: "list connected IPFS peers", Result: []string{}},
+
+	{Path: "/remote/dsync", Methods: []string{"GET", "POST", "PUT", "DELETE"}, Summary: "sync dataset blocks with this remote"},
+	{Path: "/remote/logsync", Methods: []string{"GET", "POST", "PUT", "DELETE"}, Summary: "sync dataset logs with this remote"},
+	{Path: "/remote/refs", Methods: []string{"GET"}, Summary: "list datasets stored on this remote"},
+
+	{Path: "/list", Methods: []string{"GET"}, Summary: "list local datasets", Result: []dsref.VersionInfo{}, Paginated: true},
+	{Path: "/list/", PathParams: "{username}", Methods: []string{"GET"}, Summary: "list a peer's datasets", Result: []dsref.VersionInfo{}, Paginated: true},
+	{Path: "/save", Methods: []string{"POST", "PUT"}, Summary: "save a dataset version", Params: dataset.Dataset{}, Result: reporef.DatasetRef{}},
+	{Path: "/save/", PathParams: "{username}/{name}", Methods: []string{"POST", "PUT"}, Summary: "save a dataset version", Params: dataset.Dataset{}, Result: reporef.DatasetRef{}},
+	{Path: "/upload", Methods: []string{"POST"}, Summary: "start a resumable upload of a large body. save the completed upload with /save/{username}/{name}?upload={id}", Params: lib.CreateUploadParams{}, Result: upload.Session{}},
+	{Path: "/upload/", PathParams: "{id}", Methods: []string{"GET", "PUT", "PATCH", "DELETE"}, Summary: "get the progress of an upload, send a chunk starting at the Upload-Offset header, or cancel it", Result: upload.Session{}},
+	{Path: "/remove/", PathParams: "{username}/{name}", Methods: []string{"POST", "DELETE"}, Summary: "remove dataset versions", Result: lib.RemoveResponse{}},
+	{Path: "/get/", PathParams: "{username}/{name}", Methods: []string{"GET"}, Summary: "get a dataset", Result: reporef.DatasetRef{}},
+	{Path: "/rename", Methods: []string{"POST", "PUT"}, Summary: "rename a dataset", Params: lib.RenameParams{}, Result: dsref.VersionInfo{}},
+	{Path: "/diff", Methods: []string{"GET", "POST"}, Summary: "compare two datasets", Params: lib.DiffParams{}, Result: lib.DiffResponse{}, Paginated: true},
+	{Path: "/body/", PathParams: "{username}/{name}", Methods: []string{"GET"}, Summary: "get the body of a dataset. downloads & requests with a \"Range: rows=first-last\" header stream the body as csv, json or ndjson, with an ETag", Result: DataResponse{}, Paginated: true},
+	{Path: "/stats/", PathParams: "{username}/{name}", Methods: []string{"GET"}, Summary: "get stats about a dataset body", Result: []map[string]interface{}{}},
+	{Path: "/unpack/", Methods: []string{"POST"}, Summary: "unpack a zip archive of a dataset", Result: map[string]interface{}{}},
+
+	{Path: "/push/", PathParams: "{username}/{name}", Methods: []string{"GET", "POST", "DELETE"}, Summary: "publish a dataset to a remote, or remove it", Result: ""},
+	{Path: "/pull/", PathParams: "{username}/{name}", Methods: []string{"POST", "PUT"}, Summary: "fetch a dataset from the network", Result: reporef.DatasetRef{}},
+	{Path: "/feeds", Methods: []string{"GET"}, Summary: "get feeds of datasets from the registry", Result: map[string][]dsref.VersionInfo{}, Paginated: true},
+	{Path: "/preview/", PathParams: "{username}/{name}", Methods: []string{"GET"}, Summary: "preview a dataset from the network", Result: dataset.Dataset{}},
+
+	{Path: "/status/", PathParams: "{username}/{name}", Methods: []string{"GET"}, Summary: "get the status of a linked working directory", Result: []lib.StatusItem{}},
+	{Path: "/whatchanged/", PathParams: "{username}/{name}", Methods: []string{"GET"}, Summary: "list components changed in a dataset version", Result: []lib.StatusItem{}},
+	{Path: "/init/", Methods: []string{"POST"}, Summary: "initialize a dataset in a working directory", Result: reporef.DatasetRef{}},
+	{Path: "/checkout/", PathParams: "{username}/{name}", Methods: []string{"POST"}, Summary: "checkout a dataset into a working directory", Result: ""},
+	{Path: "/restore/", PathParams: "{username}/{name}", Methods: []string{"POST"}, Summary: "restore working directory files from a version", Result: ""},
+	{Path: "/fsi/write/", PathParams: "{username}/{name}", Methods: []string{"POST"}, Summary: "write a dataset to a linked working directory", Params: dataset.Dataset{}, Result: []lib.StatusItem{}},
+
+	{Path: "/render", Methods: []string{"GET", "POST"}, Summary: "render a dataset document as HTML", Params: dataset.Dataset{}},
+	{Path: "/render/", PathParams: "{username}/{name}", Methods: []string{"GET", "POST"}, Summary: "render a dataset as HTML", Params: dataset.Dataset{}},
+
+	{Path: "/history/", PathParams: "{username}/{name}", Methods: []string{"GET"}, Summary: "list the versions of a dataset", Result: []DatasetLogItem{}, Paginated: true},
+	{Path: "/events", Methods: []string{"GET"}, Summary: "page through the event journal", Result: []journal.Entry{}, Paginated: true},
+	{Path: "/follow", Methods: []string{"GET", "POST", "DELETE"}, Summary: "list follows, follow a peer or dataset to receive announcements of new versions, or unfollow with the ref query param", Params: lib.FollowParams{}, Result: []follow.Follow{}, Paginated: true},
+	{Path: "/follow/feed", Methods: []string{"GET"}, Summary: "list new versions announced by followed peers & datasets, newest first", Result: []follow.Item{}, Paginated: true},
+	{Path: "/orgs", Methods: []string{"GET", "POST"}, Summary: "list organizations, or create an organization with the current user as admin", Params: lib.OrgParams{}, Result: []logbook.Org{}, Paginated: true},
+	{Path: "/orgs/members", Methods: []string{"GET", "POST", "DELETE"}, Summary: "show the members of an organization with the org query param, add a member or change their role, or remove a member", Params: lib.OrgMemberParams{}, Result: logbook.Org{}},
+
+	{Path: "/registry/profile/new", Methods: []string{"POST"}, Summary: "create a registry profile", Params: lib.RegistryProfile{}, Result: lib.RegistryProfile{}},
+	{Path: "/registry/profile/prove", Methods: []string{"POST"}, Summary: "prove ownership of a registry profile", Params: lib.RegistryProfile{}, Result: lib.RegistryProfile{}},
+
+	{Path: "/search", Methods: []string{"GET"}, Summary: "search the registry for datasets", Params: lib.SearchParams{}, Result: []lib.SearchResult{}, Paginated: true},
+	{Path: "/sql", Methods: []string{"GET", "POST"}, Summary: "run a SQL query", Params: lib.SQLQueryParams{}},
+	{Path: "/graphql", Methods: []string{"GET", "POST"}, Summary: "query datasets, profiles & peers with GraphQL. enabled by the api.enablegraphql config option. responses follow the GraphQL spec", Params: graphQLRequest{}},
+
+	{Path: "/webui", Methods: []string{"GET"}, Summary: "serve the frontend webapp"},
+}
+
+// route finds the table entry for a registered path
+func route(path string) (Route, bool) {
+	for _, r := range Routes {
+		if r.Path == path {
+			return r, true
+		}
+	}
+	return Route{}, false
+}
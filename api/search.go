@@ -47,6 +47,8 @@ func (h *SearchHandlers) searchHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	page := util.NewPageFromOffsetAndLimit(sp.Offset, sp.Limit)
+	sp.Limit = page.FetchLimit()
 	results := []lib.SearchResult{}
 
 	if err := h.SearchMethods.Search(sp, &results); err != nil {
@@ -55,5 +57,9 @@ func (h *SearchHandlers) searchHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	util.WriteResponse(w, results)
+	n := len(results)
+	if n > page.Size {
+		results = results[:page.Size]
+	}
+	util.WritePageResponse(w, results, r, page.WithResults(n, ""))
 }
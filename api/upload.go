@@ -0,0 +1,198 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/upload"
+)
+
+// MaxUploadChunkSize is the largest chunk an upload accepts in one request
+var MaxUploadChunkSize int64 = 64 << 20
+
+const (
+	// uploadOffsetHeader is the offset a chunk starts at. Responses include
+	// the number of bytes received so far
+	uploadOffsetHeader = "Upload-Offset"
+	// uploadChecksumHeader is the checksum of a chunk, in the form
+	// "sha256 <hex>"
+	uploadChecksumHeader = "Upload-Checksum"
+)
+
+// UploadHandlers wraps UploadMethods with http.HandlerFuncs. Uploading a
+// large body happens in three steps:
+//
+//	POST /upload starts an upload, responding with an upload ID
+//	PUT /upload/{id} sends each chunk, with an Upload-Offset header
+//	POST /save/{username}/{name}?upload={id} saves the completed upload, the
+//	request body is a JSON dataset to save with the upload, or {}
+//
+// An interrupted upload is resumed by reading the offset with
+// GET /upload/{id} & sending chunks from there
+type UploadHandlers struct {
+	lib.UploadMethods
+}
+
+// NewUploadHandlers allocates an UploadHandlers pointer
+func NewUploadHandlers(inst *lib.Instance) *UploadHandlers {
+	req := lib.NewUploadMethods(inst)
+	return &UploadHandlers{*req}
+}
+
+// UploadHandler is the endpoint for starting an upload
+func (h *UploadHandlers) UploadHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "OPTIONS":
+		util.EmptyOkHandler(w, r)
+	case "POST":
+		h.createHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+// UploadSessionHandler is the endpoint for sending chunks of an upload,
+// checking its progress & cancelling it
+func (h *UploadHandlers) UploadSessionHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "OPTIONS":
+		util.EmptyOkHandler(w, r)
+	case "GET":
+		h.statusHandler(w, r)
+	case "PUT", "PATCH":
+		h.chunkHandler(w, r)
+	case "DELETE":
+		h.cancelHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+func (h *UploadHandlers) createHandler(w http.ResponseWriter, r *http.Request) {
+	p := &lib.CreateUploadParams{}
+	if r.Header.Get("Content-Type") == "application/json" {
+		if err := json.NewDecoder(r.Body).Decode(p); err != nil {
+			util.WriteErrResponse(w, http.StatusBadRequest, err)
+			return
+		}
+	} else {
+		p.Filename = r.FormValue("filename")
+		p.SHA256 = r.FormValue("sha256")
+		size, err := strconv.ParseInt(r.FormValue("size"), 10, 64)
+		if err != nil {
+			util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("invalid size: %w", err))
+			return
+		}
+		p.Size = size
+	}
+
+	res := &upload.Session{}
+	if err := h.Create(p, res); err != nil {
+		if errors.Is(err, lib.ErrNoUploads) || errors.Is(err, upload.ErrUploadTooLarge) {
+			writeUploadError(w, err)
+			return
+		}
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	w.Header().Set(uploadOffsetHeader, strconv.FormatInt(res.Offset, 10))
+	util.WriteResponse(w, res)
+}
+
+func (h *UploadHandlers) statusHandler(w http.ResponseWriter, r *http.Request) {
+	id := uploadID(r)
+	res := &upload.Session{}
+	if err := h.Status(&id, res); err != nil {
+		writeUploadError(w, err)
+		return
+	}
+	w.Header().Set(uploadOffsetHeader, strconv.FormatInt(res.Offset, 10))
+	util.WriteResponse(w, res)
+}
+
+func (h *UploadHandlers) chunkHandler(w http.ResponseWriter, r *http.Request) {
+	offset, err := strconv.ParseInt(r.Header.Get(uploadOffsetHeader), 10, 64)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("%s header is required", uploadOffsetHeader))
+		return
+	}
+	checksum := ""
+	if v := r.Header.Get(uploadChecksumHeader); v != "" {
+		parts := strings.Fields(v)
+		if len(parts) != 2 || strings.ToLower(parts[0]) != "sha256" {
+			util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("%s header must be in the form \"sha256 <hex>\"", uploadChecksumHeader))
+			return
+		}
+		checksum = parts[1]
+	}
+
+	data, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, MaxUploadChunkSize))
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusRequestEntityTooLarge, fmt.Errorf("chunks can be at most %d bytes: %w", MaxUploadChunkSize, err))
+		return
+	}
+
+	p := &lib.UploadChunkParams{
+		ID:     uploadID(r),
+		Offset: offset,
+		SHA256: checksum,
+		Data:   data,
+	}
+	res := &upload.Session{}
+	err = h.WriteChunk(p, res)
+	if res.ID != "" {
+		w.Header().Set(uploadOffsetHeader, strconv.FormatInt(res.Offset, 10))
+	}
+	if err != nil {
+		writeUploadError(w, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+func (h *UploadHandlers) cancelHandler(w http.ResponseWriter, r *http.Request) {
+	id := uploadID(r)
+	res := false
+	if err := h.Cancel(&id, &res); err != nil {
+		writeUploadError(w, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+func uploadID(r *http.Request) string {
+	return strings.Trim(strings.TrimPrefix(r.URL.Path, "/upload/"), "/")
+}
+
+func isUploadError(err error) bool {
+	for _, target := range []error{upload.ErrNotFound, upload.ErrChecksumMismatch, upload.ErrIncomplete, lib.ErrNoUploads} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeUploadError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, upload.ErrNotFound):
+		util.WriteErrResponse(w, http.StatusNotFound, err)
+	case errors.Is(err, upload.ErrOffsetMismatch):
+		util.WriteErrResponse(w, http.StatusConflict, err)
+	case errors.Is(err, upload.ErrTooLarge), errors.Is(err, upload.ErrUploadTooLarge):
+		util.WriteErrResponse(w, http.StatusRequestEntityTooLarge, err)
+	case errors.Is(err, upload.ErrChecksumMismatch), errors.Is(err, upload.ErrIncomplete):
+		util.WriteErrResponse(w, http.StatusUnprocessableEntity, err)
+	case errors.Is(err, lib.ErrNoUploads):
+		util.WriteErrResponse(w, http.StatusServiceUnavailable, err)
+	default:
+		util.RespondWithError(w, err)
+	}
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/qri/upload"
+)
+
+func TestUploadHandlers(t *testing.T) {
+	run := NewAPITestRunner(t)
+	defer run.Delete()
+
+	uh := NewUploadHandlers(run.Inst)
+	dsh := NewDatasetHandlers(run.Inst, false)
+
+	body := "city,pop\ntoronto,40000000\nnew york,8500000\nchicago,300000\n"
+	sum := sha256.Sum256([]byte(body))
+
+	call := func(h http.HandlerFunc, method, url, reqBody string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(reqBody))
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		h(w, req)
+		return w
+	}
+	session := func(w *httptest.ResponseRecorder) upload.Session {
+		res := struct{ Data upload.Session }{}
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("decoding response %q: %s", w.Body.String(), err)
+		}
+		return res.Data
+	}
+
+	// uploads larger than the maximum are rejected before they start
+	tooLarge := fmt.Sprintf(`{"filename":"huge.csv","size":%d}`, upload.DefaultMaxSize+1)
+	w := call(uh.UploadHandler, "POST", "/upload", tooLarge, map[string]string{"Content-Type": "application/json"})
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("oversized create: expected status 413, got %d: %s", w.Code, w.Body.String())
+	}
+
+	create := fmt.Sprintf(`{"filename":"cities.csv","size":%d,"sha256":"%s"}`, len(body), hex.EncodeToString(sum[:]))
+	w = call(uh.UploadHandler, "POST", "/upload", create, map[string]string{"Content-Type": "application/json"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("create: expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	s := session(w)
+	url := "/upload/" + s.ID
+
+	w = call(uh.UploadSessionHandler, "PUT", url, body[:20], map[string]string{uploadOffsetHeader: "0"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("chunk: expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// resending a chunk conflicts, reporting the offset to resume from
+	w = call(uh.UploadSessionHandler, "PUT", url, body[:20], map[string]string{uploadOffsetHeader: "0"})
+	if w.Code != http.StatusConflict {
+		t.Errorf("repeated chunk: expected status 409, got %d", w.Code)
+	}
+	if w.Header().Get(uploadOffsetHeader) != "20" {
+		t.Errorf("expected conflict to report offset 20, got %q", w.Header().Get(uploadOffsetHeader))
+	}
+
+	w = call(uh.UploadSessionHandler, "PUT", url, body[20:], map[string]string{uploadOffsetHeader: "20", uploadChecksumHeader: "sha256 00"})
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("bad chunk checksum: expected status 422, got %d", w.Code)
+	}
+
+	// saving before the upload is complete fails
+	w = call(dsh.SaveHandler, "POST", "/save/peer/cities?upload="+s.ID, "{}", map[string]string{"Content-Type": "application/json"})
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("incomplete save: expected status 422, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = call(uh.UploadSessionHandler, "GET", url, "", nil)
+	if s = session(w); s.Offset != 20 {
+		t.Fatalf("expected upload offset 20, got %d", s.Offset)
+	}
+
+	chunkSum := sha256.Sum256([]byte(body[20:]))
+	w = call(uh.UploadSessionHandler, "PUT", url, body[20:], map[string]string{uploadOffsetHeader: "20", uploadChecksumHeader: "sha256 " + hex.EncodeToString(chunkSum[:])})
+	if w.Code != http.StatusOK {
+		t.Fatalf("last chunk: expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if s = session(w); !s.Complete() {
+		t.Errorf("expected upload to be complete, got offset %d of %d", s.Offset, s.Size)
+	}
+
+	w = call(dsh.SaveHandler, "POST", "/save/peer/cities?upload="+s.ID, "{}", map[string]string{"Content-Type": "application/json"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("save: expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = call(uh.UploadSessionHandler, "GET", url, "", nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected saved upload to be removed, got status %d", w.Code)
+	}
+
+	// cancelling removes an upload
+	w = call(uh.UploadHandler, "POST", "/upload", `{"filename":"body.json","size":10}`, map[string]string{"Content-Type": "application/json"})
+	s = session(w)
+	if w = call(uh.UploadSessionHandler, "DELETE", "/upload/"+s.ID, "", nil); w.Code != http.StatusOK {
+		t.Errorf("cancel: expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w = call(uh.UploadSessionHandler, "GET", "/upload/"+s.ID, "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected cancelled upload to be removed, got status %d", w.Code)
+	}
+}
@@ -0,0 +1,61 @@
+package util
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// ErrInvalidCursor indicates a cursor string couldn't be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is a position within an ordered list. Clients receive cursors as
+// opaque strings & pass them back unmodified to fetch the next page
+type Cursor struct {
+	// Offset is the index of the first item in the page
+	Offset int `json:"o,omitempty"`
+	// Size is the number of items in a page
+	Size int `json:"s,omitempty"`
+	// After is the key of the last item on the previous page. Lists that
+	// support keys resume after it, so pages don't shift when items are added
+	// to the front of the list. Lists fall back to Offset when the keyed item
+	// no longer exists
+	After string `json:"a,omitempty"`
+}
+
+// Encode converts a cursor to an opaque, URL-safe string
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a string created by Cursor.Encode
+func DecodeCursor(s string) (Cursor, error) {
+	c := Cursor{}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, ErrInvalidCursor
+	}
+	if c.Offset < 0 || c.Size < 0 {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// CursorFromRequest reads the "cursor" query param of a request, returning
+// nil if the request doesn't have a cursor
+func CursorFromRequest(r *http.Request) (*Cursor, error) {
+	s := r.FormValue("cursor")
+	if s == "" {
+		return nil, nil
+	}
+	c, err := DecodeCursor(s)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,97 @@
+package util
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestCursorEncodeDecode(t *testing.T) {
+	c := Cursor{Offset: 20, Size: 10, After: "peer/dataset"}
+	got, err := DecodeCursor(c.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != c {
+		t.Errorf("cursor mismatch. expected: %v, got: %v", c, got)
+	}
+
+	bad := []string{"not a cursor!", "bm90IGpzb24", Cursor{Offset: -1}.Encode()}
+	for _, s := range bad {
+		if _, err := DecodeCursor(s); err != ErrInvalidCursor {
+			t.Errorf("decoding %q: expected ErrInvalidCursor, got: %v", s, err)
+		}
+	}
+}
+
+func TestPageWithResults(t *testing.T) {
+	p := NewPage(2, 10)
+
+	last := p.WithResults(4, "d")
+	if last.NextCursor != "" {
+		t.Errorf("expected short page to have no next cursor, got: %q", last.NextCursor)
+	}
+	if last.ResultCount != 14 {
+		t.Errorf("expected result count 14, got: %d", last.ResultCount)
+	}
+
+	// a page that fills up without reading an extra result is the last page
+	exact := p.WithResults(10, "j")
+	if exact.NextCursor != "" {
+		t.Errorf("expected page without more results to have no next cursor, got: %q", exact.NextCursor)
+	}
+	if exact.ResultCount != 20 {
+		t.Errorf("expected result count 20, got: %d", exact.ResultCount)
+	}
+
+	full := p.WithResults(p.FetchLimit(), "j")
+	c, err := DecodeCursor(full.NextCursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := Cursor{Offset: 20, Size: 10, After: "j"}
+	if c != expect {
+		t.Errorf("next cursor mismatch. expected: %v, got: %v", expect, c)
+	}
+
+	u := full.SetCursorQueryParams(&url.URL{Path: "/list", RawQuery: "page=2&pageSize=10&term=a"})
+	q := u.Query()
+	if q.Get("page") != "" || q.Get("pageSize") != "" {
+		t.Errorf("expected page-number params to be removed, got: %s", u.RawQuery)
+	}
+	if q.Get("term") != "a" || q.Get("cursor") != full.NextCursor {
+		t.Errorf("unexpected next url query: %s", u.RawQuery)
+	}
+
+	r := httptest.NewRequest("GET", u.String(), nil)
+	next := PageFromRequest(r)
+	if next.Offset() != 20 || next.Size != 10 {
+		t.Errorf("expected page from cursor to have offset 20 & size 10, got: %d & %d", next.Offset(), next.Size)
+	}
+}
+
+func TestPageWithResultsOffset(t *testing.T) {
+	// cursor offsets don't need to be a multiple of the page size
+	c := Cursor{Offset: 15, Size: 10}
+	r := httptest.NewRequest("GET", "/list?cursor="+c.Encode(), nil)
+	p := PageFromRequest(r)
+	if p.Offset() != 15 {
+		t.Errorf("offset mismatch. expected %d, got: %d", 15, p.Offset())
+	}
+
+	next, err := DecodeCursor(p.WithResults(p.FetchLimit(), "").NextCursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Offset != 25 {
+		t.Errorf("next cursor offset mismatch. expected %d, got: %d", 25, next.Offset)
+	}
+
+	last := p.WithResults(10, "")
+	if last.ResultCount != 25 {
+		t.Errorf("result count mismatch. expected %d, got: %d", 25, last.ResultCount)
+	}
+	if last.NextPageExists() {
+		t.Error("expected the last page not to have a next page")
+	}
+}
@@ -21,6 +21,14 @@ type Page struct {
 	ResultCount int    `json:"resultCount,omitempty"`
 	NextURL     string `json:"nextUrl"`
 	PrevURL     string `json:"prevUrl"`
+	// NextCursor is an opaque token for fetching the next page. Pages that
+	// have a next cursor link to the next page with it
+	NextCursor string `json:"nextCursor,omitempty"`
+
+	// offset is the index of the first result in the page. pages that start at
+	// an offset that isn't a multiple of the page size can't be described by
+	// page number alone
+	offset int
 }
 
 // NewPage constructs a basic page struct, setting sensible defaults
@@ -45,36 +53,85 @@ func (p Page) Limit() int {
 }
 
 // Offset calculates the starting index for pagination based on page
-// size & number
+// size & number, or gives the offset the page was created with
 func (p Page) Offset() int {
+	if p.offset > 0 {
+		return p.offset
+	}
 	return (p.Number - 1) * p.Size
 }
 
+// FetchLimit is the number of results to read for a page, one more than the
+// page size. Reading an extra result tells WithResults a next page exists
+func (p Page) FetchLimit() int {
+	return p.Size + 1
+}
+
 // Next returns a page with the number advanced by one
 func (p Page) Next() Page {
-	return Page{
+	next := Page{
 		Number:      p.Number + 1,
 		Size:        p.Size,
 		ResultCount: p.ResultCount,
 		NextURL:     p.NextURL,
 		PrevURL:     p.PrevURL,
+		NextCursor:  p.NextCursor,
+	}
+	if p.offset > 0 {
+		next.offset = p.offset + p.Size
 	}
+	return next
 }
 
 // Prev returns a page with the number decremented by 1
 func (p Page) Prev() Page {
-	return Page{
+	prev := Page{
 		Number:      p.Number - 1,
 		Size:        p.Size,
 		ResultCount: p.ResultCount,
 		NextURL:     p.NextURL,
 		PrevURL:     p.PrevURL,
 	}
+	if p.offset > p.Size {
+		prev.offset = p.offset - p.Size
+	}
+	return prev
+}
+
+// WithResults sets the cursor for the page that follows this one, given the
+// number of results read for this page and the key of the last result in the
+// page. Callers read up to FetchLimit results, reading more results than the
+// page size means a next page exists. The last page sets the result count.
+// lastKey may be empty for lists that don't support keys
+func (p Page) WithResults(n int, lastKey string) Page {
+	if n <= p.Size {
+		p.ResultCount = p.Offset() + n
+		p.NextCursor = ""
+		return p
+	}
+	p.NextCursor = Cursor{
+		Offset: p.Offset() + p.Size,
+		Size:   p.Size,
+		After:  lastKey,
+	}.Encode()
+	return p
+}
+
+// SetCursorQueryParams adds the next cursor to a url as a query parameter,
+// replacing page-number params
+func (p Page) SetCursorQueryParams(u *url.URL) *url.URL {
+	q := u.Query()
+	q.Del("page")
+	q.Del("pageSize")
+	q.Set("cursor", p.NextCursor)
+	u.RawQuery = q.Encode()
+	return u
 }
 
 // SetQueryParams adds pagination info to a url as query parameters
 func (p Page) SetQueryParams(u *url.URL) *url.URL {
 	q := u.Query()
+	q.Del("cursor")
 	q.Set("page", strconv.Itoa(p.Number))
 	if p.Size != DefaultPageSize {
 		q.Set("pageSize", strconv.Itoa(p.Size))
@@ -87,7 +144,7 @@ func (p Page) SetQueryParams(u *url.URL) *url.URL {
 // NextPageExists returns false if the next page.ResultCount is a postive
 // number and the starting offset of the next page exceeds page.ResultCount
 func (p Page) NextPageExists() bool {
-	return p.ResultCount <= 0 || !(p.Number*p.Size >= p.ResultCount)
+	return p.ResultCount <= 0 || !(p.Offset()+p.Size >= p.ResultCount)
 }
 
 // PrevPageExists returns false if the page number is 1
@@ -95,8 +152,17 @@ func (p Page) PrevPageExists() bool {
 	return p.Number > 1
 }
 
-// PageFromRequest extracts pagination params from an http request
+// PageFromRequest extracts pagination params from an http request. Requests
+// with a valid cursor param take precedence over page-number params
 func PageFromRequest(r *http.Request) Page {
+	if c, err := CursorFromRequest(r); err == nil && c != nil && c.Size > 0 {
+		size := c.Size
+		if DefaultMaxPageSize != -1 && size > DefaultMaxPageSize {
+			size = DefaultMaxPageSize
+		}
+		return NewPageFromOffsetAndLimit(c.Offset, size)
+	}
+
 	number := ReqParamInt(r, "page", 1)
 	if number <= 0 {
 		number = 1
@@ -125,8 +191,12 @@ func NewPageFromOffsetAndLimit(offset, limit int) Page {
 		size = DefaultPageSize
 	}
 	number = offset/size + 1
+	if offset < 0 {
+		offset = 0
+	}
 	return Page{
 		Number: number,
 		Size:   size,
+		offset: offset,
 	}
 }
@@ -23,7 +23,11 @@ func WritePageResponse(w http.ResponseWriter, data interface{}, r *http.Request,
 		p.PrevURL = p.Prev().SetQueryParams(r.URL).String()
 	}
 	if p.NextPageExists() {
-		p.NextURL = p.Next().SetQueryParams(r.URL).String()
+		if p.NextCursor != "" {
+			p.NextURL = p.SetCursorQueryParams(r.URL).String()
+		} else {
+			p.NextURL = p.Next().SetQueryParams(r.URL).String()
+		}
 	}
 
 	env := map[string]interface{}{
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 
 	"github.com/qri-io/dataset"
@@ -42,6 +43,105 @@ func ReadBody(ds *dataset.Dataset, format dataset.DataFormat, fcfg dataset.Forma
 	return data, nil
 }
 
+// NDJSONFormat is the name of the newline-delimited JSON body format. ndjson
+// isn't a dataset data format, it's only available when writing bodies
+const NDJSONFormat = "ndjson"
+
+// WriteBody streams some or all of a dataset's body to w, without buffering
+// the body in memory. format is either the name of a dataset.DataFormat or
+// NDJSONFormat. When all is true limit is ignored & entries are written from
+// offset to the end of the body
+func WriteBody(w io.Writer, ds *dataset.Dataset, format string, fcfg dataset.FormatConfig, limit, offset int, all bool) error {
+	if ds == nil {
+		return fmt.Errorf("can't load body from a nil dataset")
+	}
+
+	file := ds.BodyFile()
+	if file == nil {
+		return fmt.Errorf("no body file to read")
+	}
+
+	var ew dsio.EntryWriter
+	if format == NDJSONFormat {
+		ew = newNDJSONWriter(ds.Structure, w)
+	} else {
+		df, err := dataset.ParseDataFormatString(format)
+		if err != nil {
+			return err
+		}
+		st := &dataset.Structure{}
+		assign := &dataset.Structure{
+			Format: df.String(),
+			Schema: ds.Structure.Schema,
+		}
+		if fcfg != nil {
+			assign.FormatConfig = fcfg.Map()
+		}
+		st.Assign(ds.Structure, assign)
+
+		ew, err = newBodyEntryWriter(st, w)
+		if err != nil {
+			return err
+		}
+	}
+
+	rr, err := dsio.NewEntryReader(ds.Structure, file)
+	if err != nil {
+		return fmt.Errorf("error allocating data reader: %s", err)
+	}
+	if all {
+		// a negative limit never runs out
+		limit = -1
+	}
+	if !all || offset > 0 {
+		rr = &dsio.PagedReader{
+			Reader: rr,
+			Limit:  limit,
+			Offset: offset,
+		}
+	}
+
+	if err := dsio.Copy(rr, ew); err != nil {
+		return err
+	}
+	return ew.Close()
+}
+
+// ndjsonWriter writes one JSON value per line. Entries of object bodies are
+// written as single-key objects
+type ndjsonWriter struct {
+	st     *dataset.Structure
+	enc    *json.Encoder
+	object bool
+}
+
+var _ dsio.EntryWriter = (*ndjsonWriter)(nil)
+
+func newNDJSONWriter(st *dataset.Structure, w io.Writer) *ndjsonWriter {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	tlt, _ := dsio.GetTopLevelType(st)
+	return &ndjsonWriter{st: st, enc: enc, object: tlt == "object"}
+}
+
+// Structure gives the structure being written
+func (w *ndjsonWriter) Structure() *dataset.Structure {
+	return w.st
+}
+
+// WriteEntry writes one entry as a line of JSON
+func (w *ndjsonWriter) WriteEntry(e dsio.Entry) error {
+	if w.object {
+		return w.enc.Encode(map[string]interface{}{e.Key: e.Value})
+	}
+	return w.enc.Encode(e.Value)
+}
+
+// Close is a no-op, ndjson has no closing delimiter
+func (w *ndjsonWriter) Close() error {
+	return nil
+}
+
 // ReadEntries reads entries and returns them as a native go array or map
 func ReadEntries(reader dsio.EntryReader) (interface{}, error) {
 	obj := make(map[string]interface{})
@@ -113,18 +213,7 @@ func InlineJSONBody(ds *dataset.Dataset) error {
 func ConvertBodyFile(file qfs.File, in, out *dataset.Structure, limit, offset int, all bool) (data []byte, err error) {
 	buf := &bytes.Buffer{}
 
-	w, err := dsio.NewEntryWriter(out, buf)
-	if err != nil {
-		return
-	}
-
-	// TODO(dlong): Kind of a hacky one-off. Generalize this for other format options.
-	if out.DataFormat() == dataset.JSONDataFormat {
-		ok, pretty := out.FormatConfig["pretty"].(bool)
-		if ok && pretty {
-			w, err = dsio.NewJSONPrettyWriter(out, buf, " ")
-		}
-	}
+	w, err := newBodyEntryWriter(out, buf)
 	if err != nil {
 		return
 	}
@@ -151,6 +240,17 @@ func ConvertBodyFile(file qfs.File, in, out *dataset.Structure, limit, offset in
 	return buf.Bytes(), nil
 }
 
+func newBodyEntryWriter(st *dataset.Structure, w io.Writer) (dsio.EntryWriter, error) {
+	// TODO(dlong): Kind of a hacky one-off. Generalize this for other format options.
+	if st.DataFormat() == dataset.JSONDataFormat {
+		ok, pretty := st.FormatConfig["pretty"].(bool)
+		if ok && pretty {
+			return dsio.NewJSONPrettyWriter(st, w, " ")
+		}
+	}
+	return dsio.NewEntryWriter(st, w)
+}
+
 // ConvertBodyFormat rewrites a body from a source format to a destination format.
 // TODO (b5): Combine this with ConvertBodyFile, update callers.
 func ConvertBodyFormat(bodyFile qfs.File, fromSt, toSt *dataset.Structure) (qfs.File, error) {
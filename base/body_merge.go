@@ -0,0 +1,196 @@
+package base
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/dataset/tabular"
+	"github.com/qri-io/qfs"
+)
+
+// PrimaryKey reads a primary key declaration, which can be a single column
+// name or a list of column names
+func PrimaryKey(v interface{}) ([]string, error) {
+	switch x := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{x}, nil
+	case []string:
+		return x, nil
+	case []interface{}:
+		key := make([]string, len(x))
+		for i, col := range x {
+			s, ok := col.(string)
+			if !ok {
+				return nil, fmt.Errorf("primary key column must be a string, got %v", col)
+			}
+			key[i] = s
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("primary key must be a string or list of strings, got %v", v)
+	}
+}
+
+// MergeBody merges the body of delta into the body of prev. Rows in delta
+// replace rows in prev with the same primary key, rows with new keys are
+// appended in the order they appear in delta. Entries of object bodies are
+// keyed by their entry key, and primaryKey is ignored. The merged body is
+// written with delta's structure
+func MergeBody(prev, delta *dataset.Dataset, primaryKey []string) (qfs.File, error) {
+	if prev.BodyFile() == nil || prev.Structure == nil {
+		return nil, fmt.Errorf("merging body: previous version has no body")
+	}
+	if delta.BodyFile() == nil || delta.Structure == nil {
+		return nil, fmt.Errorf("merging body: no body to merge")
+	}
+
+	prevEntries, err := readMergeEntries(prev, primaryKey)
+	if err != nil {
+		return nil, fmt.Errorf("reading previous body: %w", err)
+	}
+	deltaEntries, err := readMergeEntries(delta, primaryKey)
+	if err != nil {
+		return nil, fmt.Errorf("reading body: %w", err)
+	}
+
+	merged := prevEntries.ents
+	for i, ent := range deltaEntries.ents {
+		key := deltaEntries.keys[i]
+		if idx, ok := prevEntries.index[key]; ok {
+			merged[idx].Value = ent.Value
+			continue
+		}
+		prevEntries.index[key] = len(merged)
+		merged = append(merged, ent)
+	}
+
+	w, err := dsio.NewEntryBuffer(delta.Structure)
+	if err != nil {
+		return nil, err
+	}
+	for i, ent := range merged {
+		if ent.Key == "" {
+			ent.Index = i
+		}
+		if err := w.WriteEntry(ent); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return qfs.NewMemfileBytes(fmt.Sprintf("body.%s", delta.Structure.Format), w.Bytes()), nil
+}
+
+type mergeEntries struct {
+	ents  []dsio.Entry
+	keys  []string
+	index map[string]int
+}
+
+// readMergeEntries reads all body entries of ds, calculating the key of each
+func readMergeEntries(ds *dataset.Dataset, primaryKey []string) (*mergeEntries, error) {
+	r, err := dsio.NewEntryReader(ds.Structure, ds.BodyFile())
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var cols []int
+	if isArrayOfArrays(ds.Structure) {
+		if cols, err = keyColumnIndexes(ds.Structure, primaryKey); err != nil {
+			return nil, err
+		}
+	}
+
+	me := &mergeEntries{index: map[string]int{}}
+	for {
+		ent, err := r.ReadEntry()
+		if err != nil {
+			if err.Error() == "EOF" {
+				break
+			}
+			return nil, err
+		}
+		key := ent.Key
+		if key == "" {
+			if key, err = rowKey(ent.Value, primaryKey, cols); err != nil {
+				return nil, fmt.Errorf("row %d: %w", ent.Index, err)
+			}
+		}
+		if _, ok := me.index[key]; !ok {
+			me.index[key] = len(me.ents)
+		}
+		me.ents = append(me.ents, ent)
+		me.keys = append(me.keys, key)
+	}
+	return me, nil
+}
+
+// isArrayOfArrays checks if a structure's schema describes rows that are arrays
+func isArrayOfArrays(st *dataset.Structure) bool {
+	items, ok := st.Schema["items"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	return items["type"] == "array"
+}
+
+// keyColumnIndexes finds the position of each primary key column in a schema
+func keyColumnIndexes(st *dataset.Structure, primaryKey []string) ([]int, error) {
+	cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema)
+	if err != nil {
+		return nil, err
+	}
+	titles := cols.Titles()
+	idxs := make([]int, len(primaryKey))
+	for i, name := range primaryKey {
+		idxs[i] = -1
+		for j, title := range titles {
+			if title == name {
+				idxs[i] = j
+				break
+			}
+		}
+		if idxs[i] == -1 {
+			return nil, fmt.Errorf("primary key column %q is not in the schema", name)
+		}
+	}
+	return idxs, nil
+}
+
+// rowKey builds the primary key of a row. Array rows are keyed by column
+// position, object rows by field name
+func rowKey(row interface{}, primaryKey []string, cols []int) (string, error) {
+	if len(primaryKey) == 0 {
+		return "", fmt.Errorf("no primary key declared")
+	}
+	vals := make([]string, len(primaryKey))
+	switch r := row.(type) {
+	case []interface{}:
+		if cols == nil {
+			return "", fmt.Errorf("no column titles for primary key")
+		}
+		for i, idx := range cols {
+			if idx >= len(r) {
+				return "", fmt.Errorf("missing primary key column %q", primaryKey[i])
+			}
+			vals[i] = fmt.Sprint(r[idx])
+		}
+	case map[string]interface{}:
+		for i, name := range primaryKey {
+			v, ok := r[name]
+			if !ok {
+				return "", fmt.Errorf("missing primary key column %q", name)
+			}
+			vals[i] = fmt.Sprint(v)
+		}
+	default:
+		return "", fmt.Errorf("can't find primary key of a %T row", row)
+	}
+	return strings.Join(vals, "\x1f"), nil
+}
@@ -0,0 +1,94 @@
+package base
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+)
+
+func TestMergeBody(t *testing.T) {
+	tableSchema := map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type": "array",
+			"items": []interface{}{
+				map[string]interface{}{"title": "id", "type": "integer"},
+				map[string]interface{}{"title": "name", "type": "string"},
+			},
+		},
+	}
+
+	cases := []struct {
+		description       string
+		schema            map[string]interface{}
+		prev, delta       string
+		primaryKey        []string
+		expect, expectErr string
+	}{
+		{"array rows",
+			tableSchema,
+			`[[1,"a"],[2,"b"]]`, `[[2,"B"],[3,"c"]]`, []string{"id"},
+			`[[1,"a"],[2,"B"],[3,"c"]]`, ""},
+		{"object rows with a compound key",
+			dataset.BaseSchemaArray,
+			`[{"a":1,"b":1,"v":"x"},{"a":1,"b":2,"v":"y"}]`, `[{"a":1,"b":2,"v":"z"},{"a":2,"b":1,"v":"w"}]`, []string{"a", "b"},
+			`[{"a":1,"b":1,"v":"x"},{"a":1,"b":2,"v":"z"},{"a":2,"b":1,"v":"w"}]`, ""},
+		{"object body",
+			dataset.BaseSchemaObject,
+			`{"a":1,"b":2}`, `{"b":3,"c":4}`, nil,
+			`{"a":1,"b":3,"c":4}`, ""},
+		{"unknown column",
+			tableSchema,
+			`[[1,"a"]]`, `[[1,"b"]]`, []string{"nope"},
+			"", `reading previous body: primary key column "nope" is not in the schema`},
+		{"missing field",
+			dataset.BaseSchemaArray,
+			`[{"a":1}]`, `[{"b":1}]`, []string{"a"},
+			"", `reading body: row 0: missing primary key column "a"`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			st := &dataset.Structure{Format: "json", Schema: c.schema}
+			prev := &dataset.Dataset{Structure: st}
+			prev.SetBodyFile(qfs.NewMemfileBytes("body.json", []byte(c.prev)))
+			delta := &dataset.Dataset{Structure: st}
+			delta.SetBodyFile(qfs.NewMemfileBytes("body.json", []byte(c.delta)))
+
+			f, err := MergeBody(prev, delta, c.primaryKey)
+			if c.expectErr != "" {
+				if err == nil || err.Error() != c.expectErr {
+					t.Fatalf("error mismatch. want: %q, got: %v", c.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := ioutil.ReadAll(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != c.expect {
+				t.Errorf("body mismatch.\nwant: %s\ngot:  %s", c.expect, data)
+			}
+		})
+	}
+}
+
+func TestPrimaryKey(t *testing.T) {
+	if key, err := PrimaryKey("id"); err != nil || len(key) != 1 || key[0] != "id" {
+		t.Errorf("unexpected result: %v %v", key, err)
+	}
+	if key, err := PrimaryKey([]interface{}{"a", "b"}); err != nil || len(key) != 2 {
+		t.Errorf("unexpected result: %v %v", key, err)
+	}
+	if _, err := PrimaryKey([]interface{}{1}); err == nil {
+		t.Error("expected non-string column to error")
+	}
+	if _, err := PrimaryKey(5); err == nil {
+		t.Error("expected non-string key to error")
+	}
+}
@@ -38,6 +38,44 @@ func TestReadBody(t *testing.T) {
 	}
 }
 
+func TestWriteBody(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo(t)
+	ref := addCitiesDataset(t, r)
+
+	cases := []struct {
+		format        string
+		limit, offset int
+		all           bool
+		expect        string
+	}{
+		{"json", 1, 1, false, `[["new york",8500000,44.4,true]]`},
+		{"csv", 2, 0, false, "city,pop,avg_age,in_usa\ntoronto,40000000,55.5,false\nnew york,8500000,44.4,true\n"},
+		{"ndjson", 2, 1, false, "[\"new york\",8500000,44.4,true]\n[\"chicago\",300000,44.4,true]\n"},
+		{"ndjson", 0, 10, false, ""},
+		{"ndjson", 0, 3, true, "[\"chatham\",35000,65.25,true]\n[\"raleigh\",250000,50.65,true]\n"},
+	}
+
+	for _, c := range cases {
+		ds, err := ReadDataset(ctx, r, ref.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = OpenDataset(ctx, r.Filesystem(), ds); err != nil {
+			t.Fatal(err)
+		}
+
+		buf := &bytes.Buffer{}
+		if err := WriteBody(buf, ds, c.format, nil, c.limit, c.offset, c.all); err != nil {
+			t.Errorf("%s %d/%d: %s", c.format, c.limit, c.offset, err)
+			continue
+		}
+		if buf.String() != c.expect {
+			t.Errorf("%s %d/%d: result mismatch.\nwant: %q\ngot:  %q", c.format, c.limit, c.offset, c.expect, buf.String())
+		}
+	}
+}
+
 func TestConvertBodyFormat(t *testing.T) {
 	jsonStructure := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}
 	csvStructure := &dataset.Structure{Format: "csv", Schema: dsfs.BaseTabularSchema}
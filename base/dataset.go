@@ -155,6 +155,13 @@ func CloseDataset(ds *dataset.Dataset) (err error) {
 
 // ListDatasets lists datasets from a repo
 func ListDatasets(ctx context.Context, r repo.Repo, term string, limit, offset int, RPC, publishedOnly, showVersions bool) (res []reporef.DatasetRef, err error) {
+	return ListDatasetsAfter(ctx, r, term, "", limit, offset, RPC, publishedOnly, showVersions)
+}
+
+// ListDatasetsAfter lists datasets from a repo in alias order, starting after
+// the dataset with alias after. Datasets start at offset if after is empty or
+// no longer in the repo
+func ListDatasetsAfter(ctx context.Context, r repo.Repo, term, after string, limit, offset int, RPC, publishedOnly, showVersions bool) (res []reporef.DatasetRef, err error) {
 	store := r.Store()
 	num, err := r.RefCount()
 	if err != nil {
@@ -177,6 +184,14 @@ func ListDatasets(ctx context.Context, r repo.Repo, term string, limit, offset i
 		}
 		res = pub[:i]
 	}
+	if after != "" {
+		for i, ref := range res {
+			if ref.AliasString() == after {
+				offset = i + 1
+				break
+			}
+		}
+	}
 	// if offset is too high, return empty list
 	if offset >= len(res) {
 		return []reporef.DatasetRef{}, nil
@@ -72,13 +72,19 @@ func PrepareSaveRef(
 		return ref, false, dsref.ErrDescribeValidName
 	}
 
-	// Validate that username is our own, it's not valid to try to save a dataset with someone
-	// else's username. Without this check, base will replace the username with our own regardless,
-	// it's better to have an error to display, rather than silently ignore it.
+	// Validate that username is our own, or an organization we're a member of. It's not valid to
+	// try to save a dataset with someone else's username. Without this check, base will replace
+	// the username with our own regardless, it's better to have an error to display, rather than
+	// silently ignore it.
+	orgName := ""
 	if ref.Username != "" && ref.Username != "me" && ref.Username != pro.Peername {
-		return ref, false, fmt.Errorf("cannot save using a different username than %q", pro.Peername)
+		if !book.IsOrgMember(ctx, ref.Username) {
+			return ref, false, fmt.Errorf("cannot save using a different username than %q", pro.Peername)
+		}
+		orgName = ref.Username
+	} else {
+		ref.Username = pro.Peername
 	}
-	ref.Username = pro.Peername
 
 	// attempt to resolve the reference
 	if _, resolveErr := resolver.ResolveRef(ctx, &ref); resolveErr != nil {
@@ -111,7 +117,14 @@ func PrepareSaveRef(
 		return ref, true, fmt.Errorf("invalid dataset name: %s", ref.Name)
 	}
 
-	ref.InitID, err = book.WriteDatasetInit(ctx, ref.Name)
+	if orgName != "" {
+		ref.InitID, err = book.WriteOrgDatasetInit(ctx, orgName, ref.Name)
+		if err == nil {
+			_, err = book.ResolveRef(ctx, &ref)
+		}
+	} else {
+		ref.InitID, err = book.WriteDatasetInit(ctx, ref.Name)
+	}
 	log.Debugf("PrepareSaveRef created new initID=%q ref.Username=%q ref.Name=%q", ref.InitID, ref.Username, ref.Name)
 	return ref, true, err
 }
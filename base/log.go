@@ -23,8 +23,15 @@ var ErrDatasetLogTimeout = fmt.Errorf("datasetLog: timeout")
 
 // DatasetLog fetches the change version history of a dataset
 func DatasetLog(ctx context.Context, r repo.Repo, ref dsref.Ref, limit, offset int, loadDatasets bool) ([]DatasetLogItem, error) {
+	return DatasetLogAfter(ctx, r, ref, "", limit, offset, loadDatasets)
+}
+
+// DatasetLogAfter fetches the change version history of a dataset, starting
+// after the version with path after. History that isn't stored in the
+// logbook is always paged by offset
+func DatasetLogAfter(ctx context.Context, r repo.Repo, ref dsref.Ref, after string, limit, offset int, loadDatasets bool) ([]DatasetLogItem, error) {
 	if book := r.Logbook(); book != nil {
-		if items, err := book.Items(ctx, ref, offset, limit); err == nil {
+		if items, err := book.ItemsAfter(ctx, ref, after, offset, limit); err == nil {
 			// logs are ok with history not existing. This keeps FSI interaction behaviour consistent
 			// TODO (b5) - we should consider having "empty history" be an ok state, instead of marking as an error
 			if len(items) == 0 {
@@ -9,6 +9,7 @@ import (
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/logbook"
 	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/repo/profile"
 	reporef "github.com/qri-io/qri/repo/ref"
 )
 
@@ -104,6 +105,23 @@ func RenameDatasetRef(ctx context.Context, r repo.Repo, ref dsref.Ref, newName s
 // TODO (b5) - make this transactional
 func ModifyRepoUsername(ctx context.Context, r repo.Repo, book *logbook.Book, from, to string) error {
 	log.Debugf("change peername: %s -> %s", from, to)
+	pro, err := r.Profile()
+	if err != nil {
+		return err
+	}
+	if err := RenameRepoRefs(r, pro.ID, from, to); err != nil {
+		return err
+	}
+
+	// we also need to update the logbook
+	return book.WriteAuthorRename(ctx, to)
+}
+
+// RenameRepoRefs moves all references a profile holds in a repo's refstore
+// from one username to another. Only references with a matching ProfileID
+// move, references another profile holds under the username are untouched.
+// Peers call RenameRepoRefs when they learn another author has been renamed
+func RenameRepoRefs(r repo.Repo, profileID profile.ID, from, to string) error {
 	// TODO (b5) - we need to immediately update all dataset references in the refstore on rename
 	// because we currently rely on dsref as our source of canonicalization.
 	// Many places in our codebase call repo.CanonicalizeDatasetRef with an alias reference
@@ -112,13 +130,14 @@ func ModifyRepoUsername(ctx context.Context, r repo.Repo, book *logbook.Book, fr
 	// updates, (like logbook). This is hacky & ugly, but helps us understand how to redesign dsrefs
 	if refs, err := r.References(0, 10000000); err == nil {
 		for _, ref := range refs {
-			if ref.Peername == from {
+			if ref.Peername == from && ref.ProfileID == profileID {
 				update := reporef.DatasetRef{
 					Peername:  to,
 					Name:      ref.Name,
 					Path:      ref.Path,
 					ProfileID: ref.ProfileID,
 					FSIPath:   ref.FSIPath,
+					Published: ref.Published,
 				}
 
 				if err = r.DeleteRef(ref); err != nil {
@@ -130,7 +149,5 @@ func ModifyRepoUsername(ctx context.Context, r repo.Repo, book *logbook.Book, fr
 			}
 		}
 	}
-
-	// we also need to update the logbook
-	return book.WriteAuthorRename(ctx, to)
+	return nil
 }
@@ -137,6 +137,12 @@ func CreateDataset(ctx context.Context, r repo.Repo, writeDest qfs.Filesystem, d
 	if dsName == "" {
 		return nil, fmt.Errorf("cannot create dataset without a name")
 	}
+	// datasets are owned by the saving profile, unless they're saved to the
+	// namespace of an organization
+	ownerID, owner := pro.ID.String(), pro.Peername
+	if ds.Peername != "" && ds.Peername != pro.Peername && ds.ProfileID != "" {
+		ownerID, owner = ds.ProfileID, ds.Peername
+	}
 	if err = Drop(ds, sw.Drop); err != nil {
 		return nil, err
 	}
@@ -159,8 +165,8 @@ func CreateDataset(ctx context.Context, r repo.Repo, writeDest qfs.Filesystem, d
 		// should be ok to skip this error. we may not have the previous
 		// reference locally
 		repo.DeleteVersionInfoShim(r, dsref.Ref{
-			ProfileID: pro.ID.String(),
-			Username:  pro.Peername,
+			ProfileID: ownerID,
+			Username:  owner,
 			Name:      dsName,
 			Path:      ds.PreviousPath,
 		})
@@ -170,9 +176,9 @@ func CreateDataset(ctx context.Context, r repo.Repo, writeDest qfs.Filesystem, d
 	if err != nil {
 		return nil, err
 	}
-	ds.ProfileID = pro.ID.String()
+	ds.ProfileID = ownerID
 	ds.Name = dsName
-	ds.Peername = pro.Peername
+	ds.Peername = owner
 	ds.Path = path
 
 	// TODO(dustmop): Reference is created here in order to update refstore. As we move to initID
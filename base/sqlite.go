@@ -0,0 +1,229 @@
+package base
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	// register the sqlite3 database/sql driver
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/qri-io/qfs"
+)
+
+// SQLiteExtensions is the set of file extensions treated as SQLite databases
+var SQLiteExtensions = []string{".sqlite", ".sqlite3", ".db"}
+
+// IsSQLitePath returns true if a filepath has a SQLite database extension
+func IsSQLitePath(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range SQLiteExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// SQLiteTables lists the names of all user-defined tables in a SQLite
+// database file, in alphabetical order
+func SQLiteTables(ctx context.Context, path string) ([]string, error) {
+	db, err := openSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return sqliteTables(ctx, db)
+}
+
+// SQLiteTableBodyFile reads every row of a table in a SQLite database,
+// returning the table as a CSV file with a header row of column names. The
+// returned file is named "[table].csv", which lets InferStructure detect a
+// body format. Rows are streamed from a read transaction that stays open
+// until the file is read to the end or closed
+func SQLiteTableBodyFile(ctx context.Context, path, table string) (qfs.File, error) {
+	db, err := openSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// list tables & read rows in a single transaction so both see the same
+	// snapshot of a database another process might be writing to
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("reading sqlite database: %w", err)
+	}
+	rows, cols, err := sqliteTableRows(ctx, tx, path, table)
+	if err != nil {
+		// the transaction only reads, rolling back ends it without side effects
+		tx.Rollback()
+		db.Close()
+		return nil, err
+	}
+
+	r, w := io.Pipe()
+	go func() {
+		err := writeSQLiteCSV(w, cols, rows)
+		rows.Close()
+		tx.Rollback()
+		db.Close()
+		w.CloseWithError(err)
+	}()
+
+	return qfs.NewMemfileReader(fmt.Sprintf("%s.csv", table), r), nil
+}
+
+// sqliteTableRows selects all rows of a table within a transaction
+func sqliteTableRows(ctx context.Context, tx *sql.Tx, path, table string) (*sql.Rows, []string, error) {
+	tables, err := sqliteTables(ctx, tx)
+	if err != nil {
+		return nil, nil, err
+	}
+	found := false
+	for _, t := range tables {
+		if t == table {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, nil, fmt.Errorf("table %q not found in %q", table, filepath.Base(path))
+	}
+
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT * FROM %s", quoteSQLiteIdent(table)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading table %q: %w", table, err)
+	}
+	cols, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		return nil, nil, err
+	}
+	return rows, cols, nil
+}
+
+// writeSQLiteCSV writes a header row of column names followed by each row as
+// CSV. Writing stops with an error if the reader closes the pipe
+func writeSQLiteCSV(pw io.Writer, cols []string, rows *sql.Rows) error {
+	w := csv.NewWriter(pw)
+	if err := w.Write(cols); err != nil {
+		return err
+	}
+
+	vals := make([]interface{}, len(cols))
+	ptrs := make([]interface{}, len(cols))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+	record := make([]string, len(cols))
+
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+		for i, v := range vals {
+			record[i] = sqliteValueString(v)
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func openSQLite(path string) (*sql.DB, error) {
+	// sql.Open doesn't touch the filesystem, and the sqlite driver will happily
+	// create a new empty database, so check the file exists first
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("opening sqlite database: %w", err)
+	}
+	dsn, err := sqliteDSN(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening sqlite database: %w", err)
+	}
+	return sql.Open("sqlite3", dsn)
+}
+
+// sqliteDSN builds a read-only SQLite URI filename for a database path.
+// SQLite decodes percent-escapes in URI filenames, so characters like '?',
+// '#' and '%' in the path must be escaped
+func sqliteDSN(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	p := filepath.ToSlash(abs)
+	// windows paths like C:/data.db need a leading slash to be a URI path
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	u := &url.URL{
+		Scheme:   "file",
+		Path:     p,
+		RawQuery: url.Values{"mode": {"ro"}}.Encode(),
+	}
+	return u.String(), nil
+}
+
+// sqliteQueryer is implemented by both *sql.DB and *sql.Tx
+type sqliteQueryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func sqliteTables(ctx context.Context, db sqliteQueryer) ([]string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("listing sqlite tables: %w", err)
+	}
+	defer rows.Close()
+
+	tables := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+// quoteSQLiteIdent double-quotes an identifier, escaping any embedded quotes
+func quoteSQLiteIdent(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+func sqliteValueString(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(x)
+	case string:
+		return x
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	case time.Time:
+		return x.Format(time.RFC3339)
+	default:
+		return fmt.Sprintf("%v", x)
+	}
+}
@@ -0,0 +1,184 @@
+package base
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+)
+
+func TestSQLiteTableBodyFile(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "base_sqlite_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	dbPath := filepath.Join(dir, "test.sqlite")
+	writeTestSQLiteDB(t, dbPath)
+
+	tables, err := SQLiteTables(ctx, dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"events", "users"}, tables); diff != "" {
+		t.Errorf("tables mismatch (-want +got):\n%s", diff)
+	}
+
+	f, err := SQLiteTableBodyFile(ctx, dbPath, "events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.FileName() != "events.csv" {
+		t.Errorf("filename mismatch. want: %q, got: %q", "events.csv", f.FileName())
+	}
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "id,title,score\n1,launch,4.5\n2,\"party, with comma\",\n"
+	if diff := cmp.Diff(expect, string(data)); diff != "" {
+		t.Errorf("body mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := SQLiteTableBodyFile(ctx, dbPath, "missing"); err == nil {
+		t.Error("expected reading a missing table to error")
+	}
+	if _, err := SQLiteTables(ctx, filepath.Join(dir, "nope.sqlite")); err == nil {
+		t.Error("expected opening a missing database to error")
+	}
+
+	f, err = SQLiteTableBodyFile(ctx, dbPath, "events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	ds := &dataset.Dataset{}
+	ds.SetBodyFile(f)
+	if err := InferStructure(ds); err != nil {
+		t.Fatal(err)
+	}
+	if ds.Structure.Format != "csv" {
+		t.Errorf("expected inferred format to be csv. got: %q", ds.Structure.Format)
+	}
+}
+
+func TestSQLiteTableBodyFileClose(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "base_sqlite_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	dbPath := filepath.Join(dir, "test.sqlite")
+	writeTestSQLiteDB(t, dbPath)
+
+	// wait on locks instead of failing immediately
+	db, err := sql.Open("sqlite3", dbPath+"?_busy_timeout=5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	// add enough rows that streaming the table can't finish without a reader
+	for i := 0; i < 5000; i++ {
+		if _, err := db.Exec(`INSERT INTO users VALUES ('bob')`); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, err := SQLiteTableBodyFile(ctx, dbPath, "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := make([]byte, 5)
+	if _, err := io.ReadFull(f, line); err != nil {
+		t.Fatal(err)
+	}
+	if string(line) != "name\n" {
+		t.Errorf("header mismatch. want: %q, got: %q", "name\n", string(line))
+	}
+
+	// closing the file before reading to the end ends the read transaction,
+	// letting writers commit
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO users VALUES ('carol')`); err != nil {
+		t.Errorf("expected write after closing body file to succeed. got: %s", err)
+	}
+}
+
+func TestSQLiteURIPath(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "base_sqlite_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// characters that are meaningful in a URI must be escaped in the DSN
+	created := filepath.Join(dir, "test.sqlite")
+	writeTestSQLiteDB(t, created)
+	dbPath := filepath.Join(dir, "odd ?name #1 100%.sqlite")
+	if err := os.Rename(created, dbPath); err != nil {
+		t.Fatal(err)
+	}
+
+	tables, err := SQLiteTables(ctx, dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"events", "users"}, tables); diff != "" {
+		t.Errorf("tables mismatch (-want +got):\n%s", diff)
+	}
+	f, err := SQLiteTableBodyFile(ctx, dbPath, "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+}
+
+func TestIsSQLitePath(t *testing.T) {
+	cases := map[string]bool{
+		"data.sqlite":  true,
+		"data.SQLITE3": true,
+		"/a/b/c.db":    true,
+		"body.csv":     false,
+		"sqlite":       false,
+	}
+	for path, expect := range cases {
+		if got := IsSQLitePath(path); got != expect {
+			t.Errorf("IsSQLitePath(%q) mismatch. want: %t, got: %t", path, expect, got)
+		}
+	}
+}
+
+func writeTestSQLiteDB(t *testing.T, path string) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	stmts := []string{
+		`CREATE TABLE events (id INTEGER PRIMARY KEY, title TEXT, score REAL)`,
+		`INSERT INTO events (title, score) VALUES ('launch', 4.5)`,
+		`INSERT INTO events (title, score) VALUES ('party, with comma', NULL)`,
+		`CREATE TABLE users (name TEXT)`,
+		`INSERT INTO users VALUES ('alice')`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
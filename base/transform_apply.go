@@ -3,7 +3,6 @@ package base
 import (
 	"context"
 	"errors"
-	"fmt"
 	"io"
 
 	"github.com/qri-io/dataset"
@@ -15,28 +14,29 @@ import (
 
 // TODO(dustmop): Tests. Especially once the `apply` command exists.
 
-// TransformApply applies the transform script to order to modify the changing dataset
+// TransformApply applies the transform script to order to modify the changing
+// dataset. ref is the resolved reference of the dataset being saved, the
+// transform's previous version is loaded from it. opts are applied after the
+// default execution options
 func TransformApply(
 	ctx context.Context,
 	ds *dataset.Dataset,
 	r repo.Repo,
+	ref dsref.Ref,
 	loader dsref.ParseResolveLoad,
 	str ioes.IOStreams,
 	scriptOut io.Writer,
 	secrets map[string]string,
+	opts ...func(*startf.ExecOpts),
 ) error {
-	pro, err := r.Profile()
-	if err != nil {
-		return err
-	}
-
 	var (
+		err    error
 		target = ds
 		head   *dataset.Dataset
 	)
 
-	if ds.Name != "" {
-		head, err = loader(ctx, fmt.Sprintf("%s/%s", pro.Peername, ds.Name))
+	if ref.Username != "" && ref.Name != "" {
+		head, err = loader(ctx, ref.Human())
 		if errors.Is(err, dsref.ErrRefNotFound) || errors.Is(err, dsref.ErrNoHistory) {
 			// Dataset either does not exist yet, or has no history. Not an error
 			head = &dataset.Dataset{}
@@ -50,19 +50,54 @@ func TransformApply(
 	// the startf package will use this function to ensure the same components aren't modified
 	mutateCheck := startf.MutatedComponentsFunc(target)
 
-	opts := []func(*startf.ExecOpts){
+	opts = append([]func(*startf.ExecOpts){
 		startf.AddQriRepo(r),
 		startf.AddMutateFieldCheck(mutateCheck),
 		startf.SetErrWriter(scriptOut),
 		startf.SetSecrets(secrets),
 		startf.AddDatasetLoader(loader),
-	}
+	}, opts...)
+
+	// a body provided alongside the transform isn't a delta
+	userBody := target.BodyFile() != nil
 
 	if err = startf.ExecScript(ctx, target, head, opts...); err != nil {
 		return err
 	}
 
+	if !userBody && target.BodyFile() != nil && head != nil && head.BodyFile() != nil {
+		if err = mergeTransformBody(ctx, target, loader, ref); err != nil {
+			return err
+		}
+	}
+
 	str.PrintErr("✅ transform complete\n")
 
 	return nil
 }
+
+// mergeTransformBody merges the body a transform produced into the body of the
+// previous version when the transform declares a primary key, treating the
+// transform output as a delta of appended and changed rows
+func mergeTransformBody(ctx context.Context, target *dataset.Dataset, loader dsref.ParseResolveLoad, ref dsref.Ref) error {
+	if target.Transform == nil {
+		return nil
+	}
+	primaryKey, err := PrimaryKey(target.Transform.Config[startf.PrimaryKeyConfigKey])
+	if err != nil || primaryKey == nil {
+		return err
+	}
+
+	// the transform may have consumed the previous body by streaming it, load a
+	// fresh copy
+	prev, err := loader(ctx, ref.Human())
+	if err != nil {
+		return err
+	}
+	body, err := MergeBody(prev, target, primaryKey)
+	if err != nil {
+		return err
+	}
+	target.SetBodyFile(body)
+	return nil
+}
@@ -0,0 +1,36 @@
+package base
+
+import (
+	"context"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/dsref"
+)
+
+func TestTransformApplyLoadsResolvedRef(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo(t)
+
+	loaded := []string{}
+	loader := func(ctx context.Context, refstr string) (*dataset.Dataset, error) {
+		loaded = append(loaded, refstr)
+		return nil, dsref.ErrRefNotFound
+	}
+
+	ds := &dataset.Dataset{Transform: &dataset.Transform{}}
+	ds.Transform.SetScriptFile(qfs.NewMemfileBytes("transform.star", []byte(`
+def transform(ds, ctx):
+	ds.set_body([1, 2, 3])
+`)))
+	// datasets in an organization's namespace don't belong to the repo's profile
+	ref := dsref.Ref{Username: "climate_org", Name: "temperatures"}
+	if err := TransformApply(ctx, ds, r, ref, loader, ioes.NewDiscardIOStreams(), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 1 || loaded[0] != "climate_org/temperatures" {
+		t.Errorf("expected the previous version to load from the resolved ref, loaded: %v", loaded)
+	}
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewAccessCommand creates a `qri access` subcommand for working with access
+// tokens
+func NewAccessCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &AccessOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "access",
+		Short: "manage access tokens for this node",
+		Long: `Access tokens authenticate clients that connect to this qri node, like
+subscribers to the websocket event stream.`,
+		Annotations: map[string]string{
+			"group": "other",
+		},
+	}
+
+	token := &cobra.Command{
+		Use:   "token",
+		Short: "create an access token for your profile",
+		Long: `Token creates a signed access token for your profile. Anyone holding the token
+can act as you when connecting to this node, keep it secret.`,
+		Example: `  # Create a token that expires in one day:
+  $ qri access token --ttl 24h`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.CreateToken()
+		},
+	}
+	token.Flags().DurationVar(&o.TTL, "ttl", 0, "duration the token is valid for, defaults to two weeks")
+
+	cmd.AddCommand(token)
+	return cmd
+}
+
+// AccessOptions encapsulates state for access commands
+type AccessOptions struct {
+	ioes.IOStreams
+
+	TTL time.Duration
+
+	AccessMethods *lib.AccessMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *AccessOptions) Complete(f Factory, args []string) (err error) {
+	o.AccessMethods, err = f.AccessMethods()
+	return
+}
+
+// CreateToken prints a new access token
+func (o *AccessOptions) CreateToken() error {
+	p := &lib.CreateAuthTokenParams{TTL: o.TTL}
+	var token string
+	if err := o.AccessMethods.CreateAuthToken(p, &token); err != nil {
+		return err
+	}
+	fmt.Fprintln(o.Out, token)
+	return nil
+}
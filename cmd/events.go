@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/errors"
+	"github.com/qri-io/qri/journal"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewEventsCommand creates a `qri events` cobra command for reading the
+// event journal
+func NewEventsCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &EventsOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: "list events recorded on this node",
+		Long: `Events lists entries from the event journal, an append-only record of every
+event that happens on this qri node, like saving or removing a dataset. Each
+entry records the time of the event and the profile that caused it.
+
+The event journal is disabled by default. Enable it with:
+  $ qri config set repo.eventjournal true`,
+		Example: `  # Show commits made in the last hour:
+  $ qri events --since 1h --type dataset:CommitChange
+
+  # Show all events since a point in time as JSON:
+  $ qri events --since 2020-10-01T00:00:00Z --format json`,
+		Annotations: map[string]string{
+			"group": "other",
+		},
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().StringVar(&o.Since, "since", "", "only show events since a duration ago like 1h, or an RFC3339 timestamp")
+	cmd.Flags().StringSliceVar(&o.Types, "type", nil, "only show events of the given types")
+	cmd.Flags().StringVarP(&o.Format, "format", "f", "", "set output format [json]")
+	cmd.Flags().IntVar(&o.PageSize, "page-size", 25, "page size of results, default 25")
+	cmd.Flags().IntVar(&o.Page, "page", 1, "page number of results, default 1")
+
+	return cmd
+}
+
+// EventsOptions encapsulates state for the events command
+type EventsOptions struct {
+	ioes.IOStreams
+
+	Since    string
+	Types    []string
+	Format   string
+	PageSize int
+	Page     int
+
+	EventMethods *lib.EventMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *EventsOptions) Complete(f Factory, args []string) (err error) {
+	if o.Format != "" && o.Format != "json" {
+		return errors.New(lib.ErrBadArgs, fmt.Sprintf("invalid format %q, only json is supported", o.Format))
+	}
+	o.EventMethods, err = f.EventMethods()
+	return
+}
+
+// Run executes the events command
+func (o *EventsOptions) Run() error {
+	since, err := lib.ParseEventsSince(o.Since)
+	if err != nil {
+		return errors.New(lib.ErrBadArgs, err.Error())
+	}
+
+	// convert Page and PageSize to Limit and Offset
+	page := util.NewPage(o.Page, o.PageSize)
+	p := &lib.EventsParams{
+		Since: since,
+		Types: o.Types,
+		ListParams: lib.ListParams{
+			Limit:  page.Limit(),
+			Offset: page.Offset(),
+		},
+	}
+
+	res := []journal.Entry{}
+	if err := o.EventMethods.List(p, &res); err != nil {
+		return err
+	}
+
+	if o.Format == "json" {
+		data, err := json.MarshalIndent(res, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(o.Out, string(data))
+		return nil
+	}
+
+	if len(res) == 0 {
+		printInfo(o.Out, "no events")
+		return nil
+	}
+	for _, e := range res {
+		fmt.Fprintf(o.Out, "%d\t%s\t%s\t%s\n", e.Seq, e.Timestamp.Format("2006-01-02 15:04:05"), e.Type, e.ProfileID)
+	}
+	return nil
+}
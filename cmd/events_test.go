@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEvents(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_events", "qri_test_events")
+	defer run.Delete()
+
+	if err := run.ExecCommand("qri events"); err == nil {
+		t.Fatal("expected listing events with the journal disabled to error")
+	}
+
+	run.MustExec(t, "qri config set repo.eventjournal true")
+	run.MustExec(t, "qri save --body testdata/movies/body_ten.csv me/movies")
+
+	output := run.MustExec(t, "qri events --since 1h --type dataset:CommitChange")
+	if !strings.Contains(output, "dataset:CommitChange") {
+		t.Errorf("expected commit change event to be listed. got:\n%s", output)
+	}
+	if strings.Contains(output, "dataset:Init") {
+		t.Errorf("expected events to be filtered by type. got:\n%s", output)
+	}
+
+	if err := run.ExecCommand("qri events --since yesterday"); err == nil {
+		t.Error("expected an invalid since value to error")
+	}
+}
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"net/rpc"
 	"os"
 	"path/filepath"
 
@@ -23,11 +22,13 @@ type Factory interface {
 	CryptoGenerator() gen.CryptoGenerator
 
 	Init() error
-	RPC() *rpc.Client
+	RPC() *lib.RPCClient
 	ConnectionNode() (*p2p.QriNode, error)
 
+	AccessMethods() (*lib.AccessMethods, error)
 	ConfigMethods() (*lib.ConfigMethods, error)
 	DatasetMethods() (*lib.DatasetMethods, error)
+	EventMethods() (*lib.EventMethods, error)
 	RemoteMethods() (*lib.RemoteMethods, error)
 	RegistryClientMethods() (*lib.RegistryClientMethods, error)
 	LogMethods() (*lib.LogMethods, error)
@@ -35,8 +36,12 @@ type Factory interface {
 	ProfileMethods() (*lib.ProfileMethods, error)
 	SearchMethods() (*lib.SearchMethods, error)
 	SQLMethods() (*lib.SQLMethods, error)
+	TransformMethods() (*lib.TransformMethods, error)
 	FSIMethods() (*lib.FSIMethods, error)
 	RenderMethods() (*lib.RenderMethods, error)
+	WebhookMethods() (*lib.WebhookMethods, error)
+	FollowMethods() (*lib.FollowMethods, error)
+	OrgMethods() (*lib.OrgMethods, error)
 }
 
 // StandardRepoPath returns qri paths based on the QRI_PATH environment
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"context"
-	"net/rpc"
 
 	"github.com/qri-io/ioes"
 	"github.com/qri-io/qri/config"
@@ -28,7 +27,7 @@ type TestFactory struct {
 	config *config.Config
 	node   *p2p.QriNode
 	repo   repo.Repo
-	rpc    *rpc.Client
+	rpc    *lib.RPCClient
 }
 
 // NewTestFactory creates TestFactory object with an in memory test repo
@@ -127,10 +126,15 @@ func (t TestFactory) ConnectionNode() (*p2p.QriNode, error) {
 }
 
 // RPC returns from internal state
-func (t TestFactory) RPC() *rpc.Client {
+func (t TestFactory) RPC() *lib.RPCClient {
 	return nil
 }
 
+// AccessMethods generates a lib.AccessMethods from internal state
+func (t TestFactory) AccessMethods() (*lib.AccessMethods, error) {
+	return lib.NewAccessMethods(t.inst), nil
+}
+
 // ConfigMethods generates a lib.ConfigMethods from internal state
 func (t TestFactory) ConfigMethods() (*lib.ConfigMethods, error) {
 	return lib.NewConfigMethods(t.inst), nil
@@ -176,6 +180,11 @@ func (t TestFactory) SearchMethods() (*lib.SearchMethods, error) {
 	return lib.NewSearchMethods(t.inst), nil
 }
 
+// TransformMethods generates a lib.TransformMethods from internal state
+func (t TestFactory) TransformMethods() (*lib.TransformMethods, error) {
+	return lib.NewTransformMethods(t.inst), nil
+}
+
 // SQLMethods generates a lib.SQLhMethods from internal state
 func (t TestFactory) SQLMethods() (*lib.SQLMethods, error) {
 	return lib.NewSQLMethods(t.inst), nil
@@ -185,3 +194,23 @@ func (t TestFactory) SQLMethods() (*lib.SQLMethods, error) {
 func (t TestFactory) RenderMethods() (*lib.RenderMethods, error) {
 	return lib.NewRenderMethods(t.inst), nil
 }
+
+// EventMethods generates a lib.EventMethods from internal state
+func (t TestFactory) EventMethods() (*lib.EventMethods, error) {
+	return lib.NewEventMethods(t.inst), nil
+}
+
+// WebhookMethods generates a lib.WebhookMethods from internal state
+func (t TestFactory) WebhookMethods() (*lib.WebhookMethods, error) {
+	return lib.NewWebhookMethods(t.inst), nil
+}
+
+// FollowMethods generates a lib.FollowMethods from internal state
+func (t TestFactory) FollowMethods() (*lib.FollowMethods, error) {
+	return lib.NewFollowMethods(t.inst), nil
+}
+
+// OrgMethods generates a lib.OrgMethods from internal state
+func (t TestFactory) OrgMethods() (*lib.OrgMethods, error) {
+	return lib.NewOrgMethods(t.inst), nil
+}
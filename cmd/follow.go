@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/follow"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewFollowCommand creates a `qri follow` command for subscribing to new
+// versions of peers' datasets
+func NewFollowCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &FollowOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "follow [USERNAME | USERNAME/DATASET]",
+		Short: "follow peers & datasets to hear about new versions",
+		Long: `Peers announce new versions of published datasets on the p2p network. Follow
+a peer to hear about all of their published datasets, or a single dataset.
+Announcements are collected in a feed while qri is connected. Start
+listening with:
+
+  $ qri connect
+
+With --auto-pull, new versions are fetched as they're announced. Following
+again updates the auto-pull setting. With no arguments, follow lists
+follows.`,
+		Example: `  # Follow all published datasets of a peer:
+  $ qri follow b5
+
+  # Follow a dataset, pulling new versions as they're announced:
+  $ qri follow b5/world_bank_population --auto-pull
+
+  # Show new versions announced by follows:
+  $ qri follow --feed
+
+  # Stop following a dataset:
+  $ qri follow --unfollow b5/world_bank_population`,
+		Annotations: map[string]string{
+			"group": "network",
+		},
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			switch {
+			case o.Feed:
+				return o.ShowFeed()
+			case o.Unfollow:
+				return o.RunUnfollow()
+			case o.Ref != "":
+				return o.Follow()
+			default:
+				return o.List()
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.AutoPull, "auto-pull", false, "fetch new versions as they're announced")
+	cmd.Flags().BoolVar(&o.Unfollow, "unfollow", false, "stop following")
+	cmd.Flags().BoolVar(&o.Feed, "feed", false, "show new versions announced by follows")
+	cmd.Flags().IntVar(&o.Limit, "limit", 25, "number of feed items to show")
+	return cmd
+}
+
+// FollowOptions encapsulates state for the follow command
+type FollowOptions struct {
+	ioes.IOStreams
+
+	Ref      string
+	AutoPull bool
+	Unfollow bool
+	Feed     bool
+	Limit    int
+
+	FollowMethods *lib.FollowMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *FollowOptions) Complete(f Factory, args []string) (err error) {
+	if len(args) > 0 {
+		o.Ref = args[0]
+	}
+	if o.Unfollow && o.Ref == "" {
+		return fmt.Errorf("unfollow requires a username or dataset reference")
+	}
+	o.FollowMethods, err = f.FollowMethods()
+	return
+}
+
+// Follow adds a follow
+func (o *FollowOptions) Follow() error {
+	p := &lib.FollowParams{Ref: o.Ref, AutoPull: o.AutoPull}
+	res := follow.Follow{}
+	if err := o.FollowMethods.Follow(p, &res); err != nil {
+		return err
+	}
+	if res.AutoPull {
+		printSuccess(o.Out, "following %s, new versions will be pulled", res.Alias())
+	} else {
+		printSuccess(o.Out, "following %s", res.Alias())
+	}
+	return nil
+}
+
+// RunUnfollow removes a follow
+func (o *FollowOptions) RunUnfollow() error {
+	p := &lib.UnfollowParams{Ref: o.Ref}
+	res := follow.Follow{}
+	if err := o.FollowMethods.Unfollow(p, &res); err != nil {
+		return err
+	}
+	printSuccess(o.Out, "unfollowed %s", res.Alias())
+	return nil
+}
+
+// List prints follows
+func (o *FollowOptions) List() error {
+	p := &lib.ListParams{Limit: -1}
+	res := []follow.Follow{}
+	if err := o.FollowMethods.Following(p, &res); err != nil {
+		return err
+	}
+
+	if len(res) == 0 {
+		printInfo(o.Out, "not following anyone")
+		return nil
+	}
+	for _, f := range res {
+		if f.AutoPull {
+			fmt.Fprintf(o.Out, "%s\tauto-pull\n", f.Alias())
+		} else {
+			fmt.Fprintln(o.Out, f.Alias())
+		}
+	}
+	return nil
+}
+
+// ShowFeed prints new versions announced by follows, newest first
+func (o *FollowOptions) ShowFeed() error {
+	p := &lib.ListParams{Limit: o.Limit}
+	res := []follow.Item{}
+	if err := o.FollowMethods.Feed(p, &res); err != nil {
+		return err
+	}
+
+	if len(res) == 0 {
+		printInfo(o.Out, "no new versions")
+		return nil
+	}
+	for _, it := range res {
+		vi := it.Version
+		status := ""
+		if it.Pulled {
+			status = "\tpulled"
+		} else if it.PullError != "" {
+			status = fmt.Sprintf("\tpull failed: %s", it.PullError)
+		}
+		fmt.Fprintf(o.Out, "%s\t%s/%s@%s%s\n", it.Received.Format("2006-01-02 15:04:05"), vi.Username, vi.Name, vi.Path, status)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFollowCommand(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_follow", "qri_test_follow")
+	defer run.Delete()
+
+	run.MustExec(t, "qri save --body=testdata/movies/body_ten.csv me/movies")
+
+	output := run.MustExec(t, "qri follow me/movies --auto-pull")
+	if !strings.Contains(output, "following test_peer_follow/movies, new versions will be pulled") {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+
+	output = run.MustExec(t, "qri follow")
+	if !strings.Contains(output, "test_peer_follow/movies\tauto-pull") {
+		t.Errorf("expected follow to be listed. got:\n%s", output)
+	}
+
+	output = run.MustExec(t, "qri follow --feed")
+	if !strings.Contains(output, "no new versions") {
+		t.Errorf("expected empty feed. got:\n%s", output)
+	}
+
+	run.MustExec(t, "qri follow --unfollow test_peer_follow/movies")
+	output = run.MustExec(t, "qri follow")
+	if !strings.Contains(output, "not following anyone") {
+		t.Errorf("expected no follows. got:\n%s", output)
+	}
+}
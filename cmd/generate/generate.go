@@ -1,13 +1,17 @@
-// Package generate is a command that creates a bash completion file for qri
+// Package generate is a command that creates a bash completion file,
+// markdown docs & the OpenAPI spec for qri
 package main
 
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/api"
 	"github.com/qri-io/qri/cmd"
 	"github.com/qri-io/qri/repo/gen"
 	"github.com/spf13/cobra/doc"
@@ -37,7 +41,17 @@ func main() {
 			log.Fatal(err)
 		}
 		fmt.Println("done")
+	case "openapi":
+		fmt.Printf("generating OpenAPI spec...")
+		data, err := api.OpenAPISpec()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join("api", api.OpenAPISpecFilename), data, 0644); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("done")
 	default:
-		fmt.Println("please provide a generate argument: [docs|completions]")
+		fmt.Println("please provide a generate argument: [docs|completions|openapi]")
 	}
 }
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/errors"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewImportCommand creates a new `qri import` cobra command for creating
+// datasets from a directory of files
+func NewImportCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &ImportOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "import DIRECTORY",
+		Short: "create datasets from a directory of files",
+		Long: `Import creates a new dataset for every data file in a directory, and for
+every table of every SQLite database in that directory. Import is the quickest
+way to bootstrap a qri repo from existing data.
+
+Supported data files are csv, json, cbor, and xlsx. Files with a .sqlite,
+.sqlite3, or .db extension are read as SQLite databases. Subdirectories and
+hidden files are ignored.
+
+Dataset names are generated from the file or table name. Use the ` + "`--prefix`" + `
+flag to prepend a string to every generated name. Import only creates new
+datasets, and will fail if a generated name is already in use.`,
+		Example: `  # Create a dataset for each file in the data directory:
+  $ qri import ./data
+
+  # Create datasets from every table in a database, prefixing names with "shop":
+  $ qri import --prefix shop ./database_dir`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().StringVar(&o.Prefix, "prefix", "", "prefix to add to generated dataset names")
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", false, "list datasets that would be created without saving")
+
+	return cmd
+}
+
+// ImportOptions encapsulates state for the import command
+type ImportOptions struct {
+	ioes.IOStreams
+
+	Dir    string
+	Prefix string
+	DryRun bool
+
+	DatasetMethods *lib.DatasetMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *ImportOptions) Complete(f Factory, args []string) (err error) {
+	if len(args) > 0 {
+		o.Dir = args[0]
+	}
+	if err = qfs.AbsPath(&o.Dir); err != nil {
+		return err
+	}
+	o.DatasetMethods, err = f.DatasetMethods()
+	return
+}
+
+// Validate checks that all user input is valid
+func (o *ImportOptions) Validate() error {
+	if o.Dir == "" {
+		return errors.New(lib.ErrBadArgs, "please provide a directory to import, for example:\n    $ qri import ./data\nsee `qri import --help` for more details")
+	}
+	return nil
+}
+
+// Run executes the import command
+func (o *ImportOptions) Run() error {
+	o.StartSpinner()
+	defer o.StopSpinner()
+
+	p := &lib.ImportParams{
+		Dir:    o.Dir,
+		Prefix: o.Prefix,
+		DryRun: o.DryRun,
+	}
+	res := []dsref.VersionInfo{}
+	err := o.DatasetMethods.Import(p, &res)
+	o.StopSpinner()
+	if err != nil {
+		// a failed import can still have saved some datasets
+		for _, vi := range res {
+			ref := vi.SimpleRef()
+			ref.ProfileID = ""
+			printSuccess(o.ErrOut, "dataset saved: %s", ref.String())
+		}
+		return err
+	}
+
+	if len(res) == 0 {
+		printWarning(o.ErrOut, "no datasets found to import")
+		return nil
+	}
+	for _, vi := range res {
+		ref := vi.SimpleRef()
+		ref.ProfileID = ""
+		if o.DryRun {
+			printInfo(o.ErrOut, "dry run, would save: %s", ref.String())
+			continue
+		}
+		printSuccess(o.ErrOut, "dataset saved: %s", ref.String())
+	}
+	return nil
+}
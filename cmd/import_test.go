@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	qrierr "github.com/qri-io/qri/errors"
+	"github.com/qri-io/qri/lib"
+)
+
+func TestImportValidate(t *testing.T) {
+	opt := &ImportOptions{}
+	err := opt.Validate()
+	if err == nil {
+		t.Fatal("expected validating an empty directory to error")
+	}
+	if err.Error() != lib.ErrBadArgs.Error() {
+		t.Errorf("error mismatch. want: %q, got: %q", lib.ErrBadArgs, err)
+	}
+	expectMsg := "please provide a directory to import, for example:\n    $ qri import ./data\nsee `qri import --help` for more details"
+	if libErr, ok := err.(qrierr.Error); !ok || libErr.Message() != expectMsg {
+		t.Errorf("expected user-friendly message %q", expectMsg)
+	}
+
+	opt = &ImportOptions{Dir: "/path/to/data"}
+	if err := opt.Validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestImportDirectory(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_import", "qri_test_import")
+	defer run.Delete()
+
+	dir := run.MakeTmpDir(t, "import_dir")
+	run.MustWriteFile(t, filepath.Join(dir, "movies.csv"), "title,duration\nAvatar,178\n")
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "shop.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE Orders (id INTEGER, total REAL)`,
+		`INSERT INTO Orders VALUES (1, 9.99)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	run.MustExecCombinedOutErr(t, "qri import --prefix 2020 "+dir)
+
+	output := run.MustExec(t, "qri list --format json")
+	for _, name := range []string{"dataset_2020_movies", "dataset_2020_orders"} {
+		if !strings.Contains(output, name) {
+			t.Errorf("expected dataset %q to be listed. got:\n%s", name, output)
+		}
+	}
+
+	if err := run.ExecCommand("qri import --prefix 2020 " + dir); err == nil {
+		t.Error("expected importing the same directory twice to error")
+	}
+}
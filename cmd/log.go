@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 
 	util "github.com/qri-io/apiutil"
 	"github.com/qri-io/ioes"
@@ -33,6 +34,10 @@ working backwards in time.
 
 The log command can get the list of versions for a local dataset or a dataset
 on the network at a remote.
+
+With the --provenance flag log shows the dependency graph of a version instead:
+the datasets its transform loaded, urls it fetched & secrets it used, following
+each loaded dataset back to its own inputs.
 `,
 		Example: `  # Show log for the local dataset b5/precip:
   $ qri log b5/precip
@@ -41,7 +46,10 @@ on the network at a remote.
   $ qri log ramfox/league_stats
 	
   # Show log for a dataset chriswhong/nyc_parking_tickets on a remote named "nycdatacollection"
-  $ qri log chriswhong/nyc_parking_tickets --remote nycdatacollection`,
+  $ qri log chriswhong/nyc_parking_tickets --remote nycdatacollection
+
+  # Show the datasets & urls the transform of b5/precip drew on:
+  $ qri log --provenance b5/precip`,
 		Annotations: map[string]string{
 			"group": "dataset",
 		},
@@ -60,6 +68,7 @@ on the network at a remote.
 	cmd.Flags().StringVarP(&o.RemoteName, "remote", "", "", "name of remote to fetch from, disables local actions. `registry` will search the default qri registry")
 	cmd.Flags().BoolVarP(&o.Local, "local", "l", false, "only fetch local logs, disables network actions")
 	cmd.Flags().BoolVarP(&o.Pull, "pull", "p", false, "fetch the latest logs from the network")
+	cmd.Flags().BoolVar(&o.Provenance, "provenance", false, "show the graph of inputs that contributed to a version")
 
 	return cmd
 }
@@ -68,11 +77,12 @@ on the network at a remote.
 type LogOptions struct {
 	ioes.IOStreams
 
-	PageSize int
-	Page     int
-	Refs     *RefSelect
-	Local    bool
-	Pull     bool
+	PageSize   int
+	Page       int
+	Refs       *RefSelect
+	Local      bool
+	Pull       bool
+	Provenance bool
 
 	// remote fetching specific flags
 	RemoteName string
@@ -88,6 +98,9 @@ func (o *LogOptions) Complete(f Factory, args []string) (err error) {
 	if o.Local && (o.RemoteName != "" || o.Pull) {
 		return errors.New(err, "cannot use 'local' flag with either the 'remote' or 'pull' flags")
 	}
+	if o.Provenance && (o.RemoteName != "" || o.Pull) {
+		return fmt.Errorf("cannot use 'provenance' flag with either the 'remote' or 'pull' flags")
+	}
 
 	if o.Refs, err = GetCurrentRefSelect(f, args, AnyNumberOfReferences, nil); err != nil {
 		if err == repo.ErrEmptyRef {
@@ -106,6 +119,15 @@ type DatasetLogItem = logbook.DatasetLogItem
 func (o *LogOptions) Run() error {
 	printRefSelect(o.ErrOut, o.Refs)
 
+	if o.Provenance {
+		res := &lib.ProvenanceNode{}
+		if err := o.LogMethods.Provenance(&lib.ProvenanceParams{Ref: o.Refs.Ref()}, res); err != nil {
+			return err
+		}
+		printProvenance(o.Out, res)
+		return nil
+	}
+
 	// convert Page and PageSize to Limit and Offset
 	page := util.NewPage(o.Page, o.PageSize)
 
@@ -137,6 +159,68 @@ func makeItemsAndPrint(refs []DatasetLogItem, out io.Writer, page util.Page) {
 	printItems(out, items, page.Offset())
 }
 
+// printProvenance writes a provenance graph as a tree
+func printProvenance(w io.Writer, node *lib.ProvenanceNode) {
+	fmt.Fprintln(w, provenanceLabel(node))
+	printProvenanceChildren(w, node, "")
+}
+
+func provenanceLabel(node *lib.ProvenanceNode) string {
+	label := node.Ref
+	if node.Path != "" {
+		label = fmt.Sprintf("%s@%s", node.Ref, node.Path)
+	}
+	if node.Missing {
+		label += " (not found locally)"
+	} else if node.Repeated {
+		label += " (listed above)"
+	}
+	return label
+}
+
+func printProvenanceChildren(w io.Writer, node *lib.ProvenanceNode, prefix string) {
+	var details []string
+	if p := node.Provenance; p != nil {
+		details = append(details, fmt.Sprintf("qri: %s  starlark: %s", p.Qri, p.Starlark))
+		if len(p.Secrets) > 0 {
+			details = append(details, fmt.Sprintf("secrets: %s", strings.Join(p.Secrets, ", ")))
+		}
+	}
+
+	type child struct {
+		label string
+		node  *lib.ProvenanceNode
+	}
+	var children []child
+	if p := node.Provenance; p != nil {
+		for _, u := range p.URLs {
+			children = append(children, child{label: fmt.Sprintf("%s %s %d sha256:%s", u.Method, u.URL, u.Status, u.SHA256)})
+		}
+	}
+	for _, in := range node.Inputs {
+		children = append(children, child{label: provenanceLabel(in), node: in})
+	}
+
+	detailPrefix := prefix + "    "
+	if len(children) > 0 {
+		detailPrefix = prefix + "│   "
+	}
+	for _, d := range details {
+		fmt.Fprintln(w, detailPrefix+d)
+	}
+
+	for i, c := range children {
+		connector, indent := "├── ", "│   "
+		if i == len(children)-1 {
+			connector, indent = "└── ", "    "
+		}
+		fmt.Fprintln(w, prefix+connector+c.label)
+		if c.node != nil {
+			printProvenanceChildren(w, c.node, prefix+indent)
+		}
+	}
+}
+
 // NewLogbookCommand creates a `qri logbook` cobra command
 func NewLogbookCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
 	o := &LogbookOptions{IOStreams: ioStreams}
@@ -40,3 +40,28 @@ func TestLogbookCommand(t *testing.T) {
 		t.Errorf("unexpected (-want +got):\n%s", diff)
 	}
 }
+
+func TestLogProvenance(t *testing.T) {
+	r := NewTestRunner(t, "test_peer_log_provenance", "qri_test_log_provenance")
+	defer r.Delete()
+
+	r.MustExec(t, "qri save --body=testdata/movies/body_ten.csv me/movies")
+	r.MustExec(t, "qri save --file=testdata/movies/tf_provenance.star me/movie_count")
+	r.MustExec(t, "qri save --file=testdata/movies/tf_provenance_report.star me/report")
+
+	output := r.MustExec(t, "qri log --provenance me/report")
+	expect := `test_peer_log_provenance/report@/ipfs/QmWSFbioJH8JNUQKfrSWS9h9NTyhmiUXXVPbHtJLk4FiKc
+│   qri: test_version  starlark: test_version
+├── test_peer_log_provenance/movie_count@/ipfs/Qmd9nCwjyZ8STWr6PFrHptWpuZ5WgTqjJDsRYHUZ1rPubj
+│   │   qri: test_version  starlark: test_version
+│   └── test_peer_log_provenance/movies@/ipfs/QmNX9ZKXtdskpYSQ5spd1qvqB2CPoWfJbdAcWoFndintrF
+└── test_peer_log_provenance/movies@/ipfs/QmNX9ZKXtdskpYSQ5spd1qvqB2CPoWfJbdAcWoFndintrF (listed above)
+`
+	if diff := cmp.Diff(expect, output); diff != "" {
+		t.Errorf("provenance mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := r.ExecCommand("qri log --provenance --pull me/report"); err == nil {
+		t.Error("expected using the provenance & pull flags together to error")
+	}
+}
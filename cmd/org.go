@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/logbook"
+	"github.com/spf13/cobra"
+)
+
+// NewOrgCommand creates a `qri org` subcommand for working with organizations
+func NewOrgCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &OrgOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "org",
+		Short: "create organizations & manage their members",
+		Long: `Organizations are namespaces for datasets shared by a group of members. Any
+member can save & push to datasets in an organization's namespace:
+
+  $ qri save --body data.csv my_org/dataset
+
+Admins add & remove members. Membership is recorded in the logbook, and
+travels with the organization's datasets when they're pushed & pulled.
+Remotes only accept pushes to an organization from its members. With no
+subcommand, org lists organizations.`,
+		Annotations: map[string]string{
+			"group": "network",
+		},
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.List()
+		},
+	}
+
+	create := &cobra.Command{
+		Use:     "create NAME",
+		Short:   "create an organization, with you as its admin",
+		Example: `  $ qri org create climate_collective`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Create()
+		},
+	}
+
+	members := &cobra.Command{
+		Use:   "members ORG",
+		Short: "list members of an organization",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Members()
+		},
+	}
+
+	add := &cobra.Command{
+		Use:   "add ORG USERNAME",
+		Short: "add a member to an organization, or change their role",
+		Example: `  # Give b5 write access to datasets of climate_collective:
+  $ qri org add climate_collective b5
+
+  # Make b5 an admin:
+  $ qri org add climate_collective b5 --role admin`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.AddMember()
+		},
+	}
+	add.Flags().StringVar(&o.Role, "role", logbook.OrgRoleMember, "role of the member, either \"member\" or \"admin\"")
+
+	remove := &cobra.Command{
+		Use:   "remove ORG USERNAME",
+		Short: "remove a member from an organization",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.RemoveMember()
+		},
+	}
+
+	cmd.AddCommand(create, members, add, remove)
+	return cmd
+}
+
+// OrgOptions encapsulates state for org commands
+type OrgOptions struct {
+	ioes.IOStreams
+
+	Org      string
+	Username string
+	Role     string
+
+	OrgMethods *lib.OrgMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *OrgOptions) Complete(f Factory, args []string) (err error) {
+	if len(args) > 0 {
+		o.Org = args[0]
+	}
+	if len(args) > 1 {
+		o.Username = args[1]
+	}
+	o.OrgMethods, err = f.OrgMethods()
+	return
+}
+
+// List prints organizations
+func (o *OrgOptions) List() error {
+	p := &lib.ListParams{Limit: -1}
+	res := []logbook.Org{}
+	if err := o.OrgMethods.List(p, &res); err != nil {
+		return err
+	}
+
+	if len(res) == 0 {
+		printInfo(o.Out, "no organizations")
+		return nil
+	}
+	for _, org := range res {
+		fmt.Fprintf(o.Out, "%s\t%d members\n", org.Name, len(org.Members))
+	}
+	return nil
+}
+
+// Create makes a new organization
+func (o *OrgOptions) Create() error {
+	res := logbook.Org{}
+	if err := o.OrgMethods.Create(&lib.OrgParams{Name: o.Org}, &res); err != nil {
+		return err
+	}
+	printSuccess(o.Out, "created organization %s", res.Name)
+	return nil
+}
+
+// Members prints the members of an organization
+func (o *OrgOptions) Members() error {
+	res := logbook.Org{}
+	if err := o.OrgMethods.Get(&lib.OrgParams{Name: o.Org}, &res); err != nil {
+		return err
+	}
+	for _, m := range res.Members {
+		fmt.Fprintf(o.Out, "%s\t%s\n", m.Username, m.Role)
+	}
+	return nil
+}
+
+// AddMember adds a member to an organization
+func (o *OrgOptions) AddMember() error {
+	p := &lib.OrgMemberParams{Org: o.Org, Username: o.Username, Role: o.Role}
+	res := logbook.Org{}
+	if err := o.OrgMethods.AddMember(p, &res); err != nil {
+		return err
+	}
+	printSuccess(o.Out, "%s is now %s of %s", o.Username, roleWithArticle(o.Role), o.Org)
+	return nil
+}
+
+// RemoveMember removes a member from an organization
+func (o *OrgOptions) RemoveMember() error {
+	p := &lib.OrgMemberParams{Org: o.Org, Username: o.Username}
+	res := logbook.Org{}
+	if err := o.OrgMethods.RemoveMember(p, &res); err != nil {
+		return err
+	}
+	printSuccess(o.Out, "removed %s from %s", o.Username, o.Org)
+	return nil
+}
+
+func roleWithArticle(role string) string {
+	if role == logbook.OrgRoleAdmin {
+		return "an admin"
+	}
+	return "a member"
+}
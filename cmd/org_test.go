@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOrgCommand(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_org", "qri_test_org")
+	defer run.Delete()
+
+	output := run.MustExec(t, "qri org")
+	if !strings.Contains(output, "no organizations") {
+		t.Errorf("expected no orgs. got:\n%s", output)
+	}
+
+	output = run.MustExec(t, "qri org create climate")
+	if !strings.Contains(output, "created organization climate") {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+
+	run.MustExec(t, "qri save --body=testdata/movies/body_ten.csv climate/movies")
+	output = run.MustExec(t, "qri list")
+	if !strings.Contains(output, "climate/movies") {
+		t.Errorf("expected org dataset to be listed. got:\n%s", output)
+	}
+
+	output = run.MustExec(t, "qri org members climate")
+	if !strings.Contains(output, "test_peer_org\tadmin") {
+		t.Errorf("expected creator to be an admin. got:\n%s", output)
+	}
+
+	if err := run.ExecCommand("qri org add climate nobody"); err == nil {
+		t.Error("expected adding an unknown peer to fail")
+	}
+	if err := run.ExecCommand("qri save --body=testdata/movies/body_ten.csv someone_else/movies"); err == nil {
+		t.Error("expected saving to another user's namespace to fail")
+	}
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/logbook"
+	"github.com/qri-io/qri/repo/gen"
+	"github.com/spf13/cobra"
+)
+
+// NewProfileCommand creates a `qri profile` command for working with this
+// peer's profile
+func NewProfileCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "manage your profile",
+		Long: `Profile manages your qri identity. To edit profile details like your name
+or email, use qri config.`,
+		Annotations: map[string]string{
+			"group": "other",
+		},
+	}
+
+	cmd.AddCommand(NewProfileKeyCommand(f, ioStreams))
+	return cmd
+}
+
+// NewProfileKeyCommand creates a `qri profile key` subcommand for rotating
+// keys & managing device keys
+func NewProfileKeyCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &ProfileKeyOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "key",
+		Short: "rotate your profile key & manage device keys",
+		Long: `Your profile is identified by the key it was created with. Rotating replaces
+that key with a new one, keeping your profileID. Rotation is signed with the
+old key and recorded in your logbook, so peers can verify logs written before
+the rotation against the key that was valid at the time.
+
+Device keys let other devices sign logs on your behalf. A device first signs
+a request to join your profile with sign-device, then your primary key adds
+it with add-device. Devices are revoked with your primary key. Revoking a
+device doesn't invalidate logs it signed before it was revoked. With no
+subcommand, key lists your keys.`,
+		Example: `  # Replace your primary key, for example after a laptop is lost:
+  $ qri profile key rotate
+
+  # On the device, sign a request to join your profile:
+  $ qri profile key sign-device work_laptop QmZePf5LeXow3RW5U1AgEiNbW46YnRGhZ7HPvm1UmPFPwt
+
+  # Authorize the device, using the public key & signature it printed:
+  $ qri profile key add-device work_laptop CAASpgIwggEiMA0GCSqGSIb3DQEBAQUAA4IBDwAwggEKAoIBAQ... Xm9Kc2...
+
+  # Revoke a device key:
+  $ qri profile key revoke-device work_laptop`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.List()
+		},
+	}
+
+	rotate := &cobra.Command{
+		Use:   "rotate",
+		Short: "replace your primary key with a new one",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Rotate()
+		},
+	}
+
+	signDevice := &cobra.Command{
+		Use:   "sign-device NAME PROFILEID",
+		Short: "sign a request to add this peer's key as a device of a profile",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			o.ProfileID = args[1]
+			return o.SignDevice()
+		},
+	}
+
+	addDevice := &cobra.Command{
+		Use:   "add-device NAME PUBKEY SIGNATURE",
+		Short: "authorize a device key to sign on your behalf",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			o.PubKey = args[1]
+			o.Signature = args[2]
+			return o.AddDevice()
+		},
+	}
+
+	revokeDevice := &cobra.Command{
+		Use:   "revoke-device NAME | KEYID",
+		Short: "revoke a device key",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.RevokeDevice()
+		},
+	}
+
+	cmd.AddCommand(rotate, signDevice, addDevice, revokeDevice)
+	return cmd
+}
+
+// ProfileKeyOptions encapsulates state for profile key commands
+type ProfileKeyOptions struct {
+	ioes.IOStreams
+
+	Device    string
+	PubKey    string
+	Signature string
+	ProfileID string
+
+	Generator      gen.CryptoGenerator
+	ProfileMethods *lib.ProfileMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *ProfileKeyOptions) Complete(f Factory, args []string) (err error) {
+	if len(args) > 0 {
+		o.Device = args[0]
+	}
+	o.Generator = f.CryptoGenerator()
+	o.ProfileMethods, err = f.ProfileMethods()
+	return
+}
+
+// List prints the keys of this peer's profile
+func (o *ProfileKeyOptions) List() error {
+	res := logbook.KeyHistory{}
+	if err := o.ProfileMethods.KeyHistory(nil, &res); err != nil {
+		return err
+	}
+	for _, k := range res.Keys {
+		kind := "primary"
+		if !k.Primary {
+			kind = fmt.Sprintf("device %s", k.Device)
+		}
+		status := "active"
+		if !k.Active() {
+			status = fmt.Sprintf("retired %s", k.Retired.Format("2006-01-02 15:04:05"))
+		}
+		fmt.Fprintf(o.Out, "%s\t%s\t%s\n", k.KeyID, kind, status)
+	}
+	return nil
+}
+
+// Rotate replaces the primary key with a newly generated one
+func (o *ProfileKeyOptions) Rotate() error {
+	privKey, _ := o.Generator.GeneratePrivateKeyAndPeerID()
+	res := logbook.KeyHistory{}
+	if err := o.ProfileMethods.RotateKey(&lib.RotateKeyParams{PrivKey: privKey}, &res); err != nil {
+		return err
+	}
+	printSuccess(o.Out, "rotated primary key, new keyID: %s", res.Primary().KeyID)
+	return nil
+}
+
+// SignDevice prints this peer's public key & a signed request to be added as a
+// device of another profile
+func (o *ProfileKeyOptions) SignDevice() error {
+	p := &lib.DeviceKeyParams{Device: o.Device, ProfileID: o.ProfileID}
+	res := lib.DeviceKeyParams{}
+	if err := o.ProfileMethods.SignDeviceKey(p, &res); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "%s %s\n", res.PubKey, res.Signature)
+	return nil
+}
+
+// AddDevice authorizes a device key
+func (o *ProfileKeyOptions) AddDevice() error {
+	p := &lib.DeviceKeyParams{Device: o.Device, PubKey: o.PubKey, Signature: o.Signature}
+	res := logbook.KeyHistory{}
+	if err := o.ProfileMethods.AddDeviceKey(p, &res); err != nil {
+		return err
+	}
+	printSuccess(o.Out, "added device %s", o.Device)
+	return nil
+}
+
+// RevokeDevice revokes a device key by device name or keyID
+func (o *ProfileKeyOptions) RevokeDevice() error {
+	p := &lib.DeviceKeyParams{Device: o.Device, KeyID: o.Device}
+	res := logbook.KeyHistory{}
+	if err := o.ProfileMethods.RevokeDeviceKey(p, &res); err != nil {
+		return err
+	}
+	printSuccess(o.Out, "revoked device key %s", o.Device)
+	return nil
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+	cfgtest "github.com/qri-io/qri/config/test"
+	"github.com/qri-io/qri/logbook"
+)
+
+func TestProfileKeyCommand(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_profile_key", "qri_test_profile_key")
+	defer run.Delete()
+
+	output := run.MustExec(t, "qri profile key")
+	if !strings.Contains(output, "primary\tactive") {
+		t.Errorf("expected an active primary key. got:\n%s", output)
+	}
+	profileID := strings.Fields(output)[0]
+
+	device := cfgtest.GetTestPeerInfo(5)
+	data, err := crypto.MarshalPublicKey(device.PubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := logbook.DeviceKeySignature(profileID, "laptop", device.PrivKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := run.ExecCommand("qri profile key add-device laptop " + base64.StdEncoding.EncodeToString(data) + " " + sig + "x"); err == nil {
+		t.Error("expected adding a device with an invalid signature to fail")
+	}
+	output = run.MustExec(t, "qri profile key add-device laptop "+base64.StdEncoding.EncodeToString(data)+" "+sig)
+	if !strings.Contains(output, "added device laptop") {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+
+	output = run.MustExec(t, "qri profile key sign-device desktop "+device.EncodedPeerID)
+	if len(strings.Fields(output)) != 2 {
+		t.Errorf("expected a public key & signature. got:\n%s", output)
+	}
+
+	output = run.MustExec(t, "qri profile key rotate")
+	if !strings.Contains(output, "rotated primary key") {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+
+	// the rotated key must be usable on the next invocation
+	run.MustExec(t, "qri save --body=testdata/movies/body_ten.csv me/movies")
+
+	output = run.MustExec(t, "qri profile key revoke-device laptop")
+	if !strings.Contains(output, "revoked device key laptop") {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+	output = run.MustExec(t, "qri profile key")
+	if strings.Count(output, "retired") != 2 || !strings.Contains(output, device.EncodedPeerID+"\tdevice laptop") {
+		t.Errorf("expected a retired primary key & revoked device. got:\n%s", output)
+	}
+	if err := run.ExecCommand("qri profile key revoke-device laptop"); err == nil {
+		t.Error("expected revoking a revoked device to fail")
+	}
+}
@@ -23,7 +23,10 @@ If no remote is specified, qri pushes to the registry.`,
   $ qri push me/dataset
 
   # push a specific version of a dataset to the registry:
-  $ qri push me/dataset@/ipfs/QmHashOfVersion`,
+  $ qri push me/dataset@/ipfs/QmHashOfVersion
+
+  # push a dataset & list it in the registry catalog:
+  $ qri push --catalog me/dataset`,
 		Annotations: map[string]string{
 			"group": "network",
 		},
@@ -38,6 +41,7 @@ If no remote is specified, qri pushes to the registry.`,
 
 	cmd.Flags().BoolVarP(&o.Logs, "logs", "", false, "send only dataset history")
 	cmd.Flags().StringVarP(&o.RemoteName, "remote", "", "", "name of remote to push to")
+	cmd.Flags().BoolVarP(&o.Catalog, "catalog", "", false, "list the dataset in the registry catalog")
 
 	return cmd
 }
@@ -49,6 +53,7 @@ type PushOptions struct {
 	Refs       *RefSelect
 	Logs       bool
 	RemoteName string
+	Catalog    bool
 
 	DatasetMethods *lib.DatasetMethods
 	RemoteMethods  *lib.RemoteMethods
@@ -74,6 +79,7 @@ func (o *PushOptions) Run() error {
 		p := lib.PushParams{
 			Ref:        ref,
 			RemoteName: o.RemoteName,
+			Catalog:    o.Catalog,
 		}
 
 		if err := o.RemoteMethods.Push(&p, &res); err != nil {
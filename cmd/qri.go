@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net/rpc"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -43,14 +42,20 @@ https://github.com/qri-io/qri/issues`,
 	cmd.PersistentFlags().BoolVarP(&opt.LogAll, "log-all", "", false, "log all activity")
 
 	cmd.AddCommand(
+		NewAccessCommand(opt, ioStreams),
 		NewAutocompleteCommand(opt, ioStreams),
 		NewCheckoutCommand(opt, ioStreams),
 		NewConfigCommand(opt, ioStreams),
 		NewConnectCommand(opt, ioStreams),
 		NewDAGCommand(opt, ioStreams),
 		NewDiffCommand(opt, ioStreams),
+		NewEventsCommand(opt, ioStreams),
+		NewFollowCommand(opt, ioStreams),
+		NewOrgCommand(opt, ioStreams),
+		NewProfileCommand(opt, ioStreams),
 		NewFSICommand(opt, ioStreams),
 		NewGetCommand(opt, ioStreams),
+		NewImportCommand(opt, ioStreams),
 		NewInitCommand(opt, ioStreams),
 		NewListCommand(opt, ioStreams),
 		NewLogCommand(opt, ioStreams),
@@ -70,9 +75,11 @@ https://github.com/qri-io/qri/issues`,
 		NewStatsCommand(opt, ioStreams),
 		NewStatusCommand(opt, ioStreams),
 		NewSQLCommand(opt, ioStreams),
+		NewTestCommand(opt, ioStreams),
 		NewUseCommand(opt, ioStreams),
 		NewValidateCommand(opt, ioStreams),
 		NewVersionCommand(opt, ioStreams),
+		NewWebhookCommand(opt, ioStreams),
 		NewWhatChangedCommand(opt, ioStreams),
 	)
 
@@ -207,7 +214,7 @@ func (o *QriOptions) CryptoGenerator() gen.CryptoGenerator {
 }
 
 // RPC returns from internal state
-func (o *QriOptions) RPC() *rpc.Client {
+func (o *QriOptions) RPC() *lib.RPCClient {
 	if err := o.Init(); err != nil {
 		return nil
 	}
@@ -287,6 +294,14 @@ func (o *QriOptions) SQLMethods() (*lib.SQLMethods, error) {
 	return lib.NewSQLMethods(o.inst), nil
 }
 
+// TransformMethods generates a lib.TransformMethods from internal state
+func (o *QriOptions) TransformMethods() (*lib.TransformMethods, error) {
+	if err := o.Init(); err != nil {
+		return nil, err
+	}
+	return lib.NewTransformMethods(o.inst), nil
+}
+
 // RenderMethods generates a lib.RenderMethods from internal state
 func (o *QriOptions) RenderMethods() (*lib.RenderMethods, error) {
 	if err := o.Init(); err != nil {
@@ -295,6 +310,46 @@ func (o *QriOptions) RenderMethods() (*lib.RenderMethods, error) {
 	return lib.NewRenderMethods(o.inst), nil
 }
 
+// EventMethods generates a lib.EventMethods from internal state
+func (o *QriOptions) EventMethods() (*lib.EventMethods, error) {
+	if err := o.Init(); err != nil {
+		return nil, err
+	}
+	return lib.NewEventMethods(o.inst), nil
+}
+
+// WebhookMethods generates a lib.WebhookMethods from internal state
+func (o *QriOptions) WebhookMethods() (*lib.WebhookMethods, error) {
+	if err := o.Init(); err != nil {
+		return nil, err
+	}
+	return lib.NewWebhookMethods(o.inst), nil
+}
+
+// FollowMethods generates a lib.FollowMethods from internal state
+func (o *QriOptions) FollowMethods() (*lib.FollowMethods, error) {
+	if err := o.Init(); err != nil {
+		return nil, err
+	}
+	return lib.NewFollowMethods(o.inst), nil
+}
+
+// OrgMethods generates a lib.OrgMethods from internal state
+func (o *QriOptions) OrgMethods() (*lib.OrgMethods, error) {
+	if err := o.Init(); err != nil {
+		return nil, err
+	}
+	return lib.NewOrgMethods(o.inst), nil
+}
+
+// AccessMethods generates a lib.AccessMethods from internal state
+func (o *QriOptions) AccessMethods() (*lib.AccessMethods, error) {
+	if err := o.Init(); err != nil {
+		return nil, err
+	}
+	return lib.NewAccessMethods(o.inst), nil
+}
+
 // ConfigMethods generates a lib.ConfigMethods from internal state
 func (o *QriOptions) ConfigMethods() (m *lib.ConfigMethods, err error) {
 	if err = o.Init(); err != nil {
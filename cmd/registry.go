@@ -4,12 +4,16 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
+	"os"
 	"strings"
 	"syscall"
 
 	"github.com/qri-io/ioes"
+	"github.com/qri-io/qfs"
 	"github.com/qri-io/qri/lib"
 	"github.com/qri-io/qri/registry"
+	"github.com/qri-io/qri/registry/regserver/handlers"
 	"github.com/spf13/cobra"
 	"golang.org/x/crypto/ssh/terminal"
 )
@@ -107,7 +111,42 @@ A repo can only be associated with one registry profile.`,
 	prove.MarkFlagRequired("username")
 	prove.MarkFlagRequired("email")
 
-	cmd.AddCommand(status, signup, prove)
+	so := &RegistryServeOptions{IOStreams: ioStreams}
+	serve := &cobra.Command{
+		Use:   "serve",
+		Short: "run a registry server",
+		Long: `Serve runs a registry on this node, accepting profile signups & dataset
+publications over HTTP. Registered profiles & the catalog of published
+datasets are written to JSON files, keeping them across restarts. When remote
+mode is enabled the registry also accepts dataset pushes.
+
+Bulk profile writes & the admin API require HTTP basic auth. Admin credentials
+are read from the --admin-username & --admin-password flags, or the
+QRI_REGISTRY_ADMIN_USERNAME & QRI_REGISTRY_ADMIN_PASSWORD environment
+variables. Serve won't start without them.`,
+		Example: `  # serve a registry on port 2500, storing profiles in profiles.json and
+  # the catalog in datasets.json:
+  $ export QRI_REGISTRY_ADMIN_USERNAME=admin
+  $ export QRI_REGISTRY_ADMIN_PASSWORD=secret
+  $ qri registry serve --address :2500 --profiles profiles.json --datasets datasets.json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := so.Complete(f, args); err != nil {
+				return err
+			}
+			return so.Run()
+		},
+	}
+
+	serve.Flags().StringVar(&so.Address, "address", ":2500", "address to listen on")
+	serve.Flags().StringVar(&so.ProfilesPath, "profiles", "", "path to the file registry profiles are stored in")
+	serve.Flags().StringVar(&so.DatasetsPath, "datasets", "", "path to the file the registry catalog is stored in")
+	serve.Flags().StringVar(&so.AdminUsername, "admin-username", "", "username required for admin requests")
+	serve.Flags().StringVar(&so.AdminPassword, "admin-password", "", "password required for admin requests")
+	serve.MarkFlagRequired("profiles")
+	serve.MarkFlagRequired("datasets")
+
+	cmd.AddCommand(status, signup, prove, serve)
 	return cmd
 }
 
@@ -168,6 +207,94 @@ func (o *RegistryOptions) Prove() error {
 	return nil
 }
 
+// RegistryServeOptions encapsulates state for the registry serve command
+type RegistryServeOptions struct {
+	ioes.IOStreams
+
+	Address      string
+	ProfilesPath string
+	DatasetsPath string
+
+	// AdminUsername & AdminPassword are the basic auth credentials required
+	// for bulk profile writes & admin routes
+	AdminUsername string
+	AdminPassword string
+
+	inst *lib.Instance
+}
+
+const (
+	// registryAdminUsernameEnvVar sets the registry admin username when the
+	// --admin-username flag isn't provided
+	registryAdminUsernameEnvVar = "QRI_REGISTRY_ADMIN_USERNAME"
+	// registryAdminPasswordEnvVar sets the registry admin password when the
+	// --admin-password flag isn't provided
+	registryAdminPasswordEnvVar = "QRI_REGISTRY_ADMIN_PASSWORD"
+)
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *RegistryServeOptions) Complete(f Factory, args []string) (err error) {
+	if o.AdminUsername == "" {
+		o.AdminUsername = os.Getenv(registryAdminUsernameEnvVar)
+	}
+	if o.AdminPassword == "" {
+		o.AdminPassword = os.Getenv(registryAdminPasswordEnvVar)
+	}
+	if err = o.Validate(); err != nil {
+		return err
+	}
+	if err = f.Init(); err != nil {
+		return err
+	}
+	if err = qfs.AbsPath(&o.ProfilesPath); err != nil {
+		return err
+	}
+	if err = qfs.AbsPath(&o.DatasetsPath); err != nil {
+		return err
+	}
+	o.inst = f.Instance()
+	return nil
+}
+
+// Validate checks that admin credentials are set. Without them anyone could
+// overwrite stored profiles
+func (o *RegistryServeOptions) Validate() error {
+	if o.AdminUsername == "" || o.AdminPassword == "" {
+		return fmt.Errorf("admin credentials are required to serve a registry. set --admin-username & --admin-password, or %s & %s", registryAdminUsernameEnvVar, registryAdminPasswordEnvVar)
+	}
+	return nil
+}
+
+// Run serves the registry until the server stops
+func (o *RegistryServeOptions) Run() error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	profiles, err := registry.NewFileProfiles(o.ProfilesPath)
+	if err != nil {
+		return err
+	}
+	datasets, err := registry.NewFileDatasets(o.DatasetsPath)
+	if err != nil {
+		return err
+	}
+	reg := registry.Registry{
+		Remote:   o.inst.Remote(),
+		Profiles: profiles,
+		Datasets: datasets,
+		Orgs:     registry.LogbookOrgs{Book: o.inst.Repo().Logbook()},
+	}
+
+	printInfo(o.ErrOut, "serving registry on %s", o.Address)
+	return http.ListenAndServe(o.Address, o.routes(reg))
+}
+
+// routes creates registry handlers that require admin credentials for
+// protected requests
+func (o *RegistryServeOptions) routes(reg registry.Registry) http.Handler {
+	return handlers.NewRoutes(reg, handlers.AddProtector(handlers.NewBAProtector(o.AdminUsername, o.AdminPassword)))
+}
+
 // PromptForPassword will prompt the user for a password without echoing it to the screen
 func (o *RegistryOptions) PromptForPassword() (string, error) {
 	io.WriteString(o.Out, "password: ")
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/registry"
+)
+
+func TestRegistryServeRequiresAdminCredentials(t *testing.T) {
+	o := &RegistryServeOptions{IOStreams: ioes.NewDiscardIOStreams()}
+	if err := o.Run(); err == nil {
+		t.Error("expected serving a registry without admin credentials to fail")
+	}
+
+	o.AdminUsername = "admin"
+	if err := o.Validate(); err == nil {
+		t.Error("expected serving a registry without an admin password to fail")
+	}
+}
+
+func TestRegistryServeProtectsProfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registry_serve_protects_profiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	profiles, err := registry.NewFileProfiles(filepath.Join(dir, "profiles.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := &RegistryServeOptions{
+		IOStreams:     ioes.NewDiscardIOStreams(),
+		AdminUsername: "admin",
+		AdminPassword: "secret",
+	}
+	s := httptest.NewServer(o.routes(registry.Registry{Profiles: profiles}))
+	defer s.Close()
+
+	postProfiles := func(username, password string) int {
+		req, err := http.NewRequest("POST", s.URL+"/registry/profiles", strings.NewReader(`[{"Username":"mallory"}]`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if username != "" {
+			req.SetBasicAuth(username, password)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		return res.StatusCode
+	}
+
+	if code := postProfiles("", ""); code != http.StatusForbidden {
+		t.Errorf("unauthenticated POST status mismatch. expected %d, got: %d", http.StatusForbidden, code)
+	}
+	if code := postProfiles("admin", "wrong"); code != http.StatusForbidden {
+		t.Errorf("wrong password POST status mismatch. expected %d, got: %d", http.StatusForbidden, code)
+	}
+	if n, err := profiles.Len(); err != nil || n != 0 {
+		t.Errorf("expected rejected requests to store no profiles. got %d, err: %v", n, err)
+	}
+	if code := postProfiles("admin", "secret"); code != http.StatusOK {
+		t.Errorf("admin POST status mismatch. expected %d, got: %d", http.StatusOK, code)
+	}
+
+	res, err := http.Get(s.URL + "/registry/admin/profiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusForbidden {
+		t.Errorf("expected admin routes to be mounted & protected. got status: %d", res.StatusCode)
+	}
+}
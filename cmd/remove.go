@@ -72,6 +72,7 @@ effect on local data.`,
 	cmd.Flags().BoolVar(&o.KeepFiles, "keep-files", false, "don't modify files in working directory")
 	cmd.Flags().BoolVarP(&o.Force, "force", "f", false, "remove files even if a working directory is dirty")
 	cmd.Flags().StringVar(&o.Remote, "remote", "", "remote address to remove from")
+	cmd.Flags().BoolVar(&o.Catalog, "catalog", false, "also remove the dataset from the registry catalog")
 
 	return cmd
 }
@@ -88,6 +89,7 @@ type RemoveOptions struct {
 	All           bool
 	KeepFiles     bool
 	Force         bool
+	Catalog       bool
 
 	RemoteMethods  *lib.RemoteMethods
 	DatasetMethods *lib.DatasetMethods
@@ -184,6 +186,7 @@ func (o *RemoveOptions) RemoveRemote() error {
 	err := o.RemoteMethods.Remove(&lib.PushParams{
 		Ref:        o.Refs.Ref(),
 		RemoteName: o.Remote,
+		Catalog:    o.Catalog,
 	}, res)
 	if err != nil {
 		return fmt.Errorf("dataset not removed")
@@ -8,6 +8,7 @@ import (
 	"github.com/qri-io/ioes"
 	"github.com/qri-io/qfs"
 	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/errors"
 	"github.com/qri-io/qri/lib"
 	"github.com/qri-io/qri/repo"
 	"github.com/spf13/cobra"
@@ -43,7 +44,10 @@ commit message and title to the save.`,
   $ qri save --file /path/to/dataset.yaml me/annual_pop
   
   # Re-execute a dataset that has a transform:
-  $ qri save me/tf_dataset`,
+  $ qri save me/tf_dataset
+
+  # Save the events table of a SQLite database as the body of me/events:
+  $ qri save --from-sqlite /path/to/db.sqlite --table events me/events`,
 		Annotations: map[string]string{
 			"group": "dataset",
 		},
@@ -65,6 +69,9 @@ commit message and title to the save.`,
 	cmd.Flags().StringVarP(&o.Message, "message", "m", "", "commit message for save")
 	cmd.Flags().StringVarP(&o.BodyPath, "body", "", "", "path to file or url of data to add as dataset contents")
 	cmd.MarkFlagFilename("body")
+	cmd.Flags().StringVar(&o.FromSQLite, "from-sqlite", "", "path to a sqlite database to read body data from")
+	cmd.MarkFlagFilename("from-sqlite", "sqlite", "sqlite3", "db")
+	cmd.Flags().StringVar(&o.Table, "table", "", "name of the table to read when using --from-sqlite")
 	cmd.Flags().StringVarP(&o.Recall, "recall", "", "", "restore revisions from dataset history")
 	// cmd.Flags().BoolVarP(&o.ShowValidation, "show-validation", "s", false, "display a list of validation errors upon adding")
 	cmd.Flags().StringSliceVar(&o.Secrets, "secrets", nil, "transform secrets as comma separated key,value,key,value,... sequence")
@@ -84,11 +91,13 @@ commit message and title to the save.`,
 type SaveOptions struct {
 	ioes.IOStreams
 
-	Refs      *RefSelect
-	FilePaths []string
-	BodyPath  string
-	Recall    string
-	Drop      string
+	Refs       *RefSelect
+	FilePaths  []string
+	BodyPath   string
+	FromSQLite string
+	Table      string
+	Recall     string
+	Drop       string
 
 	Title   string
 	Message string
@@ -133,11 +142,24 @@ func (o *SaveOptions) Complete(f Factory, args []string) (err error) {
 		return fmt.Errorf("body file: %s", err)
 	}
 
+	if err := qfs.AbsPath(&o.FromSQLite); err != nil {
+		return fmt.Errorf("sqlite file: %s", err)
+	}
+
 	return nil
 }
 
 // Validate checks that all user input is valid
 func (o *SaveOptions) Validate() error {
+	if o.FromSQLite != "" && o.Table == "" {
+		return errors.New(lib.ErrBadArgs, "please provide the name of a table to read with --table")
+	}
+	if o.FromSQLite == "" && o.Table != "" {
+		return errors.New(lib.ErrBadArgs, "--table requires a sqlite database provided with --from-sqlite")
+	}
+	if o.FromSQLite != "" && o.BodyPath != "" {
+		return errors.New(lib.ErrBadArgs, "cannot use both --body and --from-sqlite")
+	}
 	return nil
 }
 
@@ -149,10 +171,12 @@ func (o *SaveOptions) Run() (err error) {
 	defer o.StopSpinner()
 
 	p := &lib.SaveParams{
-		Ref:      o.Refs.Ref(),
-		BodyPath: o.BodyPath,
-		Title:    o.Title,
-		Message:  o.Message,
+		Ref:        o.Refs.Ref(),
+		BodyPath:   o.BodyPath,
+		FromSQLite: o.FromSQLite,
+		Table:      o.Table,
+		Title:      o.Title,
+		Message:    o.Message,
 
 		ScriptOutput:        o.ErrOut,
 		FilePaths:           o.FilePaths,
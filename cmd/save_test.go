@@ -18,6 +18,7 @@ import (
 	"github.com/qri-io/qri/dscache"
 	qrierr "github.com/qri-io/qri/errors"
 	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/lib"
 )
 
 func TestSaveComplete(t *testing.T) {
@@ -72,21 +73,29 @@ func TestSaveComplete(t *testing.T) {
 
 func TestSaveValidate(t *testing.T) {
 	cases := []struct {
-		ref      string
-		filepath string
-		bodypath string
-		err      string
-		msg      string
+		ref        string
+		filepath   string
+		bodypath   string
+		fromSQLite string
+		table      string
+		err        string
+		msg        string
 	}{
-		{"me/test", "test/path.yaml", "", "", ""},
-		{"me/test", "", "test/bodypath.yaml", "", ""},
-		{"me/test", "test/filepath.yaml", "test/bodypath.yaml", "", ""},
+		{"me/test", "test/path.yaml", "", "", "", "", ""},
+		{"me/test", "", "test/bodypath.yaml", "", "", "", ""},
+		{"me/test", "test/filepath.yaml", "test/bodypath.yaml", "", "", "", ""},
+		{"me/test", "", "", "test/db.sqlite", "events", "", ""},
+		{"me/test", "", "", "test/db.sqlite", "", lib.ErrBadArgs.Error(), "please provide the name of a table to read with --table"},
+		{"me/test", "", "", "", "events", lib.ErrBadArgs.Error(), "--table requires a sqlite database provided with --from-sqlite"},
+		{"me/test", "", "test/bodypath.yaml", "test/db.sqlite", "events", lib.ErrBadArgs.Error(), "cannot use both --body and --from-sqlite"},
 	}
 	for i, c := range cases {
 		opt := &SaveOptions{
-			Refs:      NewExplicitRefSelect(c.ref),
-			FilePaths: []string{c.filepath},
-			BodyPath:  c.bodypath,
+			Refs:       NewExplicitRefSelect(c.ref),
+			FilePaths:  []string{c.filepath},
+			BodyPath:   c.bodypath,
+			FromSQLite: c.fromSQLite,
+			Table:      c.table,
 		}
 
 		err := opt.Validate()
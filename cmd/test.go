@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/startf"
+	"github.com/spf13/cobra"
+)
+
+// NewTestCommand creates a new `qri test` command for testing transform scripts
+func NewTestCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &TestOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "test SCRIPT [SCRIPT...]",
+		Short: "test transform scripts against fixtures",
+		Long: `Test runs a transform script against fixture data without saving, checking
+the dataset it produces. Tests for a script are defined in files alongside
+it. For a script named transform.star:
+
+  * transform.fixtures.json defines inputs: transform "config", "secrets",
+    the previous version of the dataset as "prev", datasets load_dataset can
+    load keyed by reference as "datasets", and recorded responses to http
+    requests as "http". Fixture datasets define their body inline.
+  * transform.expected.json defines the expected dataset. Only components &
+    fields present in the expected dataset are compared.
+  * transform_test.star defines starlark test functions. Every function with
+    a "test_" prefix is called with the dataset the transform produced.
+    Load the assert module with load("assert.star", "assert") to check
+    values with assert.eq, assert.ne, assert.true, assert.contains &
+    assert.fails.
+
+Transforms can only make http requests that have a recorded response. Run
+test with --record to make requests over the network instead, recording
+responses to the fixtures file.`,
+		Example: `  # test a transform script:
+  $ qri test transform.star
+
+  # record http responses to transform.fixtures.json while testing:
+  $ qri test --record transform.star`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.Record, "record", false, "make http requests over the network, recording responses as fixtures")
+
+	return cmd
+}
+
+// TestOptions encapsulates state for the test command
+type TestOptions struct {
+	ioes.IOStreams
+
+	Scripts []string
+	Record  bool
+
+	// absolute paths to Scripts
+	scriptPaths []string
+
+	TransformMethods *lib.TransformMethods
+}
+
+// Complete adds any missing configuration that can only be added just before
+// calling Run
+func (o *TestOptions) Complete(f Factory, args []string) (err error) {
+	o.Scripts = args
+	// make script paths absolute, a connected instance may be running in a
+	// different directory
+	o.scriptPaths = make([]string, len(args))
+	for i, script := range args {
+		o.scriptPaths[i] = script
+		if err = qfs.AbsPath(&o.scriptPaths[i]); err != nil {
+			return err
+		}
+	}
+	o.TransformMethods, err = f.TransformMethods()
+	return
+}
+
+// Run executes the test command
+func (o *TestOptions) Run() error {
+	passed, failed := 0, 0
+	for i, script := range o.Scripts {
+		p := &lib.TestTransformParams{
+			ScriptPath: o.scriptPaths[i],
+			Record:     o.Record,
+		}
+		res := &startf.TestResults{}
+		if err := o.TransformMethods.Test(p, res); err != nil {
+			return err
+		}
+
+		printInfo(o.Out, script)
+		for _, c := range res.Cases {
+			if c.Passed() {
+				printSuccess(o.Out, "  ✅ %s", c.Name)
+				passed++
+				continue
+			}
+			printErr(o.Out, fmt.Errorf("  ❌ %s", c.Name))
+			printInfo(o.Out, "%s", indent(c.Error, "     "))
+			failed++
+		}
+		if res.Failed() > 0 && res.Output != "" {
+			printInfo(o.Out, "  output:\n%s", indent(strings.TrimSuffix(res.Output, "\n"), "     "))
+		}
+	}
+
+	printInfo(o.Out, "\n%d passed, %d failed", passed, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d test(s) failed", failed)
+	}
+	return nil
+}
+
+func indent(s, prefix string) string {
+	return prefix + strings.Replace(s, "\n", "\n"+prefix, -1)
+}
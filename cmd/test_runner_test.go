@@ -38,21 +38,22 @@ type TestRunner struct {
 	RepoRoot *repotest.TempRepo
 	RepoPath string
 
-	Context       context.Context
-	ContextDone   func()
-	TmpDir        string
-	Streams       ioes.IOStreams
-	InStream      *bytes.Buffer
-	OutStream     *bytes.Buffer
-	ErrStream     *bytes.Buffer
-	DsfsTsFunc    func() time.Time
-	LogbookTsFunc func() int64
-	LocOrig       *time.Location
-	XformVersion  string
-	CmdR          *cobra.Command
-	Teardown      func()
-	CmdDoneCh     chan struct{}
-	TestCrypto    gen.CryptoGenerator
+	Context         context.Context
+	ContextDone     func()
+	TmpDir          string
+	Streams         ioes.IOStreams
+	InStream        *bytes.Buffer
+	OutStream       *bytes.Buffer
+	ErrStream       *bytes.Buffer
+	DsfsTsFunc      func() time.Time
+	LogbookTsFunc   func() int64
+	LocOrig         *time.Location
+	XformVersion    string
+	StarlarkVersion string
+	CmdR            *cobra.Command
+	Teardown        func()
+	CmdDoneCh       chan struct{}
+	TestCrypto      gen.CryptoGenerator
 
 	Registry *registry.Registry
 }
@@ -172,6 +173,8 @@ func newTestRunnerFromRoot(root *repotest.TempRepo) *TestRunner {
 	// Stub the version of starlark, because it is output when transforms run
 	run.XformVersion = startf.Version
 	startf.Version = "test_version"
+	run.StarlarkVersion = startf.StarlarkVersion
+	startf.StarlarkVersion = "test_version"
 
 	return &run
 }
@@ -188,6 +191,7 @@ func (run *TestRunner) Delete() {
 	logbook.NewTimestamp = run.LogbookTsFunc
 	StringerLocation = run.LocOrig
 	startf.Version = run.XformVersion
+	startf.StarlarkVersion = run.StarlarkVersion
 	run.ContextDone()
 	run.RepoRoot.Delete()
 }
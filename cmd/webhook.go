@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/webhook"
+	"github.com/spf13/cobra"
+)
+
+// NewWebhookCommand creates a `qri webhook` subcommand for working with
+// configured webhooks
+func NewWebhookCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &WebhookOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "test webhooks & inspect deliveries",
+		Long: `Webhooks deliver events that happen on this qri node, like saving a new dataset
+version, to HTTP endpoints. Configure webhooks by setting the webhooks field
+of your qri configuration:
+
+  Webhooks:
+  - url: https://example.com/qri-events
+    events: ["dataset:CommitChange", "dataset:DeleteAll"]
+    datasets: ["me/my_dataset"]
+    secret: a_long_random_string
+
+Each delivery is a JSON POST. When a webhook has a secret, the request carries
+an X-Qri-Signature header with an HMAC-SHA256 of the request body. Failed
+deliveries are retried with exponential backoff.`,
+		Annotations: map[string]string{
+			"group": "other",
+		},
+	}
+
+	test := &cobra.Command{
+		Use:   "test [URL]",
+		Short: "send a test event to configured webhooks",
+		Example: `  # Send a test event to all configured webhooks:
+  $ qri webhook test
+
+  # Send a test event to a single webhook:
+  $ qri webhook test https://example.com/qri-events`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Test()
+		},
+	}
+
+	logCmd := &cobra.Command{
+		Use:   "log",
+		Short: "list recent webhook deliveries",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Log()
+		},
+	}
+	logCmd.Flags().IntVar(&o.Limit, "limit", 25, "number of deliveries to show")
+
+	cmd.AddCommand(test, logCmd)
+	return cmd
+}
+
+// WebhookOptions encapsulates state for webhook commands
+type WebhookOptions struct {
+	ioes.IOStreams
+
+	URL   string
+	Limit int
+
+	WebhookMethods *lib.WebhookMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *WebhookOptions) Complete(f Factory, args []string) (err error) {
+	if len(args) > 0 {
+		o.URL = args[0]
+	}
+	o.WebhookMethods, err = f.WebhookMethods()
+	return
+}
+
+// Test sends test events to webhooks
+func (o *WebhookOptions) Test() error {
+	p := &lib.WebhookTestParams{URL: o.URL}
+	res := []webhook.Delivery{}
+	if err := o.WebhookMethods.Test(p, &res); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, del := range res {
+		if del.Succeeded() {
+			printSuccess(o.Out, "delivered test event to %s", del.URL)
+		} else {
+			failed++
+			printWarning(o.Out, "delivering test event to %s failed: %s", del.URL, del.Error)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d test deliveries failed", failed, len(res))
+	}
+	return nil
+}
+
+// Log prints recent webhook deliveries
+func (o *WebhookOptions) Log() error {
+	p := &lib.WebhookLogParams{Limit: o.Limit}
+	res := []webhook.Delivery{}
+	if err := o.WebhookMethods.Log(p, &res); err != nil {
+		return err
+	}
+
+	if len(res) == 0 {
+		printInfo(o.Out, "no webhook deliveries")
+		return nil
+	}
+	for _, del := range res {
+		status := "ok"
+		if !del.Succeeded() {
+			status = fmt.Sprintf("failed: %s", del.Error)
+		}
+		fmt.Fprintf(o.Out, "%s\t%s\t%s\tattempts: %d\t%s\n", del.Timestamp.Format("2006-01-02 15:04:05"), del.Type, del.URL, del.Attempts, status)
+	}
+	return nil
+}
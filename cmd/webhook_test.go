@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWebhookCommands(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_webhook", "qri_test_webhook")
+	defer run.Delete()
+
+	err := run.ExecCommand("qri webhook test")
+	if err == nil {
+		t.Fatal("expected testing webhooks without any configured to error")
+	}
+	if !strings.Contains(err.Error(), "no webhooks are configured") {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	output := run.MustExec(t, "qri webhook log")
+	if !strings.Contains(output, "no webhook deliveries") {
+		t.Errorf("expected empty delivery log. got:\n%s", output)
+	}
+}
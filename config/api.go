@@ -37,6 +37,23 @@ type API struct {
 	// TODO (ramfox): when we next have a config migration, we should probably rename this to
 	// EnableWebui and default to true. the double negative here can be confusing.
 	DisableWebui bool `json:"disablewebui"`
+	// EnableGraphQL serves a GraphQL endpoint at /graphql
+	EnableGraphQL bool `json:"enablegraphql,omitempty"`
+	// RateLimit is the number of requests per minute allowed from each IP
+	// address, 0 means no limit
+	RateLimit int `json:"ratelimit,omitempty"`
+	// ProfileRateLimit is the number of requests per minute allowed from each
+	// profile making remote requests, 0 means no limit
+	ProfileRateLimit int `json:"profileratelimit,omitempty"`
+	// RateLimitBurst is the number of requests allowed at once before rate
+	// limits apply, defaults to the per-minute limit
+	RateLimitBurst int `json:"ratelimitburst,omitempty"`
+	// AccessLog is where to write a JSON line for each request. "stdout",
+	// "stderr" or a file path. empty disables access logs
+	AccessLog string `json:"accesslog,omitempty"`
+	// MaxUploadSize is the largest file in bytes a chunked upload can declare,
+	// 0 uses the default maximum
+	MaxUploadSize int64 `json:"maxuploadsize,omitempty"`
 }
 
 // SetArbitrary is an interface implementation of base/fill/struct in order to safely
@@ -79,6 +96,34 @@ func (a API) Validate() error {
         "description": "when true, disables qri from serving the webui when the node is online",
         "type": "boolean"
       },
+      "enablegraphql": {
+        "description": "when true, serves a GraphQL endpoint at /graphql",
+        "type": "boolean"
+      },
+      "ratelimit": {
+        "description": "requests per minute allowed from each IP address, 0 means no limit",
+        "type": "integer",
+        "minimum": 0
+      },
+      "profileratelimit": {
+        "description": "requests per minute allowed from each profile making remote requests, 0 means no limit",
+        "type": "integer",
+        "minimum": 0
+      },
+      "ratelimitburst": {
+        "description": "requests allowed at once before rate limits apply",
+        "type": "integer",
+        "minimum": 0
+      },
+      "accesslog": {
+        "description": "where to write JSON access logs. stdout, stderr or a file path",
+        "type": "string"
+      },
+      "maxuploadsize": {
+        "description": "largest file in bytes a chunked upload can declare, 0 uses the default maximum",
+        "type": "integer",
+        "minimum": 0
+      },
       "allowedorigins": {
         "description": "Support CORS signing from a list of origins",
         "type": "array",
@@ -116,6 +161,12 @@ func (a *API) Copy() *API {
 		DisconnectAfter:    a.DisconnectAfter,
 		ServeRemoteTraffic: a.ServeRemoteTraffic,
 		DisableWebui:       a.DisableWebui,
+		EnableGraphQL:      a.EnableGraphQL,
+		RateLimit:          a.RateLimit,
+		ProfileRateLimit:   a.ProfileRateLimit,
+		RateLimitBurst:     a.RateLimitBurst,
+		AccessLog:          a.AccessLog,
+		MaxUploadSize:      a.MaxUploadSize,
 	}
 	if a.AllowedOrigins != nil {
 		res.AllowedOrigins = make([]string, len(a.AllowedOrigins))
@@ -22,6 +22,16 @@ func TestAPICopy(t *testing.T) {
 			Enabled:            true,
 			ReadOnly:           true,
 			ServeRemoteTraffic: true,
+			EnableGraphQL:      true,
+		}},
+		{"rate limits & access log", &API{
+			RateLimit:        60,
+			ProfileRateLimit: 30,
+			RateLimitBurst:   10,
+			AccessLog:        "stdout",
+		}},
+		{"upload size", &API{
+			MaxUploadSize: 1 << 20,
 		}},
 	}
 	for i, c := range cases {
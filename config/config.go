@@ -39,6 +39,9 @@ type Config struct {
 	API     *API
 	RPC     *RPC
 	Logging *Logging
+
+	Webhooks  Webhooks
+	Transform *Transform
 }
 
 // SetArbitrary is an interface implementation of base/fill/struct in order to safely
@@ -224,6 +227,8 @@ func (cfg Config) Validate() error {
 		cfg.API,
 		cfg.RPC,
 		cfg.Logging,
+		cfg.Webhooks,
+		cfg.Transform,
 	}
 	for _, val := range validators {
 		// we need to check here because we're potentially calling methods on nil
@@ -282,6 +287,12 @@ func (cfg *Config) Copy() *Config {
 	if cfg.Stats != nil {
 		res.Stats = cfg.Stats.Copy()
 	}
+	if cfg.Webhooks != nil {
+		res.Webhooks = cfg.Webhooks.Copy()
+	}
+	if cfg.Transform != nil {
+		res.Transform = cfg.Transform.Copy()
+	}
 	if cfg.Filesystems != nil {
 		for _, fs := range cfg.Filesystems {
 			res.Filesystems = append(res.Filesystems, fs)
@@ -297,6 +308,7 @@ func (cfg *Config) WithoutPrivateValues() *Config {
 
 	res.Profile.PrivKey = ""
 	res.P2P.PrivKey = ""
+	res.Webhooks = res.Webhooks.WithoutPrivateValues()
 
 	return res
 }
@@ -308,6 +320,7 @@ func (cfg *Config) WithPrivateValues(p *Config) *Config {
 
 	res.Profile.PrivKey = p.Profile.PrivKey
 	res.P2P.PrivKey = p.P2P.PrivKey
+	res.Webhooks = res.Webhooks.WithPrivateValues(p.Webhooks)
 
 	return res
 }
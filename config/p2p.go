@@ -4,9 +4,11 @@ import (
 	"encoding/base64"
 	"fmt"
 	"reflect"
+	"strings"
 
 	crypto "github.com/libp2p/go-libp2p-core/crypto"
 	peer "github.com/libp2p/go-libp2p-core/peer"
+	pnet "github.com/libp2p/go-libp2p-core/pnet"
 	ma "github.com/multiformats/go-multiaddr"
 
 	"github.com/qri-io/jsonschema"
@@ -38,8 +40,28 @@ type P2P struct {
 
 	// Enable AutoNAT service. unless you're hosting a server, leave this as false
 	AutoNAT bool `json:"autoNAT"`
+
+	// PrivateSwarm restricts this node to a private network of peers that share
+	// SwarmKey. Private nodes don't bootstrap to the public qri or IPFS
+	// networks, and discover local peers with a qri-specific tag
+	PrivateSwarm bool `json:"privateswarm,omitempty"`
+	// SwarmKey is the hex-encoded 32-byte key shared by all peers of a private
+	// swarm. generate one with "openssl rand -hex 32". IPFS filesystems write
+	// the key to swarm.key in the IPFS repo, which must be removed by hand when
+	// leaving the swarm
+	SwarmKey string `json:"swarmkey,omitempty"`
+	// DiscoveryTag is the mDNS service tag used to find peers on the local
+	// network. defaults to the libp2p tag, or PrivateSwarmDiscoveryTag in a
+	// private swarm
+	DiscoveryTag string `json:"discoverytag,omitempty"`
+	// AllowedPeers lists the peer IDs a private node connects to. an empty list
+	// accepts any peer that has the swarm key
+	AllowedPeers []string `json:"allowedpeers,omitempty"`
 }
 
+// PrivateSwarmDiscoveryTag is the default mDNS tag for private swarms
+const PrivateSwarmDiscoveryTag = "_qri-private-swarm"
+
 // SetArbitrary is an interface implementation of base/fill/struct in order to safely
 // consume config files that have definitions beyond those specified in the struct.
 // This simply ignores all additional fields at read time.
@@ -85,6 +107,37 @@ func (cfg *P2P) DecodePrivateKey() (crypto.PrivKey, error) {
 	return crypto.UnmarshalPrivateKey(data)
 }
 
+// DecodeSwarmKey decodes the private swarm key
+func (cfg *P2P) DecodeSwarmKey() (pnet.PSK, error) {
+	if cfg.SwarmKey == "" {
+		return nil, fmt.Errorf("private swarm requires a swarm key")
+	}
+	psk, err := pnet.DecodeV1PSK(strings.NewReader(cfg.SwarmKeyFile()))
+	if err != nil {
+		return nil, fmt.Errorf("decoding swarm key: %w", err)
+	}
+	return psk, nil
+}
+
+// SwarmKeyFile formats the swarm key in the swarm.key file format IPFS uses
+func (cfg *P2P) SwarmKeyFile() string {
+	return fmt.Sprintf("/key/swarm/psk/1.0.0/\n/base16/\n%s\n", cfg.SwarmKey)
+}
+
+// PeerAllowed reports whether this node may connect to a peer. All peers are
+// allowed outside of a private swarm
+func (cfg *P2P) PeerAllowed(pid peer.ID) bool {
+	if !cfg.PrivateSwarm || len(cfg.AllowedPeers) == 0 {
+		return true
+	}
+	for _, id := range cfg.AllowedPeers {
+		if id == pid.Pretty() {
+			return true
+		}
+	}
+	return false
+}
+
 // DecodePeerID takes P2P.ID (a string), and decodes it into a peer.ID
 func (cfg *P2P) DecodePeerID() (peer.ID, error) {
 	if string(cfg.PeerID) == "" {
@@ -95,6 +148,17 @@ func (cfg *P2P) DecodePeerID() (peer.ID, error) {
 
 // Validate validates all fields of p2p returning all errors found.
 func (cfg P2P) Validate() error {
+	if cfg.PrivateSwarm {
+		if _, err := cfg.DecodeSwarmKey(); err != nil {
+			return err
+		}
+	}
+	for _, id := range cfg.AllowedPeers {
+		if _, err := peer.IDB58Decode(id); err != nil {
+			return fmt.Errorf("invalid allowed peer %q: %w", id, err)
+		}
+	}
+
 	schema := jsonschema.Must(`{
     "$schema": "http://json-schema.org/draft-06/schema#",
     "title": "P2P",
@@ -144,6 +208,29 @@ func (cfg P2P) Validate() error {
         "items": {
           "type": "string"
         }
+      },
+      "privateswarm": {
+        "description": "When true, only connect to peers that share the swarm key",
+        "type": "boolean"
+      },
+      "swarmkey": {
+        "description": "Hex-encoded 32-byte key shared by peers of a private swarm",
+        "type": "string",
+        "pattern": "^([0-9a-fA-F]{64})?$"
+      },
+      "discoverytag": {
+        "description": "mDNS service tag used to discover local peers",
+        "type": "string"
+      },
+      "allowedpeers": {
+        "description": "Peer IDs a private node connects to. empty accepts any peer with the swarm key",
+        "anyOf": [
+          {"type": "array"},
+          {"type": "null"}
+        ],
+        "items": {
+          "type": "string"
+        }
       }
     }
   }`)
@@ -153,10 +240,13 @@ func (cfg P2P) Validate() error {
 // Copy returns a deep copy of a p2p struct
 func (cfg *P2P) Copy() *P2P {
 	res := &P2P{
-		Enabled: cfg.Enabled,
-		PeerID:  cfg.PeerID,
-		PrivKey: cfg.PrivKey,
-		Port:    cfg.Port,
+		Enabled:      cfg.Enabled,
+		PeerID:       cfg.PeerID,
+		PrivKey:      cfg.PrivKey,
+		Port:         cfg.Port,
+		PrivateSwarm: cfg.PrivateSwarm,
+		SwarmKey:     cfg.SwarmKey,
+		DiscoveryTag: cfg.DiscoveryTag,
 	}
 
 	if cfg.QriBootstrapAddrs != nil {
@@ -169,5 +259,10 @@ func (cfg *P2P) Copy() *P2P {
 		reflect.Copy(reflect.ValueOf(res.BootstrapAddrs), reflect.ValueOf(cfg.BootstrapAddrs))
 	}
 
+	if cfg.AllowedPeers != nil {
+		res.AllowedPeers = make([]string, len(cfg.AllowedPeers))
+		copy(res.AllowedPeers, cfg.AllowedPeers)
+	}
+
 	return res
 }
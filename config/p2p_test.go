@@ -3,6 +3,8 @@ package config
 import (
 	"reflect"
 	"testing"
+
+	peer "github.com/libp2p/go-libp2p-core/peer"
 )
 
 func TestP2PDecodePrivateKey(t *testing.T) {
@@ -38,11 +40,70 @@ func TestP2PValidate(t *testing.T) {
 	}
 }
 
+func TestP2PPrivateSwarm(t *testing.T) {
+	cfg := DefaultP2PForTesting()
+	cfg.PrivateSwarm = true
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected private swarm without a swarm key to be invalid")
+	}
+
+	cfg.SwarmKey = "not_hex"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected invalid swarm key to be invalid")
+	}
+
+	cfg.SwarmKey = "2b4ca1d1d8cfc2b0b8e2a7e3e7d3d0a77d6c2b3bd2e5a9f5f4e3c2b1a0f9e8d7"
+	psk, err := cfg.DecodeSwarmKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(psk) != 32 {
+		t.Errorf("expected a 32 byte key, got %d bytes", len(psk))
+	}
+
+	allowed := "QmeL2mdVka1eahKENjehK6tBxkkpk5dNQ1qMcgWi7Hrb4B"
+	cfg.AllowedPeers = []string{allowed}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected private swarm config to be valid, got: %s", err)
+	}
+	if !cfg.PeerAllowed(mustDecodePeerID(t, allowed)) {
+		t.Error("expected allowlisted peer to be allowed")
+	}
+	other := mustDecodePeerID(t, "QmWYgD49r9HnuXEppQEq1a7SUUryja4QNs9E6XCH2PayCD")
+	if cfg.PeerAllowed(other) {
+		t.Error("expected peer missing from the allowlist to be refused")
+	}
+	cfg.PrivateSwarm = false
+	if !cfg.PeerAllowed(other) {
+		t.Error("expected all peers to be allowed outside a private swarm")
+	}
+
+	cfg.AllowedPeers = []string{"invalid"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected invalid allowed peer ID to be invalid")
+	}
+}
+
+func mustDecodePeerID(t *testing.T, s string) peer.ID {
+	id, err := peer.IDB58Decode(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
 func TestP2PCopy(t *testing.T) {
+	private := DefaultP2PForTesting()
+	private.PrivateSwarm = true
+	private.SwarmKey = "2b4ca1d1d8cfc2b0b8e2a7e3e7d3d0a77d6c2b3bd2e5a9f5f4e3c2b1a0f9e8d7"
+	private.DiscoveryTag = "_lab-swarm"
+	private.AllowedPeers = []string{"QmeL2mdVka1eahKENjehK6tBxkkpk5dNQ1qMcgWi7Hrb4B"}
+
 	cases := []struct {
 		p2p *P2P
 	}{
 		{DefaultP2PForTesting()},
+		{private},
 	}
 	for i, c := range cases {
 		cpy := c.p2p.Copy()
@@ -19,6 +19,12 @@ type Remote struct {
 	RequireAllBlocks bool `json:"requireallblocks"`
 	// allow clients to request unpins for their own pushes
 	AllowRemoves bool `json:"allowremoves"`
+	// DailyPushBytesMax is the number of bytes each profile can push per day,
+	// 0 means no limit
+	DailyPushBytesMax int64 `json:"dailypushbytesmax,omitempty"`
+	// DailyPullBytesMax is the number of bytes each profile can pull per day,
+	// 0 means no limit
+	DailyPullBytesMax int64 `json:"dailypullbytesmax,omitempty"`
 }
 
 // SetArbitrary is an interface implementation of base/fill/struct in order to safely
@@ -43,6 +49,16 @@ func (cfg Remote) Validate() error {
       "defaultTemplateHash": {
         "description": "A hash of the compiled render. This is fetched and replaced via dsnlink when the render server starts. The value provided here is just a sensible fallback for when dnslink lookup fails.",
         "type": "string"
+      },
+      "dailypushbytesmax": {
+        "description": "bytes each profile can push per day, 0 means no limit",
+        "type": "integer",
+        "minimum": 0
+      },
+      "dailypullbytesmax": {
+        "description": "bytes each profile can pull per day, 0 means no limit",
+        "type": "integer",
+        "minimum": 0
       }
     }
   }`)
@@ -57,6 +73,9 @@ func (cfg *Remote) Copy() *Remote {
 		AcceptTimeoutMs:  cfg.AcceptTimeoutMs,
 		RequireAllBlocks: cfg.RequireAllBlocks,
 		AllowRemoves:     cfg.AllowRemoves,
+
+		DailyPushBytesMax: cfg.DailyPushBytesMax,
+		DailyPullBytesMax: cfg.DailyPullBytesMax,
 	}
 
 	return res
@@ -18,6 +18,7 @@ func TestRemoteCopy(t *testing.T) {
 		remote *Remote
 	}{
 		{&Remote{}},
+		{&Remote{AcceptSizeMax: 10, DailyPushBytesMax: 100, DailyPullBytesMax: 200}},
 	}
 	for i, c := range cases {
 		cpy := c.remote.Copy()
@@ -8,6 +8,9 @@ import (
 type Repo struct {
 	Type string `json:"type"`
 	Path string `json:"path,omitempty"`
+	// EventJournal enables recording every event to an append-only journal
+	// file in the repo directory
+	EventJournal bool `json:"eventjournal,omitempty"`
 }
 
 // SetArbitrary is an interface implementation of base/fill/struct in order to safely
@@ -40,6 +43,10 @@ func (cfg Repo) Validate() error {
           "fs",
           "mem"
         ]
+      },
+      "eventjournal": {
+        "description": "Record events to a journal in the repo directory",
+        "type": "boolean"
       }
     }
   }`)
@@ -49,7 +56,8 @@ func (cfg Repo) Validate() error {
 // Copy returns a deep copy of the Repo struct
 func (cfg *Repo) Copy() *Repo {
 	res := &Repo{
-		Type: cfg.Type,
+		Type:         cfg.Type,
+		EventJournal: cfg.EventJournal,
 	}
 
 	return res
@@ -29,6 +29,7 @@ func TestRepoCopy(t *testing.T) {
 			continue
 		}
 		cpy.Path = "newPath"
+		cpy.EventJournal = !cpy.EventJournal
 		if reflect.DeepEqual(cpy, c.repo) {
 			t.Errorf("Repo Copy test case %v, editing one repo struct should not affect the other: \ncopy: %v, \noriginal: %v", i, cpy, c.repo)
 			continue
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"github.com/qri-io/jsonschema"
+)
+
+// Transform configures limits on transform scripts this node runs
+type Transform struct {
+	// MaxSteps is the number of steps a script may execute, 0 means no limit
+	MaxSteps uint64 `json:"maxsteps,omitempty"`
+	// TimeoutMs is the number of milliseconds a script may run for, 0 means
+	// no limit
+	TimeoutMs int64 `json:"timeoutms,omitempty"`
+	// MaxBodySize is the size in bytes of a body a script may set, 0 means no
+	// limit
+	MaxBodySize int64 `json:"maxbodysize,omitempty"`
+	// AllowedHosts restricts the hosts scripts can make network requests to.
+	// Entries that start with "*." match any subdomain. An empty list allows
+	// all hosts
+	AllowedHosts []string `json:"allowedhosts,omitempty"`
+}
+
+// SetArbitrary is an interface implementation of base/fill/struct in order to safely
+// consume config files that have definitions beyond those specified in the struct.
+// This simply ignores all additional fields at read time.
+func (cfg *Transform) SetArbitrary(key string, val interface{}) error {
+	return nil
+}
+
+// Validate validates all fields of transform returning all errors found.
+func (cfg Transform) Validate() error {
+	schema := jsonschema.Must(`{
+    "$schema": "http://json-schema.org/draft-06/schema#",
+    "title": "Transform",
+    "description": "Limits on transform scripts",
+    "type": "object",
+    "properties": {
+      "maxsteps": {
+        "description": "steps a script may execute, 0 means no limit",
+        "type": "integer",
+        "minimum": 0
+      },
+      "timeoutms": {
+        "description": "milliseconds a script may run for, 0 means no limit",
+        "type": "integer",
+        "minimum": 0
+      },
+      "maxbodysize": {
+        "description": "size in bytes of a body a script may set, 0 means no limit",
+        "type": "integer",
+        "minimum": 0
+      },
+      "allowedhosts": {
+        "description": "hosts scripts may make network requests to",
+        "type": "array",
+        "items": { "type": "string" }
+      }
+    }
+  }`)
+	return validate(schema, &cfg)
+}
+
+// Copy returns a deep copy of the Transform struct
+func (cfg *Transform) Copy() *Transform {
+	res := &Transform{
+		MaxSteps:    cfg.MaxSteps,
+		TimeoutMs:   cfg.TimeoutMs,
+		MaxBodySize: cfg.MaxBodySize,
+	}
+	if cfg.AllowedHosts != nil {
+		res.AllowedHosts = make([]string, len(cfg.AllowedHosts))
+		copy(res.AllowedHosts, cfg.AllowedHosts)
+	}
+	return res
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTransformValidate(t *testing.T) {
+	cfg := &Transform{MaxSteps: 1000, TimeoutMs: 5000, AllowedHosts: []string{"*.example.com"}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("error validating transform: %s", err)
+	}
+	if err := (&Transform{TimeoutMs: -1}).Validate(); err == nil {
+		t.Error("expected negative timeout to fail validation")
+	}
+}
+
+func TestTransformCopy(t *testing.T) {
+	cfg := &Transform{MaxSteps: 1000, TimeoutMs: 5000, MaxBodySize: 100, AllowedHosts: []string{"qri.io"}}
+	cpy := cfg.Copy()
+	if !reflect.DeepEqual(cpy, cfg) {
+		t.Errorf("copy mismatch.\ncopy: %v\noriginal: %v", cpy, cfg)
+	}
+	cpy.AllowedHosts[0] = "example.com"
+	if cfg.AllowedHosts[0] != "qri.io" {
+		t.Error("expected copy to not share allowed hosts with the original")
+	}
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/qri-io/jsonschema"
+)
+
+// Webhooks is a list of endpoints to notify when events happen on this node
+type Webhooks []*Webhook
+
+// Webhook configures delivery of events to a single HTTP endpoint
+type Webhook struct {
+	// URL to POST event payloads to
+	URL string `json:"url"`
+	// Events is the list of event types to deliver, eg: "dataset:CommitChange".
+	// an empty list delivers the default set of events
+	Events []string `json:"events,omitempty"`
+	// Datasets limits delivery to events about the listed datasets, in
+	// "username/name" form. an empty list delivers events for all datasets
+	Datasets []string `json:"datasets,omitempty"`
+	// Secret is used to sign payloads. receivers can verify a delivery by
+	// checking the X-Qri-Signature header against an HMAC-SHA256 of the request
+	// body keyed with this secret
+	Secret string `json:"secret,omitempty"`
+}
+
+// SetArbitrary is an interface implementation of base/fill/struct in order to safely
+// consume config files that have definitions beyond those specified in the struct.
+// This simply ignores all additional fields at read time.
+func (cfg *Webhook) SetArbitrary(key string, val interface{}) error {
+	return nil
+}
+
+// Validate validates all fields of each webhook, returning the first error
+func (cfg Webhooks) Validate() error {
+	for i, hook := range cfg {
+		if hook == nil {
+			return fmt.Errorf("webhook %d: empty webhook", i)
+		}
+		if err := hook.Validate(); err != nil {
+			return fmt.Errorf("webhook %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Validate validates all fields of a webhook returning all errors found.
+func (cfg Webhook) Validate() error {
+	schema := jsonschema.Must(`{
+    "$schema": "http://json-schema.org/draft-06/schema#",
+    "title": "Webhook",
+    "description": "An HTTP endpoint to deliver events to",
+    "type": "object",
+    "required": ["url"],
+    "properties": {
+      "url": {
+        "description": "URL to POST event payloads to",
+        "type": "string"
+      },
+      "events": {
+        "description": "event types to deliver",
+        "type": "array",
+        "items": { "type": "string" }
+      },
+      "datasets": {
+        "description": "datasets to deliver events for, in username/name form",
+        "type": "array",
+        "items": { "type": "string" }
+      },
+      "secret": {
+        "description": "secret used to sign payloads",
+        "type": "string"
+      }
+    }
+  }`)
+	if err := validate(schema, &cfg); err != nil {
+		return err
+	}
+
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("url %q must use http or https", cfg.URL)
+	}
+	return nil
+}
+
+// Copy returns a deep copy of a list of webhooks
+func (cfg Webhooks) Copy() Webhooks {
+	if cfg == nil {
+		return nil
+	}
+	res := make(Webhooks, 0, len(cfg))
+	for _, hook := range cfg {
+		if hook != nil {
+			res = append(res, hook.Copy())
+		}
+	}
+	return res
+}
+
+// Copy returns a deep copy of a webhook
+func (cfg *Webhook) Copy() *Webhook {
+	res := &Webhook{
+		URL:    cfg.URL,
+		Secret: cfg.Secret,
+	}
+	if cfg.Events != nil {
+		res.Events = make([]string, len(cfg.Events))
+		copy(res.Events, cfg.Events)
+	}
+	if cfg.Datasets != nil {
+		res.Datasets = make([]string, len(cfg.Datasets))
+		copy(res.Datasets, cfg.Datasets)
+	}
+	return res
+}
+
+// WithoutPrivateValues returns a deep copy of the list with secrets removed
+func (cfg Webhooks) WithoutPrivateValues() Webhooks {
+	res := cfg.Copy()
+	for _, hook := range res {
+		hook.Secret = ""
+	}
+	return res
+}
+
+// WithPrivateValues returns a deep copy of the receiver, filling in missing
+// secrets from webhooks in p that share the same URL
+func (cfg Webhooks) WithPrivateValues(p Webhooks) Webhooks {
+	res := cfg.Copy()
+	for _, hook := range res {
+		if hook.Secret != "" {
+			continue
+		}
+		for _, prev := range p {
+			if prev != nil && prev.URL == hook.URL {
+				hook.Secret = prev.Secret
+				break
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWebhooksValidate(t *testing.T) {
+	good := Webhooks{
+		{URL: "https://example.com/hook"},
+		{URL: "http://localhost:8080", Events: []string{"dataset:CommitChange"}, Datasets: []string{"peer/ds"}, Secret: "shh"},
+	}
+	if err := good.Validate(); err != nil {
+		t.Errorf("unexpected error validating webhooks: %s", err)
+	}
+
+	bad := []Webhooks{
+		{{}},
+		{{URL: "ftp://example.com"}},
+		{nil},
+	}
+	for i, hooks := range bad {
+		if err := hooks.Validate(); err == nil {
+			t.Errorf("case %d: expected error, got nil", i)
+		}
+	}
+}
+
+func TestWebhooksCopy(t *testing.T) {
+	hooks := Webhooks{
+		{URL: "https://example.com/hook", Events: []string{"dataset:CommitChange"}, Datasets: []string{"peer/ds"}, Secret: "shh"},
+	}
+	cpy := hooks.Copy()
+	if !reflect.DeepEqual(cpy, hooks) {
+		t.Fatalf("webhooks are not equal: \ncopy: %v, \noriginal: %v", cpy, hooks)
+	}
+	cpy[0].Events[0] = "dataset:DeleteAll"
+	if reflect.DeepEqual(cpy, hooks) {
+		t.Errorf("editing a copy should not affect the original")
+	}
+}
+
+func TestWebhooksPrivateValues(t *testing.T) {
+	hooks := Webhooks{
+		{URL: "https://example.com/a", Secret: "a"},
+		{URL: "https://example.com/b", Secret: "b"},
+	}
+	public := hooks.WithoutPrivateValues()
+	for _, hook := range public {
+		if hook.Secret != "" {
+			t.Errorf("expected secret for %q to be removed", hook.URL)
+		}
+	}
+	if hooks[0].Secret != "a" {
+		t.Errorf("removing private values should not modify the receiver")
+	}
+
+	restored := public.WithPrivateValues(hooks)
+	if !reflect.DeepEqual(restored, hooks) {
+		t.Errorf("expected secrets to be restored. got: %v", restored)
+	}
+}
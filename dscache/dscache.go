@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"strings"
+	"sync"
 	"time"
 
 	flatbuffers "github.com/google/flatbuffers/go"
@@ -36,6 +37,12 @@ type Dscache struct {
 	CreateNewEnabled    bool
 	ProfileIDToUsername map[string]string
 	DefaultUsername     string
+
+	// lk guards the cache. updates from the bus are applied on a separate
+	// goroutine, reads flush sub first so they see every update published
+	// before the read
+	lk  sync.RWMutex
+	sub event.Subscription
 }
 
 // NewDscache will construct a dscache from the given filename, or will construct an empty dscache
@@ -55,7 +62,10 @@ func NewDscache(ctx context.Context, fsys qfs.Filesystem, bus event.Bus, usernam
 		}
 	}
 	cache.DefaultUsername = username
-	bus.Subscribe(cache.handler,
+	// updates rebuild & write the entire cache, handle them asynchronously so
+	// they don't slow down the operations that publish them. The bus applies
+	// queued updates before shutting down
+	cache.sub = bus.SubscribeAsync(cache.handler, event.AsyncOptions{},
 		event.ETDatasetNameInit,
 		event.ETDatasetCommitChange,
 		event.ETDatasetDeleteAll,
@@ -70,6 +80,13 @@ func (d *Dscache) IsEmpty() bool {
 	if d == nil {
 		return true
 	}
+	d.flush()
+	d.lk.RLock()
+	defer d.lk.RUnlock()
+	return d.isEmpty()
+}
+
+func (d *Dscache) isEmpty() bool {
 	return d.Root == nil
 }
 
@@ -78,6 +95,13 @@ func (d *Dscache) Assign(other *Dscache) error {
 	if d == nil {
 		return ErrNoDscache
 	}
+	d.flush()
+	d.lk.Lock()
+	defer d.lk.Unlock()
+	return d.assign(other)
+}
+
+func (d *Dscache) assign(other *Dscache) error {
 	d.Root = other.Root
 	d.Buffer = other.Buffer
 	return d.save()
@@ -88,6 +112,11 @@ func (d *Dscache) VerboseString(showEmpty bool) string {
 	if d.IsEmpty() {
 		return "dscache: cannot not stringify an empty dscache"
 	}
+	d.lk.RLock()
+	defer d.lk.RUnlock()
+	if d.isEmpty() {
+		return "dscache: cannot not stringify an empty dscache"
+	}
 	out := strings.Builder{}
 	out.WriteString("Dscache:\n")
 	out.WriteString(" Dscache.Users:\n")
@@ -142,6 +171,12 @@ func (d *Dscache) ListRefs() ([]reporef.DatasetRef, error) {
 	if d.IsEmpty() {
 		return nil, ErrNoDscache
 	}
+	// ensureProToUserMap may write to the cache, take a write lock
+	d.lk.Lock()
+	defer d.lk.Unlock()
+	if d.isEmpty() {
+		return nil, ErrNoDscache
+	}
 	d.ensureProToUserMap()
 	refs := make([]reporef.DatasetRef, 0, d.Root.RefsLength())
 	for i := 0; i < d.Root.RefsLength(); i++ {
@@ -188,8 +223,13 @@ func (d *Dscache) ResolveRef(ctx context.Context, ref *dsref.Ref) (string, error
 	if d.IsEmpty() {
 		return "", dsref.ErrRefNotFound
 	}
+	d.lk.RLock()
+	defer d.lk.RUnlock()
+	if d.isEmpty() {
+		return "", dsref.ErrRefNotFound
+	}
 
-	vi, err := d.LookupByName(*ref)
+	vi, err := d.lookupByName(*ref)
 	if err != nil {
 		return "", dsref.ErrRefNotFound
 	}
@@ -205,6 +245,13 @@ func (d *Dscache) ResolveRef(ctx context.Context, ref *dsref.Ref) (string, error
 
 // LookupByName looks up a dataset by dsref and returns the latest VersionInfo if found
 func (d *Dscache) LookupByName(ref dsref.Ref) (*dsref.VersionInfo, error) {
+	d.flush()
+	d.lk.RLock()
+	defer d.lk.RUnlock()
+	return d.lookupByName(ref)
+}
+
+func (d *Dscache) lookupByName(ref dsref.Ref) (*dsref.VersionInfo, error) {
 	// Convert the username into a profileID
 	for i := 0; i < d.Root.UsersLength(); i++ {
 		userAssoc := dscachefb.UserAssoc{}
@@ -237,6 +284,13 @@ func (d *Dscache) validateProfileID(profileID string) bool {
 	return len(profileID) == lengthOfProfileID
 }
 
+// flush waits for updates published before the call to be applied
+func (d *Dscache) flush() {
+	if d.sub != nil {
+		d.sub.Flush()
+	}
+}
+
 func (d *Dscache) handler(_ context.Context, t event.Type, payload interface{}) error {
 	act, ok := payload.(event.DsChange)
 	if !ok {
@@ -244,6 +298,9 @@ func (d *Dscache) handler(_ context.Context, t event.Type, payload interface{})
 		return nil
 	}
 
+	d.lk.Lock()
+	defer d.lk.Unlock()
+
 	switch t {
 	case event.ETDatasetNameInit:
 		if err := d.updateInitDataset(act); err != nil && err != ErrNoDscache {
@@ -269,7 +326,7 @@ func (d *Dscache) handler(_ context.Context, t event.Type, payload interface{})
 }
 
 func (d *Dscache) updateInitDataset(act event.DsChange) error {
-	if d.IsEmpty() {
+	if d.isEmpty() {
 		// Only create a new dscache if that feature is enabled. This way no one is forced to
 		// use dscache without opting in.
 		if !d.CreateNewEnabled {
@@ -288,7 +345,7 @@ func (d *Dscache) updateInitDataset(act event.DsChange) error {
 			Name:      act.PrettyName,
 		})
 		cache := builder.Build()
-		d.Assign(cache)
+		d.assign(cache)
 		return nil
 	}
 	builder := NewBuilder()
@@ -311,13 +368,13 @@ func (d *Dscache) updateInitDataset(act event.DsChange) error {
 		Name:      act.PrettyName,
 	})
 	cache := builder.Build()
-	d.Assign(cache)
+	d.assign(cache)
 	return nil
 }
 
 // Copy the entire dscache, except for the matching entry, rebuild that one to modify it
 func (d *Dscache) updateChangeCursor(act event.DsChange) error {
-	if d.IsEmpty() {
+	if d.isEmpty() {
 		return ErrNoDscache
 	}
 	// Flatbuffers for go do not allow mutation (for complex types like strings). So we construct
@@ -361,7 +418,7 @@ func (d *Dscache) updateChangeCursor(act event.DsChange) error {
 
 // Copy the entire dscache, except leave out the matching entry.
 func (d *Dscache) updateDeleteDataset(act event.DsChange) error {
-	if d.IsEmpty() {
+	if d.isEmpty() {
 		return ErrNoDscache
 	}
 	// Flatbuffers for go do not allow mutation (for complex types like strings). So we construct
@@ -384,7 +441,7 @@ func (d *Dscache) updateDeleteDataset(act event.DsChange) error {
 
 // Copy the entire dscache, except for the matching entry, which is copied then assigned an fsiPath
 func (d *Dscache) updateCreateLink(act event.DsChange) error {
-	if d.IsEmpty() {
+	if d.isEmpty() {
 		return ErrNoDscache
 	}
 	// Flatbuffers for go do not allow mutation (for complex types like strings). So we construct
@@ -2,6 +2,7 @@ package dscache
 
 import (
 	"context"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -116,6 +117,46 @@ func TestResolveRef(t *testing.T) {
 	})
 }
 
+func TestAsyncUpdates(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus := event.NewBus(ctx)
+	dsc := NewDscache(ctx, qfs.NewMemFS(), bus, "queued_user", "")
+
+	pid := testPeers.GetTestPeerInfo(0).EncodedPeerID
+	builder := NewBuilder()
+	builder.AddUser("queued_user", pid)
+	builder.AddDsVersionInfo(dsref.VersionInfo{
+		InitID:    "init_id",
+		ProfileID: pid,
+		Name:      "queued_ds",
+	})
+	if err := dsc.Assign(builder.Build()); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		err := bus.Publish(ctx, event.ETDatasetCommitChange, event.DsChange{
+			InitID:   "init_id",
+			TopIndex: i,
+			HeadRef:  fmt.Sprintf("/mem/QmHead%d", i),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// reads wait for queued updates to be applied, in order
+	vi, err := dsc.LookupByName(dsref.Ref{Username: "queued_user", Name: "queued_ds"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vi.Path != "/mem/QmHead3" {
+		t.Errorf("expected queued updates to be applied. want path %q, got %q", "/mem/QmHead3", vi.Path)
+	}
+}
+
 func TestCacheRefConsistency(t *testing.T) {
 	ctx := context.Background()
 
@@ -112,3 +112,42 @@ func (sr sequentialResolver) ResolveRef(ctx context.Context, ref *Ref) (string,
 
 	return "", ErrRefNotFound
 }
+
+// UsernameRedirect looks up the current username for a username that may have
+// been renamed
+type UsernameRedirect func(ctx context.Context, username string) (current string, err error)
+
+// RedirectResolver wraps a resolver, following username renames when a
+// reference isn't found. On success the resolved reference carries the
+// current username. Errors looking up a redirect are treated as not found
+func RedirectResolver(r Resolver, redirect UsernameRedirect) Resolver {
+	return redirectResolver{r: r, redirect: redirect}
+}
+
+type redirectResolver struct {
+	r        Resolver
+	redirect UsernameRedirect
+}
+
+func (rr redirectResolver) ResolveRef(ctx context.Context, ref *Ref) (string, error) {
+	if rr.r == nil {
+		return "", ErrRefNotFound
+	}
+	source, err := rr.r.ResolveRef(ctx, ref)
+	if !errors.Is(err, ErrRefNotFound) || rr.redirect == nil || ref.Username == "" {
+		return source, err
+	}
+
+	current, redirectErr := rr.redirect(ctx, ref.Username)
+	if redirectErr != nil || current == "" || current == ref.Username {
+		return "", err
+	}
+
+	cpy := ref.Copy()
+	cpy.Username = current
+	if source, err = rr.r.ResolveRef(ctx, &cpy); err != nil {
+		return "", err
+	}
+	*ref = cpy
+	return source, nil
+}
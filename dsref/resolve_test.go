@@ -1,6 +1,8 @@
 package dsref
 
 import (
+	"context"
+	"errors"
 	"testing"
 )
 
@@ -11,3 +13,42 @@ func TestParallelResolver(t *testing.T) {
 func TestSequentialResolver(t *testing.T) {
 	t.Skip("TODO(b5)")
 }
+
+func TestRedirectResolver(t *testing.T) {
+	ctx := context.Background()
+	mem := NewMemResolver("b6")
+	mem.Put(VersionInfo{InitID: "init_id", Username: "b6", Name: "ds", Path: "/ipfs/QmHead"})
+
+	redirect := func(ctx context.Context, username string) (string, error) {
+		switch username {
+		case "b5":
+			return "b6", nil
+		case "broken":
+			return "", errors.New("registry offline")
+		}
+		return "", ErrRefNotFound
+	}
+	r := RedirectResolver(mem, redirect)
+
+	ref := &Ref{Username: "b5", Name: "ds"}
+	if _, err := r.ResolveRef(ctx, ref); err != nil {
+		t.Fatal(err)
+	}
+	if ref.Username != "b6" || ref.InitID != "init_id" || ref.Path != "/ipfs/QmHead" {
+		t.Errorf("expected ref to resolve under current username, got: %#v", ref)
+	}
+
+	for _, username := range []string{"unknown", "broken"} {
+		ref := &Ref{Username: username, Name: "ds"}
+		if _, err := r.ResolveRef(ctx, ref); !errors.Is(err, ErrRefNotFound) {
+			t.Errorf("%q: expected ErrRefNotFound, got: %v", username, err)
+		}
+		if ref.Username != username {
+			t.Errorf("%q: expected unresolved ref to be unchanged, got username %q", username, ref.Username)
+		}
+	}
+
+	if _, err := r.ResolveRef(ctx, &Ref{Username: "b5", Name: "missing"}); !errors.Is(err, ErrRefNotFound) {
+		t.Errorf("expected missing dataset under a redirected username to be ErrRefNotFound, got: %v", err)
+	}
+}
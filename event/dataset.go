@@ -33,3 +33,23 @@ type DsChange struct {
 	Info       *dsref.VersionInfo `json:"info"`
 	Dir        string             `json:"dir"`
 }
+
+// DatasetName returns the "username/name" of the dataset an event payload
+// refers to, or the empty string for payloads that don't refer to a dataset
+func DatasetName(payload interface{}) string {
+	var username, name string
+	switch p := payload.(type) {
+	case DsChange:
+		username, name = p.Username, p.PrettyName
+	case RemoteEvent:
+		username, name = p.Ref.Username, p.Ref.Name
+	case WatchfsChange:
+		username, name = p.Username, p.Dsname
+	case FSICreateLinkEvent:
+		username, name = p.Username, p.Dsname
+	}
+	if username == "" || name == "" {
+		return ""
+	}
+	return username + "/" + name
+}
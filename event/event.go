@@ -31,8 +31,10 @@ type Type string
 // The handler context originates from the publisher, and in practice will often
 // be scoped to a "request context" like an HTTP request or CLI command
 // invocation.
-// Generally, even handlers should aim to return quickly, and only delegate to
-// goroutines when the publishing event is firing on a long-running process
+// Generally, even handlers should aim to return quickly. Handlers that do slow
+// work should be registered with SubscribeAsync, which calls the handler from
+// a separate goroutine with a context that carries the publisher's values but
+// isn't cancelled along with it
 type Handler func(ctx context.Context, t Type, payload interface{}) error
 
 // Publisher is an interface that can only publish an event
@@ -49,9 +51,22 @@ type Bus interface {
 	Publish(ctx context.Context, t Type, data interface{}) error
 	// Subscribe to one or more topics with a handler function that will be called
 	// whenever the event topic is published
-	Subscribe(handler Handler, topics ...Type)
+	Subscribe(handler Handler, topics ...Type) Subscription
+	// SubscribeAll registers a handler that will be called for every event
+	// published on the bus, after any handlers subscribed to the event's topic
+	SubscribeAll(handler Handler) Subscription
+	// SubscribeAsync registers a handler that is called from a separate
+	// goroutine, reading events from a bounded queue. Publishers don't wait for
+	// async handlers to finish, and errors returned by async handlers are logged
+	// instead of returned to the publisher
+	SubscribeAsync(handler Handler, opts AsyncOptions, topics ...Type) Subscription
 	// NumSubscriptions returns the number of subscribers to the bus's events
 	NumSubscribers() int
+	// Metrics reports timing & delivery stats for each subscription
+	Metrics() []HandlerMetrics
+	// Done returns a channel that closes once the bus is closed and all queued
+	// async events have been handled
+	Done() <-chan struct{}
 }
 
 // NilBus replaces a nil value. it implements the bus interface, but does
@@ -63,21 +78,51 @@ type nilBus struct{}
 // assert at compile time that nilBus implements the Bus interface
 var _ Bus = (*nilBus)(nil)
 
+// closedCh is a channel that is always closed
+var closedCh = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
 // Publish does nothing with the event
 func (nilBus) Publish(_ context.Context, _ Type, _ interface{}) error {
 	return nil
 }
 
-func (nilBus) Subscribe(handler Handler, topics ...Type) {}
+func (nilBus) Subscribe(handler Handler, topics ...Type) Subscription {
+	return nilSubscription{}
+}
+
+func (nilBus) SubscribeAll(handler Handler) Subscription {
+	return nilSubscription{}
+}
+
+func (nilBus) SubscribeAsync(handler Handler, opts AsyncOptions, topics ...Type) Subscription {
+	return nilSubscription{}
+}
 
 func (nilBus) NumSubscribers() int {
 	return 0
 }
 
+func (nilBus) Metrics() []HandlerMetrics {
+	return nil
+}
+
+func (nilBus) Done() <-chan struct{} {
+	return closedCh
+}
+
 type bus struct {
 	lk     sync.RWMutex
 	closed bool
-	subs   map[Type][]Handler
+	nextID uint64
+	subs   map[Type][]*subscription
+	all    []*subscription
+	// every active subscription, in order of subscription
+	active []*subscription
+	doneCh chan struct{}
 }
 
 // assert at compile time that bus implements the Bus interface
@@ -85,12 +130,12 @@ var _ Bus = (*bus)(nil)
 
 // NewBus creates a new event bus. Event busses should be instantiated as a
 // singleton. If the passed in context is cancelled, the bus will stop emitting
-// events and close all subscribed channels
-//
-// TODO (b5) - finish context-closing cleanup
+// events, finish handling events already queued for async subscribers, and
+// close the channel returned by Done
 func NewBus(ctx context.Context) Bus {
 	b := &bus{
-		subs: map[Type][]Handler{},
+		subs:   map[Type][]*subscription{},
+		doneCh: make(chan struct{}),
 	}
 
 	go func(b *bus) {
@@ -98,7 +143,17 @@ func NewBus(ctx context.Context) Bus {
 		log.Debugf("close bus")
 		b.lk.Lock()
 		b.closed = true
+		active := b.active
 		b.lk.Unlock()
+
+		// drain async queues
+		for _, sub := range active {
+			sub.stop()
+		}
+		for _, sub := range active {
+			<-sub.doneCh
+		}
+		close(b.doneCh)
 	}(b)
 
 	return b
@@ -107,14 +162,19 @@ func NewBus(ctx context.Context) Bus {
 // Publish sends an event to the bus
 func (b *bus) Publish(ctx context.Context, topic Type, data interface{}) error {
 	b.lk.RLock()
-	defer b.lk.RUnlock()
-
 	if b.closed {
+		b.lk.RUnlock()
 		return ErrBusClosed
 	}
+	// copy subscriptions so handlers can subscribe & unsubscribe without
+	// deadlocking
+	subs := make([]*subscription, 0, len(b.subs[topic])+len(b.all))
+	subs = append(subs, b.subs[topic]...)
+	subs = append(subs, b.all...)
+	b.lk.RUnlock()
 
-	for _, handler := range b.subs[topic] {
-		if err := handler(ctx, topic, data); err != nil {
+	for _, sub := range subs {
+		if err := sub.publish(ctx, topic, data); err != nil {
 			return err
 		}
 	}
@@ -122,24 +182,111 @@ func (b *bus) Publish(ctx context.Context, topic Type, data interface{}) error {
 	return nil
 }
 
-// Subscribe requests events from the given topic, returning a channel of those events
-func (b *bus) Subscribe(handler Handler, topics ...Type) {
+// Subscribe requests events from the given topics
+func (b *bus) Subscribe(handler Handler, topics ...Type) Subscription {
+	log.Debugf("Subscribe: %v", topics)
+	return b.add(newSubscription(b, handler, false, topics, nil))
+}
+
+// SubscribeAll requests every event published on the bus
+func (b *bus) SubscribeAll(handler Handler) Subscription {
+	log.Debugf("SubscribeAll")
+	return b.add(newSubscription(b, handler, true, nil, nil))
+}
+
+// SubscribeAsync requests events from the given topics, handling them on a
+// separate goroutine
+func (b *bus) SubscribeAsync(handler Handler, opts AsyncOptions, topics ...Type) Subscription {
+	log.Debugf("SubscribeAsync: %v", topics)
+	return b.add(newSubscription(b, handler, false, topics, &opts))
+}
+
+func (b *bus) add(sub *subscription) Subscription {
 	b.lk.Lock()
 	defer b.lk.Unlock()
-	log.Debugf("Subscribe: %v", topics)
 
-	for _, topic := range topics {
-		b.subs[topic] = append(b.subs[topic], handler)
+	if b.closed {
+		// a subscription to a closed bus never receives events
+		sub.stop()
+		return sub
+	}
+
+	b.nextID++
+	sub.id = b.nextID
+	if sub.all {
+		b.all = append(b.all, sub)
+	}
+	for _, topic := range sub.topics {
+		b.subs[topic] = append(b.subs[topic], sub)
+	}
+	b.active = append(b.active, sub)
+	return sub
+}
+
+// remove drops a subscription from the bus. it's safe to call remove more
+// than once
+func (b *bus) remove(sub *subscription) {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+
+	if sub.all {
+		b.all = without(b.all, sub)
+	}
+	for _, topic := range sub.topics {
+		if subs := without(b.subs[topic], sub); len(subs) > 0 {
+			b.subs[topic] = subs
+		} else {
+			delete(b.subs, topic)
+		}
 	}
 }
 
-// NumSubscribers returns the number of subscribers to the bus's events
-func (b *bus) NumSubscribers() int {
+// release drops a subscription from the list of active subscriptions once
+// it's done handling events. Subscriptions are kept active while the bus is
+// shutting down so the bus can wait for them to drain
+func (b *bus) release(sub *subscription) {
+	<-sub.doneCh
 	b.lk.Lock()
 	defer b.lk.Unlock()
-	total := 0
-	for _, handlers := range b.subs {
-		total += len(handlers)
+	if !b.closed {
+		b.active = without(b.active, sub)
+	}
+}
+
+func without(subs []*subscription, sub *subscription) []*subscription {
+	res := make([]*subscription, 0, len(subs))
+	for _, s := range subs {
+		if s != sub {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// NumSubscribers returns the number of subscribers to the bus's events
+func (b *bus) NumSubscribers() int {
+	b.lk.RLock()
+	defer b.lk.RUnlock()
+	total := len(b.all)
+	for _, subs := range b.subs {
+		total += len(subs)
 	}
 	return total
 }
+
+// Metrics reports timing & delivery stats for each active subscription, in
+// order of subscription
+func (b *bus) Metrics() []HandlerMetrics {
+	b.lk.RLock()
+	defer b.lk.RUnlock()
+	res := make([]HandlerMetrics, 0, len(b.active))
+	for _, sub := range b.active {
+		res = append(res, sub.metrics())
+	}
+	return res
+}
+
+// Done returns a channel that closes once the bus has shut down
+func (b *bus) Done() <-chan struct{} {
+	return b.doneCh
+}
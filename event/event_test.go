@@ -3,6 +3,10 @@ package event
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/qri-io/qri/dsref"
 )
 
 func Example() {
@@ -36,3 +40,49 @@ func Example() {
 	// third handler called
 	// first handler called
 }
+
+func TestSubscribeAll(t *testing.T) {
+	ctx, done := context.WithCancel(context.Background())
+	defer done()
+
+	bus := NewBus(ctx)
+	var got []Type
+	bus.SubscribeAll(func(ctx context.Context, t Type, payload interface{}) error {
+		got = append(got, t)
+		return nil
+	})
+	bus.Subscribe(func(ctx context.Context, t Type, payload interface{}) error {
+		return nil
+	}, Type("a"))
+
+	if bus.NumSubscribers() != 2 {
+		t.Errorf("expected 2 subscribers, got %d", bus.NumSubscribers())
+	}
+
+	bus.Publish(ctx, Type("a"), nil)
+	bus.Publish(ctx, Type("b"), nil)
+
+	expect := []Type{"a", "b"}
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("event type mismatch. want: %v, got: %v", expect, got)
+	}
+}
+
+func TestDatasetName(t *testing.T) {
+	cases := []struct {
+		payload interface{}
+		expect  string
+	}{
+		{DsChange{Username: "peer", PrettyName: "ds"}, "peer/ds"},
+		{RemoteEvent{Ref: dsref.Ref{Username: "peer", Name: "ds"}}, "peer/ds"},
+		{WatchfsChange{Username: "peer", Dsname: "ds"}, "peer/ds"},
+		{FSICreateLinkEvent{Username: "peer", Dsname: "ds"}, "peer/ds"},
+		{DsChange{Username: "peer"}, ""},
+		{"not a dataset event", ""},
+	}
+	for i, c := range cases {
+		if got := DatasetName(c.payload); got != c.expect {
+			t.Errorf("case %d: expected %q, got %q", i, c.expect, got)
+		}
+	}
+}
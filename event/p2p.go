@@ -26,8 +26,4 @@ var (
 	// ETP2PPeerDisconnected occurs after any peer has connected to this node
 	// payload will be a libp2p.peerInfo
 	ETP2PPeerDisconnected = Type("p2p:PeerDisconnected")
-	// ETP2PMessageReceived fires whenever the p2p protocol receives a message
-	// from a Qri peer
-	// payload will be a p2p.Message
-	ETP2PMessageReceived = Type("p2p:MessageReceived")
 )
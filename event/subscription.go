@@ -0,0 +1,339 @@
+package event
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// QueuePolicy determines what happens when an event is published to an async
+// subscription with a full queue
+type QueuePolicy int
+
+const (
+	// QueueBlock makes the publisher wait until the queue has room, applying
+	// backpressure to publishers. If the publisher's context is done first
+	// the event is dropped for this subscriber only. This is the default policy
+	QueueBlock QueuePolicy = iota
+	// QueueDropNewest discards the event being published
+	QueueDropNewest
+	// QueueDropOldest discards the oldest queued event to make room for the
+	// event being published
+	QueueDropOldest
+)
+
+// String implements the stringer interface for QueuePolicy
+func (p QueuePolicy) String() string {
+	switch p {
+	case QueueBlock:
+		return "block"
+	case QueueDropNewest:
+		return "dropNewest"
+	case QueueDropOldest:
+		return "dropOldest"
+	}
+	return "unknown"
+}
+
+// DefaultQueueSize is the number of events an async subscription queues when
+// AsyncOptions doesn't specify a size
+const DefaultQueueSize = 100
+
+// AsyncOptions configures an async subscription
+type AsyncOptions struct {
+	// QueueSize is the maximum number of events waiting to be handled
+	QueueSize int
+	// Policy determines what happens when the queue is full
+	Policy QueuePolicy
+}
+
+// Subscription is a handle to a handler registered with the bus
+type Subscription interface {
+	// Unsubscribe stops delivery of new events to the handler. Events already
+	// queued for an async handler are still handled. Calling Unsubscribe more
+	// than once is a no-op
+	Unsubscribe()
+	// Flush blocks until every event published to the subscription before the
+	// call has been handled or dropped. Flush returns immediately for
+	// synchronous subscriptions. Calling Flush from the subscription's own
+	// handler deadlocks
+	Flush()
+}
+
+type nilSubscription struct{}
+
+func (nilSubscription) Unsubscribe() {}
+func (nilSubscription) Flush()       {}
+
+// HandlerMetrics reports delivery stats for a single subscription
+type HandlerMetrics struct {
+	// ID is a unique identifier for the subscription within the bus
+	ID uint64 `json:"id"`
+	// Name of the handler function
+	Name string `json:"name"`
+	// Topics the handler is subscribed to. empty when subscribed to all events
+	Topics []Type `json:"topics,omitempty"`
+	// All is true when the handler is subscribed to all events
+	All bool `json:"all,omitempty"`
+	// Async is true for subscriptions that handle events on a separate goroutine
+	Async bool `json:"async,omitempty"`
+	// Calls is the number of times the handler has been called
+	Calls int64 `json:"calls"`
+	// Errors is the number of calls that returned an error
+	Errors int64 `json:"errors"`
+	// Dropped is the number of events discarded because of a full queue
+	Dropped int64 `json:"dropped"`
+	// Queued is the number of events waiting to be handled
+	Queued int `json:"queued"`
+	// TotalDuration is the time spent in the handler across all calls
+	TotalDuration time.Duration `json:"totalDuration"`
+	// MaxDuration is the duration of the slowest call
+	MaxDuration time.Duration `json:"maxDuration"`
+}
+
+// queued is an event waiting in an async subscription's queue
+type queued struct {
+	ctx     context.Context
+	topic   Type
+	payload interface{}
+}
+
+type subscription struct {
+	bus     *bus
+	id      uint64
+	name    string
+	handler Handler
+	topics  []Type
+	all     bool
+
+	// async subscriptions only
+	async  bool
+	policy QueuePolicy
+	queue  chan queued
+
+	// qlk guards sending on & closing the queue. publishers hold a read lock
+	// while sending
+	qlk     sync.RWMutex
+	stopped bool
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+	once    sync.Once
+
+	mlk sync.Mutex
+	m   HandlerMetrics
+
+	// accepted counts events accepted for the queue, settled counts accepted
+	// events that have been handled or dropped. settledCond signals changes
+	// to settled, guarded by mlk
+	accepted    int64
+	settled     int64
+	settledCond *sync.Cond
+}
+
+func newSubscription(b *bus, handler Handler, all bool, topics []Type, opts *AsyncOptions) *subscription {
+	sub := &subscription{
+		bus:     b,
+		name:    handlerName(handler),
+		handler: handler,
+		topics:  topics,
+		all:     all,
+		stopCh:  make(chan struct{}),
+		doneCh:  make(chan struct{}),
+	}
+	sub.settledCond = sync.NewCond(&sub.mlk)
+
+	if opts != nil {
+		size := opts.QueueSize
+		if size <= 0 {
+			size = DefaultQueueSize
+		}
+		sub.async = true
+		sub.policy = opts.Policy
+		sub.queue = make(chan queued, size)
+		go sub.work()
+	}
+
+	return sub
+}
+
+// Unsubscribe implements the Subscription interface
+func (s *subscription) Unsubscribe() {
+	s.bus.remove(s)
+	s.stop()
+	go s.bus.release(s)
+}
+
+// stop closes the subscription. async subscriptions finish handling queued
+// events before closing doneCh
+func (s *subscription) stop() {
+	s.once.Do(func() {
+		// unblock any publishers waiting on a full queue
+		close(s.stopCh)
+		s.qlk.Lock()
+		s.stopped = true
+		if s.async {
+			close(s.queue)
+		} else {
+			close(s.doneCh)
+		}
+		s.qlk.Unlock()
+	})
+}
+
+// Flush implements the Subscription interface
+func (s *subscription) Flush() {
+	if !s.async {
+		return
+	}
+	s.mlk.Lock()
+	defer s.mlk.Unlock()
+	target := s.accepted
+	for s.settled < target {
+		s.settledCond.Wait()
+	}
+}
+
+// publish delivers an event to the subscription, calling synchronous handlers
+// directly
+func (s *subscription) publish(ctx context.Context, topic Type, payload interface{}) error {
+	if !s.async {
+		return s.call(ctx, topic, payload)
+	}
+
+	s.qlk.RLock()
+	defer s.qlk.RUnlock()
+	if s.stopped {
+		return nil
+	}
+
+	// async handlers outlive the publisher, keep context values but not
+	// cancellation
+	e := queued{ctx: detachedContext{ctx}, topic: topic, payload: payload}
+	s.accept()
+	switch s.policy {
+	case QueueDropNewest:
+		select {
+		case s.queue <- e:
+		default:
+			s.dropped()
+		}
+	case QueueDropOldest:
+		for {
+			select {
+			case s.queue <- e:
+				return nil
+			default:
+			}
+			select {
+			case <-s.queue:
+				s.dropped()
+			default:
+			}
+		}
+	default:
+		// a publisher whose context is already done still delivers to queues
+		// with room
+		select {
+		case s.queue <- e:
+			return nil
+		default:
+		}
+		select {
+		case s.queue <- e:
+		case <-ctx.Done():
+			// one full queue shouldn't keep the event from other subscribers
+			log.Debugf("async handler %s dropped %q: %s", s.name, topic, ctx.Err())
+			s.dropped()
+		case <-s.stopCh:
+			s.dropped()
+		}
+	}
+	return nil
+}
+
+// work handles queued events until the queue is closed & empty
+func (s *subscription) work() {
+	defer close(s.doneCh)
+	for e := range s.queue {
+		if err := s.call(e.ctx, e.topic, e.payload); err != nil {
+			log.Debugf("async handler %s for %q: %s", s.name, e.topic, err)
+		}
+		s.settle()
+	}
+}
+
+func (s *subscription) call(ctx context.Context, topic Type, payload interface{}) error {
+	start := time.Now()
+	err := s.handler(ctx, topic, payload)
+	took := time.Since(start)
+
+	s.mlk.Lock()
+	s.m.Calls++
+	if err != nil {
+		s.m.Errors++
+	}
+	s.m.TotalDuration += took
+	if took > s.m.MaxDuration {
+		s.m.MaxDuration = took
+	}
+	s.mlk.Unlock()
+	return err
+}
+
+// dropped records an accepted event that won't be handled
+func (s *subscription) dropped() {
+	s.mlk.Lock()
+	s.m.Dropped++
+	s.mlk.Unlock()
+	s.settle()
+}
+
+func (s *subscription) accept() {
+	s.mlk.Lock()
+	s.accepted++
+	s.mlk.Unlock()
+}
+
+func (s *subscription) settle() {
+	s.mlk.Lock()
+	s.settled++
+	s.settledCond.Broadcast()
+	s.mlk.Unlock()
+}
+
+func (s *subscription) metrics() HandlerMetrics {
+	s.mlk.Lock()
+	m := s.m
+	s.mlk.Unlock()
+
+	m.ID = s.id
+	m.Name = s.name
+	m.Topics = s.topics
+	m.All = s.all
+	m.Async = s.async
+	if s.async {
+		m.Queued = len(s.queue)
+	}
+	return m
+}
+
+// handlerName returns the name of the function a handler refers to
+func handlerName(h Handler) string {
+	if fn := runtime.FuncForPC(reflect.ValueOf(h).Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return "unknown"
+}
+
+// detachedContext carries the values of a parent context without its
+// deadline or cancellation
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}               { return nil }
+func (detachedContext) Err() error                          { return nil }
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
@@ -0,0 +1,243 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+const ETTestEvent = Type("test:Event")
+
+func TestUnsubscribe(t *testing.T) {
+	ctx, done := context.WithCancel(context.Background())
+	defer done()
+
+	bus := NewBus(ctx)
+	calls := 0
+	var sub Subscription
+	sub = bus.Subscribe(func(ctx context.Context, t Type, payload interface{}) error {
+		calls++
+		// unsubscribing from within a handler must not deadlock
+		sub.Unsubscribe()
+		return nil
+	}, ETTestEvent)
+
+	bus.Publish(ctx, ETTestEvent, nil)
+	bus.Publish(ctx, ETTestEvent, nil)
+	if calls != 1 {
+		t.Errorf("expected handler to be called once, got %d", calls)
+	}
+	if bus.NumSubscribers() != 0 {
+		t.Errorf("expected no subscribers, got %d", bus.NumSubscribers())
+	}
+	// unsubscribing twice is a no-op
+	sub.Unsubscribe()
+}
+
+func TestSubscribeAsyncDropPolicies(t *testing.T) {
+	cases := []struct {
+		policy  QueuePolicy
+		handled []int
+	}{
+		{QueueDropNewest, []int{0, 1, 2}},
+		{QueueDropOldest, []int{0, 3, 4}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.policy.String(), func(t *testing.T) {
+			ctx, done := context.WithCancel(context.Background())
+
+			bus := NewBus(ctx)
+			release := make(chan struct{})
+			started := make(chan struct{})
+			var lk sync.Mutex
+			var handled []int
+			bus.SubscribeAsync(func(ctx context.Context, t Type, payload interface{}) error {
+				if payload.(int) == 0 {
+					close(started)
+					<-release
+				}
+				lk.Lock()
+				handled = append(handled, payload.(int))
+				lk.Unlock()
+				return nil
+			}, AsyncOptions{QueueSize: 2, Policy: c.policy}, ETTestEvent)
+
+			// the first event occupies the handler, the rest fill the queue
+			bus.Publish(ctx, ETTestEvent, 0)
+			<-started
+			for i := 1; i < 5; i++ {
+				if err := bus.Publish(ctx, ETTestEvent, i); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			m := bus.Metrics()
+			if len(m) != 1 || !m[0].Async || m[0].Dropped != 2 || m[0].Queued != 2 {
+				t.Errorf("unexpected metrics: %#v", m)
+			}
+
+			close(release)
+			done()
+			<-bus.Done()
+
+			if fmt.Sprint(handled) != fmt.Sprint(c.handled) {
+				t.Errorf("handled events mismatch. want: %v, got: %v", c.handled, handled)
+			}
+		})
+	}
+}
+
+func TestSubscribeAsyncBlocks(t *testing.T) {
+	ctx, done := context.WithCancel(context.Background())
+	defer done()
+
+	bus := NewBus(ctx)
+	release := make(chan struct{})
+	bus.SubscribeAsync(func(ctx context.Context, t Type, payload interface{}) error {
+		<-release
+		return nil
+	}, AsyncOptions{QueueSize: 1}, ETTestEvent)
+
+	bus.Publish(ctx, ETTestEvent, nil)
+	bus.Publish(ctx, ETTestEvent, nil)
+
+	// subscribed after the first queue fills, so this one is published to last
+	received := make(chan struct{}, 1)
+	bus.Subscribe(func(ctx context.Context, t Type, payload interface{}) error {
+		received <- struct{}{}
+		return nil
+	}, ETTestEvent)
+
+	// the queue is full, publishing blocks until the context expires, then
+	// drops the event for the full queue only
+	pubCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := bus.Publish(pubCtx, ETTestEvent, nil); err != nil {
+		t.Errorf("expected a dropped event not to error, got: %v", err)
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Error("expected publishing to a full queue to block until the context expires")
+	}
+	select {
+	case <-received:
+	default:
+		t.Error("expected the event to reach subscribers after the full queue")
+	}
+	for _, m := range bus.Metrics() {
+		if m.Async && m.Dropped != 1 {
+			t.Errorf("expected 1 dropped event, got: %d", m.Dropped)
+		}
+	}
+	close(release)
+}
+
+func TestSubscriptionFlush(t *testing.T) {
+	ctx, done := context.WithCancel(context.Background())
+	defer done()
+
+	bus := NewBus(ctx)
+	var lk sync.Mutex
+	handled := 0
+	sub := bus.SubscribeAsync(func(ctx context.Context, t Type, payload interface{}) error {
+		time.Sleep(time.Millisecond)
+		lk.Lock()
+		handled++
+		lk.Unlock()
+		return nil
+	}, AsyncOptions{QueueSize: 10}, ETTestEvent)
+
+	for i := 0; i < 5; i++ {
+		bus.Publish(ctx, ETTestEvent, i)
+	}
+	sub.Flush()
+
+	lk.Lock()
+	defer lk.Unlock()
+	if handled != 5 {
+		t.Errorf("flushed events mismatch. expected %d, got: %d", 5, handled)
+	}
+
+	// dropped events don't block flushing
+	release := make(chan struct{})
+	sub = bus.SubscribeAsync(func(ctx context.Context, t Type, payload interface{}) error {
+		<-release
+		return nil
+	}, AsyncOptions{QueueSize: 1, Policy: QueueDropNewest}, ETTestEvent)
+	for i := 0; i < 3; i++ {
+		bus.Publish(ctx, ETTestEvent, i)
+	}
+	close(release)
+	sub.Flush()
+}
+
+func TestBusDrainsOnClose(t *testing.T) {
+	ctx, done := context.WithCancel(context.Background())
+
+	bus := NewBus(ctx)
+	var lk sync.Mutex
+	handled := 0
+	bus.SubscribeAsync(func(ctx context.Context, t Type, payload interface{}) error {
+		time.Sleep(time.Millisecond)
+		if ctx.Err() != nil {
+			return fmt.Errorf("async handler context shouldn't be cancelled")
+		}
+		lk.Lock()
+		handled++
+		lk.Unlock()
+		return nil
+	}, AsyncOptions{QueueSize: 10}, ETTestEvent)
+
+	pubCtx, cancelPub := context.WithCancel(ctx)
+	for i := 0; i < 10; i++ {
+		bus.Publish(pubCtx, ETTestEvent, i)
+	}
+	cancelPub()
+	done()
+
+	select {
+	case <-bus.Done():
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for bus to drain")
+	}
+	if handled != 10 {
+		t.Errorf("expected all 10 queued events to be handled, got %d", handled)
+	}
+	if err := bus.Publish(context.Background(), ETTestEvent, nil); err != ErrBusClosed {
+		t.Errorf("expected publishing to a closed bus to error, got: %v", err)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	ctx, done := context.WithCancel(context.Background())
+	defer done()
+
+	bus := NewBus(ctx)
+	bus.Subscribe(func(ctx context.Context, t Type, payload interface{}) error {
+		time.Sleep(time.Millisecond)
+		if payload != nil {
+			return fmt.Errorf("oh no")
+		}
+		return nil
+	}, ETTestEvent)
+
+	bus.Publish(ctx, ETTestEvent, nil)
+	bus.Publish(ctx, ETTestEvent, "fail")
+
+	m := bus.Metrics()
+	if len(m) != 1 {
+		t.Fatalf("expected metrics for 1 subscription, got %d", len(m))
+	}
+	if m[0].Calls != 2 || m[0].Errors != 1 {
+		t.Errorf("expected 2 calls & 1 error, got: %#v", m[0])
+	}
+	if m[0].MaxDuration < time.Millisecond || m[0].TotalDuration < m[0].MaxDuration {
+		t.Errorf("unexpected durations: %#v", m[0])
+	}
+	if m[0].Name == "" || m[0].Async {
+		t.Errorf("unexpected metrics: %#v", m[0])
+	}
+}
@@ -0,0 +1,231 @@
+// Package follow keeps track of the peers & datasets a user follows, and a
+// feed of new versions announced by them
+package follow
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/qri-io/qri/dsref"
+)
+
+// Filename is the name of the follow file within a repo directory
+const Filename = "follows.json"
+
+// MaxFeedItems is the number of feed items kept. Older items are dropped
+const MaxFeedItems = 500
+
+// ErrNotFound indicates a follow doesn't exist
+var ErrNotFound = errors.New("not following")
+
+// Follow is a subscription to new versions of all published datasets of a
+// user, or a single dataset when Name is set
+type Follow struct {
+	Username  string `json:"username"`
+	ProfileID string `json:"profileID"`
+	// Name & InitID are empty when following a user
+	Name   string `json:"name,omitempty"`
+	InitID string `json:"initID,omitempty"`
+	// AutoPull fetches new versions as they're announced
+	AutoPull bool      `json:"autoPull,omitempty"`
+	Created  time.Time `json:"created"`
+}
+
+// Alias is the human-friendly name of what's followed, either "username" or
+// "username/name"
+func (f Follow) Alias() string {
+	if f.Name == "" {
+		return f.Username
+	}
+	return fmt.Sprintf("%s/%s", f.Username, f.Name)
+}
+
+// key identifies a follow, usernames & names can change
+func (f Follow) key() string {
+	return f.ProfileID + "/" + f.InitID
+}
+
+// Matches reports whether an announced version is covered by the follow.
+// Versions must always be announced by the followed profile, dataset follows
+// also match on InitID
+func (f Follow) Matches(vi dsref.VersionInfo) bool {
+	if f.ProfileID != vi.ProfileID {
+		return false
+	}
+	return f.InitID == "" || f.InitID == vi.InitID
+}
+
+// PullRef is the reference to pull an announced version by. Names are taken
+// from the follow rather than the announcement, only the dataset name of a
+// user follow comes from the announcement
+func (f Follow) PullRef(vi dsref.VersionInfo) dsref.Ref {
+	name := f.Name
+	if name == "" {
+		name = vi.Name
+	}
+	return dsref.Ref{Username: f.Username, ProfileID: f.ProfileID, Name: name, Path: vi.Path}
+}
+
+// Item is an entry in the feed of followed activity
+type Item struct {
+	Received time.Time         `json:"received"`
+	Version  dsref.VersionInfo `json:"version"`
+	// Pulled is true when the version was fetched automatically
+	Pulled    bool   `json:"pulled,omitempty"`
+	PullError string `json:"pullError,omitempty"`
+}
+
+// state is the contents of the follow file
+type state struct {
+	Follows []Follow `json:"follows"`
+	Feed    []Item   `json:"feed"`
+}
+
+// Store persists follows & the feed to a JSON file. A store with an empty
+// path keeps everything in memory
+type Store struct {
+	path string
+
+	lk    sync.Mutex
+	state state
+}
+
+// NewStore creates a store backed by the file at path, reading any follows
+// written to it previously
+func NewStore(path string) (*Store, error) {
+	st := &Store{path: path}
+	if path == "" {
+		return st, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return st, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &st.state); err != nil {
+		return nil, fmt.Errorf("reading follows: %w", err)
+	}
+	return st, nil
+}
+
+// Put adds a follow, replacing any follow of the same user or dataset
+func (st *Store) Put(f Follow) error {
+	st.lk.Lock()
+	defer st.lk.Unlock()
+
+	for i, existing := range st.state.Follows {
+		if existing.key() == f.key() {
+			st.state.Follows[i] = f
+			return st.write()
+		}
+	}
+	st.state.Follows = append(st.state.Follows, f)
+	return st.write()
+}
+
+// Remove drops a follow by alias
+func (st *Store) Remove(alias string) (Follow, error) {
+	st.lk.Lock()
+	defer st.lk.Unlock()
+
+	for i, f := range st.state.Follows {
+		if f.Alias() == alias {
+			st.state.Follows = append(st.state.Follows[:i], st.state.Follows[i+1:]...)
+			return f, st.write()
+		}
+	}
+	return Follow{}, fmt.Errorf("%w: %s", ErrNotFound, alias)
+}
+
+// List returns all follows, sorted by alias
+func (st *Store) List() []Follow {
+	st.lk.Lock()
+	defer st.lk.Unlock()
+
+	fs := make([]Follow, len(st.state.Follows))
+	copy(fs, st.state.Follows)
+	sort.Slice(fs, func(i, j int) bool { return fs[i].Alias() < fs[j].Alias() })
+	return fs
+}
+
+// Matching returns the follows an announced version is covered by
+func (st *Store) Matching(vi dsref.VersionInfo) []Follow {
+	var fs []Follow
+	for _, f := range st.List() {
+		if f.Matches(vi) {
+			fs = append(fs, f)
+		}
+	}
+	return fs
+}
+
+// Seen reports whether a version is already in the feed. Versions are
+// announced on both user & dataset topics
+func (st *Store) Seen(path string) bool {
+	st.lk.Lock()
+	defer st.lk.Unlock()
+
+	for _, it := range st.state.Feed {
+		if it.Version.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// AddItem appends an item to the feed, dropping the oldest items past
+// MaxFeedItems. Items for versions already in the feed are ignored
+func (st *Store) AddItem(it Item) error {
+	st.lk.Lock()
+	defer st.lk.Unlock()
+
+	for _, existing := range st.state.Feed {
+		if existing.Version.Path == it.Version.Path {
+			return nil
+		}
+	}
+	st.state.Feed = append(st.state.Feed, it)
+	if len(st.state.Feed) > MaxFeedItems {
+		st.state.Feed = st.state.Feed[len(st.state.Feed)-MaxFeedItems:]
+	}
+	return st.write()
+}
+
+// Items lists feed items, newest first. a limit of -1 returns all items
+func (st *Store) Items(offset, limit int) []Item {
+	st.lk.Lock()
+	defer st.lk.Unlock()
+
+	items := []Item{}
+	for i := len(st.state.Feed) - 1 - offset; i >= 0; i-- {
+		if limit >= 0 && len(items) == limit {
+			break
+		}
+		items = append(items, st.state.Feed[i])
+	}
+	return items
+}
+
+// write persists the store. must be called with the lock held
+func (st *Store) write() error {
+	if st.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(st.state)
+	if err != nil {
+		return err
+	}
+	tmp := st.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, st.path)
+}
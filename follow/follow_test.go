@@ -0,0 +1,104 @@
+package follow
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qri-io/qri/dsref"
+)
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "follow_store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, Filename)
+	st, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := Follow{Username: "peer", ProfileID: "QmPeer"}
+	ds := Follow{Username: "peer", ProfileID: "QmPeer", Name: "cities", InitID: "cities_id", AutoPull: true}
+	for _, f := range []Follow{ds, user} {
+		if err := st.Put(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// following again updates the existing follow
+	ds.AutoPull = false
+	if err := st.Put(ds); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := st.List()
+	if len(fs) != 2 || fs[0].Alias() != "peer" || fs[1].Alias() != "peer/cities" {
+		t.Fatalf("unexpected follows: %v", fs)
+	}
+	if fs[1].AutoPull {
+		t.Error("expected following again to replace the follow")
+	}
+
+	other := dsref.VersionInfo{ProfileID: "QmPeer", InitID: "other_id", Path: "/mem/QmOther"}
+	if m := st.Matching(other); len(m) != 1 || m[0].Alias() != "peer" {
+		t.Errorf("expected user follow to match all datasets of the user, got: %v", m)
+	}
+	cities := dsref.VersionInfo{ProfileID: "QmPeer", InitID: "cities_id", Path: "/mem/QmCities"}
+	if m := st.Matching(cities); len(m) != 2 {
+		t.Errorf("expected both follows to match, got: %v", m)
+	}
+	impostor := dsref.VersionInfo{ProfileID: "QmImpostor", InitID: "cities_id", Path: "/mem/QmImpostor"}
+	if m := st.Matching(impostor); len(m) != 0 {
+		t.Errorf("expected a version announced by another profile not to match, got: %v", m)
+	}
+	renamed := dsref.VersionInfo{ProfileID: "QmPeer", Username: "evil", Name: "evil", InitID: "cities_id", Path: "/mem/QmCities"}
+	if ref := ds.PullRef(renamed); ref.String() != "peer/cities@QmPeer/mem/QmCities" {
+		t.Errorf("expected pull ref to use the follow's names, got: %s", ref)
+	}
+
+	for _, vi := range []dsref.VersionInfo{other, cities, cities} {
+		if err := st.AddItem(Item{Version: vi}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !st.Seen(cities.Path) {
+		t.Error("expected version to be seen")
+	}
+
+	// reopening reads persisted state
+	if st, err = NewStore(path); err != nil {
+		t.Fatal(err)
+	}
+	items := st.Items(0, -1)
+	if len(items) != 2 {
+		t.Fatalf("expected repeated announcements to be dropped, got %d items", len(items))
+	}
+	if items[0].Version.Path != cities.Path {
+		t.Errorf("expected newest item first, got %q", items[0].Version.Path)
+	}
+	if page := st.Items(1, 1); len(page) != 1 || page[0].Version.Path != other.Path {
+		t.Errorf("unexpected page: %v", page)
+	}
+
+	if _, err := st.Remove("peer/cities"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.Remove("peer/cities"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected removing a missing follow to return ErrNotFound, got: %v", err)
+	}
+	if len(st.List()) != 1 {
+		t.Errorf("expected one follow to remain, got %d", len(st.List()))
+	}
+
+	for i := 0; i < MaxFeedItems+5; i++ {
+		st.AddItem(Item{Version: dsref.VersionInfo{Path: fmt.Sprintf("/mem/Qm%d", i)}})
+	}
+	if n := len(st.Items(0, -1)); n != MaxFeedItems {
+		t.Errorf("expected feed to be capped at %d items, got %d", MaxFeedItems, n)
+	}
+}
@@ -13,39 +13,62 @@ import (
 
 // GetBody is an FSI version of base.ReadBody
 func GetBody(dirPath string, format dataset.DataFormat, fcfg dataset.FormatConfig, offset, limit int, all bool) ([]byte, error) {
+	file, structure, err := OpenBody(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
 
+	st := &dataset.Structure{}
+	assign := &dataset.Structure{
+		Format: format.String(),
+		Schema: structure.Schema,
+	}
+	if fcfg != nil {
+		assign.FormatConfig = fcfg.Map()
+	}
+	st.Assign(structure, assign)
+
+	return base.ConvertBodyFile(file, structure, st, limit, offset, all)
+}
+
+// OpenBody opens the body file of a linked working directory without reading
+// it, returning the body's structure. Working directories can have a body
+// without a structure or schema, in which case both are inferred from the
+// body. Callers must close the returned file
+func OpenBody(dirPath string) (qfs.File, *dataset.Structure, error) {
 	components, err := component.ListDirectoryComponents(dirPath)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	err = component.ExpandListedComponents(components, nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	bodyComponent := components.Base().GetSubcomponent("body")
+	if bodyComponent == nil {
+		return nil, nil, fmt.Errorf("no body file in %q", dirPath)
+	}
 	f, err := os.Open(bodyComponent.Base().SourceFile)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	defer f.Close()
 
 	var structure *dataset.Structure
-
-	var schema map[string]interface{}
 	stComponent := components.Base().GetSubcomponent("structure")
 	if stComponent != nil {
 		stComponent.LoadAndFill(nil)
 		comp, ok := stComponent.(*component.StructureComponent)
 		if !ok {
-			return nil, fmt.Errorf("could not get structure")
+			f.Close()
+			return nil, nil, fmt.Errorf("could not get structure")
 		}
 		structure = comp.Value
-		schema = structure.Schema
 	}
 
-	if schema == nil {
+	if structure == nil || structure.Schema == nil {
 		bodyFormat := bodyComponent.Base().Format
 		// If there was no structure, define one using the body's file extension.
 		if structure == nil {
@@ -56,28 +79,16 @@ func GetBody(dirPath string, format dataset.DataFormat, fcfg dataset.FormatConfi
 		// TODO(dlong): This should move into `dsio` package.
 		entries, err := component.OpenEntryReader(f, bodyFormat)
 		if err != nil {
-			return nil, err
+			f.Close()
+			return nil, nil, err
 		}
-		schema = entries.Structure().Schema
+		structure.Schema = entries.Structure().Schema
 		// Reset the reader
-		f.Seek(0, 0)
-	}
-
-	file := qfs.NewMemfileReader(filepath.Base(bodyComponent.Base().SourceFile), f)
-
-	st := &dataset.Structure{}
-	assign := &dataset.Structure{
-		Format: format.String(),
-		Schema: schema,
-	}
-	if fcfg != nil {
-		assign.FormatConfig = fcfg.Map()
-	}
-	st.Assign(structure, assign)
-	// If there is no schema on the dataset, but one was detected, assign it as well.
-	if structure.Schema == nil && assign.Schema != nil {
-		structure.Schema = assign.Schema
+		if _, err := f.Seek(0, 0); err != nil {
+			f.Close()
+			return nil, nil, err
+		}
 	}
 
-	return base.ConvertBodyFile(file, structure, st, limit, offset, all)
+	return qfs.NewMemfileReader(filepath.Base(bodyComponent.Base().SourceFile), f), structure, nil
 }
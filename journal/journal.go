@@ -0,0 +1,404 @@
+// Package journal records events published on the event bus to an
+// append-only file, keeping an auditable history of what happened on a node.
+// Each line of the journal file is a JSON-encoded Entry
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	golog "github.com/ipfs/go-log"
+	"github.com/qri-io/qri/event"
+	"github.com/theckman/go-flock"
+)
+
+var log = golog.Logger("journal")
+
+// Filename is the name of the journal file within a repo directory
+const Filename = "events.jsonl"
+
+// MaxFileSize is the size in bytes a journal file can grow to before it's
+// rotated. The rotated file is kept alongside the journal with a ".1" suffix
+// and replaces any earlier rotated file, so the journal holds between one and
+// two files' worth of entries. Sequence numbers continue across rotations
+var MaxFileSize int64 = 64 << 20
+
+// ExcludedTypes are events that aren't written to the journal. Progress
+// events can fire once-per-block and don't describe a change
+var ExcludedTypes = map[event.Type]bool{
+	event.ETRemoteClientPushVersionProgress: true,
+	event.ETRemoteClientPullVersionProgress: true,
+}
+
+// Entry is a single journaled event
+type Entry struct {
+	// Seq is the position of the entry in the journal, starting at 1
+	Seq int64 `json:"seq"`
+	// Type of event
+	Type event.Type `json:"type"`
+	// Timestamp the event was journaled at
+	Timestamp time.Time `json:"timestamp"`
+	// ProfileID of the profile that caused the event
+	ProfileID string `json:"profileID,omitempty"`
+	// Payload is the JSON encoding of the event payload
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Query selects entries from a journal
+type Query struct {
+	// Since excludes entries journaled before this time. zero includes all
+	Since time.Time
+	// Types limits results to the listed event types. empty includes all
+	Types []event.Type
+	// Offset is the number of matching entries to skip
+	Offset int
+	// Limit is the maximum number of entries to return. -1 returns all
+	Limit int
+}
+
+// Journal writes events to an append-only file. Several processes can share
+// a journal file, sequence numbers are allocated under a lock on the file
+type Journal struct {
+	path      string
+	profileID string
+
+	lk    sync.Mutex
+	f     *os.File
+	flock *flock.Flock
+
+	doneCh chan struct{}
+}
+
+// NewJournal opens or creates the journal file at path and subscribes to all
+// events published on bus. profileID is recorded as the originating profile
+// of events whose payload doesn't say otherwise. The journal file is closed
+// when ctx is cancelled
+func NewJournal(ctx context.Context, bus event.Bus, path, profileID string) (*Journal, error) {
+	j := &Journal{
+		path:      path,
+		profileID: profileID,
+		flock:     flock.NewFlock(path + ".lock"),
+		doneCh:    make(chan struct{}),
+	}
+
+	var err error
+	if j.f, err = os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644); err != nil {
+		return nil, err
+	}
+
+	bus.SubscribeAll(j.handleEvent)
+
+	go func() {
+		<-ctx.Done()
+		j.lk.Lock()
+		if err := j.f.Close(); err != nil {
+			log.Debugf("closing journal: %s", err)
+		}
+		j.f = nil
+		if err := j.flock.Close(); err != nil {
+			log.Debugf("closing journal lock: %s", err)
+		}
+		j.lk.Unlock()
+		close(j.doneCh)
+	}()
+
+	return j, nil
+}
+
+// Done returns a channel that closes once the journal file is closed
+func (j *Journal) Done() <-chan struct{} {
+	return j.doneCh
+}
+
+func (j *Journal) handleEvent(_ context.Context, t event.Type, payload interface{}) error {
+	if ExcludedTypes[t] {
+		return nil
+	}
+
+	e := Entry{
+		Type:      t,
+		Timestamp: time.Now(),
+		ProfileID: j.profileID,
+	}
+	if ch, ok := payload.(event.DsChange); ok && ch.ProfileID != "" {
+		e.ProfileID = ch.ProfileID
+	}
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			log.Debugf("encoding %q payload: %s", t, err)
+		} else {
+			e.Payload = data
+		}
+	}
+
+	// journaling failures are logged instead of returned, an error here would
+	// stop delivery to later subscribers
+	if err := j.append(e); err != nil {
+		log.Errorf("journaling %q event: %s", t, err)
+	}
+	return nil
+}
+
+func (j *Journal) append(e Entry) error {
+	j.lk.Lock()
+	defer j.lk.Unlock()
+
+	if j.f == nil {
+		return fmt.Errorf("journal is closed")
+	}
+
+	// other processes can append to the same file, numbering continues from
+	// the last entry in the file while holding the file lock
+	if err := j.flock.Lock(); err != nil {
+		return err
+	}
+	defer j.flock.Unlock()
+
+	// another process may have rotated the file since it was opened
+	if err := j.reopenIfRotated(); err != nil {
+		return err
+	}
+	seq, err := lastSeq(j.f)
+	if err != nil {
+		return err
+	}
+	if seq == 0 {
+		// numbering continues from the rotated file
+		if seq, err = rotatedLastSeq(j.rotatedPath()); err != nil {
+			return err
+		}
+	}
+	e.Seq = seq + 1
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err = j.f.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return j.rotate()
+}
+
+func (j *Journal) rotatedPath() string {
+	return j.path + ".1"
+}
+
+// rotate moves the journal file aside once it's larger than MaxFileSize,
+// replacing any earlier rotated file. must be called with both locks held
+func (j *Journal) rotate() error {
+	fi, err := j.f.Stat()
+	if err != nil {
+		return err
+	}
+	if fi.Size() <= MaxFileSize {
+		return nil
+	}
+	if err := os.Rename(j.path, j.rotatedPath()); err != nil {
+		// some platforms can't rename open files, the journal keeps growing
+		log.Debugf("rotating journal: %s", err)
+		return nil
+	}
+	return j.reopen()
+}
+
+// reopenIfRotated reopens the journal file if the file at the journal's path
+// isn't the one held open. must be called with both locks held
+func (j *Journal) reopenIfRotated() error {
+	held, err := j.f.Stat()
+	if err != nil {
+		return err
+	}
+	current, err := os.Stat(j.path)
+	if err == nil && os.SameFile(held, current) {
+		return nil
+	} else if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return j.reopen()
+}
+
+func (j *Journal) reopen() error {
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	if err := j.f.Close(); err != nil {
+		log.Debugf("closing rotated journal: %s", err)
+	}
+	j.f = f
+	return nil
+}
+
+// rotatedLastSeq reads the sequence number of the last entry in a rotated
+// journal file, returning 0 if there isn't one
+func rotatedLastSeq(path string) (int64, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return lastSeq(f)
+}
+
+// lastSeq reads the sequence number of the last entry in a journal file by
+// reading backwards from the end of the file, returning 0 for an empty file.
+// A last line without a trailing newline was cut short mid-write, it's
+// truncated so the next entry is numbered from the last complete one
+func lastSeq(f *os.File) (int64, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := fi.Size()
+	if size == 0 {
+		return 0, nil
+	}
+
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, size-1); err != nil {
+		return 0, err
+	}
+	if last[0] != '\n' {
+		_, start, err := lastLine(f, size)
+		if err != nil {
+			return 0, err
+		}
+		log.Warnf("truncating incomplete journal entry at byte %d", start)
+		if err := f.Truncate(start); err != nil {
+			return 0, err
+		}
+		if start == 0 {
+			return 0, nil
+		}
+		size = start
+	}
+
+	// skip the trailing newline
+	line, _, err := lastLine(f, size-1)
+	if err != nil {
+		return 0, err
+	}
+	e := Entry{}
+	if err := json.Unmarshal(line, &e); err != nil {
+		return 0, fmt.Errorf("reading last journal entry: %w", err)
+	}
+	return e.Seq, nil
+}
+
+// lastLine reads back from end to the start of the line end is on, returning
+// the line & the offset it starts at
+func lastLine(f *os.File, end int64) ([]byte, int64, error) {
+	var line []byte
+	for end > 0 {
+		n := int64(4096)
+		if n > end {
+			n = end
+		}
+		chunk := make([]byte, n)
+		if _, err := f.ReadAt(chunk, end-n); err != nil {
+			return nil, 0, err
+		}
+		if i := bytes.LastIndexByte(chunk, '\n'); i >= 0 {
+			return append(chunk[i+1:], line...), end - n + int64(i) + 1, nil
+		}
+		line = append(chunk, line...)
+		end -= n
+	}
+	return line, 0, nil
+}
+
+// Query lists journal entries matching q, oldest first. Entries are read from
+// the rotated file first, then the journal file. Reading stops once the page
+// q describes is full
+func (j *Journal) Query(q Query) ([]Entry, error) {
+	j.lk.Lock()
+	defer j.lk.Unlock()
+
+	// hold a shared lock so entries other processes are writing aren't read
+	// half-written
+	if err := j.flock.RLock(); err != nil {
+		return nil, err
+	}
+	defer j.flock.Unlock()
+
+	types := map[event.Type]bool{}
+	for _, t := range q.Types {
+		types[t] = true
+	}
+
+	res := []Entry{}
+	skip := q.Offset
+	match := func(e Entry) bool {
+		if !q.Since.IsZero() && e.Timestamp.Before(q.Since) {
+			return true
+		}
+		if len(types) > 0 && !types[e.Type] {
+			return true
+		}
+		if skip > 0 {
+			skip--
+			return true
+		}
+		res = append(res, e)
+		return q.Limit < 0 || len(res) < q.Limit
+	}
+
+	if q.Limit == 0 {
+		return res, nil
+	}
+	for _, path := range []string{j.rotatedPath(), j.path} {
+		done, err := scanEntries(path, match)
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			break
+		}
+	}
+	return res, nil
+}
+
+// scanEntries calls fn on each entry in the journal file at path until fn
+// returns false, returning true if scanning was stopped by fn. A missing file
+// has no entries
+func scanEntries(path string, fn func(e Entry) bool) (stopped bool, err error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	// payloads can be larger than the default 64k token size
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	var torn error
+	line := 0
+	for sc.Scan() {
+		line++
+		if torn != nil {
+			return false, torn
+		}
+		e := Entry{}
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			// a broken last line is an entry cut short mid-write, it's dropped
+			// on the next append
+			torn = fmt.Errorf("reading journal entry %d: %w", line, err)
+			continue
+		}
+		if !fn(e) {
+			return true, nil
+		}
+	}
+	return false, sc.Err()
+}
@@ -0,0 +1,287 @@
+package journal
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qri/event"
+)
+
+func TestJournal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	dir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, Filename)
+	bus := event.NewBus(ctx)
+	j, err := NewJournal(ctx, bus, path, "node_profile")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bus.Publish(ctx, event.ETDatasetNameInit, event.DsChange{ProfileID: "author", PrettyName: "ds"})
+	bus.Publish(ctx, event.ETRemoteClientPushVersionProgress, event.RemoteEvent{})
+	bus.Publish(ctx, event.ETDatasetCommitChange, event.DsChange{PrettyName: "ds"})
+	bus.Publish(ctx, event.ETInstanceConstructed, nil)
+
+	all, err := j.Query(Query{Limit: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(all))
+	}
+	for i, e := range all {
+		if e.Seq != int64(i+1) {
+			t.Errorf("entry %d: expected seq %d, got %d", i, i+1, e.Seq)
+		}
+	}
+	if all[0].ProfileID != "author" {
+		t.Errorf("expected payload profileID to be recorded, got %q", all[0].ProfileID)
+	}
+	if all[1].ProfileID != "node_profile" {
+		t.Errorf("expected node profileID as the default, got %q", all[1].ProfileID)
+	}
+	ch := event.DsChange{}
+	if err := json.Unmarshal(all[1].Payload, &ch); err != nil {
+		t.Fatal(err)
+	}
+	if ch.PrettyName != "ds" {
+		t.Errorf("payload mismatch. got: %#v", ch)
+	}
+
+	commits, err := j.Query(Query{Types: []event.Type{event.ETDatasetCommitChange}, Limit: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 1 || commits[0].Seq != 2 {
+		t.Errorf("expected only the commit change entry. got: %#v", commits)
+	}
+
+	page, err := j.Query(Query{Offset: 1, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 1 || page[0].Seq != 2 {
+		t.Errorf("expected page to contain the second entry. got: %#v", page)
+	}
+
+	recent, err := j.Query(Query{Since: time.Now().Add(time.Hour), Limit: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recent) != 0 {
+		t.Errorf("expected no entries from the future, got %d", len(recent))
+	}
+
+	cancel()
+	<-j.Done()
+
+	// reopening the journal continues the sequence
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	bus = event.NewBus(ctx)
+	if j, err = NewJournal(ctx, bus, path, "node_profile"); err != nil {
+		t.Fatal(err)
+	}
+	bus.Publish(ctx, event.ETDatasetDeleteAll, event.DsChange{})
+	all, err = j.Query(Query{Limit: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 4 || all[3].Seq != 4 {
+		t.Errorf("expected reopened journal to append entry 4. got: %#v", all)
+	}
+}
+
+func TestJournalSharedFile(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir, err := ioutil.TempDir("", "journal_shared_file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// journals opened by separate processes share a file, each with its own
+	// bus
+	path := filepath.Join(dir, Filename)
+	busA, busB := event.NewBus(ctx), event.NewBus(ctx)
+	a, err := NewJournal(ctx, busA, path, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewJournal(ctx, busB, path, "b"); err != nil {
+		t.Fatal(err)
+	}
+
+	// entries larger than a read chunk are numbered from their end
+	big := event.DsChange{PrettyName: strings.Repeat("x", 10000)}
+	for i := 0; i < 3; i++ {
+		busA.Publish(ctx, event.ETDatasetCommitChange, big)
+		busB.Publish(ctx, event.ETDatasetCommitChange, event.DsChange{})
+	}
+
+	all, err := a.Query(Query{Limit: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 6 {
+		t.Fatalf("expected 6 entries, got %d", len(all))
+	}
+	for i, e := range all {
+		if e.Seq != int64(i+1) {
+			t.Errorf("entry %d: expected seq %d, got %d", i, i+1, e.Seq)
+		}
+	}
+}
+
+func TestJournalTornEntry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir, err := ioutil.TempDir("", "journal_torn_entry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, Filename)
+
+	// a crash mid-write leaves the last entry without a trailing newline
+	data := `{"seq":1,"type":"dataset:CommitChange","timestamp":"2020-01-01T00:00:00Z"}
+{"seq":2,"type":"dataset:Comm`
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bus := event.NewBus(ctx)
+	j, err := NewJournal(ctx, bus, path, "node_profile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, err := j.Query(Query{Limit: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 1 {
+		t.Errorf("expected the torn entry to be skipped. got: %#v", all)
+	}
+
+	bus.Publish(ctx, event.ETDatasetDeleteAll, event.DsChange{})
+	bus.Publish(ctx, event.ETDatasetDeleteAll, event.DsChange{})
+	if all, err = j.Query(Query{Limit: -1}); err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(all))
+	}
+	for i, e := range all {
+		if e.Seq != int64(i+1) {
+			t.Errorf("entry %d: expected seq %d, got %d", i, i+1, e.Seq)
+		}
+	}
+}
+
+func TestJournalRotation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir, err := ioutil.TempDir("", "journal_rotation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, Filename)
+
+	bus := event.NewBus(ctx)
+	j, err := NewJournal(ctx, bus, path, "node_profile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.Publish(ctx, event.ETDatasetDeleteAll, event.DsChange{})
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// rotate every second entry
+	prev := MaxFileSize
+	MaxFileSize = fi.Size() + fi.Size()/2
+	defer func() { MaxFileSize = prev }()
+
+	for i := 0; i < 4; i++ {
+		bus.Publish(ctx, event.ETDatasetDeleteAll, event.DsChange{})
+	}
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Fatalf("expected a rotated journal file: %s", err)
+	}
+
+	// entries 1 & 2 were rotated out when 3 & 4 were
+	all, err := j.Query(Query{Limit: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(all))
+	}
+	for i, e := range all {
+		if e.Seq != int64(i+3) {
+			t.Errorf("entry %d: expected seq %d, got %d", i, i+3, e.Seq)
+		}
+	}
+
+	page, err := j.Query(Query{Offset: 1, Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 2 || page[0].Seq != 4 || page[1].Seq != 5 {
+		t.Errorf("expected a page spanning both files, got: %#v", page)
+	}
+}
+
+func TestJournalQueryStopsAtLimit(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir, err := ioutil.TempDir("", "journal_query_limit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, Filename)
+
+	// the broken line is only an error if it's read
+	data := `{"seq":1,"type":"dataset:CommitChange","timestamp":"2020-01-01T00:00:00Z"}
+{"seq":2,"type":"dataset:CommitChange","timestamp":"2020-01-01T00:00:00Z"}
+{"seq":3,"type":"dataset:Comm
+{"seq":4,"type":"dataset:CommitChange","timestamp":"2020-01-01T00:00:00Z"}
+`
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	j, err := NewJournal(ctx, event.NewBus(ctx), path, "node_profile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := j.Query(Query{Offset: 1, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 1 || page[0].Seq != 2 {
+		t.Errorf("expected entry 2, got: %#v", page)
+	}
+	if _, err := j.Query(Query{Limit: -1}); err == nil {
+		t.Error("expected reading past a broken entry to fail")
+	}
+}
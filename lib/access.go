@@ -0,0 +1,52 @@
+package lib
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/qri-io/qri/access"
+)
+
+// AccessMethods encapsulates business logic for access control
+type AccessMethods struct {
+	inst *Instance
+}
+
+// NewAccessMethods creates AccessMethods from a qri Instance
+func NewAccessMethods(inst *Instance) *AccessMethods {
+	return &AccessMethods{inst: inst}
+}
+
+// CoreRequestsName implements the Methods interface
+func (m AccessMethods) CoreRequestsName() string { return "access" }
+
+// CreateAuthTokenParams are parameters for creating an access token
+type CreateAuthTokenParams struct {
+	// TTL is how long the token is valid for. zero uses access.DefaultTokenTTL
+	TTL time.Duration
+}
+
+// CreateAuthToken creates a signed access token for this node's profile. Tokens
+// authenticate clients connecting to this node, like websocket subscribers
+func (m *AccessMethods) CreateAuthToken(p *CreateAuthTokenParams, res *string) error {
+	return m.inst.dispatch(m.CreateAuthToken, p, res, func() error { return m.createAuthToken(p, res) })
+}
+
+func (m *AccessMethods) createAuthToken(p *CreateAuthTokenParams, res *string) error {
+	if m.inst.tokens == nil {
+		return fmt.Errorf("this node cannot create access tokens")
+	}
+
+	ttl := access.DefaultTokenTTL
+	if p != nil && p.TTL != 0 {
+		ttl = p.TTL
+	}
+
+	pro, err := m.inst.repo.Profile()
+	if err != nil {
+		return err
+	}
+
+	*res, err = m.inst.tokens.CreateToken(pro, ttl)
+	return err
+}
@@ -35,10 +35,10 @@ type GetConfigParams struct {
 // GetConfig returns the Config, or one of the specified fields of the Config,
 // as a slice of bytes the bytes can be formatted as json, concise json, or yaml
 func (m *ConfigMethods) GetConfig(p *GetConfigParams, res *[]byte) (err error) {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("ConfigMethods.GetConfig", p, res))
-	}
+	return m.inst.dispatch(m.GetConfig, p, res, func() error { return m.getConfig(p, res) })
+}
 
+func (m *ConfigMethods) getConfig(p *GetConfigParams, res *[]byte) (err error) {
 	var (
 		cfg    = m.inst.cfg
 		encode interface{}
@@ -79,10 +79,10 @@ func (m *ConfigMethods) GetConfig(p *GetConfigParams, res *[]byte) (err error) {
 // GetConfigKeys returns the Config key fields, or sub keys of the specified
 // fields of the Config, as a slice of bytes to be used for auto completion
 func (m *ConfigMethods) GetConfigKeys(p *GetConfigParams, res *[]byte) (err error) {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("ConfigMethods.GetConfigKeys", p, res))
-	}
+	return m.inst.dispatch(m.GetConfigKeys, p, res, func() error { return m.getConfigKeys(p, res) })
+}
 
+func (m *ConfigMethods) getConfigKeys(p *GetConfigParams, res *[]byte) (err error) {
 	var (
 		cfg    = m.inst.cfg
 		encode interface{}
@@ -153,10 +153,10 @@ func parseKeys(cfg interface{}, prefix, parentKey string) ([]byte, error) {
 
 // SetConfig validates, updates and saves the config
 func (m *ConfigMethods) SetConfig(update *config.Config, set *bool) (err error) {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("ConfigMethods.SetConfig", update, set))
-	}
+	return m.inst.dispatch(m.SetConfig, update, set, func() error { return m.setConfig(update, set) })
+}
 
+func (m *ConfigMethods) setConfig(update *config.Config, set *bool) (err error) {
 	if err = update.Validate(); err != nil {
 		return fmt.Errorf("validating config: %s", err)
 	}
@@ -17,6 +17,8 @@ import (
 	"github.com/qri-io/dag"
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/dataset/detect"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/dataset/validate"
 	"github.com/qri-io/jsonschema"
 	"github.com/qri-io/qfs"
 	"github.com/qri-io/qfs/cafs"
@@ -32,6 +34,7 @@ import (
 	"github.com/qri-io/qri/fsi/linkfile"
 	"github.com/qri-io/qri/repo"
 	reporef "github.com/qri-io/qri/repo/ref"
+	"github.com/qri-io/qri/startf"
 )
 
 // DatasetMethods encapsulates business logic for working with Datasets on Qri
@@ -51,10 +54,10 @@ func NewDatasetMethods(inst *Instance) *DatasetMethods {
 
 // List gets the reflist for either the local repo or a peer
 func (m *DatasetMethods) List(p *ListParams, res *[]dsref.VersionInfo) error {
-	if m.inst.rpc != nil {
-		p.RPC = true
-		return checkRPCError(m.inst.rpc.Call("DatasetMethods.List", p, res))
-	}
+	return m.inst.dispatch(m.List, p, res, func() error { return m.list(p, res) })
+}
+
+func (m *DatasetMethods) list(p *ListParams, res *[]dsref.VersionInfo) error {
 	ctx := context.TODO()
 
 	// ensure valid limit value
@@ -111,7 +114,16 @@ func (m *DatasetMethods) List(p *ListParams, res *[]dsref.VersionInfo) error {
 			}
 			refs = matched[:count]
 		}
-		// Filter references by skipping to the correct offset
+		// Filter references by skipping to the correct offset, or to the
+		// reference after p.After
+		if p.After != "" {
+			for i, ref := range refs {
+				if ref.AliasString() == p.After {
+					p.Offset = i + 1
+					break
+				}
+			}
+		}
 		if p.Offset > len(refs) {
 			refs = []reporef.DatasetRef{}
 		} else {
@@ -123,7 +135,7 @@ func (m *DatasetMethods) List(p *ListParams, res *[]dsref.VersionInfo) error {
 		}
 		// TODO(dlong): Filtered by p.Published flag
 	} else if ref.Peername == "" || pro.Peername == ref.Peername {
-		refs, err = base.ListDatasets(ctx, m.inst.repo, p.Term, p.Limit, p.Offset, p.RPC, p.Public, p.ShowNumVersions)
+		refs, err = base.ListDatasetsAfter(ctx, m.inst.repo, p.Term, p.After, p.Limit, p.Offset, p.RPC, p.Public, p.ShowNumVersions)
 	} else {
 		return fmt.Errorf("listing datasets on a peer is not implemented")
 	}
@@ -164,10 +176,11 @@ func (m *DatasetMethods) List(p *ListParams, res *[]dsref.VersionInfo) error {
 
 // ListRawRefs gets the list of raw references as string
 func (m *DatasetMethods) ListRawRefs(p *ListParams, text *string) error {
+	return m.inst.dispatch(m.ListRawRefs, p, text, func() error { return m.listRawRefs(p, text) })
+}
+
+func (m *DatasetMethods) listRawRefs(p *ListParams, text *string) error {
 	var err error
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("DatasetMethods.ListRawRefs", p, text))
-	}
 	ctx := context.TODO()
 	if p.UseDscache {
 		c := m.inst.dscache
@@ -223,10 +236,10 @@ func (m *DatasetMethods) Get(p *GetParams, res *GetResult) error {
 	if err := qfs.AbsPath(&p.Outfile); err != nil {
 		return err
 	}
+	return m.inst.dispatch(m.Get, p, res, func() error { return m.get(p, res) })
+}
 
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("DatasetMethods.Get", p, res))
-	}
+func (m *DatasetMethods) get(p *GetParams, res *GetResult) error {
 	ctx := context.TODO()
 
 	var ds *dataset.Dataset
@@ -382,6 +395,74 @@ func (m *DatasetMethods) maybeWriteOutfile(p *GetParams, res *GetResult) error {
 	return nil
 }
 
+// OpenBody resolves the dataset p refers to & opens it, without reading the
+// body. Use WriteBody to stream the body of the result, and CloseBody to close
+// it when done. Streaming only works on local calls, not over RPC
+func (m *DatasetMethods) OpenBody(ctx context.Context, p *GetParams) (*GetResult, error) {
+	if m.inst.rpc != nil {
+		return nil, fmt.Errorf("streaming a dataset body isn't supported over RPC")
+	}
+	if !p.All && (p.Limit < 0 || p.Offset < 0) {
+		return nil, fmt.Errorf("invalid limit / offset settings")
+	}
+
+	ref, source, err := m.inst.ParseAndResolveRefWithWorkingDir(ctx, p.Refstr, p.Remote)
+	if err != nil {
+		return nil, err
+	}
+	ds, err := m.inst.LoadDataset(ctx, ref, source)
+	if err != nil {
+		return nil, err
+	}
+	res := &GetResult{Ref: &ref, Dataset: ds}
+
+	if fsi.IsFSIPath(ref.Path) {
+		// linked working directories can have a body without a structure,
+		// fsi.OpenBody infers one
+		res.FSIPath = fsi.FilesystemPathToLocal(ref.Path)
+		file, st, err := fsi.OpenBody(res.FSIPath)
+		if err != nil {
+			log.Debugf("OpenBody, fsi.OpenBody failed, error: %s", err)
+			return nil, err
+		}
+		ds.Structure = st
+		ds.SetBodyFile(file)
+		return res, nil
+	}
+
+	if err = base.OpenDataset(ctx, m.inst.repo.Filesystem(), ds); err != nil {
+		log.Debugf("OpenBody, base.OpenDataset failed, error: %s", err)
+		return nil, err
+	}
+	if ds.Structure == nil {
+		base.CloseDataset(ds)
+		return nil, fmt.Errorf("dataset has no body")
+	}
+	return res, nil
+}
+
+// WriteBody streams the body of a dataset opened with OpenBody to w, using
+// the format, limit & offset of p. In addition to dataset formats, bodies can
+// be written as newline-delimited JSON with the "ndjson" format
+func (m *DatasetMethods) WriteBody(p *GetParams, res *GetResult, w io.Writer) error {
+	if res.Dataset == nil || res.Dataset.Structure == nil {
+		return fmt.Errorf("dataset has no body")
+	}
+	format := p.Format
+	if format == "" {
+		format = res.Dataset.Structure.Format
+	}
+	return base.WriteBody(w, res.Dataset, format, p.FormatConfig, p.Limit, p.Offset, p.All)
+}
+
+// CloseBody closes the files of a dataset opened with OpenBody
+func (m *DatasetMethods) CloseBody(res *GetResult) error {
+	if res == nil || res.Dataset == nil {
+		return nil
+	}
+	return base.CloseDataset(res.Dataset)
+}
+
 func scriptFileSelection(ds *dataset.Dataset, selector string) (qfs.File, bool) {
 	parts := strings.Split(selector, ".")
 	if len(parts) != 2 {
@@ -416,6 +497,13 @@ type SaveParams struct {
 	Message string
 	// path to body data
 	BodyPath string
+	// id of a completed upload to use as body data, see UploadMethods. The
+	// upload is removed once the save succeeds
+	UploadID string
+	// path to a SQLite database to read body data from, requires Table
+	FromSQLite string
+	// name of the SQLite table to use as the body when FromSQLite is set
+	Table string
 	// absolute path or URL to the list of dataset files or components to load
 	FilePaths []string
 	// secrets for transform execution
@@ -450,6 +538,11 @@ type SaveParams struct {
 	UseDscache bool
 }
 
+// prepareRPC drops the script output writer, which can't be sent over RPC
+func (p *SaveParams) prepareRPC() {
+	p.ScriptOutput = nil
+}
+
 // AbsolutizePaths converts any relative path references to their absolute
 // variations, safe to call on a nil instance
 func (p *SaveParams) AbsolutizePaths() error {
@@ -466,16 +559,18 @@ func (p *SaveParams) AbsolutizePaths() error {
 	if err := qfs.AbsPath(&p.BodyPath); err != nil {
 		return fmt.Errorf("body file: %w", err)
 	}
+	if err := qfs.AbsPath(&p.FromSQLite); err != nil {
+		return fmt.Errorf("sqlite file: %w", err)
+	}
 	return nil
 }
 
 // Save adds a history entry, updating a dataset
 func (m *DatasetMethods) Save(p *SaveParams, res *dataset.Dataset) error {
-	if m.inst.rpc != nil {
-		p.ScriptOutput = nil
-		return checkRPCError(m.inst.rpc.Call("DatasetMethods.Save", p, res))
-	}
+	return m.inst.dispatch(m.Save, p, res, func() error { return m.save(p, res) })
+}
 
+func (m *DatasetMethods) save(p *SaveParams, res *dataset.Dataset) error {
 	var (
 		ctx       = context.TODO()
 		writeDest = m.inst.qfs.DefaultWriteFS() // filesystem dataset will be written to
@@ -514,6 +609,43 @@ func (m *DatasetMethods) Save(p *SaveParams, res *dataset.Dataset) error {
 		ds = dsf
 	}
 
+	var uploadFilename string
+	if p.UploadID != "" {
+		if ds.BodyPath != "" || p.FromSQLite != "" {
+			return fmt.Errorf("cannot save from both an upload and a body file")
+		}
+		path, filename, err := m.inst.finalizeUpload(p.UploadID)
+		if err != nil {
+			return fmt.Errorf("upload %s: %w", p.UploadID, err)
+		}
+		ds.BodyPath = path
+		uploadFilename = filename
+	}
+
+	nameHint := ds.BodyPath
+	if uploadFilename != "" {
+		nameHint = uploadFilename
+	}
+	if p.FromSQLite != "" {
+		if p.Table == "" {
+			return fmt.Errorf("a table name is required to save from a sqlite database")
+		}
+		if ds.BodyPath != "" {
+			return fmt.Errorf("cannot save from both a body file and a sqlite database")
+		}
+		f, err := base.SQLiteTableBodyFile(ctx, p.FromSQLite, p.Table)
+		if err != nil {
+			return err
+		}
+		// the file holds a read transaction open until it's read or closed
+		defer f.Close()
+		ds.SetBodyFile(f)
+		if err = base.InferStructure(ds); err != nil {
+			return err
+		}
+		nameHint = p.Table
+	}
+
 	if p.Ref == "" && ds.Name != "" {
 		p.Ref = fmt.Sprintf("me/%s", ds.Name)
 	}
@@ -528,7 +660,7 @@ func (m *DatasetMethods) Save(p *SaveParams, res *dataset.Dataset) error {
 		return err
 	}
 
-	ref, isNew, err := base.PrepareSaveRef(ctx, pro, m.inst.logbook, resolver, p.Ref, ds.BodyPath, p.NewName)
+	ref, isNew, err := base.PrepareSaveRef(ctx, pro, m.inst.logbook, resolver, p.Ref, nameHint, p.NewName)
 	if err != nil {
 		return err
 	}
@@ -549,6 +681,7 @@ func (m *DatasetMethods) Save(p *SaveParams, res *dataset.Dataset) error {
 
 	ds.Name = ref.Name
 	ds.Peername = ref.Username
+	ds.ProfileID = ref.ProfileID
 
 	var fsiPath string
 	if !isNew {
@@ -576,6 +709,7 @@ func (m *DatasetMethods) Save(p *SaveParams, res *dataset.Dataset) error {
 
 	if !p.Force && p.Drop == "" &&
 		ds.BodyPath == "" &&
+		ds.BodyFile() == nil &&
 		ds.Body == nil &&
 		ds.BodyBytes == nil &&
 		ds.Structure == nil &&
@@ -612,8 +746,10 @@ func (m *DatasetMethods) Save(p *SaveParams, res *dataset.Dataset) error {
 		// string and control how transform functions
 		loader := NewParseResolveLoadFunc("", m.inst.defaultResolver(), m.inst)
 
-		// apply the transform
-		err := base.TransformApply(ctx, ds, r, loader, str, scriptOut, secrets)
+		// apply the transform, making the sql module available to the script
+		moduleLoader := transformModuleLoader(ctx, r, ds.Transform, loader)
+		opts := append(transformLimits(m.inst.cfg.Transform), startf.AddModuleLoader(moduleLoader))
+		err := base.TransformApply(ctx, ds, r, ref, loader, str, scriptOut, secrets, opts...)
 		if err != nil {
 			return err
 		}
@@ -633,6 +769,12 @@ func (m *DatasetMethods) Save(p *SaveParams, res *dataset.Dataset) error {
 	}
 
 	fileHint := p.BodyPath
+	if uploadFilename != "" {
+		fileHint = uploadFilename
+	}
+	if p.FromSQLite != "" {
+		fileHint = p.FromSQLite
+	}
 	if len(p.FilePaths) > 0 {
 		fileHint = p.FilePaths[0]
 	}
@@ -655,6 +797,12 @@ func (m *DatasetMethods) Save(p *SaveParams, res *dataset.Dataset) error {
 
 	success = true
 
+	if p.UploadID != "" {
+		if err := m.inst.uploads.Remove(p.UploadID); err != nil {
+			log.Errorf("removing saved upload %s: %s", p.UploadID, err)
+		}
+	}
+
 	// TODO (b5) - this should be integrated into base.SaveDataset
 	if fsiPath != "" && !p.DryRun {
 		vi := dsref.ConvertDatasetToVersionInfo(savedDs)
@@ -682,6 +830,178 @@ func (m *DatasetMethods) Save(p *SaveParams, res *dataset.Dataset) error {
 	return nil
 }
 
+// ImportParams encapsulates arguments to Import
+type ImportParams struct {
+	// directory of body files and SQLite databases to import
+	Dir string
+	// optional prefix for generated dataset names
+	Prefix string
+	// run without saving, returning the datasets that would be created
+	DryRun bool
+}
+
+// Import creates one new dataset for each supported body file and each table
+// of each SQLite database in the top level of a directory. Dataset names are
+// generated from the file or table name. Every source is checked before any
+// dataset is saved. If a save still fails, res lists the datasets saved
+// before the failure
+func (m *DatasetMethods) Import(p *ImportParams, res *[]dsref.VersionInfo) error {
+	return m.inst.dispatch(m.Import, p, res, func() error { return m.importDir(p, res) })
+}
+
+func (m *DatasetMethods) importDir(p *ImportParams, res *[]dsref.VersionInfo) error {
+	ctx := context.TODO()
+
+	if p.Dir == "" {
+		return fmt.Errorf("directory to import is required")
+	}
+	if err := qfs.AbsPath(&p.Dir); err != nil {
+		return err
+	}
+	infos, err := ioutil.ReadDir(p.Dir)
+	if err != nil {
+		return err
+	}
+
+	saves := []*SaveParams{}
+	for _, fi := range infos {
+		if fi.IsDir() || strings.HasPrefix(fi.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(p.Dir, fi.Name())
+
+		if base.IsSQLitePath(path) {
+			tables, err := base.SQLiteTables(ctx, path)
+			if err != nil {
+				return err
+			}
+			for _, table := range tables {
+				saves = append(saves, &SaveParams{
+					Ref:        importRef(p.Prefix, table),
+					FromSQLite: path,
+					Table:      table,
+				})
+			}
+			continue
+		}
+
+		if !isImportableBodyFile(path) {
+			log.Debugf("import: skipping unsupported file %q", path)
+			continue
+		}
+		name := strings.TrimSuffix(fi.Name(), filepath.Ext(fi.Name()))
+		saves = append(saves, &SaveParams{
+			Ref:      importRef(p.Prefix, name),
+			BodyPath: path,
+		})
+	}
+
+	// two sources can generate the same name, which a dry run of each save on
+	// its own wouldn't catch
+	names := map[string]string{}
+	for _, sp := range saves {
+		sp.NewName = true
+		if prev, ok := names[sp.Ref]; ok {
+			return fmt.Errorf("importing %s: dataset name %q is also generated for %s", importSource(sp), sp.Ref, prev)
+		}
+		names[sp.Ref] = importSource(sp)
+	}
+
+	// check every source can be read & inferred before saving anything, so a
+	// bad file fails the import without leaving it half done. dry runs check
+	// names are free, but don't read bodies
+	checked := make([]dsref.VersionInfo, 0, len(saves))
+	for _, sp := range saves {
+		if err := m.checkImportSource(ctx, sp); err != nil {
+			return fmt.Errorf("importing %s: %w", importSource(sp), err)
+		}
+		dry := *sp
+		dry.DryRun = true
+		ds := &dataset.Dataset{}
+		if err := m.Save(&dry, ds); err != nil {
+			return fmt.Errorf("importing %s: %w", importSource(sp), err)
+		}
+		checked = append(checked, dsref.ConvertDatasetToVersionInfo(ds))
+	}
+	if p.DryRun {
+		*res = checked
+		return nil
+	}
+
+	// saves can still fail, say if a file changes after it's checked. results
+	// list the datasets saved before the failure
+	results := make([]dsref.VersionInfo, 0, len(saves))
+	for _, sp := range saves {
+		ds := &dataset.Dataset{}
+		if err := m.Save(sp, ds); err != nil {
+			*res = results
+			return fmt.Errorf("importing %s: %w", importSource(sp), err)
+		}
+		results = append(results, dsref.ConvertDatasetToVersionInfo(ds))
+	}
+
+	*res = results
+	return nil
+}
+
+// checkImportSource infers a structure for an import source the way saving
+// does, then reads the source to the end, returning an error for any value
+// that can't be read
+func (m *DatasetMethods) checkImportSource(ctx context.Context, sp *SaveParams) error {
+	ds := &dataset.Dataset{BodyPath: sp.BodyPath}
+	if sp.FromSQLite != "" {
+		f, err := base.SQLiteTableBodyFile(ctx, sp.FromSQLite, sp.Table)
+		if err != nil {
+			return err
+		}
+		ds.SetBodyFile(f)
+	} else if err := base.OpenDataset(ctx, m.inst.repo.Filesystem(), ds); err != nil {
+		return err
+	}
+	defer base.CloseDataset(ds)
+
+	if err := base.InferStructure(ds); err != nil {
+		return err
+	}
+	er, err := dsio.NewEntryReader(ds.Structure, ds.BodyFile())
+	if err != nil {
+		return err
+	}
+	if _, err := validate.EntryReader(er); err != nil {
+		return fmt.Errorf("validating data: %w", err)
+	}
+	return nil
+}
+
+// importSource describes the file or table an import save reads from
+func importSource(sp *SaveParams) string {
+	if sp.FromSQLite != "" {
+		return fmt.Sprintf("%s table %q", sp.FromSQLite, sp.Table)
+	}
+	return sp.BodyPath
+}
+
+// importRef generates a dataset reference for an imported file or table name
+func importRef(prefix, name string) string {
+	if prefix != "" {
+		name = fmt.Sprintf("%s_%s", prefix, name)
+	}
+	return fmt.Sprintf("me/%s", dsref.GenerateName(name, "dataset_"))
+}
+
+func isImportableBodyFile(path string) bool {
+	df, err := detect.ExtensionDataFormat(strings.ToLower(path))
+	if err != nil {
+		return false
+	}
+	for _, supported := range dataset.SupportedDataFormats() {
+		if df == supported {
+			return true
+		}
+	}
+	return false
+}
+
 // RenameParams defines parameters for Dataset renaming
 type RenameParams struct {
 	Current, Next string
@@ -689,9 +1009,10 @@ type RenameParams struct {
 
 // Rename changes a user's given name for a dataset
 func (m *DatasetMethods) Rename(p *RenameParams, res *dsref.VersionInfo) error {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("DatasetMethods.Rename", p, res))
-	}
+	return m.inst.dispatch(m.Rename, p, res, func() error { return m.rename(p, res) })
+}
+
+func (m *DatasetMethods) rename(p *RenameParams, res *dsref.VersionInfo) error {
 	ctx := context.TODO()
 
 	if p.Current == "" {
@@ -756,9 +1077,10 @@ var ErrCantRemoveDirectoryDirty = fmt.Errorf("cannot remove files while working
 
 // Remove a dataset entirely or remove a certain number of revisions
 func (m *DatasetMethods) Remove(p *RemoveParams, res *RemoveResponse) error {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("DatasetMethods.Remove", p, res))
-	}
+	return m.inst.dispatch(m.Remove, p, res, func() error { return m.remove(p, res) })
+}
+
+func (m *DatasetMethods) remove(p *RemoveParams, res *RemoveResponse) error {
 	ctx := context.TODO()
 
 	log.Debugf("Remove dataset ref %q, revisions %v", p.Ref, p.Revision)
@@ -925,10 +1247,10 @@ func (m *DatasetMethods) Pull(p *PullParams, res *dataset.Dataset) error {
 	if err := qfs.AbsPath(&p.LinkDir); err != nil {
 		return err
 	}
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("DatasetMethods.Pull", p, res))
-	}
+	return m.inst.dispatch(m.Pull, p, res, func() error { return m.pull(p, res) })
+}
 
+func (m *DatasetMethods) pull(p *PullParams, res *dataset.Dataset) error {
 	ctx := context.TODO()
 
 	source := p.Remote
@@ -982,9 +1304,10 @@ type ValidateResponse struct {
 
 // Validate gives a dataset of errors and issues for a given dataset
 func (m *DatasetMethods) Validate(p *ValidateParams, res *ValidateResponse) error {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("DatasetMethods.Validate", p, res))
-	}
+	return m.inst.dispatch(m.Validate, p, res, func() error { return m.validate(p, res) })
+}
+
+func (m *DatasetMethods) validate(p *ValidateParams, res *ValidateResponse) error {
 	ctx := context.TODO()
 
 	// Schema can come from either schema.json or structure.json, or the dataset itself.
@@ -1119,9 +1442,10 @@ func (m *DatasetMethods) Validate(p *ValidateParams, res *ValidateResponse) erro
 
 // Manifest generates a manifest for a dataset path
 func (m *DatasetMethods) Manifest(refstr *string, mfst *dag.Manifest) error {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("DatasetMethods.Manifest", refstr, mfst))
-	}
+	return m.inst.dispatch(m.Manifest, refstr, mfst, func() error { return m.manifest(refstr, mfst) })
+}
+
+func (m *DatasetMethods) manifest(refstr *string, mfst *dag.Manifest) error {
 	ctx := context.TODO()
 
 	ref, err := repo.ParseDatasetRef(*refstr)
@@ -1143,9 +1467,10 @@ func (m *DatasetMethods) Manifest(refstr *string, mfst *dag.Manifest) error {
 
 // ManifestMissing generates a manifest of blocks that are not present on this repo for a given manifest
 func (m *DatasetMethods) ManifestMissing(a, b *dag.Manifest) error {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("DatasetMethods.Manifest", a, b))
-	}
+	return m.inst.dispatch(m.ManifestMissing, a, b, func() error { return m.manifestMissing(a, b) })
+}
+
+func (m *DatasetMethods) manifestMissing(a, b *dag.Manifest) error {
 	ctx := context.TODO()
 
 	var mf *dag.Manifest
@@ -1164,9 +1489,10 @@ type DAGInfoParams struct {
 
 // DAGInfo generates a dag.Info for a dataset path. If a label is given, DAGInfo will generate a sub-dag.Info at that label.
 func (m *DatasetMethods) DAGInfo(s *DAGInfoParams, i *dag.Info) error {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("DatasetMethods.DAGInfo", s, i))
-	}
+	return m.inst.dispatch(m.DAGInfo, s, i, func() error { return m.dAGInfo(s, i) })
+}
+
+func (m *DatasetMethods) dAGInfo(s *DAGInfoParams, i *dag.Info) error {
 	ctx := context.TODO()
 
 	ref, err := repo.ParseDatasetRef(s.RefStr)
@@ -1202,10 +1528,11 @@ type StatsResponse struct {
 
 // Stats generates stats for a dataset
 func (m *DatasetMethods) Stats(p *StatsParams, res *StatsResponse) error {
+	return m.inst.dispatch(m.Stats, p, res, func() error { return m.stats(p, res) })
+}
+
+func (m *DatasetMethods) stats(p *StatsParams, res *StatsResponse) error {
 	var err error
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("DatasetMethods.Stats", p, res))
-	}
 	ctx := context.TODO()
 
 	if p.Dataset == nil {
@@ -2,7 +2,9 @@ package lib
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -30,6 +32,7 @@ import (
 	p2ptest "github.com/qri-io/qri/p2p/test"
 	reporef "github.com/qri-io/qri/repo/ref"
 	testrepo "github.com/qri-io/qri/repo/test"
+	"github.com/qri-io/qri/startf"
 )
 
 func TestDatasetRequestsSave(t *testing.T) {
@@ -165,6 +168,43 @@ func TestDatasetRequestsForceSave(t *testing.T) {
 	}
 }
 
+func TestDatasetRequestsSaveTransformLimits(t *testing.T) {
+	ctx, done := context.WithCancel(context.Background())
+	defer done()
+
+	tmpDir, err := ioutil.TempDir("", "save_transform_limits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	scriptPath := filepath.Join(tmpDir, "transform.star")
+	script := `
+def transform(ds, ctx):
+	rows = [0] * 1000
+	for a in rows:
+		for b in rows:
+			pass
+	ds.set_body([len(rows)])
+`
+	if err := ioutil.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	node := newTestQriNode(t)
+	cfg := config.DefaultConfigForTesting()
+	cfg.Transform = &config.Transform{MaxSteps: 1000}
+	inst := NewInstanceFromConfigAndNode(ctx, cfg, node)
+	m := NewDatasetMethods(inst)
+
+	err = m.Save(&SaveParams{
+		Ref:     "me/spin",
+		Dataset: &dataset.Dataset{Transform: &dataset.Transform{ScriptPath: scriptPath}},
+	}, &dataset.Dataset{})
+	if !errors.Is(err, startf.ErrStepLimit) {
+		t.Errorf("expected configured step limit to stop the transform, got: %v", err)
+	}
+}
+
 func TestDatasetRequestsSaveRecallDrop(t *testing.T) {
 	t.Skip("TODO(dustmop): Recall will be going away soon, apply will take its place")
 	ctx, done := context.WithCancel(context.Background())
@@ -260,6 +300,85 @@ func TestDatasetRequestsSaveZip(t *testing.T) {
 		t.Fatalf("Expected 'Test Repo', got '%s'", res.Meta.Title)
 	}
 }
+func TestDatasetRequestsImport(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	dir := filepath.Join(tr.TmpDir, "import")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	tr.MustWriteFile(t, filepath.Join(dir, "Annual Pop.csv"), "city,pop\ntoronto,40000000\n")
+	tr.MustWriteFile(t, filepath.Join(dir, "notes.txt"), "not a dataset")
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "store.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE events (id INTEGER, title TEXT)`,
+		`INSERT INTO events VALUES (1, 'launch')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	m := NewDatasetMethods(tr.Instance)
+
+	res := []dsref.VersionInfo{}
+	if err := m.Import(&ImportParams{Dir: dir, Prefix: "bootstrap"}, &res); err != nil {
+		t.Fatal(err)
+	}
+
+	got := []string{}
+	for _, vi := range res {
+		got = append(got, vi.Name)
+	}
+	expect := []string{"bootstrap_annual_pop", "bootstrap_events"}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("imported names mismatch (-want +got):\n%s", diff)
+	}
+
+	ds := &dataset.Dataset{}
+	if err := m.Save(&SaveParams{Ref: "me/events", FromSQLite: filepath.Join(dir, "store.sqlite"), Table: "events"}, ds); err != nil {
+		t.Fatal(err)
+	}
+	if ds.Structure == nil || ds.Structure.Format != "csv" {
+		t.Errorf("expected saving from sqlite to infer a csv structure")
+	}
+
+	if err := m.Save(&SaveParams{Ref: "me/no_table", FromSQLite: filepath.Join(dir, "store.sqlite")}, ds); err == nil {
+		t.Error("expected saving from sqlite without a table to error")
+	}
+
+	if err := m.Import(&ImportParams{Dir: dir, Prefix: "bootstrap"}, &res); err == nil {
+		t.Error("expected importing into existing dataset names to error")
+	}
+
+	// a source that can't be read fails the import before anything is saved
+	partial := filepath.Join(tr.TmpDir, "import_partial")
+	if err := os.Mkdir(partial, 0755); err != nil {
+		t.Fatal(err)
+	}
+	tr.MustWriteFile(t, filepath.Join(partial, "a_cities.csv"), "city,pop\ntoronto,40000000\n")
+	tr.MustWriteFile(t, filepath.Join(partial, "b_broken.json"), "{not json")
+	res = []dsref.VersionInfo{}
+	if err := m.Import(&ImportParams{Dir: partial}, &res); err == nil {
+		t.Fatal("expected importing an unreadable file to error")
+	}
+	if len(res) != 0 {
+		t.Errorf("expected no datasets to be saved, got: %v", res)
+	}
+	if err := os.Remove(filepath.Join(partial, "b_broken.json")); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Import(&ImportParams{Dir: partial}, &res); err != nil {
+		t.Errorf("expected importing after the failed import to succeed, got: %s", err)
+	}
+}
+
 func TestDatasetRequestsList(t *testing.T) {
 	ctx, done := context.WithCancel(context.Background())
 	defer done()
@@ -552,6 +671,45 @@ func TestDatasetRequestsGet(t *testing.T) {
 	}
 }
 
+func TestDatasetRequestsWriteBody(t *testing.T) {
+	ctx, done := context.WithCancel(context.Background())
+	defer done()
+
+	mr, err := testrepo.NewTestRepo()
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+	node, err := p2p.NewQriNode(mr, config.DefaultP2PForTesting(), event.NilBus, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	inst := NewInstanceFromConfigAndNode(ctx, config.DefaultConfigForTesting(), node)
+	m := NewDatasetMethods(inst)
+
+	p := &GetParams{Refstr: "peer/movies", Format: "ndjson", Limit: 2, Offset: 1}
+	res, err := m.OpenBody(ctx, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.CloseBody(res)
+	if res.Dataset.Path == "" {
+		t.Error("expected opened dataset to have a path")
+	}
+
+	buf := &strings.Builder{}
+	if err := m.WriteBody(p, res, buf); err != nil {
+		t.Fatal(err)
+	}
+	expect := "[\"Pirates of the Caribbean: At World's End \",169]\n[\"Spectre \",148]\n"
+	if diff := cmp.Diff(expect, buf.String()); diff != "" {
+		t.Errorf("body mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := m.OpenBody(ctx, &GetParams{Refstr: "peer/movies", Limit: -1, Offset: -1}); err == nil {
+		t.Error("expected invalid limit & offset to error")
+	}
+}
+
 func TestDatasetRequestsGetFSIPath(t *testing.T) {
 	ctx, done := context.WithCancel(context.Background())
 	defer done()
@@ -597,6 +755,25 @@ func TestDatasetRequestsGetFSIPath(t *testing.T) {
 	if got.FSIPath != dsDir {
 		t.Errorf("incorrect FSIPath, expected %q, got %q", dsDir, got.FSIPath)
 	}
+
+	// bodies of linked working directories stream from the working directory
+	bodyParams := &GetParams{Refstr: "peer/movies", Format: "ndjson", Limit: 2, Offset: 1}
+	res, err := dsm.OpenBody(ctx, bodyParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dsm.CloseBody(res)
+	if res.FSIPath != dsDir {
+		t.Errorf("incorrect FSIPath, expected %q, got %q", dsDir, res.FSIPath)
+	}
+	buf := &strings.Builder{}
+	if err := dsm.WriteBody(bodyParams, res, buf); err != nil {
+		t.Fatal(err)
+	}
+	expect := "[\"Pirates of the Caribbean: At World's End \",169]\n[\"Spectre \",148]\n"
+	if diff := cmp.Diff(expect, buf.String()); diff != "" {
+		t.Errorf("fsi body mismatch (-want +got):\n%s", diff)
+	}
 }
 
 func setDatasetName(ds *dataset.Dataset, name string) *dataset.Dataset {
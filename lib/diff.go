@@ -116,10 +116,10 @@ func (m *DatasetMethods) Diff(p *DiffParams, res *DiffResponse) (err error) {
 			return err
 		}
 	}
+	return m.inst.dispatch(m.Diff, p, res, func() error { return m.diff(p, res) })
+}
 
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("DatasetMethods.Diff", p, res))
-	}
+func (m *DatasetMethods) diff(p *DiffParams, res *DiffResponse) (err error) {
 	ctx := context.TODO()
 
 	diffMode, err := p.diffMode()
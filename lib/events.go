@@ -0,0 +1,83 @@
+package lib
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/journal"
+)
+
+// EventMethods encapsulates business logic for reading the event journal
+type EventMethods struct {
+	inst *Instance
+}
+
+// NewEventMethods creates EventMethods from a qri Instance
+func NewEventMethods(inst *Instance) *EventMethods {
+	return &EventMethods{inst: inst}
+}
+
+// CoreRequestsName implements the Methods interface
+func (m EventMethods) CoreRequestsName() string { return "events" }
+
+// ErrNoEventJournal indicates the event journal isn't enabled on this node
+var ErrNoEventJournal = fmt.Errorf("the event journal is not enabled. enable it with:\n  $ qri config set repo.eventjournal true")
+
+// EventsParams defines parameters for listing journaled events
+type EventsParams struct {
+	ListParams
+	// Since excludes events journaled before this time
+	Since time.Time
+	// Types limits results to the given event types
+	Types []string
+}
+
+// List pages through journaled events, oldest first
+func (m *EventMethods) List(p *EventsParams, res *[]journal.Entry) error {
+	return m.inst.dispatch(m.List, p, res, func() error { return m.list(p, res) })
+}
+
+func (m *EventMethods) list(p *EventsParams, res *[]journal.Entry) error {
+	if m.inst.journal == nil {
+		return ErrNoEventJournal
+	}
+
+	q := journal.Query{
+		Since:  p.Since,
+		Offset: p.Offset,
+		Limit:  p.Limit,
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultPageSize
+	}
+	for _, t := range p.Types {
+		q.Types = append(q.Types, event.Type(t))
+	}
+
+	entries, err := m.inst.journal.Query(q)
+	if err != nil {
+		return err
+	}
+	*res = entries
+	return nil
+}
+
+// ParseEventsSince interprets s as either an RFC3339 timestamp or a duration
+// relative to now, like "1h" or "30m"
+func ParseEventsSince(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		if d < 0 {
+			d = -d
+		}
+		return time.Now().Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid since value %q: must be a duration like \"1h\" or an RFC3339 timestamp", s)
+	}
+	return t, nil
+}
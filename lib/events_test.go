@@ -0,0 +1,70 @@
+package lib
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/journal"
+	"github.com/qri-io/qri/logbook"
+)
+
+func TestEventMethodsList(t *testing.T) {
+	ctx, done := context.WithCancel(context.Background())
+	defer done()
+
+	tmpDir, err := ioutil.TempDir("", "event_journal_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	cfg := config.DefaultConfigForTesting()
+	cfg.Repo.Type = "mem"
+	cfg.Repo.EventJournal = true
+	cfg.Filesystems = []qfs.Config{{Type: "mem"}}
+
+	inst, err := NewInstance(ctx, tmpDir, OptConfig(cfg), OptLogbook(&logbook.Book{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ch := event.DsChange{Username: "peer", PrettyName: "ds"}
+	inst.bus.Publish(ctx, event.ETDatasetNameInit, ch)
+	inst.bus.Publish(ctx, event.ETDatasetCommitChange, ch)
+	inst.bus.Publish(ctx, event.ETDatasetCommitChange, ch)
+
+	m := NewEventMethods(inst)
+	res := []journal.Entry{}
+	p := &EventsParams{
+		Types:      []string{string(event.ETDatasetCommitChange)},
+		ListParams: ListParams{Limit: 10},
+	}
+	if err := m.List(p, &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 {
+		t.Fatalf("expected 2 commit change events, got %d", len(res))
+	}
+	if res[0].ProfileID != cfg.Profile.ID {
+		t.Errorf("expected events to be attributed to %q, got %q", cfg.Profile.ID, res[0].ProfileID)
+	}
+
+	p = &EventsParams{Since: time.Now().Add(time.Minute)}
+	if err := m.List(p, &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 0 {
+		t.Errorf("expected no events since a future time, got %d", len(res))
+	}
+
+	disabled := NewEventMethods(&Instance{})
+	if err := disabled.List(&EventsParams{}, &res); err != ErrNoEventJournal {
+		t.Errorf("expected ErrNoEventJournal, got: %v", err)
+	}
+}
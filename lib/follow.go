@@ -0,0 +1,297 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/follow"
+	"github.com/qri-io/qri/p2p"
+	"github.com/qri-io/qri/repo"
+)
+
+// FollowMethods encapsulates business logic for following peers & datasets
+type FollowMethods struct {
+	inst *Instance
+}
+
+// NewFollowMethods creates FollowMethods from a qri Instance
+func NewFollowMethods(inst *Instance) *FollowMethods {
+	return &FollowMethods{inst: inst}
+}
+
+// CoreRequestsName implements the Methods interface
+func (m FollowMethods) CoreRequestsName() string { return "follow" }
+
+// FollowParams are parameters for following a peer or dataset
+type FollowParams struct {
+	// Ref is either a username to follow all published datasets of a peer, or
+	// a dataset reference like "peer/dataset"
+	Ref string
+	// AutoPull fetches new versions as they're announced
+	AutoPull bool
+}
+
+// Follow subscribes to announcements of new versions from a peer or dataset.
+// Following again updates the existing follow
+func (m *FollowMethods) Follow(p *FollowParams, res *follow.Follow) error {
+	return m.inst.dispatch(m.Follow, p, res, func() error { return m.follow(p, res) })
+}
+
+func (m *FollowMethods) follow(p *FollowParams, res *follow.Follow) error {
+	if m.inst.follows == nil {
+		return fmt.Errorf("following is not available")
+	}
+	ctx := context.TODO()
+
+	f := follow.Follow{AutoPull: p.AutoPull, Created: time.Now().UTC()}
+	if p.Ref == "" {
+		return fmt.Errorf("follow requires a username or dataset reference")
+	} else if !strings.Contains(p.Ref, "/") {
+		id, err := m.inst.repo.Profiles().PeernameID(p.Ref)
+		if err != nil {
+			return fmt.Errorf("unknown peer %q, connect to them first", p.Ref)
+		}
+		f.Username = p.Ref
+		f.ProfileID = id.String()
+	} else {
+		ref, _, err := m.inst.ParseAndResolveRef(ctx, p.Ref, "")
+		if err != nil {
+			return err
+		}
+		f.Username = ref.Username
+		f.ProfileID = ref.ProfileID
+		f.Name = ref.Name
+		f.InitID = ref.InitID
+	}
+
+	if err := m.inst.follows.Put(f); err != nil {
+		return err
+	}
+	if m.inst.node != nil && m.inst.node.Online {
+		if err := m.inst.feeds.subscribe(f); err != nil {
+			return err
+		}
+	}
+	*res = f
+	return nil
+}
+
+// UnfollowParams are parameters for removing a follow
+type UnfollowParams struct {
+	// Ref is the username or "peer/dataset" reference that's followed
+	Ref string
+}
+
+// Unfollow removes a follow, ending its subscription
+func (m *FollowMethods) Unfollow(p *UnfollowParams, res *follow.Follow) error {
+	return m.inst.dispatch(m.Unfollow, p, res, func() error { return m.unfollow(p, res) })
+}
+
+func (m *FollowMethods) unfollow(p *UnfollowParams, res *follow.Follow) error {
+	if m.inst.follows == nil {
+		return fmt.Errorf("following is not available")
+	}
+
+	f, err := m.inst.follows.Remove(p.Ref)
+	if err != nil {
+		return err
+	}
+	m.inst.feeds.unsubscribe(f)
+	*res = f
+	return nil
+}
+
+// Following lists follows
+func (m *FollowMethods) Following(p *ListParams, res *[]follow.Follow) error {
+	return m.inst.dispatch(m.Following, p, res, func() error { return m.following(p, res) })
+}
+
+func (m *FollowMethods) following(p *ListParams, res *[]follow.Follow) error {
+	if m.inst.follows == nil {
+		return fmt.Errorf("following is not available")
+	}
+
+	fs := m.inst.follows.List()
+	if p.Offset > len(fs) {
+		p.Offset = len(fs)
+	}
+	fs = fs[p.Offset:]
+	if p.Limit >= 0 && p.Limit < len(fs) {
+		fs = fs[:p.Limit]
+	}
+	*res = fs
+	return nil
+}
+
+// Feed lists new versions announced by follows, newest first
+func (m *FollowMethods) Feed(p *ListParams, res *[]follow.Item) error {
+	return m.inst.dispatch(m.Feed, p, res, func() error { return m.feed(p, res) })
+}
+
+func (m *FollowMethods) feed(p *ListParams, res *[]follow.Item) error {
+	if m.inst.follows == nil {
+		return fmt.Errorf("following is not available")
+	}
+
+	*res = m.inst.follows.Items(p.Offset, p.Limit)
+	return nil
+}
+
+// feeds subscribes to the feed topics of follows while the node is online
+type feeds struct {
+	inst *Instance
+
+	lk   sync.Mutex
+	ctx  context.Context
+	subs map[string]context.CancelFunc
+}
+
+func newFeeds(inst *Instance) *feeds {
+	return &feeds{inst: inst, subs: map[string]context.CancelFunc{}}
+}
+
+func feedTopic(f follow.Follow) string {
+	if f.InitID != "" {
+		return p2p.DatasetFeedTopic(f.InitID)
+	}
+	return p2p.UserFeedTopic(f.ProfileID)
+}
+
+// start subscribes to the topics of all follows. subscriptions last until ctx
+// is cancelled
+func (fs *feeds) start(ctx context.Context) {
+	if fs == nil {
+		return
+	}
+	fs.lk.Lock()
+	fs.ctx = ctx
+	fs.lk.Unlock()
+	for _, f := range fs.inst.follows.List() {
+		if err := fs.subscribe(f); err != nil {
+			log.Errorf("following %s: %s", f.Alias(), err)
+		}
+	}
+}
+
+func (fs *feeds) subscribe(f follow.Follow) error {
+	if fs == nil {
+		return nil
+	}
+	fs.lk.Lock()
+	defer fs.lk.Unlock()
+
+	topic := feedTopic(f)
+	if _, ok := fs.subs[topic]; ok || fs.ctx == nil {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(fs.ctx)
+	anns, err := fs.inst.node.SubscribeFeed(ctx, topic)
+	if err != nil {
+		cancel()
+		return err
+	}
+	fs.subs[topic] = cancel
+	go func() {
+		for ann := range anns {
+			fs.receive(ann)
+		}
+	}()
+	return nil
+}
+
+func (fs *feeds) unsubscribe(f follow.Follow) {
+	if fs == nil {
+		return
+	}
+	fs.lk.Lock()
+	defer fs.lk.Unlock()
+
+	topic := feedTopic(f)
+	if cancel, ok := fs.subs[topic]; ok {
+		cancel()
+		delete(fs.subs, topic)
+	}
+}
+
+// receive adds an announced version to the feed, pulling it if any follow
+// covering the version asks for it
+func (fs *feeds) receive(ann p2p.Announcement) {
+	vi := ann.Version
+	matches := fs.inst.follows.Matching(vi)
+	if len(matches) == 0 || fs.inst.follows.Seen(vi.Path) {
+		return
+	}
+	log.Debugf("received announcement of %s/%s@%s from %s", vi.Username, vi.Name, vi.Path, ann.From)
+
+	it := follow.Item{Received: time.Now().UTC(), Version: vi}
+	for _, f := range matches {
+		if f.AutoPull {
+			ds := &dataset.Dataset{}
+			err := NewDatasetMethods(fs.inst).Pull(&PullParams{Ref: f.PullRef(vi).String()}, ds)
+			if err != nil {
+				it.PullError = err.Error()
+			} else {
+				it.Pulled = true
+			}
+			break
+		}
+	}
+	if err := fs.inst.follows.AddItem(it); err != nil {
+		log.Errorf("adding feed item: %s", err)
+	}
+}
+
+// announceVersion publishes new versions of this peer's published datasets
+// to feed topics
+func (inst *Instance) announceVersion(ctx context.Context, t event.Type, payload interface{}) error {
+	if inst.node == nil || !inst.node.Online {
+		return nil
+	}
+
+	var ref dsref.Ref
+	switch t {
+	case event.ETDatasetCommitChange:
+		chg, ok := payload.(event.DsChange)
+		if !ok {
+			return nil
+		}
+		ref = dsref.Ref{Username: chg.Username, ProfileID: chg.ProfileID, Name: chg.PrettyName, InitID: chg.InitID}
+	case event.ETRemoteClientPushDatasetCompleted:
+		re, ok := payload.(event.RemoteEvent)
+		if !ok {
+			return nil
+		}
+		ref = re.Ref
+	default:
+		return nil
+	}
+
+	pro, err := inst.repo.Profile()
+	if err != nil || ref.ProfileID != pro.ID.String() {
+		return nil
+	}
+	vi, err := repo.GetVersionInfoShim(inst.repo, ref)
+	if err != nil {
+		log.Debugf("announcing %s: %s", ref.Human(), err)
+		return nil
+	}
+	// pushes are announced before the dataset is marked as published
+	if !vi.Published && t != event.ETRemoteClientPushDatasetCompleted {
+		return nil
+	}
+	vi.Published = true
+	if vi.InitID == "" {
+		vi.InitID = ref.InitID
+	}
+	vi.FSIPath = ""
+	if err := inst.node.AnnounceVersion(ctx, *vi); err != nil {
+		log.Errorf("announcing %s: %s", ref.Human(), err)
+	}
+	return nil
+}
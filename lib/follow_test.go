@@ -0,0 +1,61 @@
+package lib
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/follow"
+	"github.com/qri-io/qri/p2p"
+)
+
+func TestFollowMethods(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	body := tr.MustWriteTmpFile(t, "cities.csv", "city,pop\ntoronto,40000000\n")
+	tr.MustSaveFromBody(t, "cities", body)
+
+	m := NewFollowMethods(tr.Instance)
+	f := follow.Follow{}
+	if err := m.Follow(&FollowParams{Ref: "peer/cities"}, &f); err != nil {
+		t.Fatal(err)
+	}
+	if f.InitID == "" || f.ProfileID == "" || f.Alias() != "peer/cities" {
+		t.Errorf("expected follow to be resolved, got: %#v", f)
+	}
+	if err := m.Follow(&FollowParams{Ref: "nobody"}, &f); err == nil {
+		t.Error("expected following an unknown peer to error")
+	}
+
+	fs := []follow.Follow{}
+	if err := m.Following(&ListParams{Limit: -1}, &fs); err != nil {
+		t.Fatal(err)
+	}
+	if len(fs) != 1 {
+		t.Fatalf("expected 1 follow, got %d", len(fs))
+	}
+
+	vi := dsref.VersionInfo{InitID: fs[0].InitID, ProfileID: fs[0].ProfileID, Username: "peer", Name: "cities", Path: "/mem/QmNewVersion"}
+	tr.Instance.feeds.receive(p2p.Announcement{Version: vi})
+	tr.Instance.feeds.receive(p2p.Announcement{Version: dsref.VersionInfo{InitID: "unfollowed", Path: "/mem/QmOther"}})
+	tr.Instance.feeds.receive(p2p.Announcement{Version: dsref.VersionInfo{InitID: fs[0].InitID, ProfileID: "QmImpostor", Path: "/mem/QmImpostor"}})
+
+	items := []follow.Item{}
+	if err := m.Feed(&ListParams{Limit: 10}, &items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Version.Path != vi.Path {
+		t.Errorf("expected feed to contain the followed announcement only, got: %v", items)
+	}
+	if items[0].Pulled {
+		t.Error("expected version not to be pulled without auto-pull")
+	}
+
+	if err := m.Unfollow(&UnfollowParams{Ref: "peer/cities"}, &f); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Unfollow(&UnfollowParams{Ref: "peer/cities"}, &f); !errors.Is(err, follow.ErrNotFound) {
+		t.Errorf("expected unfollowing twice to return ErrNotFound, got: %v", err)
+	}
+}
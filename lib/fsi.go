@@ -45,11 +45,10 @@ func (m *FSIMethods) CreateLink(p *LinkParams, res *dsref.VersionInfo) (err erro
 		return err
 	}
 	p.Dir = path
+	return m.inst.dispatch(m.CreateLink, p, res, func() error { return m.createLink(p, res) })
+}
 
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("FSIMethods.CreateLink", p, res))
-	}
-
+func (m *FSIMethods) createLink(p *LinkParams, res *dsref.VersionInfo) (err error) {
 	ctx := context.TODO()
 	ref, _, err := m.inst.ParseAndResolveRef(ctx, p.Ref, "local")
 	if err != nil {
@@ -64,9 +63,10 @@ func (m *FSIMethods) CreateLink(p *LinkParams, res *dsref.VersionInfo) (err erro
 // directory, will remove the link file from that directory. If given only a reference,
 // will remove the fsi path from that reference, and remove the link file from that fsi path
 func (m *FSIMethods) Unlink(p *LinkParams, res *string) (err error) {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("FSIMethods.Unlink", p, res))
-	}
+	return m.inst.dispatch(m.Unlink, p, res, func() error { return m.unlink(p, res) })
+}
+
+func (m *FSIMethods) unlink(p *LinkParams, res *string) (err error) {
 	ctx := context.TODO()
 
 	if p.Dir != "" && p.Ref != "" {
@@ -104,9 +104,10 @@ type StatusItem = fsi.StatusItem
 // Status checks for any modifications or errors in a linked directory against its previous
 // version in the repo. Must only be called if FSI is enabled for this dataset.
 func (m *FSIMethods) Status(dir *string, res *[]StatusItem) (err error) {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("FSIMethods.Status", dir, res))
-	}
+	return m.inst.dispatch(m.Status, dir, res, func() error { return m.status(dir, res) })
+}
+
+func (m *FSIMethods) status(dir *string, res *[]StatusItem) (err error) {
 	ctx := context.TODO()
 
 	*res, err = m.inst.fsi.Status(ctx, *dir)
@@ -117,9 +118,10 @@ func (m *FSIMethods) Status(dir *string, res *[]StatusItem) (err error) {
 // the status of its current working directory. It is an error to call this for a reference that
 // is not linked.
 func (m *FSIMethods) StatusForAlias(alias *string, res *[]StatusItem) (err error) {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("FSIMethods.AliasStatus", alias, res))
-	}
+	return m.inst.dispatch(m.StatusForAlias, alias, res, func() error { return m.statusForAlias(alias, res) })
+}
+
+func (m *FSIMethods) statusForAlias(alias *string, res *[]StatusItem) (err error) {
 	ctx := context.TODO()
 
 	// If only ref provided, canonicalize it to get its ref
@@ -139,9 +141,10 @@ func (m *FSIMethods) StatusForAlias(alias *string, res *[]StatusItem) (err error
 // WhatChanged gets changes that happened at a particular version in the history of the given
 // dataset reference. Not used for FSI.
 func (m *FSIMethods) WhatChanged(refstr *string, res *[]StatusItem) (err error) {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("FSIMethods.WhatChanged", refstr, res))
-	}
+	return m.inst.dispatch(m.WhatChanged, refstr, res, func() error { return m.whatChanged(refstr, res) })
+}
+
+func (m *FSIMethods) whatChanged(refstr *string, res *[]StatusItem) (err error) {
 	ctx := context.TODO()
 
 	ref, _, err := m.inst.ParseAndResolveRef(ctx, *refstr, "local")
@@ -161,9 +164,10 @@ type CheckoutParams struct {
 
 // Checkout method writes a dataset to a directory as individual files.
 func (m *FSIMethods) Checkout(p *CheckoutParams, out *string) (err error) {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("FSIMethods.Checkout", p, out))
-	}
+	return m.inst.dispatch(m.Checkout, p, out, func() error { return m.checkout(p, out) })
+}
+
+func (m *FSIMethods) checkout(p *CheckoutParams, out *string) (err error) {
 	ctx := context.TODO()
 
 	// Require a non-empty, absolute path for the checkout
@@ -235,9 +239,10 @@ type FSIWriteParams struct {
 
 // Write mutates a linked dataset on the filesystem
 func (m *FSIMethods) Write(p *FSIWriteParams, res *[]StatusItem) (err error) {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("FSIMethods.Write", p, res))
-	}
+	return m.inst.dispatch(m.Write, p, res, func() error { return m.write(p, res) })
+}
+
+func (m *FSIMethods) write(p *FSIWriteParams, res *[]StatusItem) (err error) {
 	ctx := context.TODO()
 
 	ref, err := dsref.ParseHumanFriendly(p.Ref)
@@ -278,9 +283,10 @@ type RestoreParams struct {
 
 // Restore method restores a component or all of the component files of a dataset from the repo
 func (m *FSIMethods) Restore(p *RestoreParams, out *string) (err error) {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("FSIMethods.Restore", p, out))
-	}
+	return m.inst.dispatch(m.Restore, p, out, func() error { return m.restore(p, out) })
+}
+
+func (m *FSIMethods) restore(p *RestoreParams, out *string) (err error) {
 	ctx := context.TODO()
 
 	ref, _, err := m.inst.ParseAndResolveRef(ctx, p.Ref, "local")
@@ -348,11 +354,10 @@ func (m *FSIMethods) InitDataset(p *InitFSIDatasetParams, refstr *string) (err e
 	if err = qfs.AbsPath(&p.SourceBodyPath); err != nil {
 		return err
 	}
+	return m.inst.dispatch(m.InitDataset, p, refstr, func() error { return m.initDataset(p, refstr) })
+}
 
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("FSIMethods.InitDataset", p, refstr))
-	}
-
+func (m *FSIMethods) initDataset(p *InitFSIDatasetParams, refstr *string) (err error) {
 	// If the dscache doesn't exist yet, it will only be created if the appropriate flag enables it.
 	if p.UseDscache {
 		m.inst.Dscache().CreateNewEnabled = true
@@ -378,10 +383,10 @@ type EnsureParams struct {
 
 // EnsureRef will modify the directory path in the repo for the given reference
 func (m *FSIMethods) EnsureRef(p *EnsureParams, out *dsref.VersionInfo) error {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("FSIMethods.EnsureRef", p, out))
-	}
+	return m.inst.dispatch(m.EnsureRef, p, out, func() error { return m.ensureRef(p, out) })
+}
 
+func (m *FSIMethods) ensureRef(p *EnsureParams, out *dsref.VersionInfo) error {
 	ref, err := dsref.Parse(p.Ref)
 	if err != nil {
 		return err
@@ -16,6 +16,7 @@ import (
 	"github.com/qri-io/qri/registry/regserver"
 	"github.com/qri-io/qri/remote"
 	"github.com/qri-io/qri/repo/gen"
+	"github.com/qri-io/qri/repo/profile"
 	repotest "github.com/qri-io/qri/repo/test"
 )
 
@@ -96,6 +97,44 @@ func TestTwoActorRegistryIntegration(t *testing.T) {
 	)
 }
 
+func TestCatalogIntegration(t *testing.T) {
+	tr := NewNetworkIntegrationTestRunner(t, "integration_catalog")
+	defer tr.Cleanup()
+
+	nasim := tr.InitNasim(t)
+	pro, err := profile.NewProfile(nasim.cfg.Profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := nasim.registry.PutProfile(&registry.Profile{Username: "nasim"}, pro.PrivKey); err != nil {
+		t.Fatal(err)
+	}
+
+	// - nasim creates a dataset, publishes to registry & lists it in the catalog
+	ref := InitWorldBankDataset(t, nasim)
+	rm := NewRemoteMethods(nasim)
+	res := dsref.Ref{}
+	if err := rm.Push(&PushParams{Ref: ref.Alias(), Catalog: true}, &res); err != nil {
+		t.Fatal(err)
+	}
+
+	ds, err := nasim.registry.GetDataset(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds.Path != res.Path {
+		t.Errorf("catalog path mismatch. expected: %q, got: %q", res.Path, ds.Path)
+	}
+
+	// - nasim removes the dataset from the registry & the catalog
+	if err := rm.Remove(&PushParams{Ref: ref.Alias(), Catalog: true}, &res); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := nasim.registry.GetDataset(ref); err != registry.ErrNotFound {
+		t.Errorf("expected removed dataset to be ErrNotFound, got: %v", err)
+	}
+}
+
 func TestAddCheckoutIntegration(t *testing.T) {
 	tr := NewNetworkIntegrationTestRunner(t, "integration_add_checkout")
 	defer tr.Cleanup()
@@ -348,6 +387,7 @@ func (tr *NetworkIntegrationTestRunner) InitRegistry(t *testing.T) {
 	tr.Registry = registry.Registry{
 		Remote:   rem,
 		Profiles: registry.NewMemProfiles(),
+		Datasets: registry.NewMemDatasets(),
 		Search:   regserver.MockRepoSearch{Repo: tr.RegistryInst.Repo()},
 	}
 
@@ -7,7 +7,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net/rpc"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
@@ -17,12 +17,12 @@ import (
 	golog "github.com/ipfs/go-log"
 	homedir "github.com/mitchellh/go-homedir"
 	ma "github.com/multiformats/go-multiaddr"
-	manet "github.com/multiformats/go-multiaddr-net"
 	"github.com/qri-io/ioes"
 	"github.com/qri-io/qfs"
 	"github.com/qri-io/qfs/cafs"
 	"github.com/qri-io/qfs/muxfs"
 	"github.com/qri-io/qfs/qipfs"
+	"github.com/qri-io/qri/access"
 	"github.com/qri-io/qri/base/dsfs"
 	"github.com/qri-io/qri/config"
 	"github.com/qri-io/qri/config/migrate"
@@ -30,8 +30,10 @@ import (
 	"github.com/qri-io/qri/dsref"
 	qrierr "github.com/qri-io/qri/errors"
 	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/follow"
 	"github.com/qri-io/qri/fsi"
 	"github.com/qri-io/qri/fsi/hiddenfile"
+	"github.com/qri-io/qri/journal"
 	"github.com/qri-io/qri/logbook"
 	"github.com/qri-io/qri/p2p"
 	"github.com/qri-io/qri/registry/regclient"
@@ -40,7 +42,9 @@ import (
 	"github.com/qri-io/qri/repo/buildrepo"
 	"github.com/qri-io/qri/repo/profile"
 	"github.com/qri-io/qri/stats"
+	"github.com/qri-io/qri/upload"
 	"github.com/qri-io/qri/watchfs"
+	"github.com/qri-io/qri/webhook"
 )
 
 var (
@@ -384,17 +388,19 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 
 	// check if we're operating over RPC
 	if cfg.RPC.Enabled {
-		addr, err := ma.NewMultiaddr(cfg.RPC.Address)
-		if err != nil {
+		if _, err := ma.NewMultiaddr(cfg.RPC.Address); err != nil {
 			return nil, qrierr.New(err, fmt.Sprintf("invalid config.rpc.address value: %q", cfg.RPC.Address))
 		}
-		conn, err := manet.Dial(addr)
-		if err == nil {
+		cli, err := dialRPC(ctx, cfg.RPC.Address)
+		if err != nil {
+			return nil, err
+		}
+		if cli != nil {
 			// we have a connection
 			log.Debugf("using RPC address %s", cfg.RPC.Address)
-			inst.rpc = rpc.NewClient(conn)
+			inst.rpc = cli
 			go inst.waitForAllDone()
-			return qri, err
+			return qri, nil
 		}
 	}
 
@@ -402,6 +408,20 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 		inst.bus.Subscribe(o.eventHandler, o.events...)
 	}
 
+	// wait for the bus to finish handling queued events before closing
+	inst.releasers.Add(1)
+	go func() {
+		<-inst.bus.Done()
+		inst.releasers.Done()
+	}()
+
+	inst.webhooks = webhook.NewDispatcher(ctx, inst.bus, cfg.Webhooks, filepath.Join(repoPath, "webhooks.log"))
+	inst.releasers.Add(1)
+	go func() {
+		<-inst.webhooks.Done()
+		inst.releasers.Done()
+	}()
+
 	if inst.qfs == nil {
 		inst.qfs, err = buildrepo.NewFilesystem(ctx, cfg)
 		if err != nil {
@@ -421,6 +441,30 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 		return nil, fmt.Errorf("newProfile: %s", err)
 	}
 
+	inst.tokens = newTokenSource(pro)
+
+	if cfg.Repo != nil && cfg.Repo.EventJournal {
+		if inst.journal, err = journal.NewJournal(ctx, inst.bus, filepath.Join(repoPath, journal.Filename), pro.ID.String()); err != nil {
+			return nil, fmt.Errorf("opening event journal: %w", err)
+		}
+		inst.releasers.Add(1)
+		go func() {
+			<-inst.journal.Done()
+			inst.releasers.Done()
+		}()
+	}
+
+	if inst.uploads, err = upload.NewStore(filepath.Join(repoPath, upload.DirName), maxUploadSize(cfg)); err != nil {
+		return nil, err
+	}
+	go inst.uploads.Sweep(ctx, time.Hour, upload.DefaultMaxAge)
+
+	if inst.follows, err = follow.NewStore(filepath.Join(repoPath, follow.Filename)); err != nil {
+		return nil, err
+	}
+	inst.feeds = newFeeds(inst)
+	inst.bus.Subscribe(inst.announceVersion, event.ETDatasetCommitChange, event.ETRemoteClientPushDatasetCompleted)
+
 	if inst.logbook == nil {
 		inst.logbook, err = newLogbook(inst.qfs, cfg, inst.bus, pro, inst.repoPath)
 		if err != nil {
@@ -507,6 +551,12 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 			if o.remoteOptsFuncs == nil {
 				o.remoteOptsFuncs = []remote.OptionsFunc{}
 			}
+			// quota usage is saved with the repo so it survives restarts
+			o.remoteOptsFuncs = append(o.remoteOptsFuncs, remote.OptQuotaDir(repoPath))
+			if inst.registry != nil {
+				// only follow author renames the registry has confirmed
+				o.remoteOptsFuncs = append(o.remoteOptsFuncs, remote.OptUsernameRedirect(inst.registry.ResolveUsername))
+			}
 
 			localResolver, resolverErr := inst.resolverForMode("local")
 			if resolverErr != nil {
@@ -572,6 +622,20 @@ func newDscache(ctx context.Context, fs qfs.Filesystem, bus event.Bus, username,
 	return dscache.NewDscache(ctx, fs, bus, username, dscachePath), nil
 }
 
+// newTokenSource creates a token source from the profile private key. Failing
+// to create a source isn't fatal, but no issued tokens will validate
+func newTokenSource(pro *profile.Profile) access.TokenSource {
+	if pro == nil || pro.PrivKey == nil {
+		return nil
+	}
+	tokens, err := access.NewPrivKeyTokenSource(pro.PrivKey)
+	if err != nil {
+		log.Errorf("creating access token source: %s", err)
+		return nil
+	}
+	return tokens
+}
+
 func newEventBus(ctx context.Context) event.Bus {
 	return event.NewBus(ctx)
 }
@@ -634,6 +698,7 @@ func NewInstanceFromConfigAndNodeAndBus(ctx context.Context, cfg *config.Config,
 		dscache: dc,
 		stats:   stats.New(nil),
 		logbook: r.Logbook(),
+		tokens:  newTokenSource(pro),
 	}
 
 	if node != nil && r != nil {
@@ -641,6 +706,20 @@ func NewInstanceFromConfigAndNodeAndBus(ctx context.Context, cfg *config.Config,
 		inst.bus = bus
 		inst.fsi = fsint
 		inst.qfs = r.Filesystem()
+		inst.webhooks = webhook.NewDispatcher(ctx, bus, cfg.Webhooks, "")
+		inst.follows, _ = follow.NewStore("")
+		inst.feeds = newFeeds(inst)
+		bus.Subscribe(inst.announceVersion, event.ETDatasetCommitChange, event.ETRemoteClientPushDatasetCompleted)
+	}
+
+	// instances without a repo directory keep uploads in a temp directory
+	if dir, err := ioutil.TempDir("", "qri_uploads"); err == nil {
+		if inst.uploads, err = upload.NewStore(dir, maxUploadSize(cfg)); err == nil {
+			go func() {
+				<-ctx.Done()
+				os.RemoveAll(dir)
+			}()
+		}
 	}
 
 	inst.remoteClient, err = remote.NewClient(ctx, node, inst.bus)
@@ -679,10 +758,16 @@ type Instance struct {
 	logbook         *logbook.Book
 	dscache         *dscache.Dscache
 	bus             event.Bus
+	tokens          access.TokenSource
+	webhooks        *webhook.Dispatcher
+	journal         *journal.Journal
+	uploads         *upload.Store
+	follows         *follow.Store
+	feeds           *feeds
 	watcher         *watchfs.FilesysWatcher
 	remoteOptsFuncs []remote.OptionsFunc
 
-	rpc *rpc.Client
+	rpc *RPCClient
 
 	cancel    context.CancelFunc
 	doneCh    chan struct{}
@@ -735,6 +820,7 @@ func (inst *Instance) Connect(ctx context.Context) (err error) {
 		}
 	}
 
+	inst.feeds.start(ctx)
 	return nil
 }
 
@@ -789,6 +875,9 @@ func (inst *Instance) ChangeConfig(cfg *config.Config) (err error) {
 	}
 
 	inst.cfg = cfg
+	if inst.webhooks != nil {
+		inst.webhooks.SetHooks(cfg.Webhooks)
+	}
 	return nil
 }
 
@@ -830,7 +919,7 @@ func (inst *Instance) Dscache() *dscache.Dscache {
 }
 
 // RPC accesses the instance RPC client if one exists
-func (inst *Instance) RPC() *rpc.Client {
+func (inst *Instance) RPC() *RPCClient {
 	if inst == nil {
 		return nil
 	}
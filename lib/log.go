@@ -8,6 +8,7 @@ import (
 	"github.com/qri-io/qri/base/dsfs"
 	"github.com/qri-io/qri/logbook"
 	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/startf"
 )
 
 // LogMethods extends a lib.Instance with business logic for working with lists
@@ -20,7 +21,7 @@ type LogMethods struct {
 func (m LogMethods) CoreRequestsName() string { return "log" }
 
 // NewLogMethods creates a LogMethods pointer from either a repo
-// or an rpc.Client
+// or an RPCClient
 func NewLogMethods(inst *Instance) *LogMethods {
 	return &LogMethods{
 		inst: inst,
@@ -41,9 +42,10 @@ type DatasetLogItem = logbook.DatasetLogItem
 
 // Log returns the history of changes for a given dataset
 func (m *LogMethods) Log(params *LogParams, res *[]DatasetLogItem) error {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("LogMethods.Log", params, res))
-	}
+	return m.inst.dispatch(m.Log, params, res, func() error { return m.log(params, res) })
+}
+
+func (m *LogMethods) log(params *LogParams, res *[]DatasetLogItem) error {
 	ctx := context.TODO()
 
 	// ensure valid limit value
@@ -71,7 +73,7 @@ func (m *LogMethods) Log(params *LogParams, res *[]DatasetLogItem) error {
 
 	if source == "" {
 		// local resolution
-		*res, err = base.DatasetLog(ctx, m.inst.repo, ref, params.Limit, params.Offset, true)
+		*res, err = base.DatasetLogAfter(ctx, m.inst.repo, ref, params.After, params.Limit, params.Offset, true)
 		return err
 	}
 
@@ -131,9 +133,10 @@ type LogEntry = logbook.LogEntry
 
 // Logbook lists log entries for actions taken on a given dataset
 func (m *LogMethods) Logbook(p *RefListParams, res *[]LogEntry) error {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("LogMethods.Logbook", p, res))
-	}
+	return m.inst.dispatch(m.Logbook, p, res, func() error { return m.logbook(p, res) })
+}
+
+func (m *LogMethods) logbook(p *RefListParams, res *[]LogEntry) error {
 	ctx := context.TODO()
 
 	ref, _, err := m.inst.ParseAndResolveRef(ctx, p.Ref, "local")
@@ -156,9 +159,10 @@ type PlainLogs = []logbook.PlainLog
 
 // PlainLogs encodes the full logbook as human-oriented json
 func (m *LogMethods) PlainLogs(p *PlainLogsParams, res *PlainLogs) (err error) {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("LogMethods.PlainLogs", p, res))
-	}
+	return m.inst.dispatch(m.PlainLogs, p, res, func() error { return m.plainLogs(p, res) })
+}
+
+func (m *LogMethods) plainLogs(p *PlainLogsParams, res *PlainLogs) (err error) {
 	ctx := context.TODO()
 	*res, err = m.inst.repo.Logbook().PlainLogs(ctx)
 	return err
@@ -166,10 +170,89 @@ func (m *LogMethods) PlainLogs(p *PlainLogsParams, res *PlainLogs) (err error) {
 
 // LogbookSummary returns a string overview of the logbook
 func (m *LogMethods) LogbookSummary(p *struct{}, res *string) (err error) {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("LogMethods.Diagnostic", p, res))
-	}
+	return m.inst.dispatch(m.LogbookSummary, p, res, func() error { return m.logbookSummary(p, res) })
+}
+
+func (m *LogMethods) logbookSummary(p *struct{}, res *string) (err error) {
 	ctx := context.TODO()
 	*res = m.inst.repo.Logbook().SummaryString(ctx)
 	return nil
 }
+
+// ProvenanceParams defines parameters for the Provenance method
+type ProvenanceParams struct {
+	// Reference to the dataset version to show provenance for
+	Ref string
+}
+
+// ProvenanceNode is a dataset version in a graph of dataset dependencies,
+// connected to the versions its transform loaded
+type ProvenanceNode struct {
+	Ref  string `json:"ref"`
+	Path string `json:"path"`
+	// inputs recorded when the version's transform ran. nil for versions that
+	// weren't created by a transform
+	Provenance *startf.Provenance `json:"provenance,omitempty"`
+	// dataset versions the transform loaded
+	Inputs []*ProvenanceNode `json:"inputs,omitempty"`
+	// Missing is true when the version isn't stored locally
+	Missing bool `json:"missing,omitempty"`
+	// Repeated is true when the version already appears in the graph. Inputs
+	// are only listed the first time a version appears
+	Repeated bool `json:"repeated,omitempty"`
+}
+
+// Provenance returns the graph of datasets that contributed to a dataset
+// version, following the datasets each transform loaded
+func (m *LogMethods) Provenance(p *ProvenanceParams, res *ProvenanceNode) error {
+	return m.inst.dispatch(m.Provenance, p, res, func() error { return m.provenance(p, res) })
+}
+
+func (m *LogMethods) provenance(p *ProvenanceParams, res *ProvenanceNode) error {
+	ctx := context.TODO()
+
+	ref, _, err := m.inst.ParseAndResolveRef(ctx, p.Ref, "local")
+	if err != nil {
+		return err
+	}
+
+	*res = *m.provenanceNode(ctx, ref.Human(), ref.Path, map[string]bool{})
+	return nil
+}
+
+func (m *LogMethods) provenanceNode(ctx context.Context, ref, path string, seen map[string]bool) *ProvenanceNode {
+	node := &ProvenanceNode{Ref: ref, Path: path}
+	if path == "" {
+		node.Missing = true
+		return node
+	}
+	if seen[path] {
+		node.Repeated = true
+		return node
+	}
+	seen[path] = true
+
+	ds, err := dsfs.LoadDataset(ctx, m.inst.repo.Store(), path)
+	if err != nil {
+		log.Debugf("loading provenance dataset %q: %s", path, err)
+		node.Missing = true
+		return node
+	}
+	if ds.Transform == nil {
+		return node
+	}
+
+	if node.Provenance, err = startf.ReadProvenance(ds.Transform); err != nil {
+		log.Debugf("reading provenance for %q: %s", path, err)
+	}
+	// transforms that ran before provenance was recorded still list datasets
+	// they loaded as resources
+	inputs := startf.ResourceDatasets(ds.Transform)
+	if node.Provenance != nil {
+		inputs = node.Provenance.Datasets
+	}
+	for _, in := range inputs {
+		node.Inputs = append(node.Inputs, m.provenanceNode(ctx, in.Ref, in.Path, seen))
+	}
+	return node
+}
@@ -0,0 +1,187 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/qri-io/qri/logbook"
+	"github.com/qri-io/qri/repo/profile"
+)
+
+// OrgMethods encapsulates business logic for organizations: namespaces for
+// datasets shared by a group of members
+type OrgMethods struct {
+	inst *Instance
+}
+
+// NewOrgMethods creates OrgMethods from a qri Instance
+func NewOrgMethods(inst *Instance) *OrgMethods {
+	return &OrgMethods{inst: inst}
+}
+
+// CoreRequestsName implements the Methods interface
+func (m OrgMethods) CoreRequestsName() string { return "orgs" }
+
+// OrgParams identifies an organization by name
+type OrgParams struct {
+	Name string
+}
+
+// Create makes a new organization, with the current user as its first admin
+func (m *OrgMethods) Create(p *OrgParams, res *logbook.Org) error {
+	return m.inst.dispatch(m.Create, p, res, func() error { return m.create(p, res) })
+}
+
+func (m *OrgMethods) create(p *OrgParams, res *logbook.Org) error {
+	ctx := context.TODO()
+
+	if _, err := m.inst.repo.Profiles().PeernameID(p.Name); err == nil {
+		return fmt.Errorf("name %q is already in use", p.Name)
+	}
+	org, err := m.inst.logbook.WriteOrgInit(ctx, p.Name)
+	if err != nil {
+		return err
+	}
+
+	id, err := profile.IDB58Decode(org.ProfileID)
+	if err != nil {
+		return err
+	}
+	pro := &profile.Profile{
+		ID:       id,
+		Type:     profile.TypeOrganization,
+		Peername: org.Name,
+		Created:  time.Now().UTC(),
+		Updated:  time.Now().UTC(),
+	}
+	if err := m.inst.repo.Profiles().PutProfile(pro); err != nil {
+		return err
+	}
+
+	*res = *org
+	return nil
+}
+
+// Get fetches an organization & its members
+func (m *OrgMethods) Get(p *OrgParams, res *logbook.Org) error {
+	return m.inst.dispatch(m.Get, p, res, func() error { return m.get(p, res) })
+}
+
+func (m *OrgMethods) get(p *OrgParams, res *logbook.Org) error {
+	ctx := context.TODO()
+
+	org, err := m.inst.logbook.Org(ctx, p.Name)
+	if err != nil {
+		return err
+	}
+	*res = *org
+	return nil
+}
+
+// List shows organizations known to this repo
+func (m *OrgMethods) List(p *ListParams, res *[]logbook.Org) error {
+	return m.inst.dispatch(m.List, p, res, func() error { return m.list(p, res) })
+}
+
+func (m *OrgMethods) list(p *ListParams, res *[]logbook.Org) error {
+	ctx := context.TODO()
+
+	orgs, err := m.inst.logbook.Orgs(ctx)
+	if err != nil {
+		return err
+	}
+	if p.Offset > len(orgs) {
+		p.Offset = len(orgs)
+	}
+	orgs = orgs[p.Offset:]
+	if p.Limit >= 0 && p.Limit < len(orgs) {
+		orgs = orgs[:p.Limit]
+	}
+
+	list := make([]logbook.Org, len(orgs))
+	for i, org := range orgs {
+		list[i] = *org
+	}
+	*res = list
+	return nil
+}
+
+// OrgMemberParams are parameters for changing the membership of an
+// organization
+type OrgMemberParams struct {
+	// Org is the name of the organization
+	Org string
+	// Username of the member, who must be a known peer
+	Username string
+	// Role is either "member" or "admin", defaults to "member"
+	Role string
+}
+
+// AddMember gives a peer write access to an organization's namespace, or
+// changes the role of an existing member. Only admins can add members
+func (m *OrgMethods) AddMember(p *OrgMemberParams, res *logbook.Org) error {
+	return m.inst.dispatch(m.AddMember, p, res, func() error { return m.addMember(p, res) })
+}
+
+func (m *OrgMethods) addMember(p *OrgMemberParams, res *logbook.Org) error {
+	ctx := context.TODO()
+
+	id, err := m.memberProfileID(p.Username)
+	if err != nil {
+		return err
+	}
+	member := logbook.OrgMember{Username: p.Username, ProfileID: id, Role: p.Role}
+	if err := m.inst.logbook.WriteOrgMemberAdd(ctx, p.Org, member); err != nil {
+		return err
+	}
+	return m.Get(&OrgParams{Name: p.Org}, res)
+}
+
+// RemoveMember revokes a member's write access to an organization's
+// namespace. Admins can remove any member, members can remove themselves
+func (m *OrgMethods) RemoveMember(p *OrgMemberParams, res *logbook.Org) error {
+	return m.inst.dispatch(m.RemoveMember, p, res, func() error { return m.removeMember(p, res) })
+}
+
+func (m *OrgMethods) removeMember(p *OrgMemberParams, res *logbook.Org) error {
+	ctx := context.TODO()
+
+	org, err := m.inst.logbook.Org(ctx, p.Org)
+	if err != nil {
+		return err
+	}
+	id := ""
+	for _, member := range org.Members {
+		if member.Username == p.Username {
+			id = member.ProfileID
+		}
+	}
+	if id == "" {
+		return fmt.Errorf("%q is not a member of %q", p.Username, p.Org)
+	}
+
+	if err := m.inst.logbook.WriteOrgMemberRemove(ctx, p.Org, id); err != nil {
+		return err
+	}
+	return m.Get(&OrgParams{Name: p.Org}, res)
+}
+
+// memberProfileID looks up the profileID of a peer by username
+func (m *OrgMethods) memberProfileID(username string) (string, error) {
+	if username == "" {
+		return "", fmt.Errorf("member username is required")
+	}
+	if username == m.inst.logbook.Username() {
+		h, err := m.inst.logbook.KeyHistory(context.TODO())
+		if err != nil {
+			return "", err
+		}
+		return h.ProfileID, nil
+	}
+	id, err := m.inst.repo.Profiles().PeernameID(username)
+	if err != nil {
+		return "", fmt.Errorf("unknown peer %q, connect to them first", username)
+	}
+	return id.String(), nil
+}
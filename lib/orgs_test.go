@@ -0,0 +1,63 @@
+package lib
+
+import (
+	"context"
+	"testing"
+
+	"github.com/qri-io/qri/logbook"
+)
+
+func TestOrgMethods(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+	ctx := context.Background()
+
+	m := NewOrgMethods(tr.Instance)
+	org := logbook.Org{}
+	if err := m.Create(&OrgParams{Name: "climate"}, &org); err != nil {
+		t.Fatal(err)
+	}
+	if org.ProfileID == "" || len(org.Members) != 1 || org.Members[0].Role != logbook.OrgRoleAdmin {
+		t.Errorf("expected new org to have the creator as admin, got: %#v", org)
+	}
+	if err := m.Create(&OrgParams{Name: "climate"}, &org); err == nil {
+		t.Error("expected creating an org with a taken name to fail")
+	}
+	if err := m.Create(&OrgParams{Name: "peer"}, &org); err == nil {
+		t.Error("expected creating an org with a username to fail")
+	}
+
+	body := tr.MustWriteTmpFile(t, "temps.csv", "city,temp\ntoronto,21\n")
+	if _, err := tr.SaveWithParams(&SaveParams{Ref: "climate/temps", BodyPath: body}); err != nil {
+		t.Fatalf("expected member to save to the org namespace, got: %s", err)
+	}
+	body = tr.MustWriteTmpFile(t, "temps.csv", "city,temp\ntoronto,21\nnew york,25\n")
+	ref, err := tr.SaveWithParams(&SaveParams{Ref: "climate/temps", BodyPath: body})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Username != "climate" || ref.ProfileID != org.ProfileID {
+		t.Errorf("expected dataset to be owned by the org, got: %#v", ref)
+	}
+	if _, _, err := tr.Instance.ParseAndResolveRef(ctx, "climate/temps", "local"); err != nil {
+		t.Errorf("resolving org dataset: %s", err)
+	}
+	if _, err := tr.SaveWithParams(&SaveParams{Ref: "nobody/temps", BodyPath: body}); err == nil {
+		t.Error("expected saving to a namespace that isn't an org to fail")
+	}
+
+	if err := m.AddMember(&OrgMemberParams{Org: "climate", Username: "nobody"}, &org); err == nil {
+		t.Error("expected adding an unknown peer to fail")
+	}
+	if err := m.RemoveMember(&OrgMemberParams{Org: "climate", Username: "peer"}, &org); err == nil {
+		t.Error("expected removing the last admin to fail")
+	}
+
+	orgs := []logbook.Org{}
+	if err := m.List(&ListParams{Limit: -1}, &orgs); err != nil {
+		t.Fatal(err)
+	}
+	if len(orgs) != 1 || orgs[0].Name != "climate" {
+		t.Errorf("expected to list one org, got: %#v", orgs)
+	}
+}
@@ -21,7 +21,11 @@ type ListParams struct {
 	OrderBy   string
 	Limit     int
 	Offset    int
-	// RPC is a horrible hack while we work to replace the net/rpc package
+	// After is the key of the last item on the previous page. Methods that
+	// support keyed pagination start the list after this item, falling back
+	// to Offset if the item doesn't exist
+	After string
+	// RPC is a horrible hack while we work to remove RPC-specific params
 	// TODO - remove this
 	RPC bool
 	// Public only applies to listing datasets, shows only datasets that are
@@ -35,6 +39,11 @@ type ListParams struct {
 	UseDscache bool
 }
 
+// prepareRPC marks params as sent over RPC
+func (p *ListParams) prepareRPC() {
+	p.RPC = true
+}
+
 // NewListParams creates a ListParams from page & pagesize, pages are 1-indexed
 // (the first element is 1, not 0), NewListParams performs the conversion
 func NewListParams(orderBy string, page, pageSize int) ListParams {
@@ -50,8 +59,16 @@ func NewListParams(orderBy string, page, pageSize int) ListParams {
 	}
 }
 
-// ListParamsFromRequest extracts ListParams from an http.Request pointer
+// ListParamsFromRequest extracts ListParams from an http.Request pointer.
+// Requests with a valid cursor take precedence over page & pageSize params
 func ListParamsFromRequest(r *http.Request) ListParams {
+	if c, err := util.CursorFromRequest(r); err == nil && c != nil && c.Size > 0 {
+		lp := NewListParams(r.FormValue("orderBy"), 1, c.Size)
+		lp.Offset = c.Offset
+		lp.After = c.After
+		return lp
+	}
+
 	var page, pageSize int
 	if i := util.ReqParamInt(r, "page", 0); i != 0 {
 		page = i
@@ -64,11 +81,12 @@ func ListParamsFromRequest(r *http.Request) ListParams {
 
 // Page converts a ListParams struct to a util.Page struct
 func (lp ListParams) Page() util.Page {
-	var number, size int
-	size = lp.Limit
+	size := lp.Limit
 	if size <= 0 {
 		size = DefaultPageSize
 	}
-	number = lp.Offset/size + 1
-	return util.NewPage(number, size)
+	if util.DefaultMaxPageSize != -1 && size > util.DefaultMaxPageSize {
+		size = util.DefaultMaxPageSize
+	}
+	return util.NewPageFromOffsetAndLimit(lp.Offset, size)
 }
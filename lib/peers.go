@@ -34,6 +34,8 @@ func NewPeerMethods(inst *Instance) *PeerMethods {
 // PeerListParams defines parameters for the List method
 type PeerListParams struct {
 	Limit, Offset int
+	// After is the profile ID of the last peer on the previous page
+	After string
 	// Cached == true will return offline peers from the repo
 	// as well as online peers, default is to list connected peers only
 	Cached bool
@@ -41,9 +43,10 @@ type PeerListParams struct {
 
 // List lists Peers on the qri network
 func (m *PeerMethods) List(p *PeerListParams, res *[]*config.ProfilePod) (err error) {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("PeerMethods.List", p, res))
-	}
+	return m.inst.dispatch(m.List, p, res, func() error { return m.list(p, res) })
+}
+
+func (m *PeerMethods) list(p *PeerListParams, res *[]*config.ProfilePod) (err error) {
 	if m.inst.node == nil || !m.inst.node.Online {
 		return fmt.Errorf("error: not connected, run `qri connect` in another window")
 	}
@@ -52,27 +55,27 @@ func (m *PeerMethods) List(p *PeerListParams, res *[]*config.ProfilePod) (err er
 		p.Limit = DefaultPageSize
 	}
 
-	*res, err = p2p.ListPeers(m.inst.node, p.Limit, p.Offset, !p.Cached)
+	*res, err = p2p.ListPeersAfter(m.inst.node, p.After, p.Limit, p.Offset, !p.Cached)
 	return err
 }
 
 // ConnectedIPFSPeers lists PeerID's we're currently connected to. If running
 // IPFS this will also return connected IPFS nodes
 func (m *PeerMethods) ConnectedIPFSPeers(limit *int, peers *[]string) error {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("PeerMethods.ConnectedIPFSPeers", limit, peers))
-	}
+	return m.inst.dispatch(m.ConnectedIPFSPeers, limit, peers, func() error { return m.connectedIPFSPeers(limit, peers) })
+}
 
+func (m *PeerMethods) connectedIPFSPeers(limit *int, peers *[]string) error {
 	*peers = m.inst.node.ConnectedPeers()
 	return nil
 }
 
 // ConnectedQriProfiles lists profiles we're currently connected to
 func (m *PeerMethods) ConnectedQriProfiles(limit *int, peers *[]*config.ProfilePod) (err error) {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("PeerMethods.ConnectedQriProfiles", limit, peers))
-	}
+	return m.inst.dispatch(m.ConnectedQriProfiles, limit, peers, func() error { return m.connectedQriProfiles(limit, peers) })
+}
 
+func (m *PeerMethods) connectedQriProfiles(limit *int, peers *[]*config.ProfilePod) (err error) {
 	connected := m.inst.node.ConnectedQriProfiles()
 
 	build := make([]*config.ProfilePod, intMin(len(connected), *limit))
@@ -146,9 +149,10 @@ func (p PeerConnectionParamsPod) Decode() (cp p2p.PeerConnectionParams, err erro
 
 // ConnectToPeer attempts to create a connection with a peer for a given peer.ID
 func (m *PeerMethods) ConnectToPeer(p *PeerConnectionParamsPod, res *config.ProfilePod) error {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("PeerMethods.ConnectToPeer", p, res))
-	}
+	return m.inst.dispatch(m.ConnectToPeer, p, res, func() error { return m.connectToPeer(p, res) })
+}
+
+func (m *PeerMethods) connectToPeer(p *PeerConnectionParamsPod, res *config.ProfilePod) error {
 	ctx := context.TODO()
 
 	pcp, err := p.Decode()
@@ -172,9 +176,10 @@ func (m *PeerMethods) ConnectToPeer(p *PeerConnectionParamsPod, res *config.Prof
 
 // DisconnectFromPeer explicitly closes a peer connection
 func (m *PeerMethods) DisconnectFromPeer(p *PeerConnectionParamsPod, res *bool) error {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("PeerMethods.DisconnectFromPeer", p, res))
-	}
+	return m.inst.dispatch(m.DisconnectFromPeer, p, res, func() error { return m.disconnectFromPeer(p, res) })
+}
+
+func (m *PeerMethods) disconnectFromPeer(p *PeerConnectionParamsPod, res *bool) error {
 	ctx := context.TODO()
 
 	pcp, err := p.Decode()
@@ -200,10 +205,10 @@ type PeerInfoParams struct {
 
 // Info shows peer profile details
 func (m *PeerMethods) Info(p *PeerInfoParams, res *config.ProfilePod) error {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("PeerMethods.Info", p, res))
-	}
+	return m.inst.dispatch(m.Info, p, res, func() error { return m.info(p, res) })
+}
 
+func (m *PeerMethods) info(p *PeerInfoParams, res *config.ProfilePod) error {
 	// TODO: Move most / all of this to p2p package, perhaps.
 	r := m.inst.repo
 
@@ -254,9 +259,10 @@ type PeerRefsParams struct {
 
 // GetReferences lists a peer's named datasets
 func (m *PeerMethods) GetReferences(p *PeerRefsParams, res *[]reporef.DatasetRef) error {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("PeerMethods.GetReferences", p, res))
-	}
+	return m.inst.dispatch(m.GetReferences, p, res, func() error { return m.getReferences(p, res) })
+}
+
+func (m *PeerMethods) getReferences(p *PeerRefsParams, res *[]reporef.DatasetRef) error {
 	ctx := context.TODO()
 
 	id, err := peer.IDB58Decode(p.PeerID)
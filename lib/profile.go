@@ -2,15 +2,21 @@ package lib
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
 	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/base"
 	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/logbook"
 	"github.com/qri-io/qri/registry"
 	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/repo/gen"
 	"github.com/qri-io/qri/repo/profile"
 	reporef "github.com/qri-io/qri/repo/ref"
 )
@@ -28,17 +34,17 @@ type ProfileMethods struct {
 func (ProfileMethods) CoreRequestsName() string { return "profile" }
 
 // NewProfileMethods creates a ProfileMethods pointer from either a repo
-// or an rpc.Client
+// or an RPCClient
 func NewProfileMethods(inst *Instance) *ProfileMethods {
 	return &ProfileMethods{inst: inst}
 }
 
 // GetProfile get's this node's peer profile
 func (m *ProfileMethods) GetProfile(in *bool, res *config.ProfilePod) (err error) {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("ProfileMethods.GetProfile", in, res))
-	}
+	return m.inst.dispatch(m.GetProfile, in, res, func() error { return m.getProfile(in, res) })
+}
 
+func (m *ProfileMethods) getProfile(in *bool, res *config.ProfilePod) (err error) {
 	var pro *profile.Profile
 	r := m.inst.repo
 
@@ -46,7 +52,7 @@ func (m *ProfileMethods) GetProfile(in *bool, res *config.ProfilePod) (err error
 	if res.ID == "" && res.Peername == "" {
 		pro, err = r.Profile()
 	} else {
-		pro, err = m.getProfile(r, res.ID, res.Peername)
+		pro, err = m.lookupProfile(r, res.ID, res.Peername)
 	}
 
 	if err != nil {
@@ -69,7 +75,7 @@ func (m *ProfileMethods) GetProfile(in *bool, res *config.ProfilePod) (err error
 	return nil
 }
 
-func (m *ProfileMethods) getProfile(r repo.Repo, idStr, peername string) (pro *profile.Profile, err error) {
+func (m *ProfileMethods) lookupProfile(r repo.Repo, idStr, peername string) (pro *profile.Profile, err error) {
 	var id profile.ID
 	if idStr == "" {
 		ref := &reporef.DatasetRef{
@@ -99,28 +105,22 @@ func (m *ProfileMethods) getProfile(r repo.Repo, idStr, peername string) (pro *p
 
 // SaveProfile stores changes to this peer's editable profile
 func (m *ProfileMethods) SaveProfile(p *config.ProfilePod, res *config.ProfilePod) error {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("ProfileMethods.SaveProfile", p, res))
-	}
+	return m.inst.dispatch(m.SaveProfile, p, res, func() error { return m.saveProfile(p, res) })
+}
+
+func (m *ProfileMethods) saveProfile(p *config.ProfilePod, res *config.ProfilePod) error {
 	if p == nil {
 		return fmt.Errorf("profile required for update")
 	}
 
+	ctx := context.TODO()
 	cfg := m.inst.cfg
 	r := m.inst.repo
 
 	if p.Peername != cfg.Profile.Peername && p.Peername != "" {
-		if reg := m.inst.registry; reg != nil {
-			current, err := profile.NewProfile(cfg.Profile)
-			if err != nil {
-				return err
-			}
-
-			if _, err := reg.PutProfile(&registry.Profile{Username: p.Peername}, current.PrivKey); err != nil {
-				return err
-			}
+		if err := m.rename(ctx, cfg.Profile.Peername, p.Peername); err != nil {
+			return err
 		}
-
 		cfg.Set("profile.peername", p.Peername)
 	}
 
@@ -158,16 +158,82 @@ func (m *ProfileMethods) SaveProfile(p *config.ProfilePod, res *config.ProfilePo
 	return m.inst.ChangeConfig(cfg)
 }
 
+// rename changes this peer's username. Registered usernames are renamed on the
+// registry, which keeps the old name as a redirect. The rename is written to
+// the logbook & pushed to every remote this peer has published datasets to
+func (m *ProfileMethods) rename(ctx context.Context, from, to string) error {
+	if reg := m.inst.registry; reg != nil {
+		current, err := profile.NewProfile(m.inst.cfg.Profile)
+		if err != nil {
+			return err
+		}
+		pk := current.PrivKey
+		if _, err := reg.RenameProfile(from, to, pk); err != nil {
+			if !errors.Is(err, registry.ErrNotFound) {
+				return err
+			}
+			// this peer hasn't registered its current name, register the new one
+			if _, err := reg.PutProfile(&registry.Profile{Username: to}, pk); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := base.ModifyRepoUsername(ctx, m.inst.repo, m.inst.logbook, from, to); err != nil {
+		return err
+	}
+	m.pushRename(ctx, to)
+	return nil
+}
+
+// pushRename pushes the logs of published datasets to the remotes they were
+// published to, carrying the author rename with them. Remotes that can't be
+// reached learn of the rename on the next push
+func (m *ProfileMethods) pushRename(ctx context.Context, username string) {
+	if m.inst.remoteClient == nil {
+		return
+	}
+	n, err := m.inst.repo.RefCount()
+	if err != nil {
+		log.Debugf("counting references: %s", err)
+		return
+	}
+	refs, err := m.inst.repo.References(0, n)
+	if err != nil {
+		log.Debugf("listing references: %s", err)
+		return
+	}
+	for _, rref := range refs {
+		if rref.Peername != username || !rref.Published {
+			continue
+		}
+		ref := reporef.ConvertToDsref(rref)
+		if ref.InitID, err = m.inst.logbook.RefToInitID(ref); err != nil {
+			continue
+		}
+		addrs, err := m.inst.logbook.PublishedRemotes(ctx, ref.InitID)
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if err := m.inst.remoteClient.PushLogs(ctx, ref, addr); err != nil {
+				log.Errorf("pushing rename of %q to %q: %s", ref.Alias(), addr, err)
+			}
+		}
+	}
+}
+
 // ProfilePhoto fetches the byte slice of a given user's profile photo
 func (m *ProfileMethods) ProfilePhoto(req *config.ProfilePod, res *[]byte) (err error) {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("ProfileMethods.ProfilePhoto", req, res))
-	}
+	return m.inst.dispatch(m.ProfilePhoto, req, res, func() error { return m.profilePhoto(req, res) })
+}
+
+func (m *ProfileMethods) profilePhoto(req *config.ProfilePod, res *[]byte) (err error) {
 	ctx := context.TODO()
 
 	r := m.inst.repo
 
-	pro, e := m.getProfile(r, req.ID, req.Peername)
+	pro, e := m.lookupProfile(r, req.ID, req.Peername)
 	if e != nil {
 		return e
 	}
@@ -194,9 +260,10 @@ type FileParams struct {
 
 // SetProfilePhoto changes this peer's profile image
 func (m *ProfileMethods) SetProfilePhoto(p *FileParams, res *config.ProfilePod) error {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("ProfileMethods.SetProfilePhoto", p, res))
-	}
+	return m.inst.dispatch(m.SetProfilePhoto, p, res, func() error { return m.setProfilePhoto(p, res) })
+}
+
+func (m *ProfileMethods) setProfilePhoto(p *FileParams, res *config.ProfilePod) error {
 	ctx := context.TODO()
 
 	r := m.inst.repo
@@ -260,13 +327,14 @@ func (m *ProfileMethods) SetProfilePhoto(p *FileParams, res *config.ProfilePod)
 
 // PosterPhoto fetches the byte slice of a given user's poster photo
 func (m *ProfileMethods) PosterPhoto(req *config.ProfilePod, res *[]byte) (err error) {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("ProfileMethods.PostPhoto", req, res))
-	}
+	return m.inst.dispatch(m.PosterPhoto, req, res, func() error { return m.posterPhoto(req, res) })
+}
+
+func (m *ProfileMethods) posterPhoto(req *config.ProfilePod, res *[]byte) (err error) {
 	ctx := context.TODO()
 
 	r := m.inst.repo
-	pro, e := m.getProfile(r, req.ID, req.Peername)
+	pro, e := m.lookupProfile(r, req.ID, req.Peername)
 	if e != nil {
 		return e
 	}
@@ -286,9 +354,10 @@ func (m *ProfileMethods) PosterPhoto(req *config.ProfilePod, res *[]byte) (err e
 
 // SetPosterPhoto changes this peer's poster image
 func (m *ProfileMethods) SetPosterPhoto(p *FileParams, res *config.ProfilePod) error {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("ProfileMethods.SetPosterPhoto", p, res))
-	}
+	return m.inst.dispatch(m.SetPosterPhoto, p, res, func() error { return m.setPosterPhoto(p, res) })
+}
+
+func (m *ProfileMethods) setPosterPhoto(p *FileParams, res *config.ProfilePod) error {
 	ctx := context.TODO()
 
 	if p.Data == nil {
@@ -348,3 +417,195 @@ func (m *ProfileMethods) SetPosterPhoto(p *FileParams, res *config.ProfilePod) e
 
 	return m.inst.ChangeConfig(cfg)
 }
+
+// KeyHistory lists the keys this peer's profile has used, including device
+// keys & retired keys
+func (m *ProfileMethods) KeyHistory(in *bool, res *logbook.KeyHistory) error {
+	return m.inst.dispatch(m.KeyHistory, in, res, func() error { return m.keyHistory(in, res) })
+}
+
+func (m *ProfileMethods) keyHistory(in *bool, res *logbook.KeyHistory) error {
+	ctx := context.TODO()
+
+	h, err := m.inst.logbook.KeyHistory(ctx)
+	if err != nil {
+		return err
+	}
+	*res = *h
+	return nil
+}
+
+// RotateKeyParams are parameters for rotating the profile's primary key
+type RotateKeyParams struct {
+	// PrivKey is the base64-encoded private key to rotate to. A new key is
+	// generated if none is provided
+	PrivKey string
+}
+
+// RotateKey replaces the primary key of this peer's profile. The rotation is
+// signed with the current key and recorded in the logbook, the profileID
+// stays the same. The new key replaces the old one in the config file, logs
+// signed with the old key before rotation remain valid
+func (m *ProfileMethods) RotateKey(p *RotateKeyParams, res *logbook.KeyHistory) error {
+	return m.inst.dispatch(m.RotateKey, p, res, func() error { return m.rotateKey(p, res) })
+}
+
+func (m *ProfileMethods) rotateKey(p *RotateKeyParams, res *logbook.KeyHistory) error {
+	ctx := context.TODO()
+
+	encoded := p.PrivKey
+	if encoded == "" {
+		encoded, _ = gen.NewCryptoSource().GeneratePrivateKeyAndPeerID()
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("decoding private key: %w", err)
+	}
+	pk, err := crypto.UnmarshalPrivateKey(data)
+	if err != nil {
+		return fmt.Errorf("decoding private key: %w", err)
+	}
+
+	// the logbook will be encrypted with the new key, so persist the key
+	// before rotating. The private key can't be changed with ChangeConfig,
+	// which keeps private values
+	cfg := m.inst.cfg.Copy()
+	cfg.Profile.PrivKey = encoded
+	path := m.inst.cfg.Path()
+	if path != "" {
+		if err := cfg.WriteToFile(path); err != nil {
+			return err
+		}
+	}
+
+	if err := m.inst.logbook.WriteKeyRotate(ctx, pk); err != nil {
+		// restore the key the logbook is still encrypted with
+		if path != "" {
+			if rollbackErr := m.inst.cfg.WriteToFile(path); rollbackErr != nil {
+				log.Errorf("restoring config after failed key rotation: %s", rollbackErr)
+			}
+		}
+		return err
+	}
+	m.inst.cfg = cfg
+
+	pro, err := m.inst.repo.Profile()
+	if err != nil {
+		return err
+	}
+	pro.PrivKey = pk
+	if err := m.inst.repo.SetProfile(pro); err != nil {
+		return err
+	}
+	m.inst.tokens = newTokenSource(pro)
+
+	return m.KeyHistory(nil, res)
+}
+
+// DeviceKeyParams are parameters for changing device keys
+type DeviceKeyParams struct {
+	// Device is the name of the device
+	Device string
+	// PubKey is the base64-encoded public key of the device, required when
+	// adding a device
+	PubKey string
+	// Signature is the device's signature of the request to add it, made with
+	// SignDeviceKey on the device. Required when adding a device
+	Signature string
+	// ProfileID is the profile a device is asking to be added to, required
+	// when signing a device key
+	ProfileID string
+	// KeyID identifies a device key to revoke. Revoking matches either KeyID
+	// or Device
+	KeyID string
+}
+
+// SignDeviceKey signs a request for this peer's key to be added as a device
+// key of another profile. The result holds the public key and signature the
+// profile's primary key needs to add the device
+func (m *ProfileMethods) SignDeviceKey(p *DeviceKeyParams, res *DeviceKeyParams) error {
+	return m.inst.dispatch(m.SignDeviceKey, p, res, func() error { return m.signDeviceKey(p, res) })
+}
+
+func (m *ProfileMethods) signDeviceKey(p *DeviceKeyParams, res *DeviceKeyParams) error {
+	if p.ProfileID == "" {
+		return fmt.Errorf("profileID is required")
+	}
+	if p.Device == "" {
+		return fmt.Errorf("device name is required")
+	}
+	pk := m.inst.repo.PrivateKey()
+	sig, err := logbook.DeviceKeySignature(p.ProfileID, p.Device, pk)
+	if err != nil {
+		return err
+	}
+	pubData, err := crypto.MarshalPublicKey(pk.GetPublic())
+	if err != nil {
+		return err
+	}
+	*res = DeviceKeyParams{
+		Device:    p.Device,
+		ProfileID: p.ProfileID,
+		PubKey:    base64.StdEncoding.EncodeToString(pubData),
+		Signature: sig,
+	}
+	return nil
+}
+
+// AddDeviceKey authorizes a device key to sign logs on behalf of this peer's
+// profile. Only the primary key can add devices
+func (m *ProfileMethods) AddDeviceKey(p *DeviceKeyParams, res *logbook.KeyHistory) error {
+	return m.inst.dispatch(m.AddDeviceKey, p, res, func() error { return m.addDeviceKey(p, res) })
+}
+
+func (m *ProfileMethods) addDeviceKey(p *DeviceKeyParams, res *logbook.KeyHistory) error {
+	ctx := context.TODO()
+
+	data, err := base64.StdEncoding.DecodeString(p.PubKey)
+	if err != nil {
+		return fmt.Errorf("decoding public key: %w", err)
+	}
+	pub, err := crypto.UnmarshalPublicKey(data)
+	if err != nil {
+		return fmt.Errorf("decoding public key: %w", err)
+	}
+	if err := m.inst.logbook.WriteDeviceKeyAdd(ctx, p.Device, pub, p.Signature); err != nil {
+		return err
+	}
+	return m.KeyHistory(nil, res)
+}
+
+// RevokeDeviceKey retires a device key. Logs the device signed before
+// revocation remain valid
+func (m *ProfileMethods) RevokeDeviceKey(p *DeviceKeyParams, res *logbook.KeyHistory) error {
+	return m.inst.dispatch(m.RevokeDeviceKey, p, res, func() error { return m.revokeDeviceKey(p, res) })
+}
+
+func (m *ProfileMethods) revokeDeviceKey(p *DeviceKeyParams, res *logbook.KeyHistory) error {
+	ctx := context.TODO()
+
+	h, err := m.inst.logbook.KeyHistory(ctx)
+	if err != nil {
+		return err
+	}
+	keyID := ""
+	for _, k := range h.Keys {
+		if k.Primary || !k.Active() {
+			continue
+		}
+		if (p.KeyID != "" && k.KeyID == p.KeyID) || (p.Device != "" && k.Device == p.Device) {
+			keyID = k.KeyID
+		}
+	}
+	if keyID == "" {
+		name := p.Device
+		if name == "" {
+			name = p.KeyID
+		}
+		return fmt.Errorf("no active device key for %q", name)
+	}
+	if err := m.inst.logbook.WriteDeviceKeyRevoke(ctx, keyID); err != nil {
+		return err
+	}
+	return m.KeyHistory(nil, res)
+}
@@ -2,6 +2,7 @@ package lib
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -9,8 +10,12 @@ import (
 	"testing"
 	"time"
 
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
 	"github.com/qri-io/qri/config"
+	cfgtest "github.com/qri-io/qri/config/test"
+	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/logbook"
 	"github.com/qri-io/qri/p2p"
 	"github.com/qri-io/qri/registry"
 	regmock "github.com/qri-io/qri/registry/regserver"
@@ -252,6 +257,32 @@ func TestProfileRequestsSetPeername(t *testing.T) {
 		}
 		return false, nil
 	})
+
+	if _, err := inst.logbook.WriteDatasetInit(ctx, "cities"); err != nil {
+		t.Fatal(err)
+	}
+
+	// renaming a registered profile keeps the old username as a redirect
+	pp.Peername = "keyboard_dog"
+	if err := m.SaveProfile(pp, res); err != nil {
+		t.Fatal(err)
+	}
+	current, err := reg.Profiles.Load("keyboard_dog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(current.PreviousUsernames) != 1 || current.PreviousUsernames[0] != "keyboard_cat" {
+		t.Errorf("expected previous usernames to be [keyboard_cat], got: %v", current.PreviousUsernames)
+	}
+
+	// references to datasets under the old username follow the rename
+	ref := dsref.Ref{Username: "keyboard_cat", Name: "cities"}
+	if _, err := inst.ResolveReference(ctx, &ref, "local"); err != nil {
+		t.Fatal(err)
+	}
+	if ref.Username != "keyboard_dog" {
+		t.Errorf("expected reference to resolve under %q, got: %q", "keyboard_dog", ref.Username)
+	}
 }
 
 func TestProfileRequestsSetProfilePhoto(t *testing.T) {
@@ -365,3 +396,71 @@ func TestProfileRequestsSetPosterPhoto(t *testing.T) {
 		}
 	}
 }
+
+func TestProfileKeys(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	m := NewProfileMethods(tr.Instance)
+	h := logbook.KeyHistory{}
+	if err := m.KeyHistory(nil, &h); err != nil {
+		t.Fatal(err)
+	}
+	profileID := h.ProfileID
+
+	device := cfgtest.GetTestPeerInfo(5)
+	pubData, err := crypto.MarshalPublicKey(device.PubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := logbook.DeviceKeySignature(profileID, "laptop", device.PrivKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &DeviceKeyParams{Device: "laptop", PubKey: base64.StdEncoding.EncodeToString(pubData)}
+	if err := m.AddDeviceKey(p, &h); err == nil {
+		t.Error("expected adding a device key without the device's signature to fail")
+	}
+	p.Signature = sig
+	if err := m.AddDeviceKey(p, &h); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.AddDeviceKey(&DeviceKeyParams{Device: "phone", PubKey: "not a key"}, &h); err == nil {
+		t.Error("expected adding an invalid public key to fail")
+	}
+
+	signed := DeviceKeyParams{}
+	if err := m.SignDeviceKey(&DeviceKeyParams{ProfileID: device.EncodedPeerID, Device: "desktop"}, &signed); err != nil {
+		t.Fatal(err)
+	}
+	if signed.PubKey == "" || signed.Signature == "" {
+		t.Errorf("expected a signed device key request, got: %#v", signed)
+	}
+
+	next := cfgtest.GetTestPeerInfo(6)
+	if err := m.RotateKey(&RotateKeyParams{PrivKey: next.EncodedPrivKey}, &h); err != nil {
+		t.Fatal(err)
+	}
+	if h.ProfileID != profileID {
+		t.Errorf("expected rotation to keep profileID %q, got %q", profileID, h.ProfileID)
+	}
+	if h.Primary().KeyID != next.EncodedPeerID {
+		t.Errorf("expected primary key %q, got %q", next.EncodedPeerID, h.Primary().KeyID)
+	}
+	if !tr.Instance.Repo().PrivateKey().Equals(next.PrivKey) {
+		t.Error("expected repo private key to be the rotated key")
+	}
+	if tr.Instance.Config().Profile.PrivKey != next.EncodedPrivKey {
+		t.Error("expected config private key to be the rotated key")
+	}
+
+	if err := m.RevokeDeviceKey(&DeviceKeyParams{Device: "laptop"}, &h); err != nil {
+		t.Fatal(err)
+	}
+	if k, _ := h.Key(device.EncodedPeerID); k.Active() {
+		t.Error("expected device key to be revoked")
+	}
+	if err := m.RevokeDeviceKey(&DeviceKeyParams{Device: "laptop"}, &h); err == nil {
+		t.Error("expected revoking a revoked device to fail")
+	}
+}
@@ -29,10 +29,10 @@ type RegistryProfile = registry.Profile
 
 // CreateProfile creates a profile
 func (m RegistryClientMethods) CreateProfile(p *RegistryProfile, ok *bool) (err error) {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("RegistryClientMethods.CreateProfile", p, ok))
-	}
+	return m.inst.dispatch(m.CreateProfile, p, ok, func() error { return m.createProfile(p, ok) })
+}
 
+func (m RegistryClientMethods) createProfile(p *RegistryProfile, ok *bool) (err error) {
 	pro, err := m.inst.registry.CreateProfile(p, m.inst.repo.PrivateKey())
 	if err != nil {
 		return err
@@ -47,10 +47,10 @@ func (m RegistryClientMethods) CreateProfile(p *RegistryProfile, ok *bool) (err
 // ProveProfileKey asserts to a registry that this user has control of a
 // specified private key
 func (m RegistryClientMethods) ProveProfileKey(p *RegistryProfile, ok *bool) error {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("RegistryClientMethods.CreateProfile", p, ok))
-	}
+	return m.inst.dispatch(m.ProveProfileKey, p, ok, func() error { return m.proveProfileKey(p, ok) })
+}
 
+func (m RegistryClientMethods) proveProfileKey(p *RegistryProfile, ok *bool) error {
 	pro, err := m.inst.registry.ProveProfileKey(p, m.inst.repo.PrivateKey())
 	if err != nil {
 		return err
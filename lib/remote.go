@@ -2,12 +2,15 @@ package lib
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/qri/base"
 	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/registry"
 	"github.com/qri-io/qri/remote"
+	"github.com/qri-io/qri/repo/profile"
 	reporef "github.com/qri-io/qri/repo/ref"
 )
 
@@ -32,9 +35,10 @@ func (*RemoteMethods) CoreRequestsName() string { return "remote" }
 // Feeds returns a listing of datasets from a number of feeds like featured and
 // popular. Each feed is keyed by string in the response
 func (r *RemoteMethods) Feeds(remoteName *string, res *map[string][]dsref.VersionInfo) error {
-	if r.inst.rpc != nil {
-		return checkRPCError(r.inst.rpc.Call("RemoteMethods.Feeds", remoteName, res))
-	}
+	return r.inst.dispatch(r.Feeds, remoteName, res, func() error { return r.feeds(remoteName, res) })
+}
+
+func (r *RemoteMethods) feeds(remoteName *string, res *map[string][]dsref.VersionInfo) error {
 	ctx := context.TODO()
 
 	addr, err := remote.Address(r.inst.Config(), *remoteName)
@@ -59,9 +63,10 @@ type PreviewParams struct {
 
 // Preview requests a dataset preview from a remote
 func (r *RemoteMethods) Preview(p *PreviewParams, res *dataset.Dataset) error {
-	if r.inst.rpc != nil {
-		return checkRPCError(r.inst.rpc.Call("RemoteMethods.Preview", p, res))
-	}
+	return r.inst.dispatch(r.Preview, p, res, func() error { return r.preview(p, res) })
+}
+
+func (r *RemoteMethods) preview(p *PreviewParams, res *dataset.Dataset) error {
 	ctx := context.TODO()
 
 	ref, err := dsref.Parse(p.Ref)
@@ -90,14 +95,18 @@ type PushParams struct {
 	// All indicates all versions of a dataset and the dataset namespace should
 	// be either published or removed
 	All bool
+	// Catalog lists the pushed dataset's preview in the registry catalog on
+	// push, and removes it from the catalog on remove. Catalog failures are
+	// logged, they don't fail the push or remove
+	Catalog bool
 }
 
 // Push posts a dataset version to a remote
 func (r *RemoteMethods) Push(p *PushParams, res *dsref.Ref) error {
-	if r.inst.rpc != nil {
-		return checkRPCError(r.inst.rpc.Call("RemoteMethods.Push", p, res))
-	}
+	return r.inst.dispatch(r.Push, p, res, func() error { return r.push(p, res) })
+}
 
+func (r *RemoteMethods) push(p *PushParams, res *dsref.Ref) error {
 	// TODO (b5) - need contexts yo
 	ctx := context.TODO()
 
@@ -121,16 +130,24 @@ func (r *RemoteMethods) Push(p *PushParams, res *dsref.Ref) error {
 		return err
 	}
 
+	// the push has succeeded once the remote has the dataset, a failure to
+	// list it in the catalog is logged instead of failing the push
+	if p.Catalog {
+		if err = r.catalog(ctx, ref, true); err != nil {
+			log.Errorf("listing %s in the registry catalog: %s", ref, err)
+		}
+	}
+
 	*res = ref
 	return nil
 }
 
 // Pull fetches a dataset version & logbook data from a remote
 func (r *RemoteMethods) Pull(p *PushParams, res *dataset.Dataset) error {
-	if r.inst.rpc != nil {
-		return checkRPCError(r.inst.rpc.Call("RemoteMethods.Pull", p, res))
-	}
+	return r.inst.dispatch(r.Pull, p, res, func() error { return r.pull(p, res) })
+}
 
+func (r *RemoteMethods) pull(p *PushParams, res *dataset.Dataset) error {
 	ref, err := dsref.Parse(p.Ref)
 	if err != nil {
 		return err
@@ -146,10 +163,10 @@ func (r *RemoteMethods) Pull(p *PushParams, res *dataset.Dataset) error {
 
 // Remove asks a remote to remove a dataset
 func (r *RemoteMethods) Remove(p *PushParams, res *dsref.Ref) error {
-	if r.inst.rpc != nil {
-		return checkRPCError(r.inst.rpc.Call("RemoteMethods.Remove", p, res))
-	}
+	return r.inst.dispatch(r.Remove, p, res, func() error { return r.remove(p, res) })
+}
 
+func (r *RemoteMethods) remove(p *PushParams, res *dsref.Ref) error {
 	ref, err := dsref.ParseHumanFriendly(p.Ref)
 	if err != nil {
 		if err == dsref.ErrNotHumanFriendly {
@@ -178,6 +195,38 @@ func (r *RemoteMethods) Remove(p *PushParams, res *dsref.Ref) error {
 		return err
 	}
 
+	if p.Catalog {
+		if err = r.catalog(ctx, ref, false); err != nil {
+			log.Errorf("removing %s from the registry catalog: %s", ref, err)
+		}
+	}
+
 	*res = ref
 	return nil
 }
+
+// catalog publishes or unpublishes a dataset preview in the registry catalog
+func (r *RemoteMethods) catalog(ctx context.Context, ref dsref.Ref, publish bool) error {
+	reg := r.inst.registry
+	if reg == nil {
+		return registry.ErrNoRegistry
+	}
+	pro, err := profile.NewProfile(r.inst.cfg.Profile)
+	if err != nil {
+		return err
+	}
+
+	if !publish {
+		if err := reg.UnpublishDataset(ref, pro.PrivKey); err != nil && !errors.Is(err, registry.ErrNotFound) {
+			return err
+		}
+		return nil
+	}
+
+	preview, err := base.CreatePreview(ctx, r.inst.repo, ref)
+	if err != nil {
+		return err
+	}
+	_, err = reg.PublishDataset(preview, pro.PrivKey)
+	return err
+}
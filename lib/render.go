@@ -15,7 +15,7 @@ type RenderMethods struct {
 }
 
 // NewRenderMethods creates a RenderMethods pointer from either a repo
-// or an rpc.Client
+// or an RPCClient
 func NewRenderMethods(inst *Instance) *RenderMethods {
 	return &RenderMethods{
 		inst: inst,
@@ -52,9 +52,10 @@ func (p *RenderParams) Validate() error {
 
 // RenderViz renders a viz component as html
 func (m *RenderMethods) RenderViz(p *RenderParams, res *[]byte) (err error) {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("RenderMethods.RenderViz", p, res))
-	}
+	return m.inst.dispatch(m.RenderViz, p, res, func() error { return m.renderViz(p, res) })
+}
+
+func (m *RenderMethods) renderViz(p *RenderParams, res *[]byte) (err error) {
 	ctx := context.TODO()
 
 	if err = p.Validate(); err != nil {
@@ -80,9 +81,10 @@ func (m *RenderMethods) RenderViz(p *RenderParams, res *[]byte) (err error) {
 
 // RenderReadme renders the readme into html for the given dataset
 func (m *RenderMethods) RenderReadme(p *RenderParams, res *string) (err error) {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("RenderMethods.RenderReadme", p, res))
-	}
+	return m.inst.dispatch(m.RenderReadme, p, res, func() error { return m.renderReadme(p, res) })
+}
+
+func (m *RenderMethods) renderReadme(p *RenderParams, res *string) (err error) {
 	ctx := context.TODO()
 
 	if err = p.Validate(); err != nil {
@@ -73,10 +73,7 @@ func (inst *Instance) resolverForMode(mode string) (dsref.Resolver, error) {
 	case "":
 		return inst.defaultResolver(), nil
 	case "local":
-		return dsref.SequentialResolver(
-			inst.dscache,
-			inst.repo,
-		), nil
+		return inst.localResolver(), nil
 	case "network":
 		return dsref.ParallelResolver(
 			inst.registryResolver(),
@@ -102,8 +99,7 @@ func (inst *Instance) resolverForMode(mode string) (dsref.Resolver, error) {
 
 func (inst *Instance) defaultResolver() dsref.Resolver {
 	return dsref.SequentialResolver(
-		inst.dscache,
-		inst.repo,
+		inst.localResolver(),
 		dsref.ParallelResolver(
 			inst.registryResolver(),
 			// inst.node,
@@ -111,12 +107,43 @@ func (inst *Instance) defaultResolver() dsref.Resolver {
 	)
 }
 
+// localResolver resolves references with local data, following author
+// renames recorded in the logbook
+func (inst *Instance) localResolver() dsref.Resolver {
+	return dsref.RedirectResolver(
+		dsref.SequentialResolver(
+			inst.dscache,
+			inst.repo,
+		),
+		inst.resolveLocalUsername,
+	)
+}
+
+// resolveLocalUsername follows author renames recorded in the logbook. When a
+// registry is configured the registry must agree with the rename, so author
+// logs can't claim usernames they never held
+func (inst *Instance) resolveLocalUsername(ctx context.Context, username string) (string, error) {
+	current, err := inst.logbook.ResolveUsername(ctx, username)
+	if err != nil || inst.registry == nil {
+		return current, err
+	}
+	if confirmed, err := inst.registry.ResolveUsername(ctx, username); err != nil || confirmed != current {
+		return "", dsref.ErrRefNotFound
+	}
+	return current, nil
+}
+
 func (inst *Instance) registryResolver() dsref.Resolver {
 	var location string
 	if inst.cfg.Registry != nil {
 		location = inst.cfg.Registry.Location
 	}
-	return inst.remoteClient.NewRemoteRefResolver(location)
+	resolver := inst.remoteClient.NewRemoteRefResolver(location)
+	if inst.registry == nil {
+		return resolver
+	}
+	// usernames the registry has seen renamed redirect to the current name
+	return dsref.RedirectResolver(resolver, inst.registry.ResolveUsername)
 }
 
 func (inst *Instance) p2pResolver() dsref.Resolver {
@@ -1,31 +1,43 @@
 package lib
 
 import (
+	"bytes"
 	"context"
-	"encoding/gob"
 	"encoding/json"
-	"io/ioutil"
-	stdlog "log"
-	"net/rpc"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
 
 	ma "github.com/multiformats/go-multiaddr"
 	manet "github.com/multiformats/go-multiaddr-net"
+	"github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/version"
 )
 
-func init() {
-	// We don't use the log package, and the net/rpc package spits out some complaints b/c
-	// a few methods don't conform to the proper signature (comment this out & run 'qri connect' to see errors)
-	// so we're disabling the log package for now. This is potentially very stupid.
-	// TODO (b5): remove dep on net/rpc package entirely
-	stdlog.SetOutput(ioutil.Discard)
+const (
+	// RPCProtocolVersion is the version of the JSON-over-HTTP protocol lib
+	// methods are served with. Clients and servers must speak the same version
+	RPCProtocolVersion = "1"
+	// RPCPathPrefix is the path lib methods are served under. Each method is
+	// available at RPCPathPrefix + "{Receiver}.{Method}", for example:
+	// /rpc/v1/DatasetMethods.Get
+	RPCPathPrefix = "/rpc/v" + RPCProtocolVersion + "/"
 
-	// Fields like dataset.Structure.Schema contain data of arbitrary types,
-	// registering with the gob package prevents errors when sending them
-	// over net/rpc calls.
-	gob.Register(json.RawMessage{})
-	gob.Register([]interface{}{})
-	gob.Register(map[string]interface{}{})
-}
+	// RPCVersionHeader carries the RPC protocol version of a request or response
+	RPCVersionHeader = "X-Qri-RPC-Version"
+	// QriVersionHeader carries the qri version of the client or server
+	QriVersionHeader = "X-Qri-Version"
+)
+
+// ErrRPCVersionMismatch indicates a client and server speak different
+// versions of the RPC protocol
+var ErrRPCVersionMismatch = errors.New("RPC version mismatch")
 
 // Receivers returns a slice of CoreRequests that defines the full local
 // API of lib methods
@@ -40,8 +52,15 @@ func Receivers(inst *Instance) []Methods {
 		NewConfigMethods(inst),
 		NewSearchMethods(inst),
 		NewSQLMethods(inst),
+		NewTransformMethods(inst),
 		NewRenderMethods(inst),
 		NewFSIMethods(inst),
+		NewAccessMethods(inst),
+		NewWebhookMethods(inst),
+		NewEventMethods(inst),
+		NewUploadMethods(inst),
+		NewFollowMethods(inst),
+		NewOrgMethods(inst),
 	}
 }
 
@@ -54,29 +73,338 @@ func (inst *Instance) ServeRPC(ctx context.Context) {
 	maAddress := cfg.RPC.Address
 	addr, err := ma.NewMultiaddr(maAddress)
 	if err != nil {
-		log.Errorf("cannot start RPC: error parsing RPC address %s: %w", maAddress, err.Error())
+		log.Errorf("cannot start RPC: error parsing RPC address %s: %s", maAddress, err)
+		return
 	}
 
 	mal, err := manet.Listen(addr)
 	if err != nil {
-		log.Infof("RPC listen on address %d error: %w", cfg.RPC.Address, err)
+		log.Infof("RPC listen on address %s error: %s", cfg.RPC.Address, err)
 		return
 	}
 	listener := manet.NetListener(mal)
 
+	srv := &http.Server{Handler: NewRPCHandler(inst)}
+	go func() {
+		<-ctx.Done()
+		log.Info("closing RPC")
+		srv.Close()
+	}()
+
+	if err := srv.Serve(listener); err != http.ErrServerClosed {
+		log.Infof("serving RPC: %s", err)
+	}
+}
+
+// rpcMethod is a lib method that can be called over RPC. rpc methods have the
+// signature: func (m *XMethods) Name(p ParamType, res *ResultType) error
+type rpcMethod struct {
+	rcvr   reflect.Value
+	fn     reflect.Value
+	params reflect.Type
+	res    reflect.Type
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// rpcMethods generates the set of lib methods callable over RPC from the
+// exported methods of each receiver, keyed by "{Receiver}.{Method}"
+func rpcMethods(inst *Instance) map[string]rpcMethod {
+	methods := map[string]rpcMethod{}
 	for _, rcvr := range Receivers(inst) {
-		if err := rpc.Register(rcvr); err != nil {
-			log.Errorf("cannot start RPC: error registering RPC receiver %s: %w", rcvr.CoreRequestsName(), err.Error())
+		v := reflect.ValueOf(rcvr)
+		t := v.Type()
+		name := reflect.Indirect(v).Type().Name()
+
+		for i := 0; i < t.NumMethod(); i++ {
+			m := t.Method(i)
+			mt := m.Type
+			// method types include the receiver as the first input
+			if mt.NumIn() != 3 || mt.NumOut() != 1 || mt.Out(0) != errorType {
+				continue
+			}
+			if mt.In(2).Kind() != reflect.Ptr {
+				continue
+			}
+			methods[name+"."+m.Name] = rpcMethod{
+				rcvr:   v,
+				fn:     m.Func,
+				params: mt.In(1),
+				res:    mt.In(2).Elem(),
+			}
+		}
+	}
+	return methods
+}
+
+// RPCMethodNames lists the names of all lib methods callable over RPC, sorted
+// alphabetically
+func RPCMethodNames(inst *Instance) []string {
+	methods := rpcMethods(inst)
+	names := make([]string, 0, len(methods))
+	for name := range methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// rpcPreparer is implemented by params that must change before they're sent
+// over RPC, usually to drop fields that only work on local calls
+type rpcPreparer interface {
+	prepareRPC()
+}
+
+// dispatch runs a lib method, forwarding the call to the connected RPC server
+// when the instance has one and calling local otherwise. method is the method
+// value of the lib method being called, for example m.Get. Every exported lib
+// method that can be called over RPC goes through dispatch
+func (inst *Instance) dispatch(method interface{}, p, res interface{}, local func() error) error {
+	if inst.rpc == nil {
+		return local()
+	}
+	name, err := rpcMethodName(method)
+	if err != nil {
+		return err
+	}
+	if pp, ok := p.(rpcPreparer); ok {
+		pp.prepareRPC()
+	}
+	return checkRPCError(inst.rpc.Call(name, p, res))
+}
+
+// rpcMethodName derives the "{Receiver}.{Method}" name a method is served
+// under from a method value. The runtime names method values like:
+// github.com/qri-io/qri/lib.(*DatasetMethods).Get-fm
+func rpcMethodName(method interface{}) (string, error) {
+	v := reflect.ValueOf(method)
+	if v.Kind() != reflect.Func {
+		return "", fmt.Errorf("RPC method must be a method value, got %T", method)
+	}
+	fn := runtime.FuncForPC(v.Pointer())
+	if fn == nil {
+		return "", fmt.Errorf("unknown RPC method")
+	}
+	name := fn.Name()
+	if !strings.HasSuffix(name, "-fm") {
+		return "", fmt.Errorf("%q is not a method value", name)
+	}
+	name = strings.TrimSuffix(name, "-fm")
+	name = name[strings.LastIndex(name, "/")+1:]
+	name = strings.TrimPrefix(name, "lib.")
+	name = strings.NewReplacer("(*", "", ")", "").Replace(name)
+	if strings.Count(name, ".") != 1 {
+		return "", fmt.Errorf("%q is not a lib method", fn.Name())
+	}
+	return name, nil
+}
+
+// RPCInfo describes an RPC server
+type RPCInfo struct {
+	RPCVersion string   `json:"rpcVersion"`
+	QriVersion string   `json:"qriVersion"`
+	Methods    []string `json:"methods"`
+}
+
+// NewRPCHandler creates an http.Handler that exposes every lib method as a
+// JSON endpoint. POST a JSON-encoded method parameter to
+// RPCPathPrefix + "{Receiver}.{Method}" to call a method, the result is
+// returned in the "data" field of the standard API response envelope. A GET
+// request to RPCPathPrefix describes the server
+func NewRPCHandler(inst *Instance) http.Handler {
+	methods := rpcMethods(inst)
+	info := RPCInfo{
+		RPCVersion: RPCProtocolVersion,
+		QriVersion: version.String,
+		Methods:    RPCMethodNames(inst),
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(RPCVersionHeader, RPCProtocolVersion)
+		w.Header().Set(QriVersionHeader, version.String)
+
+		// clients that don't say which version they speak are assumed to speak
+		// this one
+		if v := r.Header.Get(RPCVersionHeader); v != "" && v != RPCProtocolVersion {
+			err := rpcVersionMismatch(v, r.Header.Get(QriVersionHeader), RPCProtocolVersion, version.String)
+			util.WriteErrResponse(w, http.StatusConflict, err)
+			return
+		}
+
+		if !strings.HasPrefix(r.URL.Path, RPCPathPrefix) {
+			if strings.HasPrefix(r.URL.Path, "/rpc/") {
+				util.WriteErrResponse(w, http.StatusConflict, fmt.Errorf("%w: this server speaks RPC version %s at %s", ErrRPCVersionMismatch, RPCProtocolVersion, RPCPathPrefix))
+				return
+			}
+			util.NotFoundHandler(w, r)
+			return
+		}
+
+		name := r.URL.Path[len(RPCPathPrefix):]
+		if name == "" {
+			util.WriteResponse(w, info)
+			return
+		}
+
+		m, ok := methods[name]
+		if !ok {
+			util.WriteErrResponse(w, http.StatusNotFound, fmt.Errorf("unknown method %q", name))
+			return
+		}
+		if r.Method != http.MethodPost {
+			util.WriteErrResponse(w, http.StatusMethodNotAllowed, fmt.Errorf("methods must be called with POST"))
 			return
 		}
+
+		// decode into a pointer, dereferencing for methods that take values
+		params := reflect.New(m.params)
+		if m.params.Kind() == reflect.Ptr {
+			params.Elem().Set(reflect.New(m.params.Elem()))
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(params.Interface()); err != nil {
+				util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("decoding %s params: %w", name, err))
+				return
+			}
+		}
+		if m.params.Kind() == reflect.Ptr && params.Elem().IsNil() {
+			// a JSON null param decodes to a nil pointer
+			params.Elem().Set(reflect.New(m.params.Elem()))
+		}
+
+		res := reflect.New(m.res)
+		out := m.fn.Call([]reflect.Value{m.rcvr, params.Elem(), res})
+		if err, _ := out[0].Interface().(error); err != nil {
+			util.WriteErrResponse(w, http.StatusInternalServerError, err)
+			return
+		}
+		util.WriteResponse(w, res.Interface())
+	})
+}
+
+// RPCClient calls lib methods on another qri process over JSON-over-HTTP
+type RPCClient struct {
+	addr string
+	base string
+	http *http.Client
+}
+
+// NewRPCClient creates a client for the RPC server listening on a multiaddr
+// address. It doesn't make a connection
+func NewRPCClient(address string) (*RPCClient, error) {
+	maddr, err := ma.NewMultiaddr(address)
+	if err != nil {
+		return nil, err
+	}
+	naddr, err := manet.ToNetAddr(maddr)
+	if err != nil {
+		return nil, err
 	}
+	return &RPCClient{
+		addr: address,
+		base: "http://" + naddr.String(),
+		http: &http.Client{},
+	}, nil
+}
 
-	go func() {
-		<-ctx.Done()
-		log.Info("closing RPC")
-		listener.Close()
-	}()
+// Address returns the multiaddr the client connects to
+func (c *RPCClient) Address() string {
+	return c.addr
+}
 
-	rpc.Accept(listener)
-	return
+// Handshake fetches information about the server, returning an error wrapping
+// ErrRPCVersionMismatch if the server speaks a different protocol version
+func (c *RPCClient) Handshake(ctx context.Context) (*RPCInfo, error) {
+	req, err := http.NewRequest(http.MethodGet, c.base+RPCPathPrefix, nil)
+	if err != nil {
+		return nil, err
+	}
+	info := &RPCInfo{}
+	if err := c.do(req.WithContext(ctx), info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// Call invokes the named lib method, like "DatasetMethods.Get", decoding the
+// result into res
+func (c *RPCClient) Call(method string, params, res interface{}) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("encoding %s params: %w", method, err)
+	}
+	req, err := http.NewRequest(http.MethodPost, c.base+RPCPathPrefix+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.do(req, res)
+}
+
+func (c *RPCClient) do(req *http.Request, res interface{}) error {
+	req.Header.Set(RPCVersionHeader, RPCProtocolVersion)
+	req.Header.Set(QriVersionHeader, version.String)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if v := resp.Header.Get(RPCVersionHeader); v != RPCProtocolVersion {
+		return rpcVersionMismatch(RPCProtocolVersion, version.String, v, resp.Header.Get(QriVersionHeader))
+	}
+
+	env := struct {
+		Meta struct {
+			Error string `json:"error"`
+		} `json:"meta"`
+		Data json.RawMessage `json:"data"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return fmt.Errorf("decoding RPC response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusConflict {
+			return fmt.Errorf("%w: %s", ErrRPCVersionMismatch, env.Meta.Error)
+		}
+		return errors.New(env.Meta.Error)
+	}
+	if res != nil && len(env.Data) > 0 {
+		if err := json.Unmarshal(env.Data, res); err != nil {
+			return fmt.Errorf("decoding RPC result: %w", err)
+		}
+	}
+	return nil
+}
+
+func rpcVersionMismatch(clientRPC, clientQri, serverRPC, serverQri string) error {
+	if serverRPC == "" {
+		serverRPC = "unknown"
+	}
+	return fmt.Errorf("%w: client speaks RPC version %s (qri %s), server speaks RPC version %s (qri %s). Please run the same version of qri for both, or stop the running qri process", ErrRPCVersionMismatch, clientRPC, clientQri, serverRPC, serverQri)
+}
+
+// dialRPC connects to a qri process listening for RPC calls on address,
+// returning a nil client if nothing is listening
+func dialRPC(ctx context.Context, address string) (*RPCClient, error) {
+	cli, err := NewRPCClient(address)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+	if _, err := cli.Handshake(ctx); err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			// nothing is listening
+			return nil, nil
+		}
+		if errors.Is(err, ErrRPCVersionMismatch) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("a process is listening on the RPC address %s but didn't respond to qri's RPC handshake. It may be an older version of qri, please stop it and try again: %w", address, err)
+	}
+	return cli, nil
 }
@@ -2,8 +2,20 @@ package lib
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/qri-io/qri/config"
 )
 
 func TestRPCRequest(t *testing.T) {
@@ -48,3 +60,231 @@ func TestRPCRequest(t *testing.T) {
 		}
 	}
 }
+
+func TestRPCHandler(t *testing.T) {
+	cfg := config.DefaultConfigForTesting()
+	inst := &Instance{cfg: cfg}
+
+	s := httptest.NewServer(NewRPCHandler(inst))
+	defer s.Close()
+
+	cli := &RPCClient{base: s.URL, http: s.Client()}
+	info, err := cli.Handshake(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.RPCVersion != RPCProtocolVersion {
+		t.Errorf("rpc version mismatch. want: %q, got: %q", RPCProtocolVersion, info.RPCVersion)
+	}
+	found := false
+	for _, name := range info.Methods {
+		if name == "ConfigMethods.GetConfig" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected ConfigMethods.GetConfig to be listed. got: %v", info.Methods)
+	}
+
+	// lib methods dispatch to the RPC client when one is set
+	rpcInst := &Instance{rpc: cli}
+	res := []byte{}
+	if err := NewConfigMethods(rpcInst).GetConfig(&GetConfigParams{Field: "rpc.address", Format: "json", Concise: true}, &res); err != nil {
+		t.Fatal(err)
+	}
+	expect := fmt.Sprintf("%q", cfg.RPC.Address)
+	if string(res) != expect {
+		t.Errorf("result mismatch. want: %s, got: %s", expect, string(res))
+	}
+
+	// errors are returned to the caller
+	if err := NewConfigMethods(rpcInst).GetConfig(&GetConfigParams{Field: "unknown.field"}, &res); err == nil {
+		t.Error("expected getting an unknown field to error")
+	}
+
+	if err := cli.Call("ConfigMethods.NotAMethod", nil, nil); err == nil {
+		t.Error("expected calling an unknown method to error")
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	called := false
+	local := func() error {
+		called = true
+		return nil
+	}
+
+	inst := &Instance{}
+	if err := inst.dispatch(NewDatasetMethods(inst).List, &ListParams{}, nil, local); err != nil {
+		t.Fatal(err)
+	}
+	if !called {
+		t.Error("expected a local instance to call the local method")
+	}
+
+	// params are prepared for RPC before they're sent
+	got := map[string]interface{}{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.Header().Set(RPCVersionHeader, RPCProtocolVersion)
+		w.Write([]byte(`{"data":[]}`))
+	}))
+	defer s.Close()
+
+	called = false
+	inst = &Instance{rpc: &RPCClient{base: s.URL, http: s.Client()}}
+	res := []string{}
+	if err := inst.dispatch(NewDatasetMethods(inst).List, &ListParams{Term: "a"}, &res, local); err != nil {
+		t.Fatal(err)
+	}
+	if called {
+		t.Error("expected an RPC instance not to call the local method")
+	}
+	if got["RPC"] != true || got["Term"] != "a" {
+		t.Errorf("expected prepared params to be sent. got: %v", got)
+	}
+}
+
+func TestRPCMethodName(t *testing.T) {
+	inst := &Instance{}
+	cases := []struct {
+		method interface{}
+		expect string
+	}{
+		{NewDatasetMethods(inst).Get, "DatasetMethods.Get"},
+		{NewConfigMethods(inst).SetConfig, "ConfigMethods.SetConfig"},
+		{NewRemoteMethods(inst).Preview, "RemoteMethods.Preview"},
+	}
+	for _, c := range cases {
+		got, err := rpcMethodName(c.method)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != c.expect {
+			t.Errorf("method name mismatch. expected %q, got: %q", c.expect, got)
+		}
+	}
+
+	if _, err := rpcMethodName("DatasetMethods.Get"); err == nil {
+		t.Error("expected a string method to error")
+	}
+	if _, err := rpcMethodName(func() {}); err == nil {
+		t.Error("expected a func literal to error")
+	}
+}
+
+// TestDispatchCallsServedMethods checks every dispatch call in package lib
+// names the method it's called from, and that the method is served over RPC
+func TestDispatchCallsServedMethods(t *testing.T) {
+	served := map[string]bool{}
+	for _, name := range RPCMethodNames(&Instance{}) {
+		served[name] = true
+	}
+
+	paths, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	calls := 0
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || fn.Body == nil {
+				continue
+			}
+			rcvr := receiverTypeName(fn.Recv.List[0].Type)
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				if sel, ok := call.Fun.(*ast.SelectorExpr); !ok || sel.Sel.Name != "dispatch" || len(call.Args) == 0 {
+					return true
+				}
+				calls++
+				pos := fset.Position(call.Pos())
+				method, ok := call.Args[0].(*ast.SelectorExpr)
+				if !ok {
+					t.Errorf("%s: dispatch must be called with a method value", pos)
+					return true
+				}
+				name := rcvr + "." + method.Sel.Name
+				if method.Sel.Name != fn.Name.Name {
+					t.Errorf("%s: %s.%s dispatches %s", pos, rcvr, fn.Name.Name, name)
+				}
+				if !served[name] {
+					t.Errorf("%s: dispatched method %s isn't served over RPC", pos, name)
+				}
+				return true
+			})
+		}
+	}
+	if calls == 0 {
+		t.Error("expected to find dispatch calls")
+	}
+}
+
+func receiverTypeName(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if id, ok := expr.(*ast.Ident); ok {
+		return id.Name
+	}
+	return ""
+}
+
+func TestRPCVersionMismatch(t *testing.T) {
+	inst := &Instance{cfg: config.DefaultConfigForTesting()}
+	s := httptest.NewServer(NewRPCHandler(inst))
+	defer s.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, s.URL+RPCPathPrefix, nil)
+	req.Header.Set(RPCVersionHeader, "0")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusConflict {
+		t.Errorf("expected a mismatched client to get status %d, got %d", http.StatusConflict, res.StatusCode)
+	}
+
+	// servers that don't speak this version are rejected by the client
+	old := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(RPCVersionHeader, "0")
+		w.Write([]byte(`{}`))
+	}))
+	defer old.Close()
+	cli := &RPCClient{base: old.URL, http: old.Client()}
+	if _, err := cli.Handshake(context.Background()); !errors.Is(err, ErrRPCVersionMismatch) {
+		t.Errorf("expected version mismatch error, got: %v", err)
+	}
+}
+
+func TestDialRPC(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := fmt.Sprintf("/ip4/127.0.0.1/tcp/%d", l.Addr().(*net.TCPAddr).Port)
+	l.Close()
+
+	cli, err := dialRPC(context.Background(), addr)
+	if err != nil {
+		t.Errorf("expected dialing an address nothing listens on not to error, got: %s", err)
+	}
+	if cli != nil {
+		t.Error("expected a nil client when nothing is listening")
+	}
+}
@@ -38,9 +38,10 @@ type SearchResult struct {
 
 // Search queries for items on qri related to given parameters
 func (m *SearchMethods) Search(p *SearchParams, results *[]SearchResult) error {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("SearchMethods.Search", p, results))
-	}
+	return m.inst.dispatch(m.Search, p, results, func() error { return m.search(p, results) })
+}
+
+func (m *SearchMethods) search(p *SearchParams, results *[]SearchResult) error {
 	if p == nil {
 		return fmt.Errorf("error: search params cannot be nil")
 	}
@@ -4,8 +4,16 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/repo"
 	"github.com/qri-io/qri/sql"
+	"github.com/qri-io/qri/startf"
+	skysql "github.com/qri-io/qri/startf/sql"
+	"go.starlark.net/starlark"
 )
 
 // SQLMethods encapsulates business logic for the qri search command
@@ -32,9 +40,10 @@ type SQLQueryParams struct {
 
 // Exec runs an SQL query
 func (m *SQLMethods) Exec(p *SQLQueryParams, results *[]byte) error {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("SQLMethods.Exec", p, results))
-	}
+	return m.inst.dispatch(m.Exec, p, results, func() error { return m.exec(p, results) })
+}
+
+func (m *SQLMethods) exec(p *SQLQueryParams, results *[]byte) error {
 	if p == nil {
 		return fmt.Errorf("error: search params cannot be nil")
 	}
@@ -57,3 +66,38 @@ func (m *SQLMethods) Exec(p *SQLQueryParams, results *[]byte) error {
 	*results = buf.Bytes()
 	return nil
 }
+
+// transformModuleLoader adds the sql module to the starlark modules available
+// to a transform script. Queries load datasets with loadDataset, the same
+// function load_dataset uses, and are recorded in the transform's resources
+func transformModuleLoader(ctx context.Context, r repo.Repo, tf *dataset.Transform, loadDataset dsref.ParseResolveLoad) startf.ModuleLoader {
+	sqlModule := skysql.NewModule(ctx, r, func(ctx context.Context, refstr string) (*dataset.Dataset, error) {
+		ds, err := loadDataset(ctx, refstr)
+		if err != nil {
+			return nil, err
+		}
+		startf.AddResource(tf, ds)
+		return ds, nil
+	})
+
+	return func(thread *starlark.Thread, module string) (starlark.StringDict, error) {
+		if module == skysql.ModuleName {
+			return sqlModule.Namespace(), nil
+		}
+		return startf.DefaultModuleLoader(thread, module)
+	}
+}
+
+// transformLimits creates execution options that apply configured sandbox
+// limits to a transform script. a nil config applies no limits
+func transformLimits(cfg *config.Transform) []func(*startf.ExecOpts) {
+	if cfg == nil {
+		return nil
+	}
+	return []func(*startf.ExecOpts){
+		startf.SetMaxSteps(cfg.MaxSteps),
+		startf.SetTimeout(time.Duration(cfg.TimeoutMs) * time.Millisecond),
+		startf.SetMaxBodySize(cfg.MaxBodySize),
+		startf.SetAllowedHosts(cfg.AllowedHosts...),
+	}
+}
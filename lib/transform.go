@@ -0,0 +1,56 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/qri-io/qri/startf"
+)
+
+// TransformMethods encapsulates business logic for working with transform
+// scripts
+type TransformMethods struct {
+	inst *Instance
+}
+
+// NewTransformMethods creates TransformMethods from a qri Instance
+func NewTransformMethods(inst *Instance) *TransformMethods {
+	return &TransformMethods{inst: inst}
+}
+
+// CoreRequestsName implements the requests interface
+func (m TransformMethods) CoreRequestsName() string { return "transform" }
+
+// TestTransformParams defines parameters for the Test method
+type TestTransformParams struct {
+	// path to the transform script to test
+	ScriptPath string
+	// make http requests over the network, recording responses as fixtures
+	Record bool
+}
+
+// Test runs a transform script against fixtures, comparing the result to
+// expected dataset components & running starlark test functions
+func (m *TransformMethods) Test(p *TestTransformParams, res *startf.TestResults) error {
+	return m.inst.dispatch(m.Test, p, res, func() error { return m.test(p, res) })
+}
+
+func (m *TransformMethods) test(p *TestTransformParams, res *startf.TestResults) error {
+	if p == nil || p.ScriptPath == "" {
+		return fmt.Errorf("script path is required")
+	}
+	ctx := context.TODO()
+
+	path, err := filepath.Abs(p.ScriptPath)
+	if err != nil {
+		return err
+	}
+
+	r, err := startf.RunTest(ctx, path, p.Record)
+	if err != nil {
+		return err
+	}
+	*res = *r
+	return nil
+}
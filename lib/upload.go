@@ -0,0 +1,150 @@
+package lib
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/upload"
+)
+
+// UploadMethods encapsulates business logic for resumable uploads. Large
+// bodies are uploaded in chunks, then saved by passing the upload ID to
+// DatasetMethods.Save
+type UploadMethods struct {
+	inst *Instance
+}
+
+// NewUploadMethods creates UploadMethods from a qri Instance
+func NewUploadMethods(inst *Instance) *UploadMethods {
+	return &UploadMethods{inst: inst}
+}
+
+// CoreRequestsName implements the Methods interface
+func (m UploadMethods) CoreRequestsName() string { return "upload" }
+
+// ErrNoUploads indicates the instance has no upload store
+var ErrNoUploads = fmt.Errorf("uploads are not available on this node")
+
+// CreateUploadParams defines parameters for starting an upload
+type CreateUploadParams struct {
+	// Filename of the file being uploaded, the extension is used to detect
+	// the body format
+	Filename string `json:"filename"`
+	// Size of the complete file in bytes
+	Size int64 `json:"size"`
+	// SHA256 is the hex-encoded checksum of the complete file. The upload
+	// can't be saved if the received bytes don't match. optional
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// Create starts an upload session
+func (m *UploadMethods) Create(p *CreateUploadParams, res *upload.Session) error {
+	return m.inst.dispatch(m.Create, p, res, func() error { return m.create(p, res) })
+}
+
+func (m *UploadMethods) create(p *CreateUploadParams, res *upload.Session) error {
+	if m.inst.uploads == nil {
+		return ErrNoUploads
+	}
+	if p.Filename == "" {
+		return fmt.Errorf("filename is required")
+	}
+
+	s, err := m.inst.uploads.Create(p.Filename, p.Size, p.SHA256)
+	if err != nil {
+		return err
+	}
+	*res = *s
+	return nil
+}
+
+// Status gets an upload session. Clients resuming an interrupted upload send
+// their next chunk starting at the session offset
+func (m *UploadMethods) Status(id *string, res *upload.Session) error {
+	return m.inst.dispatch(m.Status, id, res, func() error { return m.status(id, res) })
+}
+
+func (m *UploadMethods) status(id *string, res *upload.Session) error {
+	if m.inst.uploads == nil {
+		return ErrNoUploads
+	}
+
+	s, err := m.inst.uploads.Get(*id)
+	if err != nil {
+		return err
+	}
+	*res = *s
+	return nil
+}
+
+// UploadChunkParams defines parameters for adding a chunk to an upload
+type UploadChunkParams struct {
+	// ID of the upload session
+	ID string
+	// Offset the chunk starts at, must equal the session offset
+	Offset int64
+	// SHA256 is the hex-encoded checksum of Data. optional
+	SHA256 string
+	// Data is the chunk content
+	Data []byte
+}
+
+// WriteChunk appends a chunk to an upload
+func (m *UploadMethods) WriteChunk(p *UploadChunkParams, res *upload.Session) error {
+	return m.inst.dispatch(m.WriteChunk, p, res, func() error { return m.writeChunk(p, res) })
+}
+
+func (m *UploadMethods) writeChunk(p *UploadChunkParams, res *upload.Session) error {
+	if m.inst.uploads == nil {
+		return ErrNoUploads
+	}
+
+	s, err := m.inst.uploads.WriteChunk(p.ID, p.Offset, bytes.NewReader(p.Data), p.SHA256)
+	if s != nil {
+		*res = *s
+	}
+	return err
+}
+
+// Cancel removes an upload session & the bytes it has received
+func (m *UploadMethods) Cancel(id *string, res *bool) error {
+	return m.inst.dispatch(m.Cancel, id, res, func() error { return m.cancel(id, res) })
+}
+
+func (m *UploadMethods) cancel(id *string, res *bool) error {
+	if m.inst.uploads == nil {
+		return ErrNoUploads
+	}
+
+	if err := m.inst.uploads.Remove(*id); err != nil {
+		return err
+	}
+	*res = true
+	return nil
+}
+
+// maxUploadSize reads the configured upload size limit, 0 uses the upload
+// store default
+func maxUploadSize(cfg *config.Config) int64 {
+	if cfg == nil || cfg.API == nil {
+		return 0
+	}
+	return cfg.API.MaxUploadSize
+}
+
+// finalizeUpload checks an upload is complete, returning the path to its
+// file & the name it was uploaded with
+func (inst *Instance) finalizeUpload(id string) (path, filename string, err error) {
+	if inst.uploads == nil {
+		return "", "", ErrNoUploads
+	}
+	s, err := inst.uploads.Get(id)
+	if err != nil {
+		return "", "", err
+	}
+	if path, err = inst.uploads.Finalize(id); err != nil {
+		return "", "", err
+	}
+	return path, s.Filename, nil
+}
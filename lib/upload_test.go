@@ -0,0 +1,60 @@
+package lib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/upload"
+)
+
+func TestUploadSave(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	m := NewUploadMethods(tr.Instance)
+	body := []byte("city,pop\ntoronto,40000000\nnew york,8500000\n")
+	sum := sha256.Sum256(body)
+
+	s := &upload.Session{}
+	if err := m.Create(&CreateUploadParams{Filename: "cities.csv", Size: int64(len(body)), SHA256: hex.EncodeToString(sum[:])}, s); err != nil {
+		t.Fatal(err)
+	}
+
+	first := body[:12]
+	if err := m.WriteChunk(&UploadChunkParams{ID: s.ID, Offset: 0, Data: first}, s); err != nil {
+		t.Fatal(err)
+	}
+
+	// saving an incomplete upload fails, leaving the upload in place
+	res := &dataset.Dataset{}
+	dsm := NewDatasetMethods(tr.Instance)
+	err := dsm.Save(&SaveParams{Ref: "peer/cities", UploadID: s.ID}, res)
+	if !errors.Is(err, upload.ErrIncomplete) {
+		t.Fatalf("expected saving an incomplete upload to return ErrIncomplete, got: %v", err)
+	}
+
+	id := s.ID
+	if err := m.Status(&id, s); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.WriteChunk(&UploadChunkParams{ID: s.ID, Offset: s.Offset, Data: body[s.Offset:]}, s); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := dsm.Save(&SaveParams{Ref: "peer/cities", UploadID: s.ID}, res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Structure == nil || res.Structure.Format != "csv" {
+		t.Errorf("expected upload to be saved as a csv body. got structure: %v", res.Structure)
+	}
+	if res.Structure.Entries != 2 {
+		t.Errorf("expected 2 entries, got %d", res.Structure.Entries)
+	}
+
+	if err := m.Status(&id, s); !errors.Is(err, upload.ErrNotFound) {
+		t.Errorf("expected saved upload to be removed, got: %v", err)
+	}
+}
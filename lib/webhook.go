@@ -0,0 +1,72 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/qri-io/qri/webhook"
+)
+
+// WebhookMethods encapsulates business logic for webhook delivery
+type WebhookMethods struct {
+	inst *Instance
+}
+
+// NewWebhookMethods creates WebhookMethods from a qri Instance
+func NewWebhookMethods(inst *Instance) *WebhookMethods {
+	return &WebhookMethods{inst: inst}
+}
+
+// CoreRequestsName implements the Methods interface
+func (m WebhookMethods) CoreRequestsName() string { return "webhook" }
+
+// WebhookTestParams are parameters for sending a test delivery
+type WebhookTestParams struct {
+	// URL of the webhook to test. empty tests all configured webhooks
+	URL string
+}
+
+// Test sends a test payload to configured webhooks, reporting the outcome of
+// each delivery
+func (m *WebhookMethods) Test(p *WebhookTestParams, res *[]webhook.Delivery) error {
+	return m.inst.dispatch(m.Test, p, res, func() error { return m.test(p, res) })
+}
+
+func (m *WebhookMethods) test(p *WebhookTestParams, res *[]webhook.Delivery) error {
+	if m.inst.webhooks == nil {
+		return fmt.Errorf("webhooks are not available")
+	}
+	ctx := context.TODO()
+
+	dels, err := m.inst.webhooks.Test(ctx, p.URL)
+	if err != nil {
+		return err
+	}
+	*res = dels
+	return nil
+}
+
+// WebhookLogParams are parameters for reading the webhook delivery log
+type WebhookLogParams struct {
+	// Limit is the number of most recent deliveries to return. zero returns
+	// all deliveries
+	Limit int
+}
+
+// Log lists recorded webhook deliveries, oldest first
+func (m *WebhookMethods) Log(p *WebhookLogParams, res *[]webhook.Delivery) error {
+	return m.inst.dispatch(m.Log, p, res, func() error { return m.log(p, res) })
+}
+
+func (m *WebhookMethods) log(p *WebhookLogParams, res *[]webhook.Delivery) error {
+	if m.inst.webhooks == nil {
+		return fmt.Errorf("webhooks are not available")
+	}
+
+	dels, err := m.inst.webhooks.Deliveries(p.Limit)
+	if err != nil {
+		return err
+	}
+	*res = dels
+	return nil
+}
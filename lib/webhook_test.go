@@ -0,0 +1,61 @@
+package lib
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/p2p"
+	testrepo "github.com/qri-io/qri/repo/test"
+	"github.com/qri-io/qri/webhook"
+)
+
+func TestWebhookMethods(t *testing.T) {
+	ctx, done := context.WithCancel(context.Background())
+	defer done()
+
+	var gotType string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotType = r.Header.Get(webhook.EventHeader)
+	}))
+	defer s.Close()
+
+	cfg := config.DefaultConfigForTesting()
+	cfg.Webhooks = config.Webhooks{{URL: s.URL}}
+	mr, err := testrepo.NewTestRepo()
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err)
+	}
+	node, err := p2p.NewQriNode(mr, cfg.P2P, event.NilBus, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inst := NewInstanceFromConfigAndNodeAndBus(ctx, cfg, node, event.NewBus(ctx))
+	m := NewWebhookMethods(inst)
+
+	dels := []webhook.Delivery{}
+	if err := m.Test(&WebhookTestParams{URL: "https://example.com"}, &dels); err == nil {
+		t.Error("expected testing an unconfigured webhook to error")
+	}
+	if err := m.Test(&WebhookTestParams{}, &dels); err != nil {
+		t.Fatal(err)
+	}
+	if len(dels) != 1 || !dels[0].Succeeded() {
+		t.Errorf("expected one successful delivery, got: %#v", dels)
+	}
+	if gotType != string(webhook.ETTest) {
+		t.Errorf("event header mismatch. want %q, got %q", webhook.ETTest, gotType)
+	}
+
+	logged := []webhook.Delivery{}
+	if err := m.Log(&WebhookLogParams{}, &logged); err != nil {
+		t.Fatal(err)
+	}
+	if len(logged) != 1 || logged[0].ID != dels[0].ID {
+		t.Errorf("expected test delivery to be logged, got: %#v", logged)
+	}
+}
@@ -2,12 +2,16 @@ package lib
 
 import (
 	"context"
-
+	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 
 	ma "github.com/multiformats/go-multiaddr"
 	manet "github.com/multiformats/go-multiaddr-net"
+	"github.com/qri-io/qri/access"
 	"github.com/qri-io/qri/event"
 	"github.com/qri-io/qri/watchfs"
 	"nhooyr.io/websocket"
@@ -16,8 +20,62 @@ import (
 
 const qriWebsocketProtocol = "qri-websocket"
 
+const (
+	// wsMsgSubscribe is sent by clients to set the event types & datasets they
+	// want to receive, optionally requesting a replay of missed events
+	wsMsgSubscribe = "subscribe"
+	// wsMsgSubscribed is sent to a client once a subscription is active. data is
+	// a wsSubscribedData
+	wsMsgSubscribed = "ws:Subscribed"
+	// wsMsgError is sent to a client when a message cannot be handled. data is
+	// an error string
+	wsMsgError = "ws:Error"
+)
+
+var (
+	// WebsocketReplaySize is the number of recent events a websocket server
+	// keeps for replaying to reconnecting clients
+	WebsocketReplaySize = 1000
+	// websocketClientQueueSize is the number of events that can be waiting to
+	// send to a single client before the client is disconnected
+	websocketClientQueueSize = 256
+)
+
+// websocketEventTypes is the set of bus events sent to websocket clients
+var websocketEventTypes = []event.Type{
+	event.ETDatasetNameInit,
+	event.ETDatasetCommitChange,
+	event.ETDatasetDeleteAll,
+	event.ETDatasetRename,
+	event.ETDatasetCreateLink,
+	event.ETFSICreateLinkEvent,
+	event.ETCreatedNewFile,
+	event.ETModifiedFile,
+	event.ETDeletedFile,
+	event.ETRenamedFolder,
+	event.ETRemovedFolder,
+	event.ETRemoteClientPushVersionProgress,
+	event.ETRemoteClientPushVersionCompleted,
+	event.ETRemoteClientPushDatasetCompleted,
+	event.ETRemoteClientPullVersionProgress,
+	event.ETRemoteClientPullVersionCompleted,
+	event.ETRemoteClientPullDatasetCompleted,
+	event.ETRemoteClientRemoveDatasetCompleted,
+}
+
 // ServeWebsocket creates a websocket that clients can connect to in order to
-// get realtime events
+// get realtime events.
+//
+// Clients must authenticate with an access token created by
+// AccessMethods.CreateAuthToken, supplied either as a "token" query param or
+// an "Authorization: Bearer" header. Once connected a client receives no
+// events until it sends a subscribe message:
+//
+//	{"type": "subscribe", "events": ["dataset:CommitChange"], "datasets": ["peer/ds"], "since": 12}
+//
+// empty "events" or "datasets" lists match all events. Every event sent to
+// clients carries a sequence number, clients that reconnect can set "since" to
+// the last sequence number they saw to replay missed events
 func (inst *Instance) ServeWebsocket(ctx context.Context) {
 	apiCfg := inst.cfg.API
 
@@ -46,59 +104,16 @@ func (inst *Instance) ServeWebsocket(ctx context.Context) {
 	l := manet.NetListener(mal)
 	defer l.Close()
 
-	// Collect all websocket connections
-	connections := []*websocket.Conn{}
+	hub := newWebsocketHub(inst.tokens, originPatterns(apiCfg.AllowedOrigins), WebsocketReplaySize)
 	srv := &http.Server{
-		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			c, err := websocket.Accept(w, r, &websocket.AcceptOptions{
-				Subprotocols:       []string{qriWebsocketProtocol},
-				InsecureSkipVerify: true,
-			})
-			if err != nil {
-				log.Debugf("Websocket accept error: %s", err)
-				return
-			}
-			connections = append(connections, c)
-		}),
+		Handler:      hub,
 		ReadTimeout:  time.Second * 15,
 		WriteTimeout: time.Second * 15,
 	}
 	defer srv.Close()
 
-	handler := func(_ context.Context, t event.Type, payload interface{}) error {
-		ctx := context.Background()
-		evt := map[string]interface{}{
-			"type": string(t),
-			"data": payload,
-		}
-
-		log.Debugf("sending event %q to %d websocket conns", t, len(connections))
-		for k, c := range connections {
-			go func(k int, c *websocket.Conn) {
-				err := wsjson.Write(ctx, c, evt)
-				if err != nil {
-					log.Errorf("connection %d: wsjson write error: %s", k, err)
-				}
-			}(k, c)
-		}
-		return nil
-	}
-
-	inst.bus.Subscribe(handler,
-		event.ETFSICreateLinkEvent,
-		event.ETCreatedNewFile,
-		event.ETModifiedFile,
-		event.ETDeletedFile,
-		event.ETRenamedFolder,
-		event.ETRemovedFolder,
-		event.ETRemoteClientPushVersionProgress,
-		event.ETRemoteClientPushVersionCompleted,
-		event.ETRemoteClientPushDatasetCompleted,
-		event.ETRemoteClientPullVersionProgress,
-		event.ETRemoteClientPullVersionCompleted,
-		event.ETRemoteClientPullDatasetCompleted,
-		event.ETRemoteClientRemoveDatasetCompleted,
-	)
+	sub := inst.bus.Subscribe(hub.handleEvent, websocketEventTypes...)
+	defer sub.Unsubscribe()
 
 	// Start http server for websocket.
 	go func() {
@@ -109,6 +124,7 @@ func (inst *Instance) ServeWebsocket(ctx context.Context) {
 	}()
 
 	<-ctx.Done()
+	hub.closeAll()
 
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
@@ -117,3 +133,290 @@ func (inst *Instance) ServeWebsocket(ctx context.Context) {
 		log.Error(err)
 	}
 }
+
+// originPatterns converts a list of allowed CORS origins to host patterns for
+// websocket origin verification
+func originPatterns(origins []string) []string {
+	patterns := make([]string, 0, len(origins))
+	for _, o := range origins {
+		if u, err := url.Parse(o); err == nil && u.Host != "" {
+			patterns = append(patterns, u.Host)
+		}
+	}
+	return patterns
+}
+
+// wsEvent is a bus event sent to websocket clients
+type wsEvent struct {
+	Type event.Type  `json:"type"`
+	Seq  uint64      `json:"seq,omitempty"`
+	Ts   int64       `json:"ts,omitempty"`
+	Data interface{} `json:"data"`
+
+	// dataset is the "username/name" the event concerns, if any
+	dataset string
+}
+
+// wsSubscribeMsg is a subscription request sent by a websocket client
+type wsSubscribeMsg struct {
+	Type     string       `json:"type"`
+	Events   []event.Type `json:"events"`
+	Datasets []string     `json:"datasets"`
+	Since    uint64       `json:"since"`
+}
+
+// wsSubscribedData is the payload of a subscribed confirmation message
+type wsSubscribedData struct {
+	// Seq is the sequence number of the most recent event
+	Seq uint64 `json:"seq"`
+	// ReplayComplete is false when the requested replay starts before the
+	// oldest event the server holds. Events have been missed
+	ReplayComplete bool `json:"replayComplete"`
+}
+
+// websocketHub tracks connected clients & recent events
+type websocketHub struct {
+	tokens         access.TokenSource
+	originPatterns []string
+	replaySize     int
+
+	lk      sync.Mutex
+	seq     uint64
+	recent  []wsEvent
+	clients map[*wsClient]struct{}
+}
+
+func newWebsocketHub(tokens access.TokenSource, originPatterns []string, replaySize int) *websocketHub {
+	return &websocketHub{
+		tokens:         tokens,
+		originPatterns: originPatterns,
+		replaySize:     replaySize,
+		clients:        map[*wsClient]struct{}{},
+	}
+}
+
+// handleEvent is an event.Handler that records & fans out bus events
+func (h *websocketHub) handleEvent(_ context.Context, t event.Type, payload interface{}) error {
+	h.lk.Lock()
+	defer h.lk.Unlock()
+
+	h.seq++
+	evt := wsEvent{
+		Type:    t,
+		Seq:     h.seq,
+		Ts:      time.Now().UnixNano(),
+		Data:    payload,
+		dataset: event.DatasetName(payload),
+	}
+
+	h.recent = append(h.recent, evt)
+	if len(h.recent) > h.replaySize {
+		h.recent = h.recent[len(h.recent)-h.replaySize:]
+	}
+
+	for c := range h.clients {
+		if c.matches(evt) {
+			c.send(evt)
+		}
+	}
+	return nil
+}
+
+// ServeHTTP authenticates & accepts websocket connections
+func (h *websocketHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := h.authenticate(r); err != nil {
+		log.Debugf("websocket authentication: %s", err)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{
+		Subprotocols:   []string{qriWebsocketProtocol},
+		OriginPatterns: h.originPatterns,
+	})
+	if err != nil {
+		log.Debugf("Websocket accept error: %s", err)
+		return
+	}
+
+	c := &wsClient{
+		conn:  conn,
+		queue: make(chan wsEvent, websocketClientQueueSize+h.replaySize),
+		done:  make(chan struct{}),
+	}
+	h.lk.Lock()
+	h.clients[c] = struct{}{}
+	h.lk.Unlock()
+
+	go c.writeLoop()
+	h.readLoop(r.Context(), c)
+
+	h.lk.Lock()
+	delete(h.clients, c)
+	h.lk.Unlock()
+	c.close(websocket.StatusNormalClosure, "")
+}
+
+func (h *websocketHub) authenticate(r *http.Request) error {
+	if h.tokens == nil {
+		return fmt.Errorf("websocket authentication is not available")
+	}
+
+	raw := r.URL.Query().Get("token")
+	if raw == "" {
+		raw = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	if raw == "" {
+		return fmt.Errorf("access token is required")
+	}
+
+	tok, err := access.ParseToken(raw, h.tokens)
+	if err != nil {
+		return fmt.Errorf("%w: %s", access.ErrInvalidToken, err)
+	}
+	if !tok.Valid {
+		return access.ErrInvalidToken
+	}
+	return nil
+}
+
+// readLoop handles client messages until the connection closes
+func (h *websocketHub) readLoop(ctx context.Context, c *wsClient) {
+	for {
+		msg := wsSubscribeMsg{}
+		if err := wsjson.Read(ctx, c.conn, &msg); err != nil {
+			log.Debugf("websocket read: %s", err)
+			return
+		}
+
+		if msg.Type != wsMsgSubscribe {
+			c.send(wsEvent{Type: wsMsgError, Data: fmt.Sprintf("unknown message type %q", msg.Type)})
+			continue
+		}
+		h.subscribe(c, msg)
+	}
+}
+
+// subscribe sets a client's filter & queues any replayed events. holding the
+// hub lock while queuing guarantees replayed events arrive before live ones
+func (h *websocketHub) subscribe(c *wsClient, msg wsSubscribeMsg) {
+	h.lk.Lock()
+	defer h.lk.Unlock()
+
+	c.setFilter(msg.Events, msg.Datasets)
+
+	complete := true
+	if msg.Since > 0 {
+		// a since value ahead of the current sequence means the server has
+		// restarted since the client last connected
+		if msg.Since > h.seq || (len(h.recent) > 0 && h.recent[0].Seq > msg.Since+1) {
+			complete = false
+		}
+		for _, evt := range h.recent {
+			if evt.Seq > msg.Since && c.matches(evt) {
+				c.send(evt)
+			}
+		}
+	}
+
+	c.send(wsEvent{
+		Type: wsMsgSubscribed,
+		Data: wsSubscribedData{Seq: h.seq, ReplayComplete: complete},
+	})
+}
+
+func (h *websocketHub) closeAll() {
+	h.lk.Lock()
+	clients := make([]*wsClient, 0, len(h.clients))
+	for c := range h.clients {
+		clients = append(clients, c)
+	}
+	h.lk.Unlock()
+
+	for _, c := range clients {
+		c.close(websocket.StatusGoingAway, "server shutting down")
+	}
+}
+
+// wsClient is a single websocket connection
+type wsClient struct {
+	conn *websocket.Conn
+
+	filterLk   sync.Mutex
+	subscribed bool
+	types      map[event.Type]bool
+	datasets   map[string]bool
+
+	queue     chan wsEvent
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (c *wsClient) setFilter(types []event.Type, datasets []string) {
+	c.filterLk.Lock()
+	defer c.filterLk.Unlock()
+
+	c.subscribed = true
+	c.types = map[event.Type]bool{}
+	for _, t := range types {
+		c.types[t] = true
+	}
+	c.datasets = map[string]bool{}
+	for _, ds := range datasets {
+		c.datasets[ds] = true
+	}
+}
+
+// matches returns true if an event passes the client subscription filter
+func (c *wsClient) matches(evt wsEvent) bool {
+	c.filterLk.Lock()
+	defer c.filterLk.Unlock()
+
+	if !c.subscribed {
+		return false
+	}
+	if len(c.types) > 0 && !c.types[evt.Type] {
+		return false
+	}
+	if len(c.datasets) > 0 && !c.datasets[evt.dataset] {
+		return false
+	}
+	return true
+}
+
+// send queues an event without blocking. clients that can't keep up are
+// disconnected, and can reconnect to replay missed events
+func (c *wsClient) send(evt wsEvent) {
+	select {
+	case <-c.done:
+	case c.queue <- evt:
+	default:
+		log.Debugf("websocket client queue full, disconnecting")
+		go c.close(websocket.StatusPolicyViolation, "client too slow")
+	}
+}
+
+func (c *wsClient) writeLoop() {
+	for {
+		select {
+		case <-c.done:
+			return
+		case evt := <-c.queue:
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*15)
+			err := wsjson.Write(ctx, c.conn, evt)
+			cancel()
+			if err != nil {
+				log.Debugf("websocket write error: %s", err)
+				c.close(websocket.StatusInternalError, "write failed")
+				return
+			}
+		}
+	}
+}
+
+func (c *wsClient) close(code websocket.StatusCode, reason string) {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.conn.Close(code, reason)
+	})
+}
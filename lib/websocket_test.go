@@ -2,11 +2,18 @@ package lib
 
 import (
 	"context"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/access"
 	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/event"
 	repotest "github.com/qri-io/qri/repo/test"
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
 )
 
 func TestWebsocket(t *testing.T) {
@@ -41,3 +48,99 @@ func TestWebsocket(t *testing.T) {
 	wsCancel()
 	<-done
 }
+
+func TestWebsocketHub(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tokens, err := access.NewPrivKeyTokenSource(privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tok, err := tokens.CreateToken(testPeerProfile, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hub := newWebsocketHub(tokens, nil, 3)
+	s := httptest.NewServer(hub)
+	defer s.Close()
+	wsURL := "ws" + strings.TrimPrefix(s.URL, "http")
+
+	if _, _, err := websocket.Dial(ctx, wsURL, nil); err == nil {
+		t.Error("expected connecting without a token to fail")
+	}
+	if _, _, err := websocket.Dial(ctx, wsURL+"?token=not_a_token", nil); err == nil {
+		t.Error("expected connecting with an invalid token to fail")
+	}
+
+	commit := func(name string) {
+		hub.handleEvent(ctx, event.ETDatasetCommitChange, event.DsChange{Username: "peer", PrettyName: name})
+	}
+	commit("one")
+	commit("two")
+
+	conn, _, err := websocket.Dial(ctx, wsURL+"?token="+tok, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(websocket.StatusNormalClosure, "")
+
+	read := func() map[string]interface{} {
+		t.Helper()
+		readCtx, readCancel := context.WithTimeout(ctx, time.Second*2)
+		defer readCancel()
+		msg := map[string]interface{}{}
+		if err := wsjson.Read(readCtx, conn, &msg); err != nil {
+			t.Fatal(err)
+		}
+		return msg
+	}
+
+	// replay events after sequence number 1, only for the "one" & "three" datasets
+	sub := wsSubscribeMsg{
+		Type:     wsMsgSubscribe,
+		Events:   []event.Type{event.ETDatasetCommitChange},
+		Datasets: []string{"peer/two", "peer/three"},
+		Since:    1,
+	}
+	if err := wsjson.Write(ctx, conn, sub); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := read()
+	if msg["type"] != string(event.ETDatasetCommitChange) || msg["seq"] != float64(2) {
+		t.Errorf("expected replay of event 2. got: %v", msg)
+	}
+	msg = read()
+	if msg["type"] != wsMsgSubscribed {
+		t.Fatalf("expected subscribed message. got: %v", msg)
+	}
+	if data, _ := msg["data"].(map[string]interface{}); data["replayComplete"] != true {
+		t.Errorf("expected complete replay. got: %v", msg)
+	}
+
+	// filtered out by dataset & by type
+	commit("one")
+	hub.handleEvent(ctx, event.ETDatasetDeleteAll, event.DsChange{Username: "peer", PrettyName: "three"})
+	commit("three")
+
+	msg = read()
+	if msg["seq"] != float64(5) {
+		t.Errorf("expected live event 5. got: %v", msg)
+	}
+
+	// seq 1 has fallen out of the replay buffer
+	sub.Since = 1
+	if err := wsjson.Write(ctx, conn, sub); err != nil {
+		t.Fatal(err)
+	}
+	msg = read()
+	if msg["seq"] != float64(5) {
+		t.Errorf("expected replay of event 5. got: %v", msg)
+	}
+	msg = read()
+	if data, _ := msg["data"].(map[string]interface{}); data["replayComplete"] != false {
+		t.Errorf("expected incomplete replay. got: %v", msg)
+	}
+}
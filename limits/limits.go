@@ -0,0 +1,217 @@
+// Package limits implements rate limits & daily quotas, keyed by a string
+// like a profile ID or an IP address
+package limits
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrQuotaExceeded indicates a key has used up its quota for the day
+	ErrQuotaExceeded = errors.New("quota exceeded")
+
+	// nowFunc is replaced in tests
+	nowFunc = time.Now
+)
+
+// idleBuckets is how long a full bucket goes unused before it's dropped
+const idleBuckets = 10 * time.Minute
+
+// Limiter is a set of token buckets, one per key. Each key can make burst
+// requests at once, refilling at a steady rate per minute. A nil Limiter
+// allows everything
+type Limiter struct {
+	perSecond float64
+	burst     float64
+
+	lk      sync.Mutex
+	buckets map[string]*bucket
+	pruned  time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewLimiter creates a limiter allowing perMinute requests per key, up to
+// burst at once. burst defaults to perMinute. Returns nil when perMinute is
+// zero or less
+func NewLimiter(perMinute, burst int) *Limiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = perMinute
+	}
+	return &Limiter{
+		perSecond: float64(perMinute) / 60,
+		burst:     float64(burst),
+		buckets:   map[string]*bucket{},
+		pruned:    nowFunc(),
+	}
+}
+
+// Allow takes a token for key, returning false & the time until the next
+// token is available if the bucket is empty
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+	l.lk.Lock()
+	defer l.lk.Unlock()
+
+	now := nowFunc()
+	l.prune(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, updated: now}
+		l.buckets[key] = b
+	}
+	b.tokens += now.Sub(b.updated).Seconds() * l.perSecond
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.updated = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.perSecond * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// prune drops buckets that would be full, they're the same as a new bucket.
+// must be called with the lock held
+func (l *Limiter) prune(now time.Time) {
+	if now.Sub(l.pruned) < idleBuckets {
+		return
+	}
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*l.perSecond >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+	l.pruned = now
+}
+
+// Quota tracks bytes used per key each day, resetting at midnight UTC. A nil
+// Quota is unlimited
+type Quota struct {
+	max int64
+	// path is the file usage is saved to, empty for in-memory quotas
+	path string
+
+	lk   sync.Mutex
+	day  string
+	used map[string]int64
+}
+
+// quotaFile is the saved state of a quota
+type quotaFile struct {
+	Day  string           `json:"day"`
+	Used map[string]int64 `json:"used"`
+}
+
+// NewQuota creates a quota allowing max bytes per key per day. Returns nil
+// when max is zero or less
+func NewQuota(max int64) *Quota {
+	if max <= 0 {
+		return nil
+	}
+	return &Quota{max: max, used: map[string]int64{}}
+}
+
+// NewFileQuota creates a quota that saves usage to a file at path, so usage
+// survives restarts. Usage already saved at path is loaded. Returns nil when
+// max is zero or less
+func NewFileQuota(max int64, path string) (*Quota, error) {
+	q := NewQuota(max)
+	if q == nil {
+		return nil, nil
+	}
+	q.path = path
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return q, nil
+	} else if err != nil {
+		return nil, err
+	}
+	f := quotaFile{}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("reading quota %s: %w", path, err)
+	}
+	q.day = f.Day
+	if f.Used != nil {
+		q.used = f.Used
+	}
+	return q, nil
+}
+
+// Use adds n bytes to the usage of key, returning ErrQuotaExceeded without
+// recording the usage if it would go over the quota
+func (q *Quota) Use(key string, n int64) error {
+	if q == nil {
+		return nil
+	}
+	q.lk.Lock()
+	defer q.lk.Unlock()
+
+	q.reset()
+	if q.used[key]+n > q.max {
+		return fmt.Errorf("%w: %d of %d bytes used today", ErrQuotaExceeded, q.used[key], q.max)
+	}
+	q.used[key] += n
+	if err := q.save(); err != nil {
+		q.used[key] -= n
+		return fmt.Errorf("saving quota: %w", err)
+	}
+	return nil
+}
+
+// Remaining returns the number of bytes key can use until the quota resets
+func (q *Quota) Remaining(key string) int64 {
+	if q == nil {
+		return -1
+	}
+	q.lk.Lock()
+	defer q.lk.Unlock()
+
+	q.reset()
+	return q.max - q.used[key]
+}
+
+// reset clears usage when the day changes. must be called with the lock held
+func (q *Quota) reset() {
+	day := nowFunc().UTC().Format("2006-01-02")
+	if day != q.day {
+		q.day = day
+		q.used = map[string]int64{}
+	}
+}
+
+// save writes usage to the quota file, if the quota has one. must be called
+// with the lock held
+func (q *Quota) save() error {
+	if q.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(quotaFile{Day: q.day, Used: q.used})
+	if err != nil {
+		return err
+	}
+	// write to a temp file & rename so a crash can't leave a partial file
+	if err := ioutil.WriteFile(q.path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(q.path+".tmp", q.path)
+}
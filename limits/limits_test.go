@@ -0,0 +1,120 @@
+package limits
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	nowFunc = func() time.Time { return now }
+	defer func() { nowFunc = time.Now }()
+
+	l := NewLimiter(60, 2)
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.Allow("a"); !ok {
+			t.Fatalf("request %d: expected burst to be allowed", i)
+		}
+	}
+	ok, wait := l.Allow("a")
+	if ok {
+		t.Fatal("expected request past burst to be limited")
+	}
+	if wait != time.Second {
+		t.Errorf("expected wait of 1s, got %s", wait)
+	}
+	if ok, _ := l.Allow("b"); !ok {
+		t.Error("expected keys to be limited independently")
+	}
+
+	now = now.Add(time.Second)
+	if ok, _ := l.Allow("a"); !ok {
+		t.Error("expected a token to refill after a second")
+	}
+
+	// buckets refill to at most burst
+	now = now.Add(time.Hour)
+	for i := 0; i < 2; i++ {
+		l.Allow("a")
+	}
+	if ok, _ := l.Allow("a"); ok {
+		t.Error("expected bucket to refill to burst, not beyond")
+	}
+	if len(l.buckets) != 1 {
+		t.Errorf("expected idle buckets to be pruned, have %d", len(l.buckets))
+	}
+
+	none := NewLimiter(0, 0)
+	if ok, _ := none.Allow("a"); !ok {
+		t.Error("expected a nil limiter to allow everything")
+	}
+}
+
+func TestQuota(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	nowFunc = func() time.Time { return now }
+	defer func() { nowFunc = time.Now }()
+
+	q := NewQuota(100)
+	if err := q.Use("a", 60); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Use("a", 60); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected ErrQuotaExceeded, got: %v", err)
+	}
+	if rem := q.Remaining("a"); rem != 40 {
+		t.Errorf("expected rejected usage not to count. remaining: %d", rem)
+	}
+	if err := q.Use("b", 100); err != nil {
+		t.Errorf("expected keys to have separate quotas, got: %v", err)
+	}
+
+	now = now.Add(12 * time.Hour)
+	if rem := q.Remaining("a"); rem != 100 {
+		t.Errorf("expected quota to reset at midnight UTC. remaining: %d", rem)
+	}
+
+	if err := NewQuota(0).Use("a", 1<<40); err != nil {
+		t.Errorf("expected a nil quota to be unlimited, got: %v", err)
+	}
+}
+
+func TestFileQuota(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	nowFunc = func() time.Time { return now }
+	defer func() { nowFunc = time.Now }()
+
+	dir, err := ioutil.TempDir("", "limits_file_quota")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "quota.json")
+	q, err := NewFileQuota(100, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Use("a", 60); err != nil {
+		t.Fatal(err)
+	}
+
+	// usage survives reopening the quota
+	if q, err = NewFileQuota(100, path); err != nil {
+		t.Fatal(err)
+	}
+	if rem := q.Remaining("a"); rem != 40 {
+		t.Errorf("expected saved usage to be loaded. remaining: %d", rem)
+	}
+
+	now = now.Add(12 * time.Hour)
+	if q, err = NewFileQuota(100, path); err != nil {
+		t.Fatal(err)
+	}
+	if rem := q.Remaining("a"); rem != 100 {
+		t.Errorf("expected saved usage to reset at midnight UTC. remaining: %d", rem)
+	}
+}
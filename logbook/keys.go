@@ -0,0 +1,599 @@
+package logbook
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sync"
+	"time"
+
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/qri-io/qri/identity"
+	"github.com/qri-io/qri/logbook/oplog"
+)
+
+// AuthorKey is a public key that can sign logs on behalf of a profile. Each
+// profile has one primary key that authorizes all changes to its keys, and
+// any number of device keys
+type AuthorKey struct {
+	KeyID string `json:"keyID"`
+	// PubKey is the base64-encoded public key, empty for the key a profile was
+	// created with until it's rotated
+	PubKey string `json:"pubKey,omitempty"`
+	// Device names a device key, empty for primary keys
+	Device  string    `json:"device,omitempty"`
+	Primary bool      `json:"primary"`
+	Added   time.Time `json:"added"`
+	// Retired is the time a key was rotated out or revoked, zero for keys that
+	// are still in use
+	Retired time.Time `json:"retired,omitempty"`
+}
+
+// Active returns true if the key hasn't been rotated out or revoked
+func (k AuthorKey) Active() bool {
+	return k.Retired.IsZero()
+}
+
+// KeyHistory is the set of keys a profile has used, derived from the signed
+// key ops in the profile's user log
+type KeyHistory struct {
+	ProfileID string      `json:"profileID"`
+	Keys      []AuthorKey `json:"keys"`
+}
+
+// Key gets a key by ID
+func (h *KeyHistory) Key(keyID string) (AuthorKey, bool) {
+	for _, k := range h.Keys {
+		if k.KeyID == keyID {
+			return k, true
+		}
+	}
+	return AuthorKey{}, false
+}
+
+// Primary returns the active primary key
+func (h *KeyHistory) Primary() AuthorKey {
+	for _, k := range h.Keys {
+		if k.Primary && k.Active() {
+			return k
+		}
+	}
+	return AuthorKey{}
+}
+
+func (h *KeyHistory) retire(keyID string, t time.Time) {
+	for i, k := range h.Keys {
+		if k.KeyID == keyID && k.Active() {
+			h.Keys[i].Retired = t
+		}
+	}
+}
+
+// key ops are appended to a user log with KeyModel. Each op is signed by the
+// primary key that was active when the op was written. OpTypeInit adds a
+// device key named by the op's Name, OpTypeAmend rotates the primary key and
+// OpTypeRemove revokes a device key.
+// AuthorID is the signing keyID, Ref is the keyID the op is about, and
+// Relations holds the base64-encoded subject public key, signing public key
+// and signature, in that order. Device key additions carry a fourth relation,
+// the device key's signature from DeviceKeySignature, proving the device
+// holds the key it's adding
+const (
+	keyOpSubjectPub = iota
+	keyOpSignerPub
+	keyOpSignature
+	keyOpSubjectSignature
+)
+
+// KeyHistoryFromLog verifies the chain of key ops in a user log, returning the
+// resulting key history. A profile starts with the key it was created with as
+// its primary key, and every key op must be signed by the active primary key
+func KeyHistoryFromLog(l *oplog.Log) (*KeyHistory, error) {
+	if l == nil || len(l.Ops) == 0 || l.Model() != AuthorModel {
+		return nil, fmt.Errorf("logbook: key history requires a user log")
+	}
+	init := l.Ops[0]
+	h := &KeyHistory{
+		ProfileID: init.AuthorID,
+		Keys: []AuthorKey{{
+			KeyID:   init.AuthorID,
+			Primary: true,
+			Added:   time.Unix(0, init.Timestamp),
+		}},
+	}
+
+	for _, op := range l.Ops {
+		if op.Model != KeyModel {
+			continue
+		}
+		if err := verifyKeyOp(h, op); err != nil {
+			return nil, err
+		}
+
+		t := time.Unix(0, op.Timestamp)
+		switch op.Type {
+		case oplog.OpTypeInit:
+			h.Keys = append(h.Keys, AuthorKey{
+				KeyID:  op.Ref,
+				PubKey: op.Relations[keyOpSubjectPub],
+				Device: op.Name,
+				Added:  t,
+			})
+		case oplog.OpTypeAmend:
+			for i, k := range h.Keys {
+				if k.KeyID == op.AuthorID && k.PubKey == "" {
+					h.Keys[i].PubKey = op.Relations[keyOpSignerPub]
+				}
+			}
+			h.retire(op.AuthorID, t)
+			h.Keys = append(h.Keys, AuthorKey{
+				KeyID:   op.Ref,
+				PubKey:  op.Relations[keyOpSubjectPub],
+				Primary: true,
+				Added:   t,
+			})
+		case oplog.OpTypeRemove:
+			h.retire(op.Ref, t)
+		}
+	}
+	return h, nil
+}
+
+// verifyKeyOp checks a key op is signed by the active primary key of a
+// history, and that device key additions are co-signed by the device
+func verifyKeyOp(h *KeyHistory, op oplog.Op) error {
+	if op.Type == oplog.OpTypeInit && len(op.Relations) != 4 {
+		return fmt.Errorf("%w: device key for %q not signed by the device", ErrAccessDenied, h.ProfileID)
+	} else if op.Type != oplog.OpTypeInit && len(op.Relations) != 3 {
+		return fmt.Errorf("logbook: invalid key op")
+	}
+	if op.AuthorID != h.Primary().KeyID {
+		return fmt.Errorf("%w: key op for %q not signed by the primary key", ErrAccessDenied, h.ProfileID)
+	}
+	signer, err := decodePubKey(op.Relations[keyOpSignerPub])
+	if err != nil {
+		return err
+	}
+	if id, err := identity.KeyIDFromPub(signer); err != nil || id != op.AuthorID {
+		return fmt.Errorf("logbook: key op signing key doesn't match keyID %q", op.AuthorID)
+	}
+	subject, err := decodePubKey(op.Relations[keyOpSubjectPub])
+	if err != nil {
+		return err
+	}
+	if id, err := identity.KeyIDFromPub(subject); err != nil || id != op.Ref {
+		return fmt.Errorf("logbook: key op public key doesn't match keyID %q", op.Ref)
+	}
+	sig, err := base64.StdEncoding.DecodeString(op.Relations[keyOpSignature])
+	if err != nil {
+		return err
+	}
+	ok, err := signer.Verify(keyOpSigningBytes(h.ProfileID, op), sig)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: invalid key op signature for %q", ErrAccessDenied, h.ProfileID)
+	}
+
+	if op.Type == oplog.OpTypeInit {
+		sig, err := base64.StdEncoding.DecodeString(op.Relations[keyOpSubjectSignature])
+		if err != nil {
+			return err
+		}
+		if ok, err := subject.Verify(deviceKeySigningBytes(h.ProfileID, op.Name, op.Ref), sig); err != nil || !ok {
+			return fmt.Errorf("%w: invalid device signature for key %q", ErrAccessDenied, op.Ref)
+		}
+	}
+	return nil
+}
+
+func keyOpSigningBytes(profileID string, op oplog.Op) []byte {
+	return []byte(fmt.Sprintf("%s|%d|%s|%s|%s|%d", profileID, op.Type, op.Name, op.Ref, op.Relations[keyOpSubjectPub], op.Timestamp))
+}
+
+func deviceKeySigningBytes(profileID, device, keyID string) []byte {
+	return []byte(fmt.Sprintf("%s|device|%s|%s", profileID, device, keyID))
+}
+
+// DeviceKeySignature signs a request for a device key to be added to a
+// profile, returning the base64-encoded signature. It's made on the device,
+// the profile's primary key passes it to WriteDeviceKeyAdd
+func DeviceKeySignature(profileID, device string, devicePk crypto.PrivKey) (string, error) {
+	keyID, err := identity.KeyIDFromPriv(devicePk)
+	if err != nil {
+		return "", err
+	}
+	sig, err := devicePk.Sign(deviceKeySigningBytes(profileID, device, keyID))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+func encodePubKey(pub crypto.PubKey) (string, error) {
+	data, err := crypto.MarshalPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+func decodePubKey(str string) (crypto.PubKey, error) {
+	data, err := base64.StdEncoding.DecodeString(str)
+	if err != nil {
+		return nil, fmt.Errorf("logbook: decoding public key: %w", err)
+	}
+	return crypto.UnmarshalPublicKey(data)
+}
+
+// writeKeyOp signs & appends a key op to the author's log. Only the primary
+// key can change the keys of a profile. subjectSig is the device's signature
+// for device key additions, and is ignored otherwise
+func (book *Book) writeKeyOp(ctx context.Context, opType oplog.OpType, name string, subject crypto.PubKey, subjectSig string) error {
+	authorLog, err := book.authorLog(ctx)
+	if err != nil {
+		return err
+	}
+	h, err := KeyHistoryFromLog(authorLog.l)
+	if err != nil {
+		return err
+	}
+	signerID, err := identity.KeyIDFromPriv(book.pk)
+	if err != nil {
+		return err
+	}
+	if signerID != h.Primary().KeyID {
+		return fmt.Errorf("%w: only the primary key can change profile keys", ErrAccessDenied)
+	}
+
+	subjectID, err := identity.KeyIDFromPub(subject)
+	if err != nil {
+		return err
+	}
+	if pid, ok := book.keyOwner(ctx, subjectID); ok && pid != h.ProfileID {
+		return fmt.Errorf("%w: key %q belongs to another profile", ErrAccessDenied, subjectID)
+	}
+	subjectPub, err := encodePubKey(subject)
+	if err != nil {
+		return err
+	}
+	signerPub, err := encodePubKey(book.pk.GetPublic())
+	if err != nil {
+		return err
+	}
+
+	op := oplog.Op{
+		Type:      opType,
+		Model:     KeyModel,
+		AuthorID:  signerID,
+		Name:      name,
+		Ref:       subjectID,
+		Relations: []string{subjectPub, signerPub, ""},
+		Timestamp: NewTimestamp(),
+	}
+	sig, err := book.pk.Sign(keyOpSigningBytes(h.ProfileID, op))
+	if err != nil {
+		return err
+	}
+	op.Relations[keyOpSignature] = base64.StdEncoding.EncodeToString(sig)
+	if opType == oplog.OpTypeInit {
+		op.Relations = append(op.Relations, subjectSig)
+	}
+	if err := verifyKeyOp(h, op); err != nil {
+		return err
+	}
+
+	authorLog.Append(op)
+	return book.indexKeys(authorLog.l)
+}
+
+// KeyHistory gets the keys of the book author
+func (book *Book) KeyHistory(ctx context.Context) (*KeyHistory, error) {
+	if book == nil {
+		return nil, ErrNoLogbook
+	}
+	authorLog, err := book.authorLog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return KeyHistoryFromLog(authorLog.l)
+}
+
+// WriteKeyRotate replaces the author's primary key. The rotation is signed
+// with the current primary key, which is retired. The logbook is re-encrypted
+// with the new key, callers must store the new key in place of the old one
+func (book *Book) WriteKeyRotate(ctx context.Context, newPk crypto.PrivKey) error {
+	if book == nil {
+		return ErrNoLogbook
+	}
+	if newPk == nil {
+		return fmt.Errorf("logbook: new private key is required")
+	}
+	h, err := book.KeyHistory(ctx)
+	if err != nil {
+		return err
+	}
+	if id, err := identity.KeyIDFromPriv(newPk); err != nil {
+		return err
+	} else if _, exists := h.Key(id); exists {
+		return fmt.Errorf("logbook: key %q has already been used by this profile", id)
+	}
+
+	if err := book.writeKeyOp(ctx, oplog.OpTypeAmend, "", newPk.GetPublic(), ""); err != nil {
+		return err
+	}
+	prevPk := book.pk
+	book.pk = newPk
+	if err := book.save(ctx); err != nil {
+		book.pk = prevPk
+		return err
+	}
+	return nil
+}
+
+// WriteDeviceKeyAdd authorizes a device key to sign logs on behalf of the
+// author. sig is the device's signature from DeviceKeySignature. A key can't
+// be added if it's already part of another profile's key history
+func (book *Book) WriteDeviceKeyAdd(ctx context.Context, device string, pub crypto.PubKey, sig string) error {
+	if book == nil {
+		return ErrNoLogbook
+	}
+	if device == "" {
+		return fmt.Errorf("logbook: device name is required")
+	}
+	if pub == nil {
+		return fmt.Errorf("logbook: device public key is required")
+	}
+	h, err := book.KeyHistory(ctx)
+	if err != nil {
+		return err
+	}
+	id, err := identity.KeyIDFromPub(pub)
+	if err != nil {
+		return err
+	}
+	if _, exists := h.Key(id); exists {
+		return fmt.Errorf("logbook: key %q has already been used by this profile", id)
+	}
+	for _, k := range h.Keys {
+		if k.Device == device && k.Active() {
+			return fmt.Errorf("logbook: device %q already has a key", device)
+		}
+	}
+
+	if err := book.writeKeyOp(ctx, oplog.OpTypeInit, device, pub, sig); err != nil {
+		return err
+	}
+	return book.save(ctx)
+}
+
+// WriteDeviceKeyRevoke retires a device key. Logs the device signed before
+// revocation remain valid
+func (book *Book) WriteDeviceKeyRevoke(ctx context.Context, keyID string) error {
+	if book == nil {
+		return ErrNoLogbook
+	}
+	h, err := book.KeyHistory(ctx)
+	if err != nil {
+		return err
+	}
+	k, ok := h.Key(keyID)
+	if !ok || k.Primary {
+		return fmt.Errorf("%w: no device key %q", ErrNotFound, keyID)
+	}
+	if !k.Active() {
+		return fmt.Errorf("logbook: device key %q is already revoked", keyID)
+	}
+	pub, err := decodePubKey(k.PubKey)
+	if err != nil {
+		return err
+	}
+
+	if err := book.writeKeyOp(ctx, oplog.OpTypeRemove, k.Device, pub, ""); err != nil {
+		return err
+	}
+	return book.save(ctx)
+}
+
+// keyIndex maps keyIDs to the profile that holds them
+type keyIndex struct {
+	lk sync.Mutex
+	// keys is nil until the index is loaded
+	keys map[string]indexedKey
+}
+
+type indexedKey struct {
+	profileID string
+	active    bool
+}
+
+// ProfileIDForKey finds the profile a key belongs to, using the key histories
+// of user logs in the logbook. Keys that don't appear in any key history, or
+// that have been retired, are their own profileID
+func (book *Book) ProfileIDForKey(ctx context.Context, keyID string) (string, error) {
+	if book == nil {
+		return "", ErrNoLogbook
+	}
+	if err := book.loadKeyIndex(ctx); err != nil {
+		return "", err
+	}
+	book.index.lk.Lock()
+	defer book.index.lk.Unlock()
+	if k, ok := book.index.keys[keyID]; ok && k.active {
+		return k.profileID, nil
+	}
+	return keyID, nil
+}
+
+// keyOwner returns the profile whose key history includes a key, active or
+// not
+func (book *Book) keyOwner(ctx context.Context, keyID string) (string, bool) {
+	if err := book.loadKeyIndex(ctx); err != nil {
+		return "", false
+	}
+	book.index.lk.Lock()
+	defer book.index.lk.Unlock()
+	k, ok := book.index.keys[keyID]
+	return k.profileID, ok
+}
+
+// loadKeyIndex builds the keyID to profileID index from the user logs in the
+// logbook the first time it's needed. The index is kept up to date as user
+// logs are written and merged
+func (book *Book) loadKeyIndex(ctx context.Context) error {
+	book.index.lk.Lock()
+	defer book.index.lk.Unlock()
+	if book.index.keys != nil {
+		return nil
+	}
+
+	logs, err := book.store.Logs(ctx, 0, -1)
+	if err != nil {
+		return err
+	}
+	book.index.keys = map[string]indexedKey{}
+	for _, lg := range logs {
+		if err := book.indexKeysLocked(lg); err != nil {
+			log.Debugf("logbook: skipping key history of %q: %s", lg.Name(), err)
+		}
+	}
+	return nil
+}
+
+// indexKeys adds the key history of a user log to the key index, refusing
+// histories that include another profile's key
+func (book *Book) indexKeys(lg *oplog.Log) error {
+	book.index.lk.Lock()
+	defer book.index.lk.Unlock()
+	if book.index.keys == nil {
+		// index hasn't been loaded, it'll include this log when it is
+		return nil
+	}
+	return book.indexKeysLocked(lg)
+}
+
+func (book *Book) indexKeysLocked(lg *oplog.Log) error {
+	if len(lg.Ops) == 0 || lg.Model() != AuthorModel || isOrgLog(lg) {
+		return nil
+	}
+	h, err := KeyHistoryFromLog(lg)
+	if err != nil {
+		return err
+	}
+	if err := book.checkKeyOwners(h); err != nil {
+		return err
+	}
+	for _, k := range h.Keys {
+		book.index.keys[k.KeyID] = indexedKey{profileID: h.ProfileID, active: k.Active()}
+	}
+	return nil
+}
+
+// checkKeyOwners errors if any key in a history belongs to another profile.
+// callers must hold keysLk
+func (book *Book) checkKeyOwners(h *KeyHistory) error {
+	for _, k := range h.Keys {
+		if owner, ok := book.index.keys[k.KeyID]; ok && owner.profileID != h.ProfileID {
+			return fmt.Errorf("%w: key %q belongs to another profile", ErrAccessDenied, k.KeyID)
+		}
+	}
+	return nil
+}
+
+// checkKeyHistory verifies the key history of a user log, and that none of its
+// keys belong to another profile
+func (book *Book) checkKeyHistory(ctx context.Context, lg *oplog.Log) error {
+	h, err := KeyHistoryFromLog(lg)
+	if err != nil {
+		return err
+	}
+	if err := book.loadKeyIndex(ctx); err != nil {
+		return err
+	}
+	book.index.lk.Lock()
+	defer book.index.lk.Unlock()
+	return book.checkKeyOwners(h)
+}
+
+// CheckAuthorKey confirms the key that signed a log can sign on behalf of the
+// log's author. Keys are checked against the author's key history as this
+// book has stored it, extended by any key ops the pushed log appends to it, so
+// a pushed log can't hide a rotation the book already knows about. Active keys
+// can sign anything. Retired keys can only re-send ops the book already holds,
+// so logs written before a rotation still verify against the old key. Op
+// timestamps are set by the author and aren't used. Logs in an organization's
+// namespace are checked by membership instead, see CheckOrgWriteAccess
+func (book *Book) CheckAuthorKey(ctx context.Context, keyID string, lg *oplog.Log) error {
+	if book == nil {
+		return ErrNoLogbook
+	}
+	root, err := book.rootLog(ctx, lg)
+	if err != nil {
+		return err
+	}
+	if isOrgLog(root) {
+		return nil
+	}
+	h, err := book.knownKeyHistory(ctx, root)
+	if err != nil {
+		return err
+	}
+	if err := book.loadKeyIndex(ctx); err != nil {
+		return err
+	}
+	book.index.lk.Lock()
+	err = book.checkKeyOwners(h)
+	book.index.lk.Unlock()
+	if err != nil {
+		return err
+	}
+	k, ok := h.Key(keyID)
+	if !ok || (!k.Active() && book.hasNewOps(ctx, lg)) {
+		return fmt.Errorf("%w: key %q cannot sign logs for %q", ErrAccessDenied, keyID, root.Name())
+	}
+	return nil
+}
+
+// knownKeyHistory builds the key history of a user log from the copy of the
+// log stored in the book. A pushed copy is only used if the book hasn't seen
+// the log, or if the pushed copy extends the stored one
+func (book *Book) knownKeyHistory(ctx context.Context, pushed *oplog.Log) (*KeyHistory, error) {
+	stored, err := book.store.Get(ctx, pushed.ID())
+	if err != nil || extendsOps(stored.Ops, pushed.Ops) {
+		return KeyHistoryFromLog(pushed)
+	}
+	return KeyHistoryFromLog(stored)
+}
+
+// extendsOps checks if ops starts with every op in prefix, in order
+func extendsOps(prefix, ops []oplog.Op) bool {
+	if len(ops) < len(prefix) {
+		return false
+	}
+	for i, op := range prefix {
+		if !op.Equal(ops[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasNewOps checks if a log or any of its descendants has ops the book
+// doesn't have stored
+func (book *Book) hasNewOps(ctx context.Context, lg *oplog.Log) bool {
+	stored, err := book.store.Get(ctx, lg.ID())
+	if err != nil {
+		if len(lg.Ops) > 0 {
+			return true
+		}
+	} else if !extendsOps(lg.Ops, stored.Ops) {
+		return true
+	}
+	for _, l := range lg.Logs {
+		if book.hasNewOps(ctx, l) {
+			return true
+		}
+	}
+	return false
+}
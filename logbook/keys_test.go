@@ -0,0 +1,283 @@
+package logbook_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	testPeers "github.com/qri-io/qri/config/test"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/identity"
+	"github.com/qri-io/qri/logbook"
+	"github.com/qri-io/qri/logbook/oplog"
+)
+
+func TestKeyRotation(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+	ctx := tr.Ctx
+
+	fs := qfs.NewMemFS()
+	book, err := logbook.NewJournal(testPrivKey(t), tr.Username, event.NilBus, fs, "/mem/logbook.qfb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldID, err := identity.KeyIDFromPriv(testPrivKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	initID, err := book.WriteDatasetInit(ctx, "world_bank_population")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds := &dataset.Dataset{
+		Commit: &dataset.Commit{Timestamp: time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC), Title: "initial commit"},
+		Path:   "HashOfVersion1",
+	}
+	if err := book.WriteVersionSave(ctx, initID, ds); err != nil {
+		t.Fatal(err)
+	}
+	historical, err := book.UserDatasetBranchesLog(ctx, initID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := book.SignLog(historical); err != nil {
+		t.Fatal(err)
+	}
+
+	devicePk := testPeers.GetTestPeerInfo(5).PrivKey
+	deviceID, err := identity.KeyIDFromPriv(devicePk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := logbook.DeviceKeySignature(oldID, "laptop", devicePk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := book.WriteDeviceKeyAdd(ctx, "laptop", devicePk.GetPublic(), sig); err != nil {
+		t.Fatal(err)
+	}
+	otherPk := testPeers.GetTestPeerInfo(4).PrivKey
+	if sig, err = logbook.DeviceKeySignature(oldID, "laptop", otherPk); err != nil {
+		t.Fatal(err)
+	}
+	if err := book.WriteDeviceKeyAdd(ctx, "laptop", otherPk.GetPublic(), sig); err == nil {
+		t.Error("expected adding a second key for the same device to fail")
+	}
+
+	newPk := testPeers.GetTestPeerInfo(6).PrivKey
+	newID, err := identity.KeyIDFromPriv(newPk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := book.WriteKeyRotate(ctx, newPk); err != nil {
+		t.Fatal(err)
+	}
+	if err := book.WriteKeyRotate(ctx, testPrivKey(t)); err == nil {
+		t.Error("expected rotating back to a retired key to fail")
+	}
+
+	h, err := book.KeyHistory(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.ProfileID != oldID {
+		t.Errorf("expected rotation to keep profileID %q, got %q", oldID, h.ProfileID)
+	}
+	if h.Primary().KeyID != newID {
+		t.Errorf("expected primary key to be %q, got %q", newID, h.Primary().KeyID)
+	}
+	if old, _ := h.Key(oldID); old.Active() || old.PubKey == "" {
+		t.Errorf("expected old key to be retired with a public key, got: %#v", old)
+	}
+	if device, _ := h.Key(deviceID); !device.Active() {
+		t.Error("expected device key to survive rotation")
+	}
+
+	// logs written before the rotation verify against the old key
+	remote, err := logbook.NewJournal(testPeers.GetTestPeerInfo(7).PrivKey, "registry", event.NilBus, qfs.NewMemFS(), "/mem/logbook.qfb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.CheckAuthorKey(ctx, oldID, historical); err != nil {
+		t.Errorf("expected historical log to verify against the old key, got: %s", err)
+	}
+	current, err := book.UserDatasetBranchesLog(ctx, initID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.CheckAuthorKey(ctx, oldID, current); !errors.Is(err, logbook.ErrAccessDenied) {
+		t.Errorf("expected old key to be denied after rotation, got: %v", err)
+	}
+	for _, id := range []string{newID, deviceID} {
+		if err := remote.CheckAuthorKey(ctx, id, current); err != nil {
+			t.Errorf("expected key %q to be valid, got: %s", id, err)
+		}
+	}
+
+	if err := book.SignLog(current); err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.MergeLog(ctx, book.Author(), current); err != nil {
+		t.Fatal(err)
+	}
+	if pid, _ := remote.ProfileIDForKey(ctx, deviceID); pid != oldID {
+		t.Errorf("expected device key to map to profileID %q, got %q", oldID, pid)
+	}
+
+	// once the remote knows about the rotation, the old key can re-send logs
+	// the remote already holds, but can't add to them by pushing a log that
+	// omits the rotation, however the new ops are timestamped
+	if err := remote.CheckAuthorKey(ctx, oldID, historical); err != nil {
+		t.Errorf("expected known historical log to verify against the old key, got: %s", err)
+	}
+	forged := historical.DeepCopy()
+	forged.Logs[0].Logs[0].Append(oplog.Op{
+		Type:      oplog.OpTypeInit,
+		Model:     logbook.CommitModel,
+		Ref:       "HashOfBackdatedVersion",
+		Timestamp: 1,
+	})
+	if err := remote.CheckAuthorKey(ctx, oldID, forged); !errors.Is(err, logbook.ErrAccessDenied) {
+		t.Errorf("expected old key to be denied adding to a log that omits the rotation, got: %v", err)
+	}
+
+	if err := book.WriteDeviceKeyRevoke(ctx, deviceID); err != nil {
+		t.Fatal(err)
+	}
+	if err := book.WriteDeviceKeyRevoke(ctx, newID); !errors.Is(err, logbook.ErrNotFound) {
+		t.Errorf("expected revoking the primary key as a device to fail, got: %v", err)
+	}
+	revoked, err := book.UserDatasetBranchesLog(ctx, initID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.CheckAuthorKey(ctx, deviceID, revoked); !errors.Is(err, logbook.ErrAccessDenied) {
+		t.Errorf("expected revoked device key to be denied, got: %v", err)
+	}
+
+	// the logbook is re-encrypted with the new key
+	if _, err := logbook.NewJournal(newPk, tr.Username, event.NilBus, fs, "/mem/logbook.qfb"); err != nil {
+		t.Errorf("expected logbook to load with the new key, got: %s", err)
+	}
+}
+
+func TestForgedKeyOps(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+	ctx := tr.Ctx
+
+	initID := tr.WriteWorldBankExample(t)
+	lg, err := tr.Book.UserDatasetBranchesLog(ctx, initID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a rotation written by someone who doesn't hold the primary key
+	thief := tr.foreignLogbook(t, "thief")
+	thiefID, err := identity.KeyIDFromPriv(testPrivKey2(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lg.Append(oplog.Op{
+		Type:      oplog.OpTypeAmend,
+		Model:     logbook.KeyModel,
+		AuthorID:  thiefID,
+		Ref:       thiefID,
+		Relations: []string{"", "", ""},
+		Timestamp: logbook.NewTimestamp(),
+	})
+	if err := tr.Book.SignLog(lg); err != nil {
+		t.Fatal(err)
+	}
+	if err := thief.MergeLog(ctx, tr.Book.Author(), lg); !errors.Is(err, logbook.ErrAccessDenied) {
+		t.Errorf("expected merging a forged key rotation to fail, got: %v", err)
+	}
+}
+
+func TestDeviceKeyOwnership(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+	ctx := tr.Ctx
+
+	profileID, err := identity.KeyIDFromPriv(testPrivKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	devicePk := testPrivKey2(t)
+	deviceID, err := identity.KeyIDFromPriv(devicePk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the device must co-sign its addition, for this profile and device name
+	if err := tr.Book.WriteDeviceKeyAdd(ctx, "laptop", devicePk.GetPublic(), ""); !errors.Is(err, logbook.ErrAccessDenied) {
+		t.Errorf("expected adding a device key without its signature to fail, got: %v", err)
+	}
+	sig, err := logbook.DeviceKeySignature(deviceID, "laptop", devicePk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Book.WriteDeviceKeyAdd(ctx, "laptop", devicePk.GetPublic(), sig); !errors.Is(err, logbook.ErrAccessDenied) {
+		t.Errorf("expected a device signature for another profile to fail, got: %v", err)
+	}
+	if sig, err = logbook.DeviceKeySignature(profileID, "laptop", devicePk); err != nil {
+		t.Fatal(err)
+	}
+
+	// once the book knows the key belongs to another profile, it can't be added
+	other := tr.foreignLogbook(t, "janelle")
+	otherInitID, err := other.WriteDatasetInit(ctx, "rainfall")
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherLog, err := other.UserDatasetBranchesLog(ctx, otherInitID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.SignLog(otherLog); err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Book.MergeLog(ctx, other.Author(), otherLog); err != nil {
+		t.Fatal(err)
+	}
+	if pid, _ := tr.Book.ProfileIDForKey(ctx, deviceID); pid != deviceID {
+		t.Errorf("expected key to map to its own profile %q, got %q", deviceID, pid)
+	}
+	if err := tr.Book.WriteDeviceKeyAdd(ctx, "laptop", devicePk.GetPublic(), sig); !errors.Is(err, logbook.ErrAccessDenied) {
+		t.Errorf("expected adding another profile's key to fail, got: %v", err)
+	}
+
+	// nor can a log claiming it be merged
+	claimant, err := logbook.NewJournal(testPeers.GetTestPeerInfo(4).PrivKey, "claimant", event.NilBus, qfs.NewMemFS(), "/mem/logbook.qfb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claimantID, err := identity.KeyIDFromPriv(testPeers.GetTestPeerInfo(4).PrivKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig, err = logbook.DeviceKeySignature(claimantID, "laptop", devicePk); err != nil {
+		t.Fatal(err)
+	}
+	if err := claimant.WriteDeviceKeyAdd(ctx, "laptop", devicePk.GetPublic(), sig); err != nil {
+		t.Fatal(err)
+	}
+	claimantInitID, err := claimant.WriteDatasetInit(ctx, "rainfall")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claimantLog, err := claimant.UserDatasetBranchesLog(ctx, claimantInitID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := claimant.SignLog(claimantLog); err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Book.MergeLog(ctx, claimant.Author(), claimantLog); !errors.Is(err, logbook.ErrAccessDenied) {
+		t.Errorf("expected merging a log claiming another profile's key to fail, got: %v", err)
+	}
+}
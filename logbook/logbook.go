@@ -58,6 +58,8 @@ const (
 	PushModel
 	// ACLModel is the enum for a acl model
 	ACLModel
+	// KeyModel is the enum for a key model
+	KeyModel
 )
 
 // DefaultBranchName is the default name all branch-level logbook data is read
@@ -80,6 +82,8 @@ func ModelString(m uint32) string {
 		return "push"
 	case ACLModel:
 		return "acl"
+	case KeyModel:
+		return "key"
 	default:
 		return ""
 	}
@@ -97,11 +101,13 @@ type Book struct {
 	fs         qfs.Filesystem
 
 	publisher event.Publisher
+
+	index *keyIndex
 }
 
 // NewBook creates a book with a user-provided logstore
 func NewBook(pk crypto.PrivKey, store oplog.Logstore) *Book {
-	return &Book{pk: pk, store: store}
+	return &Book{pk: pk, store: store, index: &keyIndex{}}
 }
 
 // NewJournal initializes a logbook owned by a single author, reading any
@@ -129,6 +135,7 @@ func NewJournal(pk crypto.PrivKey, username string, bus event.Bus, fs qfs.Filesy
 		authorName: username,
 		fsLocation: location,
 		publisher:  bus,
+		index:      &keyIndex{},
 	}
 
 	if err := book.load(ctx); err != nil {
@@ -283,26 +290,86 @@ func (book *Book) WriteAuthorRename(ctx context.Context, newName string) error {
 	return nil
 }
 
+// ResolveUsername finds the current name of an author that was previously
+// named username, following author renames merged into the logbook. It
+// returns dsref.ErrRefNotFound if no author has held the name. Author logs
+// can claim any previous name, so a name another author currently holds never
+// redirects, and names claimed by more than one author are ambiguous and
+// don't redirect either. ResolveUsername satisfies dsref.UsernameRedirect
+func (book *Book) ResolveUsername(ctx context.Context, username string) (string, error) {
+	if book == nil {
+		return "", dsref.ErrRefNotFound
+	}
+	logs, err := book.store.Logs(ctx, 0, -1)
+	if err != nil {
+		return "", err
+	}
+	var found *oplog.Log
+	for _, lg := range logs {
+		if lg.Model() != AuthorModel || lg.Removed() || len(lg.Ops) == 0 {
+			continue
+		}
+		if lg.Name() == username {
+			return "", dsref.ErrRefNotFound
+		}
+		for _, name := range AuthorNames(lg) {
+			if name != username {
+				continue
+			}
+			if found != nil && found.Ops[0].AuthorID != lg.Ops[0].AuthorID {
+				return "", dsref.ErrRefNotFound
+			}
+			found = lg
+		}
+	}
+	if found == nil {
+		return "", dsref.ErrRefNotFound
+	}
+	return found.Name(), nil
+}
+
+// AuthorNames lists every name an author log has held, oldest first. The last
+// name is the author's current name
+func AuthorNames(lg *oplog.Log) []string {
+	names := []string{}
+	for _, op := range lg.Ops {
+		if op.Model != AuthorModel || op.Name == "" {
+			continue
+		}
+		if len(names) == 0 || names[len(names)-1] != op.Name {
+			names = append(names, op.Name)
+		}
+	}
+	return names
+}
+
 // WriteDatasetInit initializes a new dataset name within the author's namespace
 func (book *Book) WriteDatasetInit(ctx context.Context, dsName string) (string, error) {
 	if book == nil {
 		return "", ErrNoLogbook
 	}
+
+	authorLog, err := book.authorLog(ctx)
+	if err != nil {
+		return "", err
+	}
+	return book.writeDatasetInit(ctx, authorLog, dsName)
+}
+
+// writeDatasetInit adds a new dataset log to a user log, which is either the
+// author's log or the log of an organization the author is a member of
+func (book *Book) writeDatasetInit(ctx context.Context, userLog *UserLog, dsName string) (string, error) {
 	if dsName == "" {
 		return "", fmt.Errorf("logbook: name is required to initialize a dataset")
 	}
 	if !dsref.IsValidName(dsName) {
 		return "", fmt.Errorf("logbook: dataset name %q invalid", dsName)
 	}
-	if _, err := book.DatasetRef(ctx, dsref.Ref{Username: book.Username(), Name: dsName}); err == nil {
+	username := userLog.l.Name()
+	if _, err := book.DatasetRef(ctx, dsref.Ref{Username: username, Name: dsName}); err == nil {
 		return "", fmt.Errorf("logbook: dataset named %q already exists", dsName)
 	}
-
-	authorLog, err := book.authorLog(ctx)
-	if err != nil {
-		return "", err
-	}
-	profileID := authorLog.ProfileID()
+	profileID := userLog.ProfileID()
 
 	log.Debugf("initializing name: '%s'", dsName)
 	dsLog := oplog.InitLog(oplog.Op{
@@ -323,15 +390,15 @@ func (book *Book) WriteDatasetInit(ctx context.Context, dsName string) (string,
 
 	dsLog.AddChild(branch)
 
-	authorLog.AddChild(dsLog)
+	userLog.AddChild(dsLog)
 
 	initID := dsLog.ID()
 
 	// TODO(dlong): Perhaps in the future, pass the authorID (hash of the author creation
 	// block) to the dscache, use that instead-of or in-addition-to the profileID.
-	err = book.publisher.Publish(ctx, event.ETDatasetNameInit, event.DsChange{
+	err := book.publisher.Publish(ctx, event.ETDatasetNameInit, event.DsChange{
 		InitID:     initID,
-		Username:   book.Username(),
+		Username:   username,
 		ProfileID:  profileID,
 		PrettyName: dsName,
 	})
@@ -358,7 +425,7 @@ func (book *Book) WriteDatasetRename(ctx context.Context, initID string, newName
 		return err
 	}
 
-	if err := book.hasWriteAccess(dsLog.l); err != nil {
+	if err := book.hasWriteAccess(ctx, dsLog.l); err != nil {
 		return err
 	}
 
@@ -441,12 +508,45 @@ func (book *Book) branchLog(ctx context.Context, initID string) (*BranchLog, err
 	return newBranchLog(lg.Logs[0]), nil
 }
 
-// hasWriteAccess is a simple author-matching check
-func (book *Book) hasWriteAccess(log *oplog.Log) error {
-	if log.Ops[0].AuthorID != book.authorID {
-		return fmt.Errorf("%w: you do not have write access", ErrAccessDenied)
+// hasWriteAccess checks the book author created the log, or is a member of the
+// organization the log belongs to
+func (book *Book) hasWriteAccess(ctx context.Context, log *oplog.Log) error {
+	if log.Ops[0].AuthorID == book.authorID {
+		return nil
 	}
-	return nil
+	if root, err := book.rootLog(ctx, log); err == nil && isOrgLog(root) {
+		if _, ok := orgFromLog(root).Member(book.profileID()); ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: you do not have write access", ErrAccessDenied)
+}
+
+// rootLog walks up the parents of a log to the top-level user log
+func (book *Book) rootLog(ctx context.Context, lg *oplog.Log) (*oplog.Log, error) {
+	for lg.ParentID != "" {
+		parent, err := book.store.Get(ctx, lg.ParentID)
+		if err != nil {
+			if parent = lg.Parent(); parent == nil {
+				return nil, err
+			}
+		}
+		lg = parent
+	}
+	return lg, nil
+}
+
+// profileID is the identifier of the book author, which is the ID of the key
+// the author was created with. Rotating keys doesn't change the profileID
+func (book *Book) profileID() string {
+	if lg, err := book.store.Get(context.Background(), book.authorID); err == nil && len(lg.Ops) > 0 {
+		return lg.Ops[0].AuthorID
+	}
+	id, err := identity.KeyIDFromPriv(book.pk)
+	if err != nil {
+		log.Debugf("getting book author profileID: %s", err)
+	}
+	return id
 }
 
 // WriteDatasetDelete closes a dataset, marking it as deleted
@@ -461,7 +561,7 @@ func (book *Book) WriteDatasetDelete(ctx context.Context, initID string) error {
 		return err
 	}
 
-	if err := book.hasWriteAccess(dsLog.l); err != nil {
+	if err := book.hasWriteAccess(ctx, dsLog.l); err != nil {
 		return err
 	}
 
@@ -494,7 +594,7 @@ func (book *Book) WriteVersionSave(ctx context.Context, initID string, ds *datas
 		return err
 	}
 
-	if err := book.hasWriteAccess(branchLog.l); err != nil {
+	if err := book.hasWriteAccess(ctx, branchLog.l); err != nil {
 		return err
 	}
 
@@ -552,7 +652,7 @@ func (book *Book) WriteVersionAmend(ctx context.Context, initID string, ds *data
 	if err != nil {
 		return err
 	}
-	if err := book.hasWriteAccess(branchLog.l); err != nil {
+	if err := book.hasWriteAccess(ctx, branchLog.l); err != nil {
 		return err
 	}
 
@@ -582,7 +682,7 @@ func (book *Book) WriteVersionDelete(ctx context.Context, initID string, revisio
 	if err != nil {
 		return err
 	}
-	if err := book.hasWriteAccess(branchLog.l); err != nil {
+	if err := book.hasWriteAccess(ctx, branchLog.l); err != nil {
 		return err
 	}
 
@@ -625,7 +725,7 @@ func (book *Book) WriteRemotePush(ctx context.Context, initID string, revisions
 	if err != nil {
 		return nil, nil, err
 	}
-	if err := book.hasWriteAccess(branchLog.l); err != nil {
+	if err := book.hasWriteAccess(ctx, branchLog.l); err != nil {
 		return nil, nil, err
 	}
 
@@ -686,7 +786,7 @@ func (book *Book) WriteRemoteDelete(ctx context.Context, initID string, revision
 	if err != nil {
 		return nil, nil, err
 	}
-	if err := book.hasWriteAccess(branchLog.l); err != nil {
+	if err := book.hasWriteAccess(ctx, branchLog.l); err != nil {
 		return nil, nil, err
 	}
 
@@ -729,6 +829,38 @@ func (book *Book) WriteRemoteDelete(ctx context.Context, initID string, revision
 	return sparseLog, rollback, nil
 }
 
+// PublishedRemotes lists the addresses of remotes a dataset has been pushed
+// to and not since removed from, ordered by most recent push
+func (book *Book) PublishedRemotes(ctx context.Context, initID string) ([]string, error) {
+	if book == nil {
+		return nil, ErrNoLogbook
+	}
+	branchLog, err := book.branchLog(ctx, initID)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := []string{}
+	for _, op := range branchLog.l.Ops {
+		if op.Model != PushModel || len(op.Relations) == 0 {
+			continue
+		}
+		addr := op.Relations[0]
+		i := 0
+		for _, a := range addrs {
+			if a != addr {
+				addrs[i] = a
+				i++
+			}
+		}
+		addrs = addrs[:i]
+		if op.Type == oplog.OpTypeInit {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs, nil
+}
+
 // ListAllLogs lists all of the logs in the logbook
 func (book Book) ListAllLogs(ctx context.Context) ([]*oplog.Log, error) {
 	return book.store.Logs(ctx, 0, -1)
@@ -806,6 +938,12 @@ func (book *Book) ResolveRef(ctx context.Context, ref *dsref.Ref) (string, error
 			}
 		}
 
+		// datasets in an organization's namespace belong to the organization
+		if root, err := book.rootLog(ctx, branchLog.l); err == nil && isOrgLog(root) {
+			ref.ProfileID = root.Ops[0].AuthorID
+			return "", nil
+		}
+
 		authorLog, err := book.store.Get(ctx, branchLog.l.Author())
 		if err != nil {
 			return "", err
@@ -949,10 +1087,26 @@ func (book *Book) MergeLog(ctx context.Context, sender identity.Author, lg *oplo
 	if err := lg.Verify(sender.AuthorPubKey()); err != nil {
 		return err
 	}
+	// reject user logs with forged key changes, and organization logs with
+	// forged membership changes
+	if len(lg.Ops) > 0 && lg.Model() == AuthorModel {
+		if isOrgLog(lg) {
+			if err := book.verifyOrgLog(ctx, lg); err != nil {
+				return err
+			}
+		} else if err := book.checkKeyHistory(ctx, lg); err != nil {
+			return err
+		}
+	}
 
 	if err := book.store.MergeLog(ctx, lg); err != nil {
 		return err
 	}
+	if merged, err := book.store.Get(ctx, lg.ID()); err == nil {
+		if err := book.indexKeys(merged); err != nil {
+			return err
+		}
+	}
 
 	return book.save(ctx)
 }
@@ -1022,6 +1176,14 @@ func itemFromOp(ref dsref.Ref, op oplog.Op) DatasetLogItem {
 
 // Items collapses the history of a dataset branch into linear log items
 func (book Book) Items(ctx context.Context, ref dsref.Ref, offset, limit int) ([]DatasetLogItem, error) {
+	return book.ItemsAfter(ctx, ref, "", offset, limit)
+}
+
+// ItemsAfter collapses the history of a dataset branch into linear log items,
+// starting with the item that follows the version with path after. Items
+// start at offset if after is empty or not part of the history, which keeps
+// pages stable when new versions are added to the dataset
+func (book Book) ItemsAfter(ctx context.Context, ref dsref.Ref, after string, offset, limit int) ([]DatasetLogItem, error) {
 	initID, err := book.RefToInitID(dsref.Ref{Username: ref.Username, Name: ref.Name})
 	if err != nil {
 		return nil, err
@@ -1031,7 +1193,7 @@ func (book Book) Items(ctx context.Context, ref dsref.Ref, offset, limit int) ([
 		return nil, err
 	}
 
-	return branchToLogItems(branchLog, ref, offset, limit, true), nil
+	return branchLogItemsAfter(branchLog, ref, after, offset, limit, true), nil
 }
 
 // ConvertLogsToItems collapses the history of a dataset branch into linear log items
@@ -1043,20 +1205,34 @@ func ConvertLogsToItems(l *oplog.Log, ref dsref.Ref) []DatasetLogItem {
 // If collapseAllDeletes is true, all delete operations will remove the refs before them. Otherwise,
 // only refs at the end of history will be removed in this manner.
 func branchToLogItems(blog *BranchLog, ref dsref.Ref, offset, limit int, collapseAllDeletes bool) []DatasetLogItem {
-	refs := []DatasetLogItem{}
+	return branchLogItemsAfter(blog, ref, "", offset, limit, collapseAllDeletes)
+}
+
+// branchLogItemsAfter collapses branch history like branchToLogItems,
+// starting after the version with path after when it's part of the history.
+// History is collapsed to op indexes first, so only items in the requested
+// page are created
+func branchLogItemsAfter(blog *BranchLog, ref dsref.Ref, after string, offset, limit int, collapseAllDeletes bool) []DatasetLogItem {
+	ops := blog.Ops()
+	// indexes of the commit ops of each version, oldest first
+	versions := []int{}
+	published := []bool{}
 	deleteAtEnd := 0
-	for _, op := range blog.Ops() {
+	for i, op := range ops {
 		switch op.Model {
 		case CommitModel:
 			switch op.Type {
 			case oplog.OpTypeInit:
-				refs = append(refs, itemFromOp(ref, op))
+				versions = append(versions, i)
+				published = append(published, false)
 			case oplog.OpTypeAmend:
 				deleteAtEnd = 0
-				refs[len(refs)-1] = itemFromOp(ref, op)
+				versions[len(versions)-1] = i
+				published[len(published)-1] = false
 			case oplog.OpTypeRemove:
 				if collapseAllDeletes {
-					refs = refs[:len(refs)-int(op.Size)]
+					versions = versions[:len(versions)-int(op.Size)]
+					published = published[:len(published)-int(op.Size)]
 				} else {
 					deleteAtEnd += int(op.Size)
 				}
@@ -1065,41 +1241,50 @@ func branchToLogItems(blog *BranchLog, ref dsref.Ref, offset, limit int, collaps
 			switch op.Type {
 			case oplog.OpTypeInit:
 				for i := 1; i <= int(op.Size); i++ {
-					refs[len(refs)-i].Published = true
+					published[len(published)-i] = true
 				}
 			case oplog.OpTypeRemove:
 				for i := 1; i <= int(op.Size); i++ {
-					refs[len(refs)-i].Published = false
+					published[len(published)-i] = false
 				}
 			}
 		}
 	}
 
 	if deleteAtEnd > 0 {
-		if deleteAtEnd < len(refs) {
-			refs = refs[:len(refs)-deleteAtEnd]
+		if deleteAtEnd < len(versions) {
+			versions = versions[:len(versions)-deleteAtEnd]
 		} else {
-			refs = []DatasetLogItem{}
+			versions = []int{}
 		}
 	}
 
-	// reverse the slice, placing newest first
-	// https://github.com/golang/go/wiki/SliceTricks#reversing
-	for i := len(refs)/2 - 1; i >= 0; i-- {
-		opp := len(refs) - 1 - i
-		refs[i], refs[opp] = refs[opp], refs[i]
+	// items are ordered newest first, the item at position i is the version
+	// at index len(versions)-1-i
+	if after != "" {
+		for i := range versions {
+			if ops[versions[len(versions)-1-i]].Ref == after {
+				offset = i + 1
+				break
+			}
+		}
 	}
-
-	if offset > len(refs) {
-		offset = len(refs)
+	if offset > len(versions) {
+		offset = len(versions)
 	}
-	refs = refs[offset:]
-
-	if limit < len(refs) && limit != -1 {
-		refs = refs[:limit]
+	end := len(versions)
+	if limit >= 0 && offset+limit < end {
+		end = offset + limit
 	}
 
-	return refs
+	items := make([]DatasetLogItem, 0, end-offset)
+	for i := offset; i < end; i++ {
+		v := len(versions) - 1 - i
+		item := itemFromOp(ref, ops[versions[v]])
+		item.Published = published[v]
+		items = append(items, item)
+	}
+	return items
 }
 
 // LogEntry is a simplified representation of a log operation
@@ -1145,6 +1330,7 @@ var actionStrings = map[uint32][3]string{
 	CommitModel:  {"save commit", "amend commit", "remove commit"},
 	PushModel:    {"publish", "", "unpublish"},
 	ACLModel:     {"update access", "update access", "remove all access"},
+	KeyModel:     {"add device key", "rotate key", "revoke device key"},
 }
 
 func logEntryFromOp(author string, op oplog.Op) LogEntry {
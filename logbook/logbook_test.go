@@ -476,6 +476,9 @@ func TestPushModel(t *testing.T) {
 	if err != nil {
 		t.Errorf("error writing push: %q", err)
 	}
+	if addrs, err := tr.Book.PublishedRemotes(ctx, initID); err != nil || len(addrs) != 1 || addrs[0] != "example/remote/address" {
+		t.Errorf("expected dataset to be published to example/remote/address, got: %v, %v", addrs, err)
+	}
 
 	if len(lg.Logs[0].Logs[0].Ops) != 3 {
 		t.Errorf("expected branch log to have 3 operations. got: %d", len(lg.Logs[0].Logs[0].Ops))
@@ -789,6 +792,46 @@ func TestRenameAuthor(t *testing.T) {
 		t.Fatalf("fetching new ref shouldn't fail. got: %s", err)
 	}
 
+	// the old username redirects to the new one
+	if current, err := tr.Book.ResolveUsername(tr.Ctx, tr.Username); err != nil || current != rename {
+		t.Errorf("expected %q to redirect to %q, got: %q, %v", tr.Username, rename, current, err)
+	}
+	if _, err := tr.Book.ResolveUsername(tr.Ctx, rename); !errors.Is(err, dsref.ErrRefNotFound) {
+		t.Errorf("expected current username not to redirect, got: %v", err)
+	}
+
+	// the rename travels with pushed logs
+	initID, err := tr.Book.RefToInitID(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lg, err := tr.Book.UserDatasetBranchesLog(tr.Ctx, initID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := logbook.AuthorNames(lg); len(names) != 2 || names[0] != tr.Username || names[1] != rename {
+		t.Errorf("expected pushed log to carry both author names, got: %v", names)
+	}
+	if err := tr.Book.SignLog(lg); err != nil {
+		t.Fatal(err)
+	}
+	remote := tr.foreignLogbook(t, "remote")
+	if err := remote.MergeLog(tr.Ctx, tr.Book.Author(), lg); err != nil {
+		t.Fatal(err)
+	}
+	if current, err := remote.ResolveUsername(tr.Ctx, tr.Username); err != nil || current != rename {
+		t.Errorf("expected merged rename to redirect %q to %q, got: %q, %v", tr.Username, rename, current, err)
+	}
+
+	// a name another author holds never redirects, even if a merged log
+	// claims to have held it
+	holder := tr.foreignLogbook(t, tr.Username)
+	if err := holder.MergeLog(tr.Ctx, tr.Book.Author(), lg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := holder.ResolveUsername(tr.Ctx, tr.Username); !errors.Is(err, dsref.ErrRefNotFound) {
+		t.Errorf("expected a held username not to redirect, got: %v", err)
+	}
 }
 
 func TestRenameDataset(t *testing.T) {
@@ -892,6 +935,15 @@ func TestItems(t *testing.T) {
 	if diff := cmp.Diff(expect, items); diff != "" {
 		t.Errorf("result mismatch (-want +got):\n%s", diff)
 	}
+
+	// keyed pages start after the given version, ignoring offset
+	items, err = book.ItemsAfter(tr.Ctx, tr.WorldBankRef(), "QmHashOfVersion5", 2, 1)
+	if err != nil {
+		t.Error(err)
+	}
+	if diff := cmp.Diff(expect, items); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
 }
 
 func TestConstructDatasetLog(t *testing.T) {
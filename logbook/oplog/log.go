@@ -133,9 +133,11 @@ func GetWithSparseAncestorsAllDescendants(ctx context.Context, store Logstore, i
 
 	cursor := l
 	for cursor.ParentID != "" {
-		parent := cursor.parent
-		if parent == nil {
-			if parent, err = store.Get(ctx, cursor.ParentID); err != nil {
+		// prefer the stored parent, cursor.parent may be a sparse copy made by
+		// an earlier call that's missing ops appended since
+		parent, err := store.Get(ctx, cursor.ParentID)
+		if err != nil {
+			if parent = cursor.parent; parent == nil {
 				return nil, err
 			}
 		}
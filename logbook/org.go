@@ -0,0 +1,464 @@
+package logbook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/identity"
+	"github.com/qri-io/qri/logbook/oplog"
+)
+
+const (
+	// OrgRoleAdmin is the role of organization members that manage membership
+	OrgRoleAdmin = "admin"
+	// OrgRoleMember is the role of organization members that can write to
+	// datasets in the organization's namespace
+	OrgRoleMember = "member"
+
+	// orgLogNote marks the initialization op of a user log that belongs to an
+	// organization
+	orgLogNote = "organization"
+)
+
+// Org is a namespace for datasets shared by a group of members. Organizations
+// are stored as top-level user logs, membership is recorded as ACL ops
+// appended to the organization's log
+type Org struct {
+	Name      string      `json:"name"`
+	ProfileID string      `json:"profileID"`
+	Members   []OrgMember `json:"members"`
+}
+
+// OrgMember is a profile with write access to an organization's namespace
+type OrgMember struct {
+	Username  string `json:"username"`
+	ProfileID string `json:"profileID"`
+	Role      string `json:"role"`
+}
+
+// Member gets the membership of a profile, if one exists
+func (o *Org) Member(profileID string) (OrgMember, bool) {
+	for _, m := range o.Members {
+		if m.ProfileID == profileID {
+			return m, true
+		}
+	}
+	return OrgMember{}, false
+}
+
+// IsAdmin returns true if a profile is an admin of the organization
+func (o *Org) IsAdmin(profileID string) bool {
+	m, ok := o.Member(profileID)
+	return ok && m.Role == OrgRoleAdmin
+}
+
+func (o *Org) admins() (n int) {
+	for _, m := range o.Members {
+		if m.Role == OrgRoleAdmin {
+			n++
+		}
+	}
+	return n
+}
+
+func validOrgRole(role string) bool {
+	return role == OrgRoleAdmin || role == OrgRoleMember
+}
+
+// isOrgLog returns true if a top-level log belongs to an organization
+func isOrgLog(l *oplog.Log) bool {
+	return len(l.Ops) > 0 && l.Model() == AuthorModel && l.Ops[0].Note == orgLogNote
+}
+
+// ACL ops are appended to an organization's log with ACLModel. Name is the
+// member's username and AuthorID the member's profileID. Every ACL op is
+// signed by the profile that made the change, which must be an admin when
+// the op is written, or the member itself for removals. Ref is the signer's
+// profileID, Relations holds the member's role, the base64-encoded signing
+// public key and signature, in that order
+const (
+	aclOpRole = iota
+	aclOpSignerPub
+	aclOpSignature
+)
+
+// aclOpSigningBytes covers the hash of the op before it in the log, tying each
+// signature to its position. Without it a signed op could be replayed later in
+// the log, re-adding a removed member
+func aclOpSigningBytes(orgID string, prev, op oplog.Op) []byte {
+	return []byte(fmt.Sprintf("%s|%s|%d|%s|%s|%s|%s|%d", orgID, prev.Hash(), op.Type, op.Name, op.AuthorID, op.Relations[aclOpRole], op.Ref, op.Timestamp))
+}
+
+// signACLOp signs an ACL op for an organization with the book author's key.
+// prev is the op the signed op will be appended after
+func (book *Book) signACLOp(orgID string, prev oplog.Op, op *oplog.Op) error {
+	signerPub, err := encodePubKey(book.pk.GetPublic())
+	if err != nil {
+		return err
+	}
+	op.Ref = book.profileID()
+	op.Relations = []string{op.Relations[aclOpRole], signerPub, ""}
+	sig, err := book.pk.Sign(aclOpSigningBytes(orgID, prev, *op))
+	if err != nil {
+		return err
+	}
+	op.Relations[aclOpSignature] = base64.StdEncoding.EncodeToString(sig)
+	return nil
+}
+
+// verifyOrgLog replays the ACL ops of an organization's log, checking each op
+// is signed by a key of the profile that made the change, and that the
+// profile was allowed to make it. The first ACL op must be the creator
+// granting itself admin
+func (book *Book) verifyOrgLog(ctx context.Context, l *oplog.Log) error {
+	orgID := l.Ops[0].AuthorID
+	roles := map[string]string{}
+	seen := map[string]bool{}
+	for i, op := range l.Ops {
+		if op.Model != ACLModel {
+			continue
+		}
+		if i == 0 {
+			return fmt.Errorf("%w: %q must start with an init op", ErrAccessDenied, l.Name())
+		}
+		if err := book.verifyACLOp(ctx, orgID, l.Ops[i-1], op); err != nil {
+			return err
+		}
+		if seen[op.Relations[aclOpSignature]] {
+			return fmt.Errorf("%w: duplicate membership change in %q", ErrAccessDenied, l.Name())
+		}
+		seen[op.Relations[aclOpSignature]] = true
+
+		switch {
+		case len(roles) == 0:
+			if op.Ref != op.AuthorID || op.Relations[aclOpRole] != OrgRoleAdmin {
+				return fmt.Errorf("%w: %q must be created by an admin", ErrAccessDenied, l.Name())
+			}
+		case roles[op.Ref] == OrgRoleAdmin:
+		case op.Type == oplog.OpTypeRemove && op.Ref == op.AuthorID && roles[op.Ref] != "":
+		default:
+			return fmt.Errorf("%w: only admins of %q can change membership", ErrAccessDenied, l.Name())
+		}
+
+		if op.Type == oplog.OpTypeRemove {
+			delete(roles, op.AuthorID)
+		} else {
+			roles[op.AuthorID] = op.Relations[aclOpRole]
+		}
+	}
+	return nil
+}
+
+// verifyACLOp checks an ACL op's signature, and that the signing key belongs
+// to the profile the op names as its signer. prev is the op before op in the
+// log
+func (book *Book) verifyACLOp(ctx context.Context, orgID string, prev, op oplog.Op) error {
+	if len(op.Relations) != 3 || op.Ref == "" {
+		return fmt.Errorf("%w: unsigned membership change", ErrAccessDenied)
+	}
+	signer, err := decodePubKey(op.Relations[aclOpSignerPub])
+	if err != nil {
+		return fmt.Errorf("%w: invalid membership change signing key", ErrAccessDenied)
+	}
+	keyID, err := identity.KeyIDFromPub(signer)
+	if err != nil {
+		return err
+	}
+	if pid, err := book.ProfileIDForKey(ctx, keyID); err != nil || pid != op.Ref {
+		return fmt.Errorf("%w: membership change signing key doesn't belong to %q", ErrAccessDenied, op.Ref)
+	}
+	sig, err := base64.StdEncoding.DecodeString(op.Relations[aclOpSignature])
+	if err != nil {
+		return fmt.Errorf("%w: invalid membership change signature", ErrAccessDenied)
+	}
+	if ok, err := signer.Verify(aclOpSigningBytes(orgID, prev, op), sig); err != nil || !ok {
+		return fmt.Errorf("%w: invalid membership change signature", ErrAccessDenied)
+	}
+	return nil
+}
+
+// orgFromLog folds the ACL ops of an organization's log into membership
+func orgFromLog(l *oplog.Log) *Org {
+	org := &Org{
+		Name:      l.Name(),
+		ProfileID: l.Ops[0].AuthorID,
+		Members:   []OrgMember{},
+	}
+
+	for _, op := range l.Ops {
+		if op.Model != ACLModel {
+			continue
+		}
+		for i, m := range org.Members {
+			if m.ProfileID == op.AuthorID {
+				org.Members = append(org.Members[:i], org.Members[i+1:]...)
+				break
+			}
+		}
+		if op.Type == oplog.OpTypeRemove {
+			continue
+		}
+		role := OrgRoleMember
+		if len(op.Relations) > 0 && op.Relations[aclOpRole] != "" {
+			role = op.Relations[aclOpRole]
+		}
+		org.Members = append(org.Members, OrgMember{
+			Username:  op.Name,
+			ProfileID: op.AuthorID,
+			Role:      role,
+		})
+	}
+	return org
+}
+
+// orgLog gets the log of an organization by name
+func (book *Book) orgLog(ctx context.Context, orgName string) (*UserLog, error) {
+	lg, err := book.store.HeadRef(ctx, orgName)
+	if err != nil {
+		if err == oplog.ErrNotFound {
+			return nil, fmt.Errorf("%w: no organization named %q", ErrNotFound, orgName)
+		}
+		return nil, err
+	}
+	if !isOrgLog(lg) {
+		return nil, fmt.Errorf("%w: %q is not an organization", ErrNotFound, orgName)
+	}
+	return newUserLog(lg), nil
+}
+
+// WriteOrgInit creates an organization, making the book author its first
+// admin. Organizations get a fresh profileID, derived from a keypair that
+// isn't kept: the organization's log is written by its members
+func (book *Book) WriteOrgInit(ctx context.Context, orgName string) (*Org, error) {
+	if book == nil {
+		return nil, ErrNoLogbook
+	}
+	if !dsref.IsValidName(orgName) {
+		return nil, fmt.Errorf("logbook: organization name %q invalid", orgName)
+	}
+	if orgName == book.Username() {
+		return nil, fmt.Errorf("logbook: name %q is already in use", orgName)
+	}
+	if _, err := book.store.HeadRef(ctx, orgName); err == nil {
+		return nil, fmt.Errorf("logbook: name %q is already in use", orgName)
+	}
+
+	_, pub, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	orgID, err := identity.KeyIDFromPub(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	lg := oplog.InitLog(oplog.Op{
+		Type:      oplog.OpTypeInit,
+		Model:     AuthorModel,
+		Name:      orgName,
+		AuthorID:  orgID,
+		Note:      orgLogNote,
+		Timestamp: NewTimestamp(),
+	})
+	op := oplog.Op{
+		Type:      oplog.OpTypeInit,
+		Model:     ACLModel,
+		Name:      book.Username(),
+		AuthorID:  book.profileID(),
+		Relations: []string{OrgRoleAdmin},
+		Timestamp: NewTimestamp(),
+	}
+	if err := book.signACLOp(orgID, lg.Ops[len(lg.Ops)-1], &op); err != nil {
+		return nil, err
+	}
+	lg.Append(op)
+
+	if err := book.store.MergeLog(ctx, lg); err != nil {
+		return nil, err
+	}
+	return orgFromLog(lg), book.save(ctx)
+}
+
+// Org gets an organization by name
+func (book *Book) Org(ctx context.Context, orgName string) (*Org, error) {
+	if book == nil {
+		return nil, ErrNoLogbook
+	}
+	olog, err := book.orgLog(ctx, orgName)
+	if err != nil {
+		return nil, err
+	}
+	return orgFromLog(olog.l), nil
+}
+
+// Orgs lists all organizations in the logbook
+func (book *Book) Orgs(ctx context.Context) ([]*Org, error) {
+	if book == nil {
+		return nil, ErrNoLogbook
+	}
+	logs, err := book.store.Logs(ctx, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+	orgs := []*Org{}
+	for _, lg := range logs {
+		if isOrgLog(lg) {
+			orgs = append(orgs, orgFromLog(lg))
+		}
+	}
+	return orgs, nil
+}
+
+// IsOrgMember returns true if the book author is a member of an organization
+func (book *Book) IsOrgMember(ctx context.Context, orgName string) bool {
+	org, err := book.Org(ctx, orgName)
+	if err != nil {
+		return false
+	}
+	_, ok := org.Member(book.profileID())
+	return ok
+}
+
+// WriteOrgMemberAdd adds a member to an organization, or changes the role of
+// an existing member. Only admins can change membership
+func (book *Book) WriteOrgMemberAdd(ctx context.Context, orgName string, m OrgMember) error {
+	if book == nil {
+		return ErrNoLogbook
+	}
+	if m.ProfileID == "" {
+		return fmt.Errorf("logbook: member profileID is required")
+	}
+	if m.Role == "" {
+		m.Role = OrgRoleMember
+	}
+	if !validOrgRole(m.Role) {
+		return fmt.Errorf("logbook: invalid organization role %q", m.Role)
+	}
+
+	olog, err := book.orgLog(ctx, orgName)
+	if err != nil {
+		return err
+	}
+	org := orgFromLog(olog.l)
+	if !org.IsAdmin(book.profileID()) {
+		return fmt.Errorf("%w: only admins of %q can add members", ErrAccessDenied, orgName)
+	}
+
+	opType := oplog.OpTypeInit
+	if prev, ok := org.Member(m.ProfileID); ok {
+		if prev.Role == OrgRoleAdmin && m.Role != OrgRoleAdmin && org.admins() == 1 {
+			return fmt.Errorf("logbook: %q must have at least one admin", orgName)
+		}
+		opType = oplog.OpTypeAmend
+	}
+
+	op := oplog.Op{
+		Type:      opType,
+		Model:     ACLModel,
+		Name:      m.Username,
+		AuthorID:  m.ProfileID,
+		Relations: []string{m.Role},
+		Timestamp: NewTimestamp(),
+	}
+	if err := book.signACLOp(org.ProfileID, olog.l.Ops[len(olog.l.Ops)-1], &op); err != nil {
+		return err
+	}
+	olog.Append(op)
+	return book.save(ctx)
+}
+
+// WriteOrgMemberRemove revokes a member's access to an organization. Admins
+// can remove any member, members can remove themselves
+func (book *Book) WriteOrgMemberRemove(ctx context.Context, orgName, profileID string) error {
+	if book == nil {
+		return ErrNoLogbook
+	}
+
+	olog, err := book.orgLog(ctx, orgName)
+	if err != nil {
+		return err
+	}
+	org := orgFromLog(olog.l)
+	self := book.profileID()
+	if profileID != self && !org.IsAdmin(self) {
+		return fmt.Errorf("%w: only admins of %q can remove members", ErrAccessDenied, orgName)
+	}
+	m, ok := org.Member(profileID)
+	if !ok {
+		return fmt.Errorf("%w: %q is not a member of %q", ErrNotFound, profileID, orgName)
+	}
+	if m.Role == OrgRoleAdmin && org.admins() == 1 {
+		return fmt.Errorf("logbook: %q must have at least one admin", orgName)
+	}
+
+	op := oplog.Op{
+		Type:      oplog.OpTypeRemove,
+		Model:     ACLModel,
+		Name:      m.Username,
+		AuthorID:  profileID,
+		Relations: []string{""},
+		Timestamp: NewTimestamp(),
+	}
+	if err := book.signACLOp(org.ProfileID, olog.l.Ops[len(olog.l.Ops)-1], &op); err != nil {
+		return err
+	}
+	olog.Append(op)
+	return book.save(ctx)
+}
+
+// WriteOrgDatasetInit initializes a new dataset name within an organization's
+// namespace. The book author must be a member of the organization
+func (book *Book) WriteOrgDatasetInit(ctx context.Context, orgName, dsName string) (string, error) {
+	if book == nil {
+		return "", ErrNoLogbook
+	}
+
+	olog, err := book.orgLog(ctx, orgName)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := orgFromLog(olog.l).Member(book.profileID()); !ok {
+		return "", fmt.Errorf("%w: you are not a member of %q", ErrAccessDenied, orgName)
+	}
+	return book.writeDatasetInit(ctx, olog, dsName)
+}
+
+// CheckOrgWriteAccess confirms a log sent by profileID may be merged into the
+// logbook. Logs outside of organizations always pass. Logs within an
+// organization's namespace must be sent by a member, and every membership
+// change must be signed by an admin. Membership is taken from the
+// organization's log already in the logbook. An organization seen for the
+// first time takes membership from the sent log, and can't use a name the
+// logbook already knows. A sent log that diverges from the stored one is
+// denied, Merge would otherwise keep whichever copy is longer
+func (book *Book) CheckOrgWriteAccess(ctx context.Context, profileID string, lg *oplog.Log) error {
+	if book == nil {
+		return ErrNoLogbook
+	}
+	if lg == nil || len(lg.Ops) == 0 || !isOrgLog(lg) {
+		return nil
+	}
+	if err := book.verifyOrgLog(ctx, lg); err != nil {
+		return err
+	}
+
+	local, err := book.store.Get(ctx, lg.ID())
+	if err != nil {
+		if existing, err := book.store.HeadRef(ctx, lg.Name()); err == nil && existing.ID() != lg.ID() {
+			return fmt.Errorf("%w: name %q is already in use", ErrAccessDenied, lg.Name())
+		}
+		local = lg
+	} else if !extendsOps(local.Ops, lg.Ops) && !extendsOps(lg.Ops, local.Ops) {
+		return fmt.Errorf("%w: log for %q diverges from the stored log", ErrAccessDenied, lg.Name())
+	}
+
+	org := orgFromLog(local)
+	if _, ok := org.Member(profileID); !ok {
+		return fmt.Errorf("%w: not a member of %q", ErrAccessDenied, org.Name)
+	}
+	return nil
+}
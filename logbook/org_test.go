@@ -0,0 +1,213 @@
+package logbook_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	testPeers "github.com/qri-io/qri/config/test"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/identity"
+	"github.com/qri-io/qri/logbook"
+	"github.com/qri-io/qri/logbook/oplog"
+)
+
+func TestOrgs(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+	ctx := tr.Ctx
+
+	adminID, err := identity.KeyIDFromPriv(testPrivKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	org, err := tr.Book.WriteOrgInit(ctx, "climate_org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if org.ProfileID == "" || !org.IsAdmin(adminID) || len(org.Members) != 1 {
+		t.Errorf("expected creator to be the only admin of a new org, got: %#v", org)
+	}
+	if _, err := tr.Book.WriteOrgInit(ctx, "climate_org"); err == nil {
+		t.Error("expected creating an org with a taken name to fail")
+	}
+	if _, err := tr.Book.WriteOrgInit(ctx, tr.Username); err == nil {
+		t.Error("expected creating an org with the author's name to fail")
+	}
+
+	member := tr.foreignLogbook(t, "janelle")
+	memberID, err := identity.KeyIDFromPriv(testPrivKey2(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Book.WriteOrgMemberAdd(ctx, "climate_org", logbook.OrgMember{Username: "janelle", ProfileID: memberID}); err != nil {
+		t.Fatal(err)
+	}
+	if org, err = tr.Book.Org(ctx, "climate_org"); err != nil {
+		t.Fatal(err)
+	}
+	if m, ok := org.Member(memberID); !ok || m.Role != logbook.OrgRoleMember {
+		t.Errorf("expected janelle to be a member, got: %#v", org.Members)
+	}
+
+	initID, err := tr.Book.WriteOrgDatasetInit(ctx, "climate_org", "temperatures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds := &dataset.Dataset{
+		Peername: "climate_org",
+		Name:     "temperatures",
+		Commit: &dataset.Commit{
+			Timestamp: time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC),
+			Title:     "initial commit",
+		},
+		Path: "HashOfVersion1",
+	}
+	if err := tr.Book.WriteVersionSave(ctx, initID, ds); err != nil {
+		t.Fatal(err)
+	}
+
+	lg, err := tr.Book.UserDatasetBranchesLog(ctx, initID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Book.SignLog(lg); err != nil {
+		t.Fatal(err)
+	}
+	// merging shares logs by pointer, keep a copy from before membership changes
+	unchanged := lg.DeepCopy()
+
+	// a remote seeing the org for the first time takes membership from the log
+	outsiderPk := testPeers.GetTestPeerInfo(8).PrivKey
+	outsiderID, err := identity.KeyIDFromPriv(outsiderPk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote, err := logbook.NewJournal(testPeers.GetTestPeerInfo(7).PrivKey, "registry", event.NilBus, qfs.NewMemFS(), "/mem/logbook.qfb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.CheckOrgWriteAccess(ctx, memberID, lg); err != nil {
+		t.Errorf("expected member to have write access, got: %s", err)
+	}
+	if err := remote.CheckOrgWriteAccess(ctx, outsiderID, lg); !errors.Is(err, logbook.ErrAccessDenied) {
+		t.Errorf("expected non-member push to be denied, got: %v", err)
+	}
+
+	// membership changes must be signed by an admin
+	forged := lg.DeepCopy()
+	forged.Append(oplog.Op{
+		Type:      oplog.OpTypeInit,
+		Model:     logbook.ACLModel,
+		Name:      "outsider",
+		AuthorID:  outsiderID,
+		Ref:       memberID,
+		Relations: []string{logbook.OrgRoleAdmin, "", ""},
+		Timestamp: logbook.NewTimestamp(),
+	})
+	if err := remote.CheckOrgWriteAccess(ctx, outsiderID, forged); !errors.Is(err, logbook.ErrAccessDenied) {
+		t.Errorf("expected forged membership change to be denied, got: %v", err)
+	}
+
+	// an organization can't take a name the remote already knows
+	if err := remote.MergeLog(ctx, tr.Book.Author(), lg); err != nil {
+		t.Fatal(err)
+	}
+	impostor := tr.foreignLogbook(t, "janelle")
+	if _, err := impostor.WriteOrgInit(ctx, "climate_org"); err != nil {
+		t.Fatal(err)
+	}
+	impostorInitID, err := impostor.WriteOrgDatasetInit(ctx, "climate_org", "temperatures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	impostorLog, err := impostor.UserDatasetBranchesLog(ctx, impostorInitID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.CheckOrgWriteAccess(ctx, memberID, impostorLog); !errors.Is(err, logbook.ErrAccessDenied) {
+		t.Errorf("expected an org reusing a known name to be denied, got: %v", err)
+	}
+
+	if err := member.MergeLog(ctx, tr.Book.Author(), lg); err != nil {
+		t.Fatal(err)
+	}
+	ds.Path = "HashOfVersion2"
+	ds.PreviousPath = "HashOfVersion1"
+	if err := member.WriteVersionSave(ctx, initID, ds); err != nil {
+		t.Errorf("expected member to be able to save to an org dataset, got: %s", err)
+	}
+	if err := member.WriteOrgMemberAdd(ctx, "climate_org", logbook.OrgMember{Username: "outsider", ProfileID: outsiderID}); !errors.Is(err, logbook.ErrAccessDenied) {
+		t.Errorf("expected non-admin adding a member to be denied, got: %v", err)
+	}
+
+	outsider, err := logbook.NewJournal(outsiderPk, "outsider", event.NilBus, qfs.NewMemFS(), "/mem/logbook.qfb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := outsider.MergeLog(ctx, tr.Book.Author(), lg); err != nil {
+		t.Fatal(err)
+	}
+	if err := outsider.WriteVersionSave(ctx, initID, ds); !errors.Is(err, logbook.ErrAccessDenied) {
+		t.Errorf("expected non-member save to be denied, got: %v", err)
+	}
+	if _, err := outsider.WriteOrgDatasetInit(ctx, "climate_org", "rainfall"); !errors.Is(err, logbook.ErrAccessDenied) {
+		t.Errorf("expected non-member dataset init to be denied, got: %v", err)
+	}
+
+	if err := tr.Book.WriteOrgMemberRemove(ctx, "climate_org", adminID); err == nil {
+		t.Error("expected removing the last admin to fail")
+	}
+	if err := tr.Book.WriteOrgMemberRemove(ctx, "climate_org", memberID); err != nil {
+		t.Fatal(err)
+	}
+	orgs, err := tr.Book.Orgs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orgs) != 1 || len(orgs[0].Members) != 1 {
+		t.Errorf("expected one org with one member, got: %#v", orgs)
+	}
+
+	// a removed member can't replay the admin-signed op that added them
+	removed, err := tr.Book.UserDatasetBranchesLog(ctx, initID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed := removed.DeepCopy()
+	for _, op := range unchanged.Ops {
+		if op.Model == logbook.ACLModel && op.AuthorID == memberID {
+			replayed.Append(op)
+		}
+	}
+	if err := remote.CheckOrgWriteAccess(ctx, memberID, replayed); !errors.Is(err, logbook.ErrAccessDenied) {
+		t.Errorf("expected replayed membership change to be denied, got: %v", err)
+	}
+
+	// nor push a copy of the log that diverges from the one the remote has
+	if err := tr.Book.SignLog(removed); err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.MergeLog(ctx, tr.Book.Author(), removed); err != nil {
+		t.Fatal(err)
+	}
+	fork, err := logbook.NewJournal(testPrivKey(t), tr.Username, event.NilBus, qfs.NewMemFS(), "/mem/logbook.qfb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fork.MergeLog(ctx, tr.Book.Author(), unchanged); err != nil {
+		t.Fatal(err)
+	}
+	if err := fork.WriteOrgMemberAdd(ctx, "climate_org", logbook.OrgMember{Username: "outsider", ProfileID: outsiderID}); err != nil {
+		t.Fatal(err)
+	}
+	diverged, err := fork.UserDatasetBranchesLog(ctx, initID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.CheckOrgWriteAccess(ctx, memberID, diverged); !errors.Is(err, logbook.ErrAccessDenied) {
+		t.Errorf("expected a diverging org log to be denied, got: %v", err)
+	}
+}
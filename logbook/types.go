@@ -16,9 +16,10 @@ func newUserLog(log *oplog.Log) *UserLog {
 // TODO(dustmop): Consider changing the "Append" methods to type-safe methods that are specific
 // to each log level, which accept individual parameters instead of type-unsafe Op values.
 
-// Append adds an op to the UserLog
+// Append adds an op to the UserLog. ACL ops record the membership of
+// organizations, key ops record changes to the user's keys
 func (alog *UserLog) Append(op oplog.Op) {
-	if op.Model != AuthorModel {
+	if op.Model != AuthorModel && op.Model != ACLModel && op.Model != KeyModel {
 		log.Errorf("cannot Append, incorrect model %d for UserLog", op.Model)
 		return
 	}
@@ -8,6 +8,7 @@ import (
 	"github.com/ipfs/go-ipfs/core/bootstrap"
 	peer "github.com/libp2p/go-libp2p-core/peer"
 	ma "github.com/multiformats/go-multiaddr"
+	"github.com/qri-io/qri/config"
 )
 
 // Bootstrap samples a subset of peers & requests their peers list
@@ -28,6 +29,9 @@ func (n *QriNode) Bootstrap(boostrapAddrs []string) {
 	// only dialing to a random subset
 	// for _, p := range randomSubsetOfPeers(pinfos, 4) {
 	for _, p := range pinfos {
+		if !n.cfg.PeerAllowed(p.ID) {
+			continue
+		}
 		go func(p peer.AddrInfo) {
 			log.Debugf("boostrapping to: %s", p.ID.Pretty())
 			if err := n.host.Connect(context.Background(), p); err != nil {
@@ -46,6 +50,22 @@ func (n *QriNode) BootstrapIPFS() {
 	}
 }
 
+// withoutPublicBootstrapAddrs removes the public qri bootstrap nodes from a
+// list of addresses, for private swarms that bootstrap off their own peers
+func withoutPublicBootstrapAddrs(addrs []string) []string {
+	public := map[string]bool{}
+	for _, addr := range config.DefaultP2P().QriBootstrapAddrs {
+		public[addr] = true
+	}
+	private := []string{}
+	for _, addr := range addrs {
+		if !public[addr] {
+			private = append(private, addr)
+		}
+	}
+	return private
+}
+
 // ParseMultiaddrs turns a slice of strings into a slice of Multiaddrs
 func ParseMultiaddrs(addrs []string) (maddrs []ma.Multiaddr, err error) {
 	maddrs = make([]ma.Multiaddr, len(addrs))
@@ -1,32 +1,38 @@
 package p2p
 
-// TODO (ramfox): relies on old `depQriProtocolID`
-// Should have its own protocol & protobuf & not rely on the Message struct
-
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
+	"github.com/libp2p/go-libp2p-core/helpers"
+	"github.com/libp2p/go-libp2p-core/network"
+	peer "github.com/libp2p/go-libp2p-core/peer"
+	protocol "github.com/libp2p/go-libp2p-core/protocol"
 	"github.com/qri-io/qri/base"
 	reporef "github.com/qri-io/qri/repo/ref"
-
-	peer "github.com/libp2p/go-libp2p-core/peer"
 )
 
-// MtDatasets is a dataset list message
-const MtDatasets = MsgType("list_datasets")
-
-// listMax is the highest number of entries a list request should return
-const listMax = 30
+const (
+	// DatasetsProtocolID is the protocol for listing a peer's datasets
+	DatasetsProtocolID = protocol.ID("/qri/datasets/1.0.0")
+	// listMax is the highest number of entries a list request should return
+	listMax = 30
+)
 
-// DatasetsListParams encapsulates options for requesting datasets
+// DatasetsListParams encapsulates options for requesting datasets. It's the
+// request message of the DatasetsProtocolID
 type DatasetsListParams struct {
 	Term   string
 	Limit  int
 	Offset int
 }
 
+// datasetsListResponse is the response message of the DatasetsProtocolID
+type datasetsListResponse struct {
+	Refs  []reporef.DatasetRef
+	Error string `json:",omitempty"`
+}
+
 // RequestDatasetsList gets a list of a peer's datasets
 func (n *QriNode) RequestDatasetsList(ctx context.Context, pid peer.ID, p DatasetsListParams) ([]reporef.DatasetRef, error) {
 	log.Debugf("%s RequestDatasetList: %s", n.ID, pid)
@@ -40,62 +46,57 @@ func (n *QriNode) RequestDatasetsList(ctx context.Context, pid peer.ID, p Datase
 		return nil, fmt.Errorf("not connected to p2p network")
 	}
 
-	req, err := NewJSONBodyMessage(n.ID, MtDatasets, p)
-	if err != nil {
-		log.Debug(err.Error())
-		return nil, err
+	if !n.qis.SupportsProtocol(pid, DatasetsProtocolID) {
+		return nil, fmt.Errorf("%w: %s", ErrProtocolNotSupported, DatasetsProtocolID)
 	}
 
-	req = req.WithHeaders("phase", "request")
-
-	s, err := n.host.NewStream(ctx, pid, depQriProtocolID)
+	s, err := n.host.NewStream(ctx, pid, DatasetsProtocolID)
 	if err != nil {
-		return nil, fmt.Errorf("error opening stream: %s", err.Error())
+		return nil, fmt.Errorf("error opening stream: %w", err)
 	}
-	defer s.Close()
+	// close the stream from this end & wait for the other end to close
+	defer func() { go helpers.FullClose(s) }()
 
 	ws := WrapStream(s)
-	if err := ws.sendMessage(req); err != nil {
+	if err := ws.sendJSON(p); err != nil {
 		return nil, err
 	}
 
-	res, err := ws.receiveMessage()
-	if err != nil {
+	res := datasetsListResponse{}
+	if err := ws.receiveJSON(&res); err != nil {
 		return nil, err
 	}
-
-	ref := []reporef.DatasetRef{}
-	err = json.Unmarshal(res.Body, &ref)
-	return ref, err
+	if res.Error != "" {
+		return nil, fmt.Errorf("listing datasets of peer %s: %s", pid, res.Error)
+	}
+	return res.Refs, nil
 }
 
-func (n *QriNode) handleDatasetsList(ws *WrappedStream, msg Message) (hangup bool) {
-	hangup = true
-	switch msg.Header("phase") {
-	case "request":
-		dlp := DatasetsListParams{}
-		if err := json.Unmarshal(msg.Body, &dlp); err != nil {
-			log.Debugf("%s %s", n.ID, err.Error())
-			return
-		}
-
-		if dlp.Limit == 0 || dlp.Limit > listMax {
-			dlp.Limit = listMax
-		}
-
-		refs, err := base.ListDatasets(context.TODO(), n.Repo, dlp.Term, dlp.Limit, dlp.Offset, false, true, false)
-		if err != nil {
-			log.Error(err)
-			return
-		}
-
-		reply, err := msg.UpdateJSON(refs)
-		reply = reply.WithHeaders("phase", "response")
-		if err := ws.sendMessage(reply); err != nil {
-			log.Debug(err.Error())
-			return
-		}
+// datasetsListHandler responds to requests on the DatasetsProtocolID
+func (n *QriNode) datasetsListHandler(s network.Stream) {
+	defer helpers.FullClose(s)
+
+	ws := WrapStream(s)
+	p := DatasetsListParams{}
+	if err := ws.receiveJSON(&p); err != nil {
+		log.Debugf("%s error reading datasets list request from %s: %s", DatasetsProtocolID, s.Conn().RemotePeer(), err)
+		return
 	}
 
-	return
+	if p.Limit <= 0 || p.Limit > listMax {
+		p.Limit = listMax
+	}
+
+	res := datasetsListResponse{}
+	refs, err := base.ListDatasets(context.TODO(), n.Repo, p.Term, p.Limit, p.Offset, false, true, false)
+	if err != nil {
+		log.Error(err)
+		res.Error = err.Error()
+	} else {
+		res.Refs = refs
+	}
+
+	if err := ws.sendJSON(res); err != nil {
+		log.Debugf("%s error sending datasets list to %s: %s", DatasetsProtocolID, s.Conn().RemotePeer(), err)
+	}
 }
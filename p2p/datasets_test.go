@@ -2,6 +2,7 @@ package p2p
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 
@@ -45,3 +46,59 @@ func TestRequestDatasetsList(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestDatasetsProtocol(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	factory := p2ptest.NewTestNodeFactory(NewTestableQriNode)
+	testPeers, err := p2ptest.NewTestNetwork(ctx, factory, 3)
+	if err != nil {
+		t.Fatalf("error creating network: %s", err.Error())
+	}
+	nodes := asQriNodes(testPeers)
+	for _, n := range nodes {
+		n.Discovery.Close()
+		defer n.GoOffline()
+	}
+	a, b, legacy := nodes[0], nodes[1], nodes[2]
+
+	// peers only list published datasets
+	bRefs, err := b.Repo.References(0, 1)
+	if err != nil || len(bRefs) == 0 {
+		t.Fatalf("expected peer to have datasets. err: %v", err)
+	}
+	bRefs[0].Published = true
+	if err := b.Repo.PutRef(bRefs[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	// legacy speaks the profile exchange older versions of qri use, and
+	// doesn't know the datasets protocol
+	legacy.host.RemoveStreamHandler(ProfileProtocolID)
+	legacy.host.RemoveStreamHandler(DatasetsProtocolID)
+
+	for _, n := range []*QriNode{b, legacy} {
+		if err := a.host.Connect(ctx, n.SimpleAddrInfo()); err != nil {
+			t.Fatal(err)
+		}
+		<-a.qis.profileWait(ctx, n.ID)
+		if pro := a.qis.ConnectedPeerProfile(n.ID); pro == nil {
+			t.Fatalf("expected to exchange profiles with peer %s", n.ID)
+		}
+	}
+
+	if !a.qis.SupportsProtocol(b.ID, DatasetsProtocolID) {
+		t.Errorf("expected peer to send %s in the profile exchange", DatasetsProtocolID)
+	}
+	refs, err := a.RequestDatasetsList(ctx, b.ID, DatasetsListParams{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 1 || refs[0].Name != bRefs[0].Name {
+		t.Errorf("expected peer to list published dataset %q, got: %v", bRefs[0].Name, refs)
+	}
+
+	if _, err := a.RequestDatasetsList(ctx, legacy.ID, DatasetsListParams{Limit: 10}); !errors.Is(err, ErrProtocolNotSupported) {
+		t.Errorf("expected listing datasets of a peer that doesn't speak the protocol to return ErrProtocolNotSupported, got: %v", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	peer "github.com/libp2p/go-libp2p-core/peer"
 	discovery "github.com/libp2p/go-libp2p/p2p/discovery"
+	"github.com/qri-io/qri/config"
 )
 
 const (
@@ -17,7 +18,7 @@ const (
 // if one doesn't exist, then registering to be notified on peer discovery
 func (n *QriNode) setupDiscovery(ctx context.Context) error {
 	var err error
-	if n.Discovery, err = discovery.NewMdnsService(ctx, n.host, discoveryInterval, discovery.ServiceTag); err != nil {
+	if n.Discovery, err = discovery.NewMdnsService(ctx, n.host, discoveryInterval, n.discoveryTag()); err != nil {
 		return err
 	}
 	// Registering will call n.HandlePeerFound when peers are discovered
@@ -25,10 +26,26 @@ func (n *QriNode) setupDiscovery(ctx context.Context) error {
 	return nil
 }
 
+// discoveryTag is the mDNS service tag for finding local peers. private swarms
+// use a qri-specific tag so they don't find every libp2p node on the network
+func (n *QriNode) discoveryTag() string {
+	if n.cfg.DiscoveryTag != "" {
+		return n.cfg.DiscoveryTag
+	}
+	if n.cfg.PrivateSwarm {
+		return config.PrivateSwarmDiscoveryTag
+	}
+	return discovery.ServiceTag
+}
+
 // HandlePeerFound deals with the discovery of a peer that may or may not
-// support the qri protocol
+// support the qri protocol. Peers a private swarm doesn't allow are ignored
 func (n *QriNode) HandlePeerFound(pinfo peer.AddrInfo) {
 	log.Debugf("found peer %s", pinfo.ID)
+	if !n.cfg.PeerAllowed(pinfo.ID) {
+		log.Debugf("ignoring peer %s, not in the private swarm allowlist", pinfo.ID)
+		return
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), discoveryConnTimeout)
 	defer cancel()
 	n.Host().Connect(ctx, pinfo)
@@ -0,0 +1,179 @@
+package p2p
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	peer "github.com/libp2p/go-libp2p-core/peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/qri-io/qfs/qipfs"
+	"github.com/qri-io/qri/dsref"
+)
+
+// FeedTopicPrefix namespaces the pubsub topics qri nodes announce new
+// versions of published datasets on
+const FeedTopicPrefix = "/qri/feed/1.0.0"
+
+// UserFeedTopic is the topic announcements for all datasets of a profile are
+// published on
+func UserFeedTopic(profileID string) string {
+	return fmt.Sprintf("%s/user/%s", FeedTopicPrefix, profileID)
+}
+
+// DatasetFeedTopic is the topic announcements for a single dataset are
+// published on
+func DatasetFeedTopic(initID string) string {
+	return fmt.Sprintf("%s/dataset/%s", FeedTopicPrefix, initID)
+}
+
+// Announcement is a message published to feed topics when a peer commits a
+// new version of a published dataset
+type Announcement struct {
+	// From is the peer that published the announcement. Set on receipt
+	From peer.ID `json:"-"`
+	// Topic the announcement arrived on. Set on receipt
+	Topic string `json:"-"`
+	// Version is the announced dataset version
+	Version dsref.VersionInfo `json:"version"`
+}
+
+// setupPubSub joins the node to the pubsub network. IPFS nodes with pubsub
+// enabled already have a router, which is shared
+func (n *QriNode) setupPubSub(ctx context.Context) (err error) {
+	n.topicsLk.Lock()
+	defer n.topicsLk.Unlock()
+	n.topics = map[string]*pubsub.Topic{}
+
+	if ipfsfs, ok := n.Repo.Store().(*qipfs.Filestore); ok {
+		if ps := ipfsfs.Node().PubSub; ps != nil {
+			n.pubsub = ps
+			return nil
+		}
+	}
+	n.pubsub, err = pubsub.NewGossipSub(ctx, n.host)
+	return err
+}
+
+// topic returns the joined pubsub topic with name, joining if necessary
+func (n *QriNode) topic(name string) (*pubsub.Topic, error) {
+	n.topicsLk.Lock()
+	defer n.topicsLk.Unlock()
+	if n.pubsub == nil {
+		return nil, fmt.Errorf("not connected to p2p network")
+	}
+	if t, ok := n.topics[name]; ok {
+		return t, nil
+	}
+	t, err := n.pubsub.Join(name)
+	if err != nil {
+		return nil, err
+	}
+	n.topics[name] = t
+	return t, nil
+}
+
+// AnnounceVersion publishes a new version of a dataset to the user & dataset
+// feed topics. Only versions authored by this node's profile can be announced
+func (n *QriNode) AnnounceVersion(ctx context.Context, vi dsref.VersionInfo) error {
+	if !n.Online {
+		return fmt.Errorf("not connected to p2p network")
+	}
+	pro, err := n.Repo.Profile()
+	if err != nil {
+		return err
+	}
+	if vi.ProfileID != pro.ID.String() {
+		return fmt.Errorf("can only announce datasets authored by %s", pro.ID)
+	}
+	if vi.InitID == "" {
+		return fmt.Errorf("announcing a version requires an initID")
+	}
+
+	data, err := json.Marshal(Announcement{Version: vi})
+	if err != nil {
+		return err
+	}
+	for _, name := range []string{UserFeedTopic(vi.ProfileID), DatasetFeedTopic(vi.InitID)} {
+		t, err := n.topic(name)
+		if err != nil {
+			return err
+		}
+		if err := t.Publish(ctx, data); err != nil {
+			return fmt.Errorf("publishing to %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// SubscribeFeed listens for announcements on a feed topic until ctx is
+// cancelled, which closes the returned channel. Announcements are dropped
+// unless the publishing peer belongs to the announced profile, and the
+// announced dataset belongs on the topic
+func (n *QriNode) SubscribeFeed(ctx context.Context, name string) (<-chan Announcement, error) {
+	t, err := n.topic(name)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := t.Subscribe()
+	if err != nil {
+		return nil, err
+	}
+
+	anns := make(chan Announcement)
+	go func() {
+		defer close(anns)
+		defer sub.Cancel()
+		for {
+			msg, err := sub.Next(ctx)
+			if err != nil {
+				return
+			}
+			ann, err := n.receiveAnnouncement(name, msg)
+			if err != nil {
+				log.Debugf("dropping announcement on %s from %s: %s", name, msg.GetFrom(), err)
+				continue
+			}
+			select {
+			case anns <- ann:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return anns, nil
+}
+
+// receiveAnnouncement decodes & checks an announcement. pubsub messages are
+// signed by the publishing peer, which is matched against known profiles
+func (n *QriNode) receiveAnnouncement(topic string, msg *pubsub.Message) (Announcement, error) {
+	ann := Announcement{}
+	from := msg.GetFrom()
+	if from == n.host.ID() {
+		return ann, fmt.Errorf("announcement is from this node")
+	}
+	if err := json.Unmarshal(msg.Data, &ann); err != nil {
+		return ann, err
+	}
+	ann.From = from
+	ann.Topic = topic
+
+	vi := ann.Version
+	if topic != UserFeedTopic(vi.ProfileID) && topic != DatasetFeedTopic(vi.InitID) {
+		return ann, fmt.Errorf("announced dataset doesn't belong on topic")
+	}
+
+	pro, err := n.Repo.Profiles().PeerProfile(from)
+	if err != nil {
+		return ann, fmt.Errorf("unknown peer")
+	}
+	if pro.ID.String() != vi.ProfileID {
+		return ann, fmt.Errorf("peer doesn't belong to profile %s", vi.ProfileID)
+	}
+	// announcements are only made for published datasets, and describe a
+	// dataset this node doesn't have yet
+	ann.Version.Published = true
+	ann.Version.Foreign = true
+	ann.Version.FSIPath = ""
+	return ann, nil
+}
@@ -0,0 +1,90 @@
+package p2p
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qri/dsref"
+	p2ptest "github.com/qri-io/qri/p2p/test"
+)
+
+func TestFeedAnnouncements(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	factory := p2ptest.NewTestNodeFactory(NewTestableQriNode)
+	testPeers, err := p2ptest.NewTestNetwork(ctx, factory, 3)
+	if err != nil {
+		t.Fatalf("error creating network: %s", err.Error())
+	}
+	nodes := asQriNodes(testPeers)
+	for _, n := range nodes {
+		n.Discovery.Close()
+		defer n.GoOffline()
+	}
+	a, b, impostor := nodes[0], nodes[1], nodes[2]
+
+	for _, n := range []*QriNode{a, impostor} {
+		if err := b.host.Connect(ctx, n.SimpleAddrInfo()); err != nil {
+			t.Fatal(err)
+		}
+		<-b.qis.profileWait(ctx, n.ID)
+	}
+
+	pro, err := a.Repo.Profile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vi := dsref.VersionInfo{
+		InitID:    "init_id",
+		ProfileID: pro.ID.String(),
+		Username:  pro.Peername,
+		Name:      "ds",
+		Path:      "/mem/QmVersion",
+	}
+
+	if err := impostor.AnnounceVersion(ctx, vi); err == nil {
+		t.Error("expected announcing another profile's dataset to error")
+	}
+
+	anns, err := b.SubscribeFeed(ctx, UserFeedTopic(vi.ProfileID))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the impostor skips AnnounceVersion checks & publishes directly. b must
+	// drop these
+	forged := vi
+	forged.Path = "/mem/QmForged"
+	data, _ := json.Marshal(Announcement{Version: forged})
+	forgedTopic, err := impostor.topic(UserFeedTopic(vi.ProfileID))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// pubsub meshes take a moment to form, keep announcing until one arrives
+	tick := time.NewTicker(200 * time.Millisecond)
+	defer tick.Stop()
+	for {
+		select {
+		case ann := <-anns:
+			if ann.Version.Path == forged.Path {
+				t.Fatal("expected announcement from a peer that doesn't belong to the profile to be dropped")
+			}
+			if ann.Version.Path != vi.Path || ann.From != a.ID || !ann.Version.Foreign {
+				t.Errorf("unexpected announcement: %#v", ann)
+			}
+			return
+		case <-tick.C:
+			if err := forgedTopic.Publish(ctx, data); err != nil {
+				t.Fatal(err)
+			}
+			if err := a.AnnounceVersion(ctx, vi); err != nil {
+				t.Fatal(err)
+			}
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for announcement")
+		}
+	}
+}
@@ -2,25 +2,38 @@ package p2p
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/repo/profile"
 )
 
 // ListPeers lists Peers on the qri network
 func ListPeers(node *QriNode, limit, offset int, onlineOnly bool) ([]*config.ProfilePod, error) {
+	return ListPeersAfter(node, "", limit, offset, onlineOnly)
+}
 
+// ListPeersAfter lists Peers on the qri network ordered by profile ID,
+// starting after the peer with profile ID after. Peers start at offset if
+// after is empty or not in the list
+func ListPeersAfter(node *QriNode, after string, limit, offset int, onlineOnly bool) ([]*config.ProfilePod, error) {
 	r := node.Repo
 	user, err := r.Profile()
 	if err != nil {
 		return nil, err
 	}
 
-	peers := make([]*config.ProfilePod, 0, limit)
 	connected := node.ConnectedQriProfiles()
-
 	if onlineOnly {
-		for _, p := range connected {
-			peers = append(peers, p)
+		ids := make([]profile.ID, 0, len(connected))
+		for id := range connected {
+			ids = append(ids, id)
+		}
+		sortIDs(ids)
+
+		peers := make([]*config.ProfilePod, 0, len(ids))
+		for _, id := range pagePeerIDs(ids, after, limit, offset) {
+			peers = append(peers, connected[id])
 		}
 		return peers, nil
 	}
@@ -30,22 +43,18 @@ func ListPeers(node *QriNode, limit, offset int, onlineOnly bool) ([]*config.Pro
 		return nil, fmt.Errorf("error listing peers: %s", err.Error())
 	}
 
-	if len(ps) == 0 || offset >= len(ps) {
-		return []*config.ProfilePod{}, nil
-	}
-
-	for _, pro := range ps {
-		if offset > 0 {
-			offset--
-			continue
-		}
-		if len(peers) >= limit {
-			break
-		}
+	ids := make([]profile.ID, 0, len(ps))
+	for id, pro := range ps {
 		if pro == nil || pro.ID == user.ID {
 			continue
 		}
+		ids = append(ids, id)
+	}
+	sortIDs(ids)
 
+	peers := make([]*config.ProfilePod, 0, limit)
+	for _, id := range pagePeerIDs(ids, after, limit, offset) {
+		pro := ps[id]
 		if _, ok := connected[pro.ID]; ok {
 			pro.Online = true
 		}
@@ -59,3 +68,26 @@ func ListPeers(node *QriNode, limit, offset int, onlineOnly bool) ([]*config.Pro
 
 	return peers, nil
 }
+
+func sortIDs(ids []profile.ID) {
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+}
+
+func pagePeerIDs(ids []profile.ID, after string, limit, offset int) []profile.ID {
+	if after != "" {
+		for i, id := range ids {
+			if id.String() == after {
+				offset = i + 1
+				break
+			}
+		}
+	}
+	if offset > len(ids) {
+		offset = len(ids)
+	}
+	ids = ids[offset:]
+	if limit >= 0 && limit < len(ids) {
+		ids = ids[:limit]
+	}
+	return ids
+}
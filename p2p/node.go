@@ -19,6 +19,7 @@ import (
 	peer "github.com/libp2p/go-libp2p-core/peer"
 	peerstore "github.com/libp2p/go-libp2p-peerstore"
 	pstoremem "github.com/libp2p/go-libp2p-peerstore/pstoremem"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	discovery "github.com/libp2p/go-libp2p/p2p/discovery"
 	ma "github.com/multiformats/go-multiaddr"
 	"github.com/qri-io/ioes"
@@ -60,17 +61,15 @@ type QriNode struct {
 	// requests for this node's profile
 	qis *QriProfileService
 
+	// pubsub carries announcements on feed topics. topics are the topics
+	// this node has joined
+	pubsub   *pubsub.PubSub
+	topicsLk sync.Mutex
+	topics   map[string]*pubsub.Topic
+
 	// localResolver allows the node to resolve local dataset references
 	localResolver dsref.Resolver
 
-	// msgState keeps a "scratch pad" of message IDS & timeouts
-	msgState *sync.Map
-	// receivers is a list of anyone who wants to be notifed on new
-	// message arrival
-	receivers []chan Message
-	// receiversMu is the lock for the receivers list
-	receiversMu sync.Mutex
-
 	// pub is the event publisher on which to publish p2p events
 	pub     event.Publisher
 	notifee *net.NotifyBundle
@@ -109,9 +108,7 @@ func NewQriNode(r repo.Repo, p2pconf *config.P2P, pub event.Publisher, localReso
 		ID:            pid,
 		cfg:           p2pconf,
 		Repo:          r,
-		msgState:      &sync.Map{},
 		pub:           pub,
-		receiversMu:   sync.Mutex{},
 		localResolver: localResolver,
 		// Make sure we always have proper IOStreams, this can be set later
 		LocalStreams: ioes.NewDiscardIOStreams(),
@@ -187,21 +184,20 @@ func (n *QriNode) GoOnline(c context.Context) (err error) {
 
 	n.qis.Start(n.host)
 
-	// add multistream handler for qri protocol to the host
-	// setting a stream handler for the QriPrtocolID indicates to peers on
-	// the distributed web that this node supports Qri. for more info on
-	// multistreams  check github.com/multformats/go-multistream
-	// note: even though we are phasing out the old qri protocol, we
-	// we are still handling 3 different cases on the `depQriProtocolID`, using
-	// the previous style of message communication:
-	// * profile requests handler - the older `upgradeToQriConnection` function emits this request
-	// * dataset list requests handler - the current `lib.Peers` actions depend on this functioning
-	// * all other cases get a "sorry please upgrade your version of Qri" response
-	n.host.SetStreamHandler(depQriProtocolID, n.depQriStreamHandler)
+	// add multistream handlers for each versioned qri protocol. peers that
+	// don't speak a protocol version get a "protocol not supported" error
+	// when opening a stream. for more info on multistreams check
+	// github.com/multformats/go-multistream
+	n.host.SetStreamHandler(DatasetsProtocolID, n.datasetsListHandler)
 
 	// add ref resolution capabilities:
 	n.host.SetStreamHandler(ResolveRefProtocolID, n.resolveRefHandler)
 
+	if err := n.setupPubSub(ctx); err != nil {
+		cancel()
+		return fmt.Errorf("starting pubsub: %w", err)
+	}
+
 	// register ourselves as a notifee on connected
 	n.host.Network().Notify(n.notifee)
 	if err := n.libp2pSubscribe(ctx); err != nil {
@@ -238,9 +234,16 @@ func (n *QriNode) startOnlineServices(ctx context.Context) error {
 	log.Debugf("starting online services")
 
 	// Boostrap off of default addresses
-	go n.Bootstrap(n.cfg.QriBootstrapAddrs)
-	// Bootstrap to IPFS network if this node is using an IPFS fs
-	go n.BootstrapIPFS()
+	addrs := n.cfg.QriBootstrapAddrs
+	if n.cfg.PrivateSwarm {
+		addrs = withoutPublicBootstrapAddrs(addrs)
+	}
+	go n.Bootstrap(addrs)
+	// Bootstrap to IPFS network if this node is using an IPFS fs. private
+	// swarms stay off the public network
+	if !n.cfg.PrivateSwarm {
+		go n.BootstrapIPFS()
+	}
 	return nil
 }
 
@@ -250,6 +253,10 @@ func (n *QriNode) GoOffline() error {
 		err := n.Host().Close()
 		// clean up the "GoOnline" context
 		n.shutdown()
+		n.topicsLk.Lock()
+		n.pubsub = nil
+		n.topics = nil
+		n.topicsLk.Unlock()
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 		n.pub.Publish(ctx, event.ETP2PGoneOffline, nil)
@@ -354,11 +361,26 @@ func makeBasicHost(ctx context.Context, ps peerstore.Peerstore, p2pconf *config.
 	// So instead, we pass in the libp2p basic ConnManager:
 	opts = append(opts, libp2p.ConnectionManager(connmgr.NewConnManager(1000, 0, time.Millisecond)))
 
+	// private swarms only complete connections with peers that have the same
+	// pre-shared key
+	if p2pconf.PrivateSwarm {
+		psk, err := p2pconf.DecodeSwarmKey()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, libp2p.PrivateNetwork(psk))
+	}
+
 	return libp2p.New(ctx, opts...)
 }
 
 // connected is called when a connection opened via the network notifee bundle
 func (n *QriNode) connected(_ net.Network, conn net.Conn) {
+	if !n.cfg.PeerAllowed(conn.RemotePeer()) {
+		log.Debugf("closing connection to peer %s, not in the private swarm allowlist", conn.RemotePeer())
+		go conn.Close()
+		return
+	}
 	log.Debugf("connected to peer: %s", conn.RemotePeer())
 	pi := n.Host().Peerstore().PeerInfo(conn.RemotePeer())
 	n.pub.Publish(context.Background(), event.ETP2PPeerConnected, pi)
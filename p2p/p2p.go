@@ -18,24 +18,32 @@ var (
 	ErrNotConnected = fmt.Errorf("no p2p connection")
 	// ErrQriProtocolNotSupported is returned when a connection can't be upgraded
 	ErrQriProtocolNotSupported = fmt.Errorf("peer doesn't support the qri protocol")
+	// ErrProtocolNotSupported is returned when a peer doesn't speak the
+	// protocol version a request needs, usually because it runs a different
+	// version of qri
+	ErrProtocolNotSupported = fmt.Errorf("peer doesn't support the protocol")
 	// ErrNoQriNode indicates a qri node doesn't exist
 	ErrNoQriNode = fmt.Errorf("p2p: no qri node")
 )
 
 const (
-	// depQriProtocolID is the top level Protocol Identifier
-	// TODO (ramfox): soon to be removed - protocols now use semantic versioning
-	depQriProtocolID = protocol.ID("/qri")
-	// QriProtocolID is the protocol we use to determing if a node speaks qri
-	// if it speaks the qri protocol, we assume it speaks any of the qri protocols
-	// that we support
-	QriProtocolID = protocol.ID("/qri/0.1.0")
 	// default value to give qri peer connections in connmanager, one hunnit
 	qriSupportValue = 100
 	// qriSupportKey is the key we store the flag for qri support under in Peerstores and in ConnManager()
 	qriSupportKey = "qri-support"
 )
 
+// Protocols lists the versioned protocols this node speaks. Peers send each
+// other their lists during the profile exchange, and only make requests on
+// protocols both peers speak
+func Protocols() []protocol.ID {
+	return []protocol.ID{
+		ProfileProtocolID,
+		ResolveRefProtocolID,
+		DatasetsProtocolID,
+	}
+}
+
 func init() {
 	// golog.SetLogLevel("qrip2p", "debug")
 	identify.ClientVersion = QriServiceTag
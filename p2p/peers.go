@@ -69,7 +69,7 @@ func (n *QriNode) ClosestConnectedQriPeers(profileID profile.ID, max int) (pid [
 	if len(pid) == 0 {
 		for _, conn := range n.host.Network().Conns() {
 			peerID := conn.RemotePeer()
-			protocols, err := n.host.Peerstore().SupportsProtocols(peerID, string(depQriProtocolID))
+			protocols, err := n.host.Peerstore().SupportsProtocols(peerID, profileProtocols...)
 			if err != nil {
 				continue
 			}
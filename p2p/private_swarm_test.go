@@ -0,0 +1,88 @@
+package p2p
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/qri-io/qri/config"
+	p2ptest "github.com/qri-io/qri/p2p/test"
+	"github.com/qri-io/qri/repo/profile"
+	"github.com/qri-io/qri/repo/test"
+)
+
+func TestPrivateSwarm(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	factory := p2ptest.NewTestNodeFactory(NewTestableQriNode)
+
+	const (
+		labKey   = "2b4ca1d1d8cfc2b0b8e2a7e3e7d3d0a77d6c2b3bd2e5a9f5f4e3c2b1a0f9e8d7"
+		otherKey = "9f8e7d6c5b4a39281706f5e4d3c2b1a09f8e7d6c5b4a39281706f5e4d3c2b1a0"
+	)
+	newNode := func(swarmKey string, allowed ...*QriNode) *QriNode {
+		info := factory.NextInfo()
+		r, err := test.NewTestRepoFromProfileID(profile.IDFromPeerID(info.PeerID), 0, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		addr, _ := ma.NewMultiaddr("/ip4/127.0.0.1/tcp/0")
+		cfg := config.DefaultP2P()
+		cfg.Addrs = []ma.Multiaddr{addr}
+		cfg.PrivateSwarm = true
+		cfg.SwarmKey = swarmKey
+		for _, n := range allowed {
+			cfg.AllowedPeers = append(cfg.AllowedPeers, n.ID.Pretty())
+		}
+		tn, err := factory.NewWithConf(r, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := tn.(*QriNode)
+		if err := n.GoOnline(ctx); err != nil {
+			t.Fatal(err)
+		}
+		n.Discovery.Close()
+		return n
+	}
+
+	a := newNode(labKey)
+	defer a.GoOffline()
+	b := newNode(labKey, a)
+	defer b.GoOffline()
+	outsider := newNode(otherKey)
+	defer outsider.GoOffline()
+	stranger := newNode(labKey)
+	defer stranger.GoOffline()
+
+	if tag := a.discoveryTag(); tag != config.PrivateSwarmDiscoveryTag {
+		t.Errorf("expected private swarm to use discovery tag %q, got %q", config.PrivateSwarmDiscoveryTag, tag)
+	}
+	if addrs := withoutPublicBootstrapAddrs(a.cfg.QriBootstrapAddrs); len(addrs) != 0 {
+		t.Errorf("expected private swarm not to bootstrap off public nodes, got: %v", addrs)
+	}
+
+	if err := a.host.Connect(ctx, b.SimpleAddrInfo()); err != nil {
+		t.Errorf("expected peers sharing a swarm key to connect, got: %s", err)
+	}
+	if err := a.host.Connect(ctx, outsider.SimpleAddrInfo()); err == nil {
+		t.Error("expected connecting to a peer with a different swarm key to fail")
+	}
+
+	// b only allows a. discovering the stranger is ignored
+	b.HandlePeerFound(stranger.SimpleAddrInfo())
+	if len(b.host.Network().ConnsToPeer(stranger.ID)) != 0 {
+		t.Error("expected discovered peer missing from the allowlist to be ignored")
+	}
+
+	// connections the stranger opens to b are closed
+	stranger.host.Connect(ctx, b.SimpleAddrInfo())
+	deadline := time.Now().Add(5 * time.Second)
+	for len(b.host.Network().ConnsToPeer(stranger.ID)) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected connection from peer missing from the allowlist to be closed")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
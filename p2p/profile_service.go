@@ -18,19 +18,23 @@ import (
 )
 
 const (
-	// ProfileProtocolID is the protocol id for the profile exchange service
-	ProfileProtocolID = protocol.ID("/qri/profile/0.1.0")
+	// ProfileProtocolID is the protocol id for the profile exchange service.
+	// peers send their profile & the list of protocols they speak
+	ProfileProtocolID = protocol.ID("/qri/profile/1.0.0")
+	// legacyProfileProtocolID exchanges profiles without protocol lists, for
+	// peers running older versions of qri
+	legacyProfileProtocolID = protocol.ID("/qri/profile/0.1.0")
 	// ProfileTimeout is the length of time we will wait for a response in a
 	// profile exchange
 	ProfileTimeout = time.Minute * 2
 )
 
 var (
-	// expectedProtocols is the list of protocols we expect a node that has a
-	// profile service to speak
-	expectedProtocols = []string{
-		string(QriProtocolID),
+	// profileProtocols lists the profile exchange versions, a peer that speaks
+	// any of them is a qri peer
+	profileProtocols = []string{
 		string(ProfileProtocolID),
+		string(legacyProfileProtocolID),
 	}
 	// ErrPeerNotFound is returned when the profile service cannot find the
 	// peer in question
@@ -52,16 +56,26 @@ type QriProfileService struct {
 	// that channel is closed once the profile has been received
 	// it only tracks peers we are currently connected to
 	peers map[peer.ID]chan struct{}
+	// protocols holds the protocols each connected peer sent during the
+	// profile exchange. peers on the legacy exchange have no entry
+	protocols map[peer.ID][]protocol.ID
+}
+
+// profileExchange is the message sent on the ProfileProtocolID
+type profileExchange struct {
+	Profile   *config.ProfilePod
+	Protocols []protocol.ID
 }
 
 // NewQriProfileService creates an profile exchange service
 func NewQriProfileService(r repo.Repo, p event.Publisher) *QriProfileService {
 	q := &QriProfileService{
-		repo:     r,
-		pub:      p,
-		profiles: r.Profiles(),
-		peersMu:  &sync.Mutex{},
-		peers:    map[peer.ID]chan struct{}{},
+		repo:      r,
+		pub:       p,
+		profiles:  r.Profiles(),
+		peersMu:   &sync.Mutex{},
+		peers:     map[peer.ID]chan struct{}{},
+		protocols: map[peer.ID][]protocol.ID{},
 	}
 
 	return q
@@ -103,6 +117,7 @@ func (q *QriProfileService) HandleQriPeerDisconnect(pid peer.ID) {
 
 	q.peersMu.Lock()
 	delete(q.peers, pid)
+	delete(q.protocols, pid)
 	q.peersMu.Unlock()
 }
 
@@ -123,21 +138,58 @@ func (q *QriProfileService) ConnectedPeerProfile(pid peer.ID) *profile.Profile {
 	return pro
 }
 
-// Start adds a profile handler to the host, retains a local reference to the host
+// SupportsProtocol reports whether a peer speaks a protocol, using the list
+// the peer sent during the profile exchange. Peers on the legacy exchange
+// fall back to the protocols libp2p identified
+func (q *QriProfileService) SupportsProtocol(pid peer.ID, proto protocol.ID) bool {
+	q.peersMu.Lock()
+	protos, ok := q.protocols[pid]
+	q.peersMu.Unlock()
+	if ok {
+		for _, p := range protos {
+			if p == proto {
+				return true
+			}
+		}
+		return false
+	}
+
+	if q.host == nil {
+		return false
+	}
+	supported, err := q.host.Peerstore().SupportsProtocols(pid, string(proto))
+	return err == nil && len(supported) > 0
+}
+
+// Start adds profile handlers to the host, retains a local reference to the host
 func (q *QriProfileService) Start(h host.Host) {
 	q.host = h
 	h.SetStreamHandler(ProfileProtocolID, q.ProfileHandler)
+	h.SetStreamHandler(legacyProfileProtocolID, q.legacyProfileHandler)
 }
 
 // ProfileHandler listens for profile requests
-// it sends it's node's profile on the given stream
+// it sends it's node's profile & the protocols it speaks on the given stream
 // whenever a request comes in
 func (q *QriProfileService) ProfileHandler(s network.Stream) {
-	var (
-		err error
-		pro *profile.Profile
-	)
+	q.handleProfileRequest(s, func(pro *profile.Profile) error {
+		pod, err := pro.Encode()
+		if err != nil {
+			return fmt.Errorf("error encoding profile.Profile to config.ProfilePod: %s", err)
+		}
+		return WrapStream(s).sendJSON(&profileExchange{Profile: pod, Protocols: Protocols()})
+	})
+}
+
+// legacyProfileHandler responds to profile requests from older versions of
+// qri with just a profile
+func (q *QriProfileService) legacyProfileHandler(s network.Stream) {
+	q.handleProfileRequest(s, func(pro *profile.Profile) error {
+		return sendProfile(s, pro)
+	})
+}
 
+func (q *QriProfileService) handleProfileRequest(s network.Stream, send func(pro *profile.Profile) error) {
 	p := s.Conn().RemotePeer()
 
 	defer func() {
@@ -146,17 +198,16 @@ func (q *QriProfileService) ProfileHandler(s network.Stream) {
 		helpers.FullClose(s)
 	}()
 
-	log.Debugf("%s received a profile request from %s %s", ProfileProtocolID, p, s.Conn().RemoteMultiaddr())
+	log.Debugf("%s received a profile request from %s %s", s.Protocol(), p, s.Conn().RemoteMultiaddr())
 
-	pro, err = q.repo.Profile()
+	pro, err := q.repo.Profile()
 	if err != nil {
-		log.Debugf("%s error getting this node's profile: %s", ProfileProtocolID, err)
+		log.Debugf("%s error getting this node's profile: %s", s.Protocol(), err)
 		return
 	}
 
-	err = sendProfile(s, pro)
-	if err != nil {
-		log.Debugf("%s error sending profile to %s: %s", ProfileProtocolID, p, err)
+	if err := send(pro); err != nil {
+		log.Debugf("%s error sending profile to %s: %s", s.Protocol(), p, err)
 		return
 	}
 }
@@ -165,13 +216,13 @@ func (q *QriProfileService) ProfileHandler(s network.Stream) {
 // if it does, it protects the connection and sends a request for the
 // QriIdentifyService to get the peer's qri profile information
 func (q *QriProfileService) QriProfileRequest(ctx context.Context, pid peer.ID) error {
-	protocols, err := q.host.Peerstore().SupportsProtocols(pid, expectedProtocols...)
+	protocols, err := q.host.Peerstore().SupportsProtocols(pid, profileProtocols...)
 	if err != nil {
 		log.Debugf("error examining the protocols for peer %s: %w", pid, err)
 		return fmt.Errorf("error examining the protocols for peer %s: %w", pid, err)
 	}
 
-	if len(protocols) == len(expectedProtocols) {
+	if len(protocols) == 0 {
 		log.Debugf("peer %q does not speak the expected qri protocols", pid)
 		return fmt.Errorf("peer %q does not speak the expected qri protocols", pid)
 	}
@@ -233,7 +284,9 @@ func (q *QriProfileService) profileRequest(ctx context.Context, pid peer.ID, sig
 		}
 	}()
 
-	s, err := q.host.NewStream(ctx, pid, ProfileProtocolID)
+	// prefer the current exchange, falling back to the legacy exchange for
+	// peers running older versions of qri
+	s, err := q.host.NewStream(ctx, pid, ProfileProtocolID, legacyProfileProtocolID)
 	if err != nil {
 		log.Debugf("error opening profile stream to %q: %s", pid, err)
 		return
@@ -254,7 +307,16 @@ func (q *QriProfileService) receiveAndStoreProfile(ctx context.Context, s networ
 		go helpers.FullClose(s)
 	}()
 
-	pro, err := receiveProfile(s)
+	var (
+		pro       *profile.Profile
+		protocols []protocol.ID
+		err       error
+	)
+	if s.Protocol() == legacyProfileProtocolID {
+		pro, err = receiveProfile(s)
+	} else {
+		pro, protocols, err = receiveProfileExchange(s)
+	}
 	if err != nil {
 		log.Errorf("%s error reading profile message from %q: %s", s.Protocol(), s.Conn().RemotePeer(), err)
 		return
@@ -262,10 +324,33 @@ func (q *QriProfileService) receiveAndStoreProfile(ctx context.Context, s networ
 
 	log.Debugf("%s received profile message from %q %s", s.Protocol(), s.Conn().RemotePeer(), s.Conn().RemoteMultiaddr())
 
+	if protocols != nil {
+		q.peersMu.Lock()
+		q.protocols[s.Conn().RemotePeer()] = protocols
+		q.peersMu.Unlock()
+	}
 	q.repo.Profiles().PutProfile(pro)
 	return
 }
 
+func receiveProfileExchange(s network.Stream) (*profile.Profile, []protocol.ID, error) {
+	msg := &profileExchange{}
+	if err := WrapStream(s).receiveJSON(msg); err != nil {
+		return nil, nil, err
+	}
+	if msg.Profile == nil {
+		return nil, nil, fmt.Errorf("profile exchange is missing a profile")
+	}
+	pro := &profile.Profile{}
+	if err := pro.Decode(msg.Profile); err != nil {
+		return nil, nil, fmt.Errorf("error decoding Profile from config.ProfilePod: %s", err)
+	}
+	if msg.Protocols == nil {
+		msg.Protocols = []protocol.ID{}
+	}
+	return pro, msg.Protocols, nil
+}
+
 func sendProfile(s network.Stream, pro *profile.Profile) error {
 	ws := WrapStream(s)
 
@@ -2,15 +2,13 @@ package p2p
 
 import (
 	"bufio"
+	"fmt"
 
 	net "github.com/libp2p/go-libp2p-core/network"
 	multicodec "github.com/multiformats/go-multicodec"
 	json "github.com/multiformats/go-multicodec/json"
 )
 
-// HandlerFunc is the signature of a function that can handle p2p messages
-type HandlerFunc func(ws *WrappedStream, msg Message) (hangup bool)
-
 // WrappedStream wraps a libp2p stream. We encode/decode whenever we
 // write/read from a stream, so we can just carry the encoders
 // and bufios with us
@@ -45,24 +43,24 @@ func WrapStream(s net.Stream) *WrappedStream {
 	}
 }
 
-// TODO (ramfox): currently, each protocol (except for the one marked for deprecation)
-// has its own `receiveMessage` and `sendMessage` functions, that ensure the
-// messages get encoded and decoded to the structures they need. We should figure out
-// how to generalize so that each protocol can pass in the way they want to
-// be able to encode and decode the stream to suit their data needs
-// // receiveMessage reads and decodes a message from the stream
-// func (ws *WrappedStream) receiveMessage() (msg Message, err error) {
-// 	err = ws.dec.Decode(&msg)
-// 	msg.provider = ws.stream.Conn().RemotePeer()
-// 	log.Debugf("%s '%s' <- %s", ws.stream.Conn().LocalPeer(), msg.Type, ws.stream.Conn().RemotePeer())
-// 	return
-// }
+// sendJSON encodes & writes a typed message to the stream
+func (ws *WrappedStream) sendJSON(v interface{}) error {
+	if err := ws.enc.Encode(v); err != nil {
+		return fmt.Errorf("encoding message: %w", err)
+	}
+	// output is buffered with bufio, we need to flush!
+	if err := ws.w.Flush(); err != nil {
+		return fmt.Errorf("flushing stream: %w", err)
+	}
+	log.Debugf("%s %s -> %s", ws.stream.Conn().LocalPeer(), ws.stream.Protocol(), ws.stream.Conn().RemotePeer())
+	return nil
+}
 
-// // sendMessage encodes and writes a message to the stream
-// func (ws *WrappedStream) sendMessage(msg Message) error {
-// 	err := ws.enc.Encode(&msg)
-// 	// Because output is buffered with bufio, we need to flush!
-// 	ws.w.Flush()
-// 	log.Debugf("%s '%s' -> %s", ws.stream.Conn().LocalPeer(), msg.Type, ws.stream.Conn().RemotePeer())
-// 	return err
-// }
+// receiveJSON reads & decodes a typed message from the stream
+func (ws *WrappedStream) receiveJSON(v interface{}) error {
+	if err := ws.dec.Decode(v); err != nil {
+		return fmt.Errorf("decoding message: %w", err)
+	}
+	log.Debugf("%s %s <- %s", ws.stream.Conn().LocalPeer(), ws.stream.Protocol(), ws.stream.Conn().RemotePeer())
+	return nil
+}
@@ -0,0 +1,483 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/qri-io/dataset"
+)
+
+// Datasets is the interface for working with the registry's catalog of
+// published datasets. Catalog entries are dataset previews keyed by
+// DatasetKey. Load, Len, Range and SortedRange are safe to hook up to public
+// http endpoints, callers should prefer PublishDataset & UnpublishDataset to
+// Create, Update & Delete
+type Datasets interface {
+	// Len returns the number of records in the set
+	Len() (int, error)
+	// Load fetches a dataset from the catalog by key
+	Load(key string) (value *dataset.Dataset, err error)
+	// Range calls an iteration fuction on each element in the catalog until
+	// the end of the list is reached or iter returns false
+	Range(iter func(key string, ds *dataset.Dataset) (kontinue bool, err error)) error
+	// SortedRange is like range but with deterministic key ordering
+	SortedRange(iter func(key string, ds *dataset.Dataset) (kontinue bool, err error)) error
+
+	// Create adds an entry, bypassing the publish process
+	Create(key string, value *dataset.Dataset) error
+	// Update modifies an existing entry
+	Update(key string, value *dataset.Dataset) error
+	// Delete removes an entry from the catalog at key
+	Delete(key string) error
+
+	// AcceptRequest records the timestamp of a dataset request for key,
+	// returning false if it isn't later than the last request accepted for
+	// key. Timestamps are kept after the entry they're for is deleted
+	AcceptRequest(key string, ts time.Time) (bool, error)
+}
+
+// DatasetKey is the catalog key for a dataset. Datasets are keyed by the
+// profileID of the publisher, so catalog entries survive username changes
+func DatasetKey(profileID, name string) string {
+	return fmt.Sprintf("%s/%s", profileID, name)
+}
+
+// DatasetRequestMaxAge is the longest a registry accepts a signed dataset
+// request for after it was created
+var DatasetRequestMaxAge = time.Minute * 10
+
+const (
+	// ActionPublish adds a dataset to the catalog
+	ActionPublish = "publish"
+	// ActionUnpublish removes a dataset from the catalog
+	ActionUnpublish = "unpublish"
+)
+
+// DatasetRequest is a signed request to publish or unpublish a dataset.
+// Requests must be signed by the key the publishing profile is registered with
+type DatasetRequest struct {
+	Action    string `json:"action"`
+	Username  string `json:"username"`
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	ProfileID string `json:"profileid"`
+	// Timestamp is when the request was signed
+	Timestamp time.Time `json:"timestamp"`
+	Signature string    `json:"signature"`
+	// Preview of the dataset version to list. Required to publish
+	Preview *dataset.Dataset `json:"preview,omitempty"`
+}
+
+// NewDatasetRequest creates a dataset request signed with privKey. Publish
+// requests list preview, unpublish requests only need the username & name of
+// preview to be set
+func NewDatasetRequest(action string, preview *dataset.Dataset, privKey crypto.PrivKey) (*DatasetRequest, error) {
+	if preview == nil {
+		return nil, fmt.Errorf("dataset is required")
+	}
+	pid, err := profileIDFromPrivKey(privKey)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &DatasetRequest{
+		Action:    action,
+		Username:  preview.Peername,
+		Name:      preview.Name,
+		Path:      preview.Path,
+		ProfileID: pid,
+		Timestamp: nowFunc().UTC(),
+	}
+	if action == ActionPublish {
+		r.Preview = preview
+	}
+	sigbytes, err := privKey.Sign(r.signingBytes())
+	if err != nil {
+		return nil, fmt.Errorf("error signing %s", err.Error())
+	}
+	r.Signature = base64.StdEncoding.EncodeToString(sigbytes)
+	return r, nil
+}
+
+// Validate is a sanity check that all required values are present
+func (r *DatasetRequest) Validate() error {
+	if r.Action != ActionPublish && r.Action != ActionUnpublish {
+		return fmt.Errorf("action must be %q or %q", ActionPublish, ActionUnpublish)
+	}
+	if r.Username == "" {
+		return fmt.Errorf("username is required")
+	}
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if r.ProfileID == "" {
+		return fmt.Errorf("profileID is required")
+	}
+	if r.Timestamp.IsZero() {
+		return fmt.Errorf("timestamp is required")
+	}
+	if r.Signature == "" {
+		return fmt.Errorf("signature is required")
+	}
+	if r.Action == ActionPublish {
+		if r.Path == "" {
+			return fmt.Errorf("path is required")
+		}
+		if r.Preview == nil {
+			return fmt.Errorf("preview is required")
+		}
+	}
+	return nil
+}
+
+// signingBytes covers the action so a publish signature can't be replayed to
+// unpublish, the path & a hash of the preview so a signature can't list
+// different data, and the time of the request, which the registry uses to
+// reject requests it has already seen
+func (r *DatasetRequest) signingBytes() []byte {
+	return []byte(fmt.Sprintf("%s:%s/%s@%s:%s:%d", r.Action, r.Username, r.Name, r.Path, r.previewHash(), r.Timestamp.UnixNano()))
+}
+
+// previewHash is the hex-encoded sha256 hash of the JSON-encoded preview,
+// empty for requests without one
+func (r *DatasetRequest) previewHash() string {
+	if r.Preview == nil {
+		return ""
+	}
+	data, err := json.Marshal(r.Preview)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// accept rejects requests for a catalog key that aren't newer than the last
+// request the catalog accepted for it, so a signed request can't be replayed
+// while it's younger than DatasetRequestMaxAge
+func (r *DatasetRequest) accept(datasets Datasets, key string) error {
+	ok, err := datasets.AcceptRequest(key, r.Timestamp)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("dataset request has expired")
+	}
+	return nil
+}
+
+// checkAge rejects requests older than DatasetRequestMaxAge. Requests are
+// checked after their signature, so the timestamp can be trusted
+func (r *DatasetRequest) checkAge() error {
+	if nowFunc().Sub(r.Timestamp) > DatasetRequestMaxAge {
+		return fmt.Errorf("dataset request has expired")
+	}
+	return nil
+}
+
+// verify checks the request was signed by the profile registered to the
+// request username, returning the profile. Requests for datasets in an
+// organization's namespace must be signed by a member of the organization,
+// and return a profile that stands in for the organization
+func (r *DatasetRequest) verify(profiles Profiles, orgs Orgs) (*Profile, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	pro, err := profiles.Load(r.Username)
+	if err == ErrNotFound && orgs != nil {
+		return r.verifyOrgMember(profiles, orgs)
+	}
+	if err != nil {
+		if err == ErrNotFound {
+			return nil, fmt.Errorf("username '%s' is not registered", r.Username)
+		}
+		return nil, err
+	}
+	if pro.ProfileID != r.ProfileID {
+		return nil, fmt.Errorf("username '%s' is taken", r.Username)
+	}
+	if pro.Suspended {
+		return nil, ErrProfileSuspended
+	}
+	if err := verify(pro.PublicKey, r.Signature, r.signingBytes()); err != nil {
+		return nil, err
+	}
+	if err := r.checkAge(); err != nil {
+		return nil, err
+	}
+	return pro, nil
+}
+
+// verifyOrgMember checks a request was signed by the registered key of a
+// member of the organization named by the request username
+func (r *DatasetRequest) verifyOrgMember(profiles Profiles, orgs Orgs) (*Profile, error) {
+	org, err := orgs.LoadOrg(r.Username)
+	if err != nil {
+		if err == ErrNotFound {
+			return nil, fmt.Errorf("username '%s' is not registered", r.Username)
+		}
+		return nil, err
+	}
+	if !org.IsMember(r.ProfileID) {
+		return nil, fmt.Errorf("not a member of '%s'", org.Name)
+	}
+	member, err := loadProfileByID(profiles, r.ProfileID)
+	if err != nil {
+		if err == ErrNotFound {
+			return nil, fmt.Errorf("profile '%s' is not registered", r.ProfileID)
+		}
+		return nil, err
+	}
+	if member.Suspended {
+		return nil, ErrProfileSuspended
+	}
+	if err := verify(member.PublicKey, r.Signature, r.signingBytes()); err != nil {
+		return nil, err
+	}
+	if err := r.checkAge(); err != nil {
+		return nil, err
+	}
+	return &Profile{Username: org.Name, ProfileID: org.ProfileID}, nil
+}
+
+// PublishDataset adds a dataset preview to the catalog, replacing any
+// previously published version of the dataset. The request must be signed by
+// the profile registered to the dataset's username, or by a member when the
+// username belongs to an organization in orgs. orgs may be nil
+func PublishDataset(datasets Datasets, profiles Profiles, orgs Orgs, r *DatasetRequest) (*dataset.Dataset, error) {
+	if r.Action != ActionPublish {
+		return nil, fmt.Errorf("action must be %q", ActionPublish)
+	}
+	pro, err := r.verify(profiles, orgs)
+	if err != nil {
+		return nil, err
+	}
+
+	key := DatasetKey(pro.ProfileID, r.Name)
+	if err := r.accept(datasets, key); err != nil {
+		return nil, err
+	}
+
+	cpy := *r.Preview
+	ds := &cpy
+	ds.Peername = pro.Username
+	ds.Name = r.Name
+	ds.Path = r.Path
+	ds.ProfileID = pro.ProfileID
+
+	if _, err := datasets.Load(key); err == nil {
+		return ds, datasets.Update(key, ds)
+	}
+	return ds, datasets.Create(key, ds)
+}
+
+// UnpublishDataset removes a dataset from the catalog. The request must be
+// signed by the profile registered to the dataset's username, or by a member
+// when the username belongs to an organization in orgs. orgs may be nil
+func UnpublishDataset(datasets Datasets, profiles Profiles, orgs Orgs, r *DatasetRequest) (*dataset.Dataset, error) {
+	if r.Action != ActionUnpublish {
+		return nil, fmt.Errorf("action must be %q", ActionUnpublish)
+	}
+	pro, err := r.verify(profiles, orgs)
+	if err != nil {
+		return nil, err
+	}
+
+	key := DatasetKey(pro.ProfileID, r.Name)
+	ds, err := datasets.Load(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.accept(datasets, key); err != nil {
+		return nil, err
+	}
+	return ds, datasets.Delete(key)
+}
+
+// LookupDataset fetches the catalog entry for a dataset by username & name,
+// following profile renames. The returned dataset lists the publisher's
+// current username. Usernames that aren't registered are looked up in orgs,
+// which may be nil
+func LookupDataset(datasets Datasets, profiles Profiles, orgs Orgs, username, name string) (*dataset.Dataset, error) {
+	pro, err := namespaceOwner(profiles, orgs, username)
+	if err != nil {
+		return nil, err
+	}
+	if pro.Suspended {
+		return nil, ErrNotFound
+	}
+	ds, err := datasets.Load(DatasetKey(pro.ProfileID, name))
+	if err != nil {
+		return nil, err
+	}
+	cpy := *ds
+	cpy.Peername = pro.Username
+	return &cpy, nil
+}
+
+// ListDatasets lists the catalog ordered by key. If username is set, only
+// datasets published by that user are listed. Datasets published by suspended
+// profiles aren't listed. Usernames that aren't registered are looked up in
+// orgs, which may be nil
+func ListDatasets(datasets Datasets, profiles Profiles, orgs Orgs, username string, offset, limit int) ([]*dataset.Dataset, error) {
+	var pro *Profile
+	suspended := map[string]bool{}
+	if username != "" {
+		var err error
+		if pro, err = namespaceOwner(profiles, orgs, username); err != nil {
+			return nil, err
+		}
+		if pro.Suspended {
+			return []*dataset.Dataset{}, nil
+		}
+	} else {
+		err := profiles.Range(func(_ string, p *Profile) (bool, error) {
+			if p.Suspended {
+				suspended[p.ProfileID] = true
+			}
+			return true, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res := []*dataset.Dataset{}
+	i := 0
+	err := datasets.SortedRange(func(key string, ds *dataset.Dataset) (bool, error) {
+		if pro != nil && ds.ProfileID != pro.ProfileID || suspended[ds.ProfileID] {
+			return true, nil
+		}
+		if i >= offset {
+			if pro != nil {
+				cpy := *ds
+				cpy.Peername = pro.Username
+				ds = &cpy
+			}
+			res = append(res, ds)
+		}
+		i++
+		return limit < 0 || len(res) < limit, nil
+	})
+	return res, err
+}
+
+// MemDatasets is a catalog of datasets safe for concurrent use
+type MemDatasets struct {
+	sync.RWMutex
+	ds       map[string]*dataset.Dataset
+	requests map[string]time.Time
+}
+
+var _ Datasets = (*MemDatasets)(nil)
+
+// NewMemDatasets allocates a new *MemDatasets map
+func NewMemDatasets() *MemDatasets {
+	return &MemDatasets{
+		ds:       make(map[string]*dataset.Dataset),
+		requests: make(map[string]time.Time),
+	}
+}
+
+// Len returns the number of records in the map
+func (ds *MemDatasets) Len() (int, error) {
+	ds.RLock()
+	defer ds.RUnlock()
+	return len(ds.ds), nil
+}
+
+// Load fetches a dataset from the catalog by key
+func (ds *MemDatasets) Load(key string) (*dataset.Dataset, error) {
+	ds.RLock()
+	defer ds.RUnlock()
+	d, ok := ds.ds[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return d, nil
+}
+
+// Range calls an iteration fuction on each element in the map until
+// the end of the list is reached or iter returns false
+func (ds *MemDatasets) Range(iter func(key string, d *dataset.Dataset) (kontinue bool, err error)) error {
+	ds.RLock()
+	defer ds.RUnlock()
+	for key, d := range ds.ds {
+		kontinue, err := iter(key, d)
+		if err != nil {
+			return err
+		}
+		if !kontinue {
+			break
+		}
+	}
+	return nil
+}
+
+// SortedRange is like range but with deterministic key ordering
+func (ds *MemDatasets) SortedRange(iter func(key string, d *dataset.Dataset) (kontinue bool, err error)) error {
+	ds.RLock()
+	defer ds.RUnlock()
+	keys := make([]string, 0, len(ds.ds))
+	for key := range ds.ds {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		kontinue, err := iter(key, ds.ds[key])
+		if err != nil {
+			return err
+		}
+		if !kontinue {
+			break
+		}
+	}
+	return nil
+}
+
+// Create adds a dataset
+func (ds *MemDatasets) Create(key string, value *dataset.Dataset) error {
+	ds.Lock()
+	ds.ds[key] = value
+	ds.Unlock()
+	return nil
+}
+
+// Update modifies an existing dataset
+func (ds *MemDatasets) Update(key string, value *dataset.Dataset) error {
+	ds.Lock()
+	defer ds.Unlock()
+	if _, ok := ds.ds[key]; !ok {
+		return ErrNotFound
+	}
+	ds.ds[key] = value
+	return nil
+}
+
+// Delete removes a dataset from the catalog at key
+func (ds *MemDatasets) Delete(key string) error {
+	ds.Lock()
+	delete(ds.ds, key)
+	ds.Unlock()
+	return nil
+}
+
+// AcceptRequest records the timestamp of a dataset request for key if it's
+// later than the last one
+func (ds *MemDatasets) AcceptRequest(key string, ts time.Time) (bool, error) {
+	ds.Lock()
+	defer ds.Unlock()
+	if !ts.After(ds.requests[key]) {
+		return false, nil
+	}
+	ds.requests[key] = ts
+	return true, nil
+}
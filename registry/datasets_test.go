@@ -0,0 +1,229 @@
+package registry
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/qri-io/dataset"
+)
+
+func TestPublishDataset(t *testing.T) {
+	ps := NewMemProfiles()
+	datasets := NewMemDatasets()
+	src := rand.New(rand.NewSource(0))
+	newKey := func() crypto.PrivKey {
+		pk, _, err := crypto.GenerateSecp256k1Key(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pk
+	}
+	register := func(username string, pk crypto.PrivKey) {
+		p, err := ProfileFromPrivateKey(&Profile{Username: username}, pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := RegisterProfile(ps, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	request := func(action, username, name string, pk crypto.PrivKey) *DatasetRequest {
+		r, err := NewDatasetRequest(action, &dataset.Dataset{Peername: username, Name: name, Path: "/ipfs/QmFoo"}, pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	key, otherKey := newKey(), newKey()
+	register("b5", key)
+	register("other", otherKey)
+
+	forged := request(ActionPublish, "b5", "cities", otherKey)
+	replayed := *request(ActionPublish, "b5", "cities", key)
+	replayed.Path = "/ipfs/QmBar"
+	retimed := *request(ActionPublish, "b5", "cities", key)
+	retimed.Timestamp = retimed.Timestamp.Add(time.Second)
+	tampered := *request(ActionPublish, "b5", "cities", key)
+	tampered.Preview = &dataset.Dataset{Peername: "b5", Name: "cities", Path: "/ipfs/QmFoo", Meta: &dataset.Meta{Title: "tampered"}}
+
+	prevNowFunc := nowFunc
+	nowFunc = func() time.Time { return prevNowFunc().Add(-2 * DatasetRequestMaxAge) }
+	expired := request(ActionPublish, "b5", "cities", key)
+	nowFunc = prevNowFunc
+
+	bad := []struct {
+		description string
+		r           *DatasetRequest
+		err         string
+	}{
+		{"missing fields", &DatasetRequest{Action: ActionPublish, Username: "b5"}, "name is required"},
+		{"wrong action", request(ActionUnpublish, "b5", "cities", key), `action must be "publish"`},
+		{"unregistered username", request(ActionPublish, "nope", "cities", key), "username 'nope' is not registered"},
+		{"another user's key", forged, "username 'b5' is taken"},
+		{"replayed signature", &replayed, "mismatched signature"},
+		{"retimed signature", &retimed, "mismatched signature"},
+		{"tampered preview", &tampered, "mismatched signature"},
+		{"expired request", expired, "dataset request has expired"},
+	}
+	for _, c := range bad {
+		if _, err := PublishDataset(datasets, ps, nil, c.r); err == nil || err.Error() != c.err {
+			t.Errorf("%s: error mismatch. expected: %q, got: %v", c.description, c.err, err)
+		}
+	}
+
+	for _, name := range []string{"cities", "airports"} {
+		if _, err := PublishDataset(datasets, ps, nil, request(ActionPublish, "b5", name, key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := PublishDataset(datasets, ps, nil, request(ActionPublish, "other", "cities", otherKey)); err != nil {
+		t.Fatal(err)
+	}
+	// re-publishing replaces the existing entry
+	republish := request(ActionPublish, "b5", "cities", key)
+	if _, err := PublishDataset(datasets, ps, nil, republish); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := PublishDataset(datasets, ps, nil, republish); err == nil || err.Error() != "dataset request has expired" {
+		t.Errorf("expected a replayed request to be rejected, got: %v", err)
+	}
+	if n, _ := datasets.Len(); n != 3 {
+		t.Errorf("expected 3 catalog entries, got: %d", n)
+	}
+
+	ds, err := LookupDataset(datasets, ps, nil, "b5", "cities")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds.Peername != "b5" || ds.Path != "/ipfs/QmFoo" {
+		t.Errorf("unexpected dataset. got peername: %q path: %q", ds.Peername, ds.Path)
+	}
+
+	list, err := ListDatasets(datasets, ps, nil, "b5", 0, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Errorf("expected b5 to have 2 datasets listed, got: %d", len(list))
+	}
+	if list, _ = ListDatasets(datasets, ps, nil, "", 1, 1); len(list) != 1 {
+		t.Errorf("expected paginated listing to return 1 dataset, got: %d", len(list))
+	}
+
+	// catalog entries follow profile renames
+	rename, err := NewRename("b5", "b6", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RenameProfile(ps, rename); err != nil {
+		t.Fatal(err)
+	}
+	if ds, err = LookupDataset(datasets, ps, nil, "b5", "cities"); err != nil {
+		t.Fatal(err)
+	}
+	if ds.Peername != "b6" {
+		t.Errorf("expected renamed dataset peername to be b6, got: %q", ds.Peername)
+	}
+
+	// suspended profiles aren't listed
+	other, err := ps.Load("other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.Suspended = true
+	if err := ps.Update("other", other); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ = ListDatasets(datasets, ps, nil, "", 0, -1); len(list) != 2 {
+		t.Errorf("expected suspended profile datasets to be omitted, got %d datasets", len(list))
+	}
+	if _, err := LookupDataset(datasets, ps, nil, "other", "cities"); err != ErrNotFound {
+		t.Errorf("expected looking up a suspended profile dataset to be ErrNotFound, got: %v", err)
+	}
+
+	if _, err := UnpublishDataset(datasets, ps, nil, request(ActionUnpublish, "b6", "cities", key)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LookupDataset(datasets, ps, nil, "b6", "cities"); err != ErrNotFound {
+		t.Errorf("expected unpublished dataset to be ErrNotFound, got: %v", err)
+	}
+	if _, err := PublishDataset(datasets, ps, nil, republish); err == nil {
+		t.Error("expected replaying a publish after unpublishing to fail")
+	}
+	if _, err := UnpublishDataset(datasets, ps, nil, request(ActionUnpublish, "b6", "cities", key)); err != ErrNotFound {
+		t.Errorf("expected unpublishing twice to be ErrNotFound, got: %v", err)
+	}
+}
+
+type testOrgs map[string]*Org
+
+func (o testOrgs) LoadOrg(name string) (*Org, error) {
+	if org, ok := o[name]; ok {
+		return org, nil
+	}
+	return nil, ErrNotFound
+}
+
+func TestPublishOrgDataset(t *testing.T) {
+	ps := NewMemProfiles()
+	datasets := NewMemDatasets()
+	src := rand.New(rand.NewSource(0))
+	newKey := func() crypto.PrivKey {
+		pk, _, err := crypto.GenerateSecp256k1Key(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pk
+	}
+	register := func(username string, pk crypto.PrivKey) *Profile {
+		p, err := ProfileFromPrivateKey(&Profile{Username: username}, pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := RegisterProfile(ps, p); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	request := func(action, username, name string, pk crypto.PrivKey) *DatasetRequest {
+		r, err := NewDatasetRequest(action, &dataset.Dataset{Peername: username, Name: name, Path: "/ipfs/QmFoo"}, pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	memberKey, outsiderKey := newKey(), newKey()
+	member := register("janelle", memberKey)
+	register("outsider", outsiderKey)
+	orgs := testOrgs{
+		"climate_org": {Name: "climate_org", ProfileID: "QmOrgID", Members: []string{member.ProfileID}},
+	}
+
+	if _, err := PublishDataset(datasets, ps, nil, request(ActionPublish, "climate_org", "temperatures", memberKey)); err == nil {
+		t.Error("expected publishing to an org without org support to fail")
+	}
+	if _, err := PublishDataset(datasets, ps, orgs, request(ActionPublish, "climate_org", "temperatures", outsiderKey)); err == nil || err.Error() != "not a member of 'climate_org'" {
+		t.Errorf("expected non-member publish to fail, got: %v", err)
+	}
+	ds, err := PublishDataset(datasets, ps, orgs, request(ActionPublish, "climate_org", "temperatures", memberKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds.Peername != "climate_org" || ds.ProfileID != "QmOrgID" {
+		t.Errorf("expected dataset published under the org, got: %s/%s", ds.Peername, ds.ProfileID)
+	}
+
+	if _, err := LookupDataset(datasets, ps, orgs, "climate_org", "temperatures"); err != nil {
+		t.Errorf("expected looking up an org dataset to succeed, got: %s", err)
+	}
+	if list, err := ListDatasets(datasets, ps, orgs, "climate_org", 0, -1); err != nil || len(list) != 1 {
+		t.Errorf("expected one org dataset, got: %d, %v", len(list), err)
+	}
+	if _, err := UnpublishDataset(datasets, ps, orgs, request(ActionUnpublish, "climate_org", "temperatures", memberKey)); err != nil {
+		t.Errorf("expected member unpublish to succeed, got: %s", err)
+	}
+}
@@ -0,0 +1,171 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/qri-io/dataset"
+)
+
+// FileDatasets is a catalog of datasets persisted to a JSON file, keeping the
+// catalog across registry restarts. Every change rewrites the file
+type FileDatasets struct {
+	path string
+
+	lk       sync.RWMutex
+	ds       map[string]*dataset.Dataset
+	requests map[string]time.Time
+}
+
+// fileDatasetsData is the layout of the catalog file
+type fileDatasetsData struct {
+	Datasets map[string]*dataset.Dataset `json:"datasets"`
+	Requests map[string]time.Time        `json:"requests"`
+}
+
+var _ Datasets = (*FileDatasets)(nil)
+
+// NewFileDatasets creates a catalog backed by the file at path, reading any
+// datasets written to it previously
+func NewFileDatasets(path string) (*FileDatasets, error) {
+	if path == "" {
+		return nil, fmt.Errorf("datasets file path is required")
+	}
+	ds := &FileDatasets{
+		path:     path,
+		ds:       map[string]*dataset.Dataset{},
+		requests: map[string]time.Time{},
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ds, nil
+		}
+		return nil, err
+	}
+	file := fileDatasetsData{}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("reading datasets: %w", err)
+	}
+	if file.Datasets != nil {
+		ds.ds = file.Datasets
+	}
+	if file.Requests != nil {
+		ds.requests = file.Requests
+	}
+	return ds, nil
+}
+
+// Len returns the number of records in the catalog
+func (ds *FileDatasets) Len() (int, error) {
+	ds.lk.RLock()
+	defer ds.lk.RUnlock()
+	return len(ds.ds), nil
+}
+
+// Load fetches a dataset from the catalog by key
+func (ds *FileDatasets) Load(key string) (*dataset.Dataset, error) {
+	ds.lk.RLock()
+	defer ds.lk.RUnlock()
+	d, ok := ds.ds[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return d, nil
+}
+
+// Range calls an iteration fuction on each element in the catalog until
+// the end of the list is reached or iter returns false
+func (ds *FileDatasets) Range(iter func(key string, d *dataset.Dataset) (kontinue bool, err error)) error {
+	ds.lk.RLock()
+	defer ds.lk.RUnlock()
+	for key, d := range ds.ds {
+		kontinue, err := iter(key, d)
+		if err != nil {
+			return err
+		}
+		if !kontinue {
+			break
+		}
+	}
+	return nil
+}
+
+// SortedRange is like range but with deterministic key ordering
+func (ds *FileDatasets) SortedRange(iter func(key string, d *dataset.Dataset) (kontinue bool, err error)) error {
+	ds.lk.RLock()
+	defer ds.lk.RUnlock()
+	keys := make([]string, 0, len(ds.ds))
+	for key := range ds.ds {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		kontinue, err := iter(key, ds.ds[key])
+		if err != nil {
+			return err
+		}
+		if !kontinue {
+			break
+		}
+	}
+	return nil
+}
+
+// Create adds a dataset
+func (ds *FileDatasets) Create(key string, value *dataset.Dataset) error {
+	ds.lk.Lock()
+	defer ds.lk.Unlock()
+	ds.ds[key] = value
+	return ds.write()
+}
+
+// Update modifies an existing dataset
+func (ds *FileDatasets) Update(key string, value *dataset.Dataset) error {
+	ds.lk.Lock()
+	defer ds.lk.Unlock()
+	if _, ok := ds.ds[key]; !ok {
+		return ErrNotFound
+	}
+	ds.ds[key] = value
+	return ds.write()
+}
+
+// Delete removes a dataset from the catalog at key
+func (ds *FileDatasets) Delete(key string) error {
+	ds.lk.Lock()
+	defer ds.lk.Unlock()
+	delete(ds.ds, key)
+	return ds.write()
+}
+
+// AcceptRequest records the timestamp of a dataset request for key if it's
+// later than the last one
+func (ds *FileDatasets) AcceptRequest(key string, ts time.Time) (bool, error) {
+	ds.lk.Lock()
+	defer ds.lk.Unlock()
+	if !ts.After(ds.requests[key]) {
+		return false, nil
+	}
+	ds.requests[key] = ts
+	return true, ds.write()
+}
+
+// write persists the catalog. must be called with the lock held
+func (ds *FileDatasets) write() error {
+	data, err := json.Marshal(fileDatasetsData{Datasets: ds.ds, Requests: ds.requests})
+	if err != nil {
+		return err
+	}
+	tmp := ds.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, ds.path)
+}
@@ -0,0 +1,69 @@
+package registry
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/qri-io/dataset"
+)
+
+func TestFileDatasets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registry_file_datasets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "datasets.json")
+
+	ds, err := NewFileDatasets(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileDatasets(""); err == nil {
+		t.Error("expected creating file datasets without a path to fail")
+	}
+
+	for _, name := range []string{"a", "b", "c"} {
+		if err := ds.Create(DatasetKey("pid", name), &dataset.Dataset{Name: name, Path: "/ipfs/" + name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ds.Delete(DatasetKey("pid", "b")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ds.Update(DatasetKey("pid", "b"), &dataset.Dataset{Name: "b"}); err != ErrNotFound {
+		t.Errorf("expected updating a missing dataset to return ErrNotFound, got: %v", err)
+	}
+	if err := ds.Update(DatasetKey("pid", "c"), &dataset.Dataset{Name: "c", Path: "/ipfs/c2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := ds.AcceptRequest(DatasetKey("pid", "b"), time.Unix(10, 0)); !ok || err != nil {
+		t.Errorf("expected first request to be accepted, got: %t %v", ok, err)
+	}
+
+	// the catalog survives reopening the file
+	if ds, err = NewFileDatasets(path); err != nil {
+		t.Fatal(err)
+	}
+	if l, _ := ds.Len(); l != 2 {
+		t.Errorf("expected 2 datasets after reopening, got: %d", l)
+	}
+	keys := []string{}
+	ds.SortedRange(func(key string, _ *dataset.Dataset) (bool, error) {
+		keys = append(keys, key)
+		return true, nil
+	})
+	if len(keys) != 2 || keys[0] != "pid/a" || keys[1] != "pid/c" {
+		t.Errorf("unexpected sorted keys: %v", keys)
+	}
+	if d, err := ds.Load(DatasetKey("pid", "c")); err != nil || d.Path != "/ipfs/c2" {
+		t.Errorf("expected dataset c to keep its update, got: %v %v", d, err)
+	}
+	if ok, _ := ds.AcceptRequest(DatasetKey("pid", "b"), time.Unix(10, 0)); ok {
+		t.Error("expected a request no later than the last accepted one to be rejected after reopening")
+	}
+}
@@ -0,0 +1,167 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+)
+
+// FileProfiles is a set of profiles persisted to a JSON file, keeping profiles
+// across registry restarts. Every change rewrites the file
+type FileProfiles struct {
+	path string
+
+	lk sync.RWMutex
+	ps map[string]*Profile
+}
+
+var _ Profiles = (*FileProfiles)(nil)
+
+// NewFileProfiles creates profiles backed by the file at path, reading any
+// profiles written to it previously
+func NewFileProfiles(path string) (*FileProfiles, error) {
+	if path == "" {
+		return nil, fmt.Errorf("profiles file path is required")
+	}
+	ps := &FileProfiles{
+		path: path,
+		ps:   map[string]*Profile{},
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ps, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &ps.ps); err != nil {
+		return nil, fmt.Errorf("reading profiles: %w", err)
+	}
+	return ps, nil
+}
+
+// Len returns the number of records in the set
+func (ps *FileProfiles) Len() (int, error) {
+	ps.lk.RLock()
+	defer ps.lk.RUnlock()
+	return len(ps.ps), nil
+}
+
+// Load fetches a profile from the list by key
+func (ps *FileProfiles) Load(key string) (*Profile, error) {
+	ps.lk.RLock()
+	defer ps.lk.RUnlock()
+	p, ok := ps.ps[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return p, nil
+}
+
+// Range calls an iteration fuction on each element in the set until
+// the end of the list is reached or iter returns false
+func (ps *FileProfiles) Range(iter func(key string, p *Profile) (kontinue bool, err error)) error {
+	ps.lk.RLock()
+	defer ps.lk.RUnlock()
+	for key, p := range ps.ps {
+		kontinue, err := iter(key, p)
+		if err != nil {
+			return err
+		}
+		if !kontinue {
+			break
+		}
+	}
+	return nil
+}
+
+// SortedRange is like range but with deterministic key ordering
+func (ps *FileProfiles) SortedRange(iter func(key string, p *Profile) (kontinue bool, err error)) error {
+	ps.lk.RLock()
+	defer ps.lk.RUnlock()
+	keys := make([]string, 0, len(ps.ps))
+	for key := range ps.ps {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		kontinue, err := iter(key, ps.ps[key])
+		if err != nil {
+			return err
+		}
+		if !kontinue {
+			break
+		}
+	}
+	return nil
+}
+
+// Create adds a profile, failing if the username is taken
+func (ps *FileProfiles) Create(key string, value *Profile) error {
+	ps.lk.Lock()
+	defer ps.lk.Unlock()
+	if err := checkUsernameFree(ps.ps, key, value, ""); err != nil {
+		return err
+	}
+	ps.ps[key] = value
+	return ps.write()
+}
+
+// Rename moves a profile from one username to another, writing the change
+// in a single rewrite of the file
+func (ps *FileProfiles) Rename(from, to string, value *Profile) error {
+	ps.lk.Lock()
+	defer ps.lk.Unlock()
+	prev, ok := ps.ps[from]
+	if !ok {
+		return ErrNotFound
+	}
+	if err := checkUsernameFree(ps.ps, to, value, from); err != nil {
+		return err
+	}
+	delete(ps.ps, from)
+	ps.ps[to] = value
+	if err := ps.write(); err != nil {
+		delete(ps.ps, to)
+		ps.ps[from] = prev
+		return err
+	}
+	return nil
+}
+
+// Update modifies an existing profile
+func (ps *FileProfiles) Update(key string, value *Profile) error {
+	ps.lk.Lock()
+	defer ps.lk.Unlock()
+	if _, ok := ps.ps[key]; !ok {
+		return ErrNotFound
+	}
+	ps.ps[key] = value
+	return ps.write()
+}
+
+// Delete removes a profile at key
+func (ps *FileProfiles) Delete(key string) error {
+	ps.lk.Lock()
+	defer ps.lk.Unlock()
+	delete(ps.ps, key)
+	return ps.write()
+}
+
+// write persists the set. must be called with the lock held. profiles can
+// include passwords, so the file is only readable by its owner
+func (ps *FileProfiles) write() error {
+	data, err := json.Marshal(ps.ps)
+	if err != nil {
+		return err
+	}
+	tmp := ps.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, ps.path)
+}
@@ -0,0 +1,87 @@
+package registry
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+)
+
+func TestFileProfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registry_file_profiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "profiles.json")
+
+	ps, err := NewFileProfiles(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileProfiles(""); err == nil {
+		t.Error("expected creating file profiles without a path to fail")
+	}
+
+	src := rand.New(rand.NewSource(0))
+	for _, username := range []string{"a", "b", "c"} {
+		pk, _, err := crypto.GenerateSecp256k1Key(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := ProfileFromPrivateKey(&Profile{Username: username}, pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := RegisterProfile(ps, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ps.Delete("b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ps.Update("b", &Profile{Username: "b"}); err != ErrNotFound {
+		t.Errorf("expected updating a missing profile to return ErrNotFound, got: %v", err)
+	}
+	if _, err := SuspendProfile(ps, "c", true); err != nil {
+		t.Fatal(err)
+	}
+	a, err := ps.Load("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ps.Rename("a", "c", a); err == nil {
+		t.Error("expected renaming to a taken username to fail")
+	}
+	if err := ps.Rename("b", "d", a); err != ErrNotFound {
+		t.Errorf("expected renaming a missing profile to return ErrNotFound, got: %v", err)
+	}
+	renamed := *a
+	renamed.Username = "d"
+	if err := ps.Rename("a", "d", &renamed); err != nil {
+		t.Fatal(err)
+	}
+
+	// profiles survive reopening the file
+	ps, err = NewFileProfiles(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l, _ := ps.Len(); l != 2 {
+		t.Errorf("expected 2 profiles after reopening, got: %d", l)
+	}
+	keys := []string{}
+	ps.SortedRange(func(key string, p *Profile) (bool, error) {
+		keys = append(keys, key)
+		return true, nil
+	})
+	if len(keys) != 2 || keys[0] != "c" || keys[1] != "d" {
+		t.Errorf("unexpected sorted keys: %v", keys)
+	}
+	if p, err := ps.Load("c"); err != nil || !p.Suspended {
+		t.Errorf("expected profile c to stay suspended, got: %v %v", p, err)
+	}
+}
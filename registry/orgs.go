@@ -0,0 +1,93 @@
+package registry
+
+import (
+	"context"
+	"errors"
+
+	"github.com/qri-io/qri/logbook"
+)
+
+// Org is an organization that publishes datasets to the registry.
+// Organizations don't have keys of their own, members publish datasets in an
+// organization's namespace by signing with their own registered keys
+type Org struct {
+	Name      string
+	ProfileID string
+	// Members lists the profileIDs of organization members
+	Members []string
+}
+
+// IsMember returns true if profileID is a member of the organization
+func (o *Org) IsMember(profileID string) bool {
+	for _, id := range o.Members {
+		if id == profileID {
+			return true
+		}
+	}
+	return false
+}
+
+// Orgs looks up organizations by name
+type Orgs interface {
+	LoadOrg(name string) (*Org, error)
+}
+
+// LogbookOrgs reads organizations from a logbook, usually the logbook of the
+// registry's remote. Remotes only accept organization logs pushed by members
+// with membership changes signed by an admin, making the remote's logbook the
+// registry's record of membership
+type LogbookOrgs struct {
+	Book *logbook.Book
+}
+
+var _ Orgs = (*LogbookOrgs)(nil)
+
+// LoadOrg gets an organization by name
+func (o LogbookOrgs) LoadOrg(name string) (*Org, error) {
+	org, err := o.Book.Org(context.Background(), name)
+	if err != nil {
+		if errors.Is(err, logbook.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	res := &Org{
+		Name:      org.Name,
+		ProfileID: org.ProfileID,
+		Members:   make([]string, len(org.Members)),
+	}
+	for i, m := range org.Members {
+		res.Members[i] = m.ProfileID
+	}
+	return res, nil
+}
+
+// loadProfileByID finds the registered profile for a profileID
+func loadProfileByID(store Profiles, profileID string) (pro *Profile, err error) {
+	err = store.Range(func(_ string, p *Profile) (bool, error) {
+		if p.ProfileID == profileID {
+			pro = p
+			return false, nil
+		}
+		return true, nil
+	})
+	if err == nil && pro == nil {
+		err = ErrNotFound
+	}
+	return pro, err
+}
+
+// namespaceOwner resolves the owner of a dataset namespace, following profile
+// renames. Usernames that aren't registered fall back to organizations when
+// orgs is non-nil, returning a profile that stands in for the organization
+func namespaceOwner(profiles Profiles, orgs Orgs, username string) (*Profile, error) {
+	pro, err := ResolveUsername(profiles, username)
+	if err != ErrNotFound || orgs == nil {
+		return pro, err
+	}
+	org, err := orgs.LoadOrg(username)
+	if err != nil {
+		return nil, err
+	}
+	return &Profile{Username: org.Name, ProfileID: org.ProfileID}, nil
+}
@@ -29,6 +29,17 @@ type Profile struct {
 	ProfileID string `json:"profileid"`
 	PublicKey string `json:"publickey"`
 	Signature string `json:"signature"`
+
+	// PreviousUsernames lists usernames this profile was renamed from, oldest
+	// first. Previous usernames redirect to the current username
+	PreviousUsernames []string `json:"previousUsernames,omitempty"`
+	// Renamed is the timestamp of the last rename applied to the profile.
+	// Renames signed at or before Renamed are rejected as replays
+	Renamed time.Time `json:"renamed,omitempty"`
+
+	// Suspended profiles are hidden from lookups and can't be changed by their
+	// owners. A suspended profile keeps its username
+	Suspended bool `json:"suspended,omitempty"`
 }
 
 // Validate is a sanity check that all required values are present
@@ -3,8 +3,11 @@ package registry
 import (
 	"fmt"
 	"sort"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/qri-io/qri/dsref"
 )
 
 var (
@@ -31,7 +34,10 @@ type Profiles interface {
 
 	// Create adds an entry, bypassing the register process
 	// store is only exported for administrative use cases.
-	// most of the time callers should use Register instead
+	// most of the time callers should use Register instead.
+	// Create must fail if key is already registered regardless of case, or is
+	// a previous username of another profile, checking & writing under one
+	// lock
 	Create(key string, value *Profile) error
 	// Update modifies an existing profile
 	Update(key string, value *Profile) error
@@ -39,9 +45,48 @@ type Profiles interface {
 	// Delete is only exported for administrative use cases.
 	// most of the time callers should use Deregister instead
 	Delete(key string) error
+	// Rename atomically moves the profile at from to to, storing value in its
+	// place. Rename fails like Create if to is taken by another profile, and
+	// returns ErrNotFound if from doesn't exist
+	Rename(from, to string, value *Profile) error
+}
+
+// ReservedUsernames can't be registered by peers. Administrators can still
+// create profiles with reserved usernames with Profiles.Create
+var ReservedUsernames = []string{
+	"admin",
+	"administrator",
+	"api",
+	"help",
+	"me",
+	"qri",
+	"registry",
+	"root",
+	"support",
+	"system",
+	"www",
+}
+
+// ValidateUsername checks a new username can be registered. Usernames must be
+// lower-case valid names and can't be reserved. Profiles registered before
+// usernames had to be lower-case keep their names, so ValidateUsername only
+// applies when a profile takes a name it doesn't already hold
+func ValidateUsername(username string) error {
+	if !dsref.IsValidName(username) || strings.ToLower(username) != username {
+		return dsref.ErrDescribeValidUsername
+	}
+	for _, r := range ReservedUsernames {
+		if strings.EqualFold(r, username) {
+			return fmt.Errorf("%w: %q", ErrUsernameReserved, username)
+		}
+	}
+	return nil
 }
 
-// RegisterProfile adds a profile to the list if it's valid and the desired handle isn't taken
+// RegisterProfile adds a profile to the list if it's valid and the desired
+// handle isn't taken. Usernames are unique regardless of case, and can't be
+// registered while another profile holds them as a previous username. The
+// store enforces uniqueness when the profile is written
 func RegisterProfile(store Profiles, p *Profile) (err error) {
 	if err = p.Validate(); err != nil {
 		return err
@@ -50,35 +95,42 @@ func RegisterProfile(store Profiles, p *Profile) (err error) {
 		return err
 	}
 
-	pro, err := store.Load(p.Username)
-	if err == nil {
-		// if peer is registring a name they already own, we're good
-		if pro.ProfileID == p.ProfileID {
-			return nil
-		}
-		return fmt.Errorf("username '%s' is taken", p.Username)
-	}
-
-	prev := ""
-	store.Range(func(key string, profile *Profile) (bool, error) {
+	var prev *Profile
+	err = store.Range(func(key string, profile *Profile) (bool, error) {
 		if profile.ProfileID == p.ProfileID {
-			prev = key
-			return true, nil
+			prev = profile
+			return false, nil
 		}
-		return false, nil
+		return true, nil
 	})
-
-	if prev != "" {
-		if err = store.Delete(prev); err != nil {
+	if err != nil {
+		return err
+	}
+	if prev == nil || prev.Username != p.Username {
+		if err = ValidateUsername(p.Username); err != nil {
 			return err
 		}
 	}
+	if prev != nil {
+		if prev.Suspended {
+			return ErrProfileSuspended
+		}
+		// if peer is registring a name they already own, we're good
+		if prev.Username == p.Username {
+			return nil
+		}
+		p.PreviousUsernames = prev.PreviousUsernames
+		p.Renamed = prev.Renamed
+		p.Created = nowFunc()
+		return store.Rename(prev.Username, p.Username, p)
+	}
 
-	p.Created = time.Now()
+	p.Created = nowFunc()
 	return store.Create(p.Username, p)
 }
 
-// UpdateProfile alters profile data
+// UpdateProfile alters profile data. Only the owner of a profile can update
+// it, and suspended profiles can't be updated
 func UpdateProfile(store Profiles, p *Profile) (err error) {
 	if err = p.Validate(); err != nil {
 		return err
@@ -87,6 +139,13 @@ func UpdateProfile(store Profiles, p *Profile) (err error) {
 		return err
 	}
 
+	prev, err := ownedProfile(store, p)
+	if err != nil {
+		return err
+	}
+	p.Created = prev.Created
+	p.PreviousUsernames = prev.PreviousUsernames
+	p.Renamed = prev.Renamed
 	return store.Update(p.Username, p)
 }
 
@@ -100,7 +159,67 @@ func DeregisterProfile(store Profiles, p *Profile) error {
 		return err
 	}
 
-	store.Delete(p.Username)
+	if _, err := ownedProfile(store, p); err != nil {
+		if err == ErrNotFound {
+			return nil
+		}
+		return err
+	}
+	return store.Delete(p.Username)
+}
+
+// ownedProfile loads the stored profile for a username, confirming it belongs
+// to the same profileID as p and isn't suspended
+func ownedProfile(store Profiles, p *Profile) (*Profile, error) {
+	prev, err := store.Load(p.Username)
+	if err != nil {
+		return nil, err
+	}
+	if prev.ProfileID != p.ProfileID {
+		return nil, fmt.Errorf("username '%s' is taken", p.Username)
+	}
+	if prev.Suspended {
+		return nil, ErrProfileSuspended
+	}
+	return prev, nil
+}
+
+// SuspendProfile marks a profile as suspended, or lifts a suspension.
+// SuspendProfile is an administrative action that doesn't check signatures
+func SuspendProfile(store Profiles, username string, suspended bool) (*Profile, error) {
+	p, err := store.Load(username)
+	if err != nil {
+		return nil, err
+	}
+	updated := *p
+	updated.Suspended = suspended
+	if err := store.Update(username, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// checkUsernameFree errors if a profile can't be created at key: key is
+// registered regardless of case, or is a previous username of a profile other
+// than value. The entry at replacing is ignored, so a profile can be renamed
+// to a different case of its own username. Stores call it with their lock held
+func checkUsernameFree(ps map[string]*Profile, key string, value *Profile, replacing string) error {
+	for k, p := range ps {
+		if k == replacing {
+			continue
+		}
+		if strings.EqualFold(k, key) {
+			return fmt.Errorf("username '%s' is taken", key)
+		}
+		if p.ProfileID == value.ProfileID {
+			continue
+		}
+		for _, name := range p.PreviousUsernames {
+			if strings.EqualFold(name, key) {
+				return fmt.Errorf("username '%s' is taken", key)
+			}
+		}
+	}
 	return nil
 }
 
@@ -191,11 +310,29 @@ func (ps *MemProfiles) Delete(key string) error {
 	return nil
 }
 
-// Create adds a profile
+// Create adds a profile, failing if the username is taken
 func (ps *MemProfiles) Create(key string, value *Profile) error {
 	ps.Lock()
+	defer ps.Unlock()
+	if err := checkUsernameFree(ps.ps, key, value, ""); err != nil {
+		return err
+	}
 	ps.ps[key] = value
-	ps.Unlock()
+	return nil
+}
+
+// Rename moves a profile from one username to another
+func (ps *MemProfiles) Rename(from, to string, value *Profile) error {
+	ps.Lock()
+	defer ps.Unlock()
+	if _, ok := ps.ps[from]; !ok {
+		return ErrNotFound
+	}
+	if err := checkUsernameFree(ps.ps, to, value, from); err != nil {
+		return err
+	}
+	delete(ps.ps, from)
+	ps.ps[to] = value
 	return nil
 }
 
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	crypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/qri-io/qri/dsref"
 )
 
 func TestRegisterProfile(t *testing.T) {
@@ -128,3 +129,104 @@ func TestProfilesSortedRange(t *testing.T) {
 		break
 	}
 }
+
+func TestUsernameRules(t *testing.T) {
+	ps := NewMemProfiles()
+	src := rand.New(rand.NewSource(0))
+	newProfile := func(username string) *Profile {
+		pk, _, err := crypto.GenerateSecp256k1Key(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := ProfileFromPrivateKey(&Profile{Username: username}, pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	legacy := newProfile("Legacy")
+	if err := ps.Create("Legacy", legacy); err != nil {
+		t.Fatal(err)
+	}
+	// profiles registered before the lower-case rule keep their usernames
+	if err := RegisterProfile(ps, legacy); err != nil {
+		t.Errorf("expected a mixed-case profile to re-register its username, got: %v", err)
+	}
+	for _, key := range []string{"Legacy", "LEGACY"} {
+		if err := ps.Create(key, newProfile(key)); err == nil {
+			t.Errorf("expected creating %q over an existing username to fail", key)
+		}
+	}
+
+	cases := []struct {
+		username string
+		err      string
+	}{
+		{"admin", `username is reserved: "admin"`},
+		{"Registry", dsref.ErrDescribeValidUsername.Error()},
+		{"9lives", dsref.ErrDescribeValidUsername.Error()},
+		{"has space", dsref.ErrDescribeValidUsername.Error()},
+		{"legacy", "username 'legacy' is taken"},
+		{"b5", ""},
+	}
+	for _, c := range cases {
+		err := RegisterProfile(ps, newProfile(c.username))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("%q error mismatch. expected: %q, got: %v", c.username, c.err, err)
+		}
+	}
+
+	p := newProfile("suspendme")
+	if err := RegisterProfile(ps, p); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SuspendProfile(ps, "suspendme", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateProfile(ps, p); err != ErrProfileSuspended {
+		t.Errorf("expected updating a suspended profile to fail, got: %v", err)
+	}
+	if err := DeregisterProfile(ps, p); err != ErrProfileSuspended {
+		t.Errorf("expected deregistering a suspended profile to fail, got: %v", err)
+	}
+	if err := RegisterProfile(ps, newProfile("suspendme")); err == nil {
+		t.Error("expected a suspended profile to keep its username")
+	}
+
+	other := newProfile("b5")
+	if err := UpdateProfile(ps, other); err == nil {
+		t.Error("expected updating another profile's username to fail")
+	}
+}
+
+func TestRegisterProfileConcurrent(t *testing.T) {
+	ps := NewMemProfiles()
+	src := rand.New(rand.NewSource(0))
+	profiles := make([]*Profile, 10)
+	for i := range profiles {
+		pk, _, err := crypto.GenerateSecp256k1Key(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if profiles[i], err = ProfileFromPrivateKey(&Profile{Username: "popular"}, pk); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	errs := make(chan error, len(profiles))
+	for _, p := range profiles {
+		go func(p *Profile) {
+			errs <- RegisterProfile(ps, p)
+		}(p)
+	}
+	registered := 0
+	for range profiles {
+		if err := <-errs; err == nil {
+			registered++
+		}
+	}
+	if registered != 1 {
+		t.Errorf("expected exactly one profile to register a username, got: %d", registered)
+	}
+}
@@ -54,6 +54,7 @@ func NewTestRunner(t *testing.T) (*TestRunner, func()) {
 
 	reg := registry.Registry{
 		Profiles: registry.NewMemProfiles(),
+		Datasets: registry.NewMemDatasets(),
 		Search:   &registry.MockSearch{},
 		Remote:   rem,
 	}
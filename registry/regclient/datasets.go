@@ -0,0 +1,137 @@
+package regclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/registry"
+)
+
+// PublishDataset lists a dataset preview in the registry catalog. The preview
+// must set Peername, Name & Path
+func (c *Client) PublishDataset(preview *dataset.Dataset, privKey crypto.PrivKey) (*dataset.Dataset, error) {
+	if c == nil {
+		return nil, registry.ErrNoRegistry
+	}
+
+	req, err := registry.NewDatasetRequest(registry.ActionPublish, preview, privKey)
+	if err != nil {
+		return nil, err
+	}
+	res := &dataset.Dataset{}
+	if err := c.doJSONDatasetReq("POST", "/registry/dataset", req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// UnpublishDataset removes a dataset from the registry catalog
+func (c *Client) UnpublishDataset(ref dsref.Ref, privKey crypto.PrivKey) error {
+	if c == nil {
+		return registry.ErrNoRegistry
+	}
+
+	req, err := registry.NewDatasetRequest(registry.ActionUnpublish, &dataset.Dataset{Peername: ref.Username, Name: ref.Name}, privKey)
+	if err != nil {
+		return err
+	}
+	return c.doJSONDatasetReq("DELETE", "/registry/dataset", req, nil)
+}
+
+// GetDataset fetches a dataset from the registry catalog
+func (c *Client) GetDataset(ref dsref.Ref) (*dataset.Dataset, error) {
+	if c == nil {
+		return nil, registry.ErrNoRegistry
+	}
+
+	q := url.Values{}
+	q.Set("ref", ref.Alias())
+	res := &dataset.Dataset{}
+	if err := c.doJSONDatasetReq("GET", "/registry/dataset?"+q.Encode(), nil, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ListDatasets lists the registry catalog. If username is set, only datasets
+// published by that user are listed
+func (c *Client) ListDatasets(username string, offset, limit int) ([]*dataset.Dataset, error) {
+	if c == nil {
+		return nil, registry.ErrNoRegistry
+	}
+
+	q := url.Values{}
+	if username != "" {
+		q.Set("username", username)
+	}
+	q.Set("offset", fmt.Sprintf("%d", offset))
+	q.Set("limit", fmt.Sprintf("%d", limit))
+	res := []*dataset.Dataset{}
+	if err := c.doJSONDatasetReq("GET", "/registry/datasets?"+q.Encode(), nil, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// doJSONDatasetReq is a common wrapper for catalog endpoint requests, decoding
+// response data into res if it isn't nil
+func (c Client) doJSONDatasetReq(method, path string, body, res interface{}) error {
+	if c.cfg.Location == "" {
+		return ErrNoRegistry
+	}
+
+	var req *http.Request
+	var err error
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		if req, err = http.NewRequest(method, c.cfg.Location+path, bytes.NewReader(data)); err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+	} else if req, err = http.NewRequest(method, c.cfg.Location+path, nil); err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such host") {
+			return ErrNoRegistry
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	// add response to an envelope
+	env := struct {
+		Data json.RawMessage
+		Meta struct {
+			Error  string
+			Status string
+			Code   int
+		}
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return registry.ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("registry: %s", env.Meta.Error)
+	}
+	if res != nil {
+		return json.Unmarshal(env.Data, res)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package regclient
+
+import (
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/registry"
+)
+
+func TestDatasetCatalog(t *testing.T) {
+	tr, cleanup := NewTestRunner(t)
+	defer cleanup()
+
+	client := tr.Client
+	ref := dsref.Ref{Username: "b5", Name: "cities"}
+	preview := &dataset.Dataset{Peername: "b5", Name: "cities", Path: "/ipfs/QmFoo"}
+
+	if _, err := client.PublishDataset(preview, tr.ClientPrivKey); err == nil {
+		t.Error("expected publishing with an unregistered username to fail")
+	}
+	if _, err := client.PutProfile(&registry.Profile{Username: "b5"}, tr.ClientPrivKey); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.PublishDataset(preview, tr.ClientPrivKey); err != nil {
+		t.Fatal(err)
+	}
+	got, err := client.GetDataset(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Path != preview.Path {
+		t.Errorf("path mismatch. expected: %q, got: %q", preview.Path, got.Path)
+	}
+
+	list, err := client.ListDatasets("b5", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 {
+		t.Errorf("expected 1 listed dataset, got: %d", len(list))
+	}
+
+	if err := client.UnpublishDataset(ref, tr.ClientPrivKey); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetDataset(ref); err != registry.ErrNotFound {
+		t.Errorf("expected getting an unpublished dataset to be ErrNotFound, got: %v", err)
+	}
+	if err := client.UnpublishDataset(ref, tr.ClientPrivKey); err != registry.ErrNotFound {
+		t.Errorf("expected unpublishing twice to be ErrNotFound, got: %v", err)
+	}
+}
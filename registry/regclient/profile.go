@@ -2,6 +2,7 @@ package regclient
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -82,18 +83,54 @@ func (c *Client) DeleteProfile(p *registry.Profile, privKey crypto.PrivKey) erro
 	return err
 }
 
+// RenameProfile changes the username of a registered profile, signing the
+// rename with privKey. The registry keeps the old username as a redirect to
+// the new one
+func (c *Client) RenameProfile(from, to string, privKey crypto.PrivKey) (*registry.Profile, error) {
+	if c == nil {
+		return nil, registry.ErrNoRegistry
+	}
+
+	rename, err := registry.NewRename(from, to, privKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.doJSONReq("POST", "/registry/profile/rename", rename)
+}
+
+// ResolveUsername gets the current username for a username, following any
+// renames the registry has recorded
+func (c *Client) ResolveUsername(ctx context.Context, username string) (string, error) {
+	if c == nil {
+		return "", registry.ErrNoRegistry
+	}
+
+	p := &registry.Profile{Username: username}
+	if err := c.GetProfile(p); err != nil {
+		return "", err
+	}
+	return p.Username, nil
+}
+
 // doJSONProfileReq is a common wrapper for /profile endpoint requests
 func (c Client) doJSONProfileReq(method string, p *registry.Profile) (*registry.Profile, error) {
+	return c.doJSONReq(method, "/registry/profile", p)
+}
+
+// doJSONReq sends a JSON body to a registry endpoint that responds with a
+// profile
+func (c Client) doJSONReq(method, path string, body interface{}) (*registry.Profile, error) {
 	if c.cfg.Location == "" {
 		return nil, ErrNoRegistry
 	}
 
-	data, err := json.Marshal(p)
+	data, err := json.Marshal(body)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest(method, fmt.Sprintf("%s/registry/profile", c.cfg.Location), bytes.NewReader(data))
+	req, err := http.NewRequest(method, fmt.Sprintf("%s%s", c.cfg.Location, path), bytes.NewReader(data))
 	if err != nil {
 		return nil, err
 	}
@@ -124,6 +161,9 @@ func (c Client) doJSONProfileReq(method string, p *registry.Profile) (*registry.
 		if strings.Contains(env.Meta.Error, "taken") {
 			return nil, registry.ErrUsernameTaken
 		}
+		if env.Meta.Error == registry.ErrNotFound.Error() {
+			return nil, registry.ErrNotFound
+		}
 		return nil, fmt.Errorf("registry: %s", env.Meta.Error)
 	}
 
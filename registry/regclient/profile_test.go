@@ -1,6 +1,7 @@
 package regclient
 
 import (
+	"context"
 	"encoding/base64"
 	"testing"
 
@@ -50,3 +51,32 @@ func TestProfileRequests(t *testing.T) {
 		t.Error(err.Error())
 	}
 }
+
+func TestRenameProfile(t *testing.T) {
+	tr, cleanup := NewTestRunner(t)
+	defer cleanup()
+
+	client := tr.Client
+	if _, err := client.RenameProfile("b5", "b6", tr.ClientPrivKey); err != registry.ErrNotFound {
+		t.Errorf("expected renaming an unregistered profile to be ErrNotFound, got: %v", err)
+	}
+	if _, err := client.PutProfile(&registry.Profile{Username: "b5"}, tr.ClientPrivKey); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := client.RenameProfile("b5", "b6", tr.ClientPrivKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Username != "b6" {
+		t.Errorf("expected renamed username to be b6, got: %q", p.Username)
+	}
+
+	current, err := client.ResolveUsername(context.Background(), "b5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current != "b6" {
+		t.Errorf("expected b5 to resolve to b6, got: %q", current)
+	}
+}
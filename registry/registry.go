@@ -32,6 +32,8 @@ import (
 type Registry struct {
 	Remote   *remote.Remote
 	Profiles Profiles
+	Datasets Datasets
+	Orgs     Orgs
 	Search   Searchable
 	Indexer  Indexer
 }
@@ -43,4 +45,8 @@ var (
 	ErrNoRegistry = fmt.Errorf("no registry is configured")
 	// ErrNotFound represents a missing record
 	ErrNotFound = fmt.Errorf("not found")
+	// ErrUsernameReserved is for usernames peers can't register
+	ErrUsernameReserved = fmt.Errorf("username is reserved")
+	// ErrProfileSuspended is for changes to a suspended profile
+	ErrProfileSuspended = fmt.Errorf("profile is suspended")
 )
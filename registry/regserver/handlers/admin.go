@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/qri-io/apiutil"
+	"github.com/qri-io/qri/registry"
+)
+
+// NewAdminProfilesHandler creates a handler that lists all profiles,
+// including suspended profiles. Admin handlers must be wrapped in a
+// MethodProtector
+func NewAdminProfilesHandler(profiles registry.Profiles) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			onlySuspended := r.FormValue("suspended") == "true"
+			ps := []*registry.Profile{}
+			err := profiles.SortedRange(func(key string, p *registry.Profile) (bool, error) {
+				if !onlySuspended || p.Suspended {
+					ps = append(ps, p)
+				}
+				return true, nil
+			})
+			if err != nil {
+				apiutil.WriteErrResponse(w, http.StatusInternalServerError, err)
+				return
+			}
+			apiutil.WriteResponse(w, ps)
+		default:
+			apiutil.NotFoundHandler(w, r)
+		}
+	}
+}
+
+// NewAdminProfileHandler creates a handler for administering a single
+// profile, identified by the "username" query param. GET fetches a profile,
+// POST with a "suspended" param suspends or reinstates it, DELETE removes it.
+// Admin handlers must be wrapped in a MethodProtector
+func NewAdminProfileHandler(profiles registry.Profiles) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username := r.FormValue("username")
+		if username == "" {
+			apiutil.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("username is required"))
+			return
+		}
+
+		switch r.Method {
+		case "GET":
+			p, err := profiles.Load(username)
+			if err != nil {
+				apiutil.NotFoundHandler(w, r)
+				return
+			}
+			apiutil.WriteResponse(w, p)
+		case "POST":
+			suspended, err := strconv.ParseBool(r.FormValue("suspended"))
+			if err != nil {
+				apiutil.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("suspended must be true or false"))
+				return
+			}
+			p, err := registry.SuspendProfile(profiles, username, suspended)
+			if err != nil {
+				if err == registry.ErrNotFound {
+					apiutil.NotFoundHandler(w, r)
+					return
+				}
+				apiutil.WriteErrResponse(w, http.StatusInternalServerError, err)
+				return
+			}
+			log.Infof("set profile %q suspended=%t", username, suspended)
+			apiutil.WriteResponse(w, p)
+		case "DELETE":
+			p, err := profiles.Load(username)
+			if err != nil {
+				apiutil.NotFoundHandler(w, r)
+				return
+			}
+			if err := profiles.Delete(username); err != nil {
+				apiutil.WriteErrResponse(w, http.StatusInternalServerError, err)
+				return
+			}
+			log.Infof("deleted profile %q", username)
+			apiutil.WriteResponse(w, p)
+		default:
+			apiutil.NotFoundHandler(w, r)
+		}
+	}
+}
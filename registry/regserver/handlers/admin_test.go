@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qri-io/qri/registry"
+)
+
+func TestAdminProfiles(t *testing.T) {
+	un, pw := "username", "password"
+	ps := registry.NewMemProfiles()
+	s := httptest.NewServer(NewRoutes(registry.Registry{Profiles: ps}, AddProtector(NewBAProtector(un, pw))))
+	defer s.Close()
+
+	p1, err := registry.ProfileFromPrivateKey(&registry.Profile{Username: "b5"}, privKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.RegisterProfile(ps, p1); err != nil {
+		t.Fatal(err)
+	}
+
+	do := func(method, path string, auth bool) (int, []*registry.Profile) {
+		req, err := http.NewRequest(method, s.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if auth {
+			req.SetBasicAuth(un, pw)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		env := struct{ Data json.RawMessage }{}
+		json.NewDecoder(res.Body).Decode(&env)
+		list := []*registry.Profile{}
+		if err := json.Unmarshal(env.Data, &list); err != nil {
+			p := &registry.Profile{}
+			json.Unmarshal(env.Data, p)
+			list = []*registry.Profile{p}
+		}
+		return res.StatusCode, list
+	}
+
+	cases := []struct {
+		method, path string
+		auth         bool
+		status       int
+	}{
+		{"GET", "/registry/admin/profiles", false, http.StatusForbidden},
+		{"GET", "/registry/admin/profiles", true, http.StatusOK},
+		{"GET", "/registry/admin/profile", true, http.StatusBadRequest},
+		{"GET", "/registry/admin/profile?username=nobody", true, http.StatusNotFound},
+		{"POST", "/registry/admin/profile?username=b5&suspended=maybe", true, http.StatusBadRequest},
+		{"POST", "/registry/admin/profile?username=b5&suspended=true", false, http.StatusForbidden},
+		{"POST", "/registry/admin/profile?username=b5&suspended=true", true, http.StatusOK},
+	}
+	for i, c := range cases {
+		if status, _ := do(c.method, c.path, c.auth); status != c.status {
+			t.Errorf("case %d %s %s: expected status %d, got %d", i, c.method, c.path, c.status, status)
+		}
+	}
+
+	// suspended profiles are hidden from public lookups
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/registry/profiles", s.URL), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := struct{ Data []*registry.Profile }{}
+	if err := json.NewDecoder(res.Body).Decode(&env); err != nil {
+		t.Fatal(err)
+	}
+	if len(env.Data) != 0 {
+		t.Errorf("expected suspended profile to be hidden from public listing, got: %v", env.Data)
+	}
+	if _, list := do("GET", "/registry/admin/profiles?suspended=true", true); len(list) != 1 || !list[0].Suspended {
+		t.Errorf("expected admin to list the suspended profile, got: %v", list)
+	}
+
+	if status, _ := do("DELETE", "/registry/admin/profile?username=b5", true); status != http.StatusOK {
+		t.Errorf("expected deleting a profile to succeed, got status %d", status)
+	}
+	if l, _ := ps.Len(); l != 0 {
+		t.Errorf("expected profile to be deleted, %d profiles remain", l)
+	}
+}
+
+func TestAdminRoutesRequireProtector(t *testing.T) {
+	s := httptest.NewServer(NewRoutes(registry.Registry{Profiles: registry.NewMemProfiles()}))
+	defer s.Close()
+
+	res, err := http.Get(s.URL + "/registry/admin/profiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("expected admin routes to be absent without a protector, got status %d", res.StatusCode)
+	}
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/qri-io/apiutil"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/registry"
+)
+
+// NewDatasetHandler creates a handler for a single catalog entry. GET fetches
+// a dataset by the "ref" query param. POST publishes & DELETE unpublishes a
+// dataset with a JSON registry.DatasetRequest. Published datasets are added
+// to idx when idx isn't nil. Members of organizations in orgs can publish to
+// the organization's namespace, orgs may be nil
+func NewDatasetHandler(datasets registry.Datasets, profiles registry.Profiles, orgs registry.Orgs, idx registry.Indexer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			ref, err := dsref.Parse(r.FormValue("ref"))
+			if err != nil {
+				apiutil.WriteErrResponse(w, http.StatusBadRequest, err)
+				return
+			}
+			ds, err := registry.LookupDataset(datasets, profiles, orgs, ref.Username, ref.Name)
+			if err != nil {
+				apiutil.NotFoundHandler(w, r)
+				return
+			}
+			apiutil.WriteResponse(w, ds)
+		case "POST", "DELETE":
+			if r.Header.Get("Content-Type") != "application/json" {
+				err := fmt.Errorf("Content-Type must be application/json")
+				apiutil.WriteErrResponse(w, http.StatusBadRequest, err)
+				return
+			}
+			req := &registry.DatasetRequest{}
+			if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+				apiutil.WriteErrResponse(w, http.StatusBadRequest, err)
+				return
+			}
+
+			var (
+				ds  *dataset.Dataset
+				err error
+			)
+			if r.Method == "POST" {
+				ds, err = registry.PublishDataset(datasets, profiles, orgs, req)
+			} else {
+				ds, err = registry.UnpublishDataset(datasets, profiles, orgs, req)
+			}
+			if err != nil {
+				if err == registry.ErrNotFound {
+					apiutil.WriteErrResponse(w, http.StatusNotFound, err)
+					return
+				}
+				apiutil.WriteErrResponse(w, http.StatusBadRequest, err)
+				return
+			}
+			log.Infof("%s dataset %s/%s", req.Action, req.Username, req.Name)
+
+			if idx != nil {
+				if r.Method == "POST" {
+					err = idx.IndexDatasets([]*dataset.Dataset{ds})
+				} else {
+					err = idx.UnindexDatasets([]*dataset.Dataset{ds})
+				}
+				if err != nil {
+					log.Errorf("indexing %s/%s: %s", req.Username, req.Name, err)
+				}
+			}
+			apiutil.WriteResponse(w, ds)
+		default:
+			apiutil.NotFoundHandler(w, r)
+		}
+	}
+}
+
+// NewDatasetsHandler creates a handler that lists the catalog, paginated with
+// "offset" & "limit" query params. A "username" param lists only datasets
+// published by that user or organization
+func NewDatasetsHandler(datasets registry.Datasets, profiles registry.Profiles, orgs registry.Orgs) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			offset := apiutil.ReqParamInt(r, "offset", defaultOffset)
+			limit := apiutil.ReqParamInt(r, "limit", defaultLimit)
+			res, err := registry.ListDatasets(datasets, profiles, orgs, r.FormValue("username"), offset, limit)
+			if err != nil {
+				if err == registry.ErrNotFound {
+					apiutil.NotFoundHandler(w, r)
+					return
+				}
+				apiutil.WriteErrResponse(w, http.StatusInternalServerError, err)
+				return
+			}
+			apiutil.WriteResponse(w, res)
+		default:
+			apiutil.NotFoundHandler(w, r)
+		}
+	}
+}
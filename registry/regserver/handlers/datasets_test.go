@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/registry"
+)
+
+func TestDatasets(t *testing.T) {
+	ps := registry.NewMemProfiles()
+	s := httptest.NewServer(NewRoutes(registry.Registry{Profiles: ps, Datasets: registry.NewMemDatasets()}))
+	defer s.Close()
+
+	p1, err := registry.ProfileFromPrivateKey(&registry.Profile{Username: "b5"}, privKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.RegisterProfile(ps, p1); err != nil {
+		t.Fatal(err)
+	}
+
+	publish, err := registry.NewDatasetRequest(registry.ActionPublish, &dataset.Dataset{Peername: "b5", Name: "cities", Path: "/ipfs/QmFoo"}, privKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged, err := registry.NewDatasetRequest(registry.ActionPublish, &dataset.Dataset{Peername: "b5", Name: "cities", Path: "/ipfs/QmFoo"}, privKey2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unpublish, err := registry.NewDatasetRequest(registry.ActionUnpublish, &dataset.Dataset{Peername: "b5", Name: "cities"}, privKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		method, endpoint string
+		body             interface{}
+		resStatus        int
+	}{
+		{"GET", "/registry/dataset?ref=b5/cities", nil, http.StatusNotFound},
+		{"GET", "/registry/dataset?ref=", nil, http.StatusBadRequest},
+		{"POST", "/registry/dataset", forged, http.StatusBadRequest},
+		{"DELETE", "/registry/dataset", unpublish, http.StatusNotFound},
+		{"POST", "/registry/dataset", publish, http.StatusOK},
+		{"GET", "/registry/dataset?ref=b5/cities", nil, http.StatusOK},
+		{"GET", "/registry/datasets?username=b5", nil, http.StatusOK},
+		{"GET", "/registry/datasets?username=nope", nil, http.StatusNotFound},
+		{"DELETE", "/registry/dataset", unpublish, http.StatusOK},
+		{"GET", "/registry/dataset?ref=b5/cities", nil, http.StatusNotFound},
+		{"PUT", "/registry/dataset", nil, http.StatusNotFound},
+	}
+
+	for i, c := range cases {
+		req, err := http.NewRequest(c.method, s.URL+c.endpoint, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.body != nil {
+			data, err := json.Marshal(c.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if req, err = http.NewRequest(c.method, s.URL+c.endpoint, bytes.NewReader(data)); err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != c.resStatus {
+			t.Errorf("case %d %s %s: status code mismatch. expected: %d, got: %d", i, c.method, c.endpoint, c.resStatus, res.StatusCode)
+		}
+	}
+}
@@ -56,7 +56,19 @@ func NewRoutes(reg registry.Registry, opts ...func(o *RouteOptions)) *http.Serve
 
 	if ps := reg.Profiles; ps != nil {
 		mux.HandleFunc("/registry/profile", logReq(NewProfileHandler(ps)))
+		mux.HandleFunc("/registry/profile/rename", logReq(NewProfileRenameHandler(ps)))
 		mux.HandleFunc("/registry/profiles", pro.ProtectMethods("POST")(logReq(NewProfilesHandler(ps))))
+
+		// admin routes are only added when requests can be authenticated
+		if _, noop := pro.(NoopProtector); !noop {
+			mux.HandleFunc("/registry/admin/profiles", pro.ProtectMethods("*")(logReq(NewAdminProfilesHandler(ps))))
+			mux.HandleFunc("/registry/admin/profile", pro.ProtectMethods("*")(logReq(NewAdminProfileHandler(ps))))
+		}
+	}
+
+	if ds := reg.Datasets; ds != nil && reg.Profiles != nil {
+		mux.HandleFunc("/registry/dataset", logReq(NewDatasetHandler(ds, reg.Profiles, reg.Orgs, reg.Indexer)))
+		mux.HandleFunc("/registry/datasets", logReq(NewDatasetsHandler(ds, reg.Profiles, reg.Orgs)))
 	}
 
 	if s := reg.Search; s != nil {
@@ -29,24 +29,25 @@ func NewProfilesHandler(profiles registry.Profiles) http.HandlerFunc {
 			}
 
 			for _, pro := range ps {
-				profiles.Create(pro.Username, pro)
+				if err := profiles.Create(pro.Username, pro); err != nil {
+					apiutil.WriteErrResponse(w, http.StatusInternalServerError, err)
+					return
+				}
 			}
 
 			apiutil.WriteResponse(w, ps)
 		case "GET":
-
-			l, err := profiles.Len()
+			ps := []*registry.Profile{}
+			err := profiles.SortedRange(func(key string, p *registry.Profile) (bool, error) {
+				if !p.Suspended {
+					ps = append(ps, p)
+				}
+				return true, nil
+			})
 			if err != nil {
 				apiutil.WriteErrResponse(w, http.StatusInternalServerError, err)
+				return
 			}
-			ps := make([]*registry.Profile, l)
-
-			i := 0
-			profiles.SortedRange(func(key string, p *registry.Profile) (bool, error) {
-				ps[i] = p
-				i++
-				return true, nil
-			})
 
 			apiutil.WriteResponse(w, ps)
 		default:
@@ -76,22 +77,23 @@ func NewProfileHandler(profiles registry.Profiles) http.HandlerFunc {
 		case "GET":
 			var err error
 			if p.Username != "" {
-				p, err = profiles.Load(p.Username)
+				// follow renames, responding with the current profile
+				p, err = registry.ResolveUsername(profiles, p.Username)
 			} else {
 				var ok bool
 				err = profiles.Range(func(_ string, profile *registry.Profile) (bool, error) {
 					if profile.ProfileID == p.ProfileID || profile.PublicKey == p.PublicKey {
 						p = profile
 						ok = true
-						return true, nil
+						return false, nil
 					}
-					return false, nil
+					return true, nil
 				})
 				if !ok {
 					err = registry.ErrNotFound
 				}
 			}
-			if err != nil {
+			if err != nil || p.Suspended {
 				apiutil.NotFoundHandler(w, r)
 				return
 			}
@@ -118,3 +120,37 @@ func NewProfileHandler(profiles registry.Profiles) http.HandlerFunc {
 		apiutil.WriteResponse(w, p)
 	}
 }
+
+// NewProfileRenameHandler creates a handler that changes the username of a
+// profile. Renames are POSTed as a JSON registry.Rename, responding with the
+// renamed profile
+func NewProfileRenameHandler(profiles registry.Profiles) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			apiutil.NotFoundHandler(w, r)
+			return
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			err := fmt.Errorf("Content-Type must be application/json")
+			apiutil.WriteErrResponse(w, http.StatusBadRequest, err)
+			return
+		}
+
+		rename := &registry.Rename{}
+		if err := json.NewDecoder(r.Body).Decode(rename); err != nil {
+			apiutil.WriteErrResponse(w, http.StatusBadRequest, err)
+			return
+		}
+		p, err := registry.RenameProfile(profiles, rename)
+		if err != nil {
+			if err == registry.ErrNotFound {
+				apiutil.WriteErrResponse(w, http.StatusNotFound, err)
+				return
+			}
+			apiutil.WriteErrResponse(w, http.StatusBadRequest, err)
+			return
+		}
+		log.Infof("renamed profile %q to %q", rename.From, rename.To)
+		apiutil.WriteResponse(w, p)
+	}
+}
@@ -166,6 +166,88 @@ func TestProfile(t *testing.T) {
 	}
 }
 
+func TestProfileRename(t *testing.T) {
+	ps := registry.NewMemProfiles()
+	s := httptest.NewServer(NewRoutes(registry.Registry{Profiles: ps}))
+
+	p1, err := registry.ProfileFromPrivateKey(&registry.Profile{Username: "b5"}, privKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.RegisterProfile(ps, p1); err != nil {
+		t.Fatal(err)
+	}
+	rename, err := registry.NewRename("b5", "b6", privKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged, err := registry.NewRename("b5", "b7", privKey2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged.ProfileID = rename.ProfileID
+	missing, err := registry.NewRename("nobody", "b7", privKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		method      string
+		contentType string
+		body        interface{}
+		resStatus   int
+	}{
+		{"GET", "application/json", rename, http.StatusNotFound},
+		{"POST", "", rename, http.StatusBadRequest},
+		{"POST", "application/json", forged, http.StatusBadRequest},
+		{"POST", "application/json", missing, http.StatusNotFound},
+		{"POST", "application/json", rename, http.StatusOK},
+	}
+
+	for i, c := range cases {
+		data, err := json.Marshal(c.body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := http.NewRequest(c.method, fmt.Sprintf("%s/registry/profile/rename", s.URL), bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.contentType != "" {
+			req.Header.Set("Content-Type", c.contentType)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != c.resStatus {
+			t.Errorf("case %d res status mismatch. expected: %d, got: %d", i, c.resStatus, res.StatusCode)
+		}
+	}
+
+	// the old username redirects to the renamed profile
+	data, err := json.Marshal(&registry.Profile{Username: "b5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/registry/profile", s.URL), bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := struct{ Data *registry.Profile }{}
+	if err := json.NewDecoder(res.Body).Decode(&e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Data == nil || e.Data.Username != "b6" {
+		t.Errorf("expected old username to redirect to b6, got: %#v", e.Data)
+	}
+}
+
 func TestProfiles(t *testing.T) {
 	s := httptest.NewServer(NewRoutes(registry.Registry{Profiles: registry.NewMemProfiles()}))
 
@@ -44,14 +44,68 @@ func NewMemRegistry(rem *remote.Remote) registry.Registry {
 	return registry.Registry{
 		Remote:   rem,
 		Profiles: registry.NewMemProfiles(),
+		Datasets: registry.NewMemDatasets(),
 	}
 }
 
+// Options configures registries created by NewTempRegistry
+type Options struct {
+	// ProfilesPath persists registry profiles to a JSON file when set.
+	// profiles are kept in memory otherwise
+	ProfilesPath string
+	// DatasetsPath persists the registry catalog to a JSON file when set.
+	// the catalog is kept in memory otherwise
+	DatasetsPath string
+}
+
+// OptProfilesPath persists registry profiles to the file at path
+func OptProfilesPath(path string) func(o *Options) {
+	return func(o *Options) {
+		o.ProfilesPath = path
+	}
+}
+
+// OptDatasetsPath persists the registry catalog to the file at path
+func OptDatasetsPath(path string) func(o *Options) {
+	return func(o *Options) {
+		o.DatasetsPath = path
+	}
+}
+
+// newProfiles creates the profile store options call for
+func newProfiles(o *Options) (registry.Profiles, error) {
+	if o.ProfilesPath != "" {
+		return registry.NewFileProfiles(o.ProfilesPath)
+	}
+	return registry.NewMemProfiles(), nil
+}
+
+// newDatasets creates the catalog options call for
+func newDatasets(o *Options) (registry.Datasets, error) {
+	if o.DatasetsPath != "" {
+		return registry.NewFileDatasets(o.DatasetsPath)
+	}
+	return registry.NewMemDatasets(), nil
+}
+
 // NewTempRegistry creates a functioning registry with a teardown function
 // TODO(b5) - the tempRepo.Repo call in this func *requires* the passed-in
 // context be cancelled at some point. drop the cleanup function return in
 // favour of listening for ctx.Done and running the cleanup routine internally
-func NewTempRegistry(ctx context.Context, peername, tmpDirPrefix string, g gen.CryptoGenerator) (*registry.Registry, func(), error) {
+func NewTempRegistry(ctx context.Context, peername, tmpDirPrefix string, g gen.CryptoGenerator, opts ...func(o *Options)) (*registry.Registry, func(), error) {
+	o := &Options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	profiles, err := newProfiles(o)
+	if err != nil {
+		return nil, nil, err
+	}
+	datasets, err := newDatasets(o)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	tempRepo, err := repotest.NewTempRepo(peername, tmpDirPrefix, g)
 	if err != nil {
 		return nil, nil, err
@@ -84,14 +138,25 @@ func NewTempRegistry(ctx context.Context, peername, tmpDirPrefix string, g gen.C
 		AllowRemoves:     true,
 	}
 
-	rem, err := remote.NewRemote(node, remoteCfg, node.Repo.Logbook())
+	// follow author renames the registry has confirmed
+	redirect := func(_ context.Context, username string) (string, error) {
+		p, err := registry.ResolveUsername(profiles, username)
+		if err != nil {
+			return "", err
+		}
+		return p.Username, nil
+	}
+
+	rem, err := remote.NewRemote(node, remoteCfg, node.Repo.Logbook(), remote.OptUsernameRedirect(redirect))
 	if err != nil {
 		return nil, nil, err
 	}
 
 	reg := &registry.Registry{
 		Remote:   rem,
-		Profiles: registry.NewMemProfiles(),
+		Profiles: profiles,
+		Datasets: datasets,
+		Orgs:     registry.LogbookOrgs{Book: node.Repo.Logbook()},
 		Search:   MockRepoSearch{Repo: r},
 	}
 
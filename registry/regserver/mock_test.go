@@ -2,8 +2,12 @@ package regserver
 
 import (
 	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/qri-io/qri/registry"
 	repotest "github.com/qri-io/qri/repo/test"
 )
 
@@ -24,3 +28,30 @@ func TestTempRegistry(t *testing.T) {
 	defer cleanup()
 	cancel()
 }
+
+func TestTempRegistryProfilesPath(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir, err := ioutil.TempDir("", "regserver_profiles_path")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "profiles.json")
+	dsPath := filepath.Join(dir, "datasets.json")
+
+	gen := repotest.NewTestCrypto()
+	reg, cleanup, err := NewTempRegistry(ctx, "registry", "regserver_profiles_path", gen, OptProfilesPath(path), OptDatasetsPath(dsPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	if _, ok := reg.Profiles.(*registry.FileProfiles); !ok {
+		t.Errorf("expected file-backed profiles, got %T", reg.Profiles)
+	}
+	if _, ok := reg.Datasets.(*registry.FileDatasets); !ok {
+		t.Errorf("expected a file-backed catalog, got %T", reg.Datasets)
+	}
+	cancel()
+}
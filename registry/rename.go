@@ -0,0 +1,153 @@
+package registry
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+)
+
+// RenameMaxAge is the longest a registry accepts a signed rename for after it
+// was created
+var RenameMaxAge = time.Minute * 10
+
+// Rename is a request to change the username of a registered profile.
+// Renames must be signed by the key the profile is registered with
+type Rename struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	ProfileID string    `json:"profileid"`
+	Timestamp time.Time `json:"timestamp"`
+	Signature string    `json:"signature"`
+}
+
+// NewRename creates a rename request signed by privKey
+func NewRename(from, to string, privKey crypto.PrivKey) (*Rename, error) {
+	pid, err := profileIDFromPrivKey(privKey)
+	if err != nil {
+		return nil, err
+	}
+	r := &Rename{
+		From:      from,
+		To:        to,
+		ProfileID: pid,
+		Timestamp: nowFunc().UTC(),
+	}
+	sigbytes, err := privKey.Sign(r.signingBytes())
+	if err != nil {
+		return nil, fmt.Errorf("error signing %s", err.Error())
+	}
+	r.Signature = base64.StdEncoding.EncodeToString(sigbytes)
+	return r, nil
+}
+
+// Validate is a sanity check that all required values are present
+func (r *Rename) Validate() error {
+	if r.From == "" {
+		return fmt.Errorf("from is required")
+	}
+	if r.To == "" {
+		return fmt.Errorf("to is required")
+	}
+	if r.ProfileID == "" {
+		return fmt.Errorf("profileID is required")
+	}
+	if r.Timestamp.IsZero() {
+		return fmt.Errorf("timestamp is required")
+	}
+	if r.Signature == "" {
+		return fmt.Errorf("signature is required")
+	}
+	return nil
+}
+
+// signingBytes covers the profile, both usernames and the time of the rename
+// so a signed rename can't be replayed to move a profile to or from any other
+// username, or to repeat a rename after the profile has changed names again
+func (r *Rename) signingBytes() []byte {
+	return []byte(fmt.Sprintf("rename:%s:%s:%s:%d", r.ProfileID, r.From, r.To, r.Timestamp.UnixNano()))
+}
+
+// RenameProfile changes the username of a profile, recording the old username
+// in the profile's PreviousUsernames. Previous usernames stay reserved for
+// the profile that held them, so references to the old username can be
+// redirected to the new one. The rename signature is checked against the
+// public key the profile is registered with. Renames older than RenameMaxAge,
+// or no newer than the last rename of the profile are rejected
+func RenameProfile(store Profiles, r *Rename) (*Profile, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	prev, err := store.Load(r.From)
+	if err != nil {
+		return nil, err
+	}
+	if prev.ProfileID != r.ProfileID {
+		return nil, fmt.Errorf("username '%s' is taken", r.From)
+	}
+	if prev.Suspended {
+		return nil, ErrProfileSuspended
+	}
+	if err := verify(prev.PublicKey, r.Signature, r.signingBytes()); err != nil {
+		return nil, err
+	}
+	if nowFunc().Sub(r.Timestamp) > RenameMaxAge || !r.Timestamp.After(prev.Renamed) {
+		return nil, fmt.Errorf("rename has expired")
+	}
+	if r.From == r.To {
+		return prev, nil
+	}
+	if err := ValidateUsername(r.To); err != nil {
+		return nil, err
+	}
+
+	p := *prev
+	p.Username = r.To
+	p.Peername = ""
+	p.Renamed = r.Timestamp
+	// the stored signature covers the old username
+	p.Signature = ""
+	p.PreviousUsernames = []string{}
+	for _, name := range prev.PreviousUsernames {
+		if name != r.To {
+			p.PreviousUsernames = append(p.PreviousUsernames, name)
+		}
+	}
+	p.PreviousUsernames = append(p.PreviousUsernames, r.From)
+
+	if err := store.Rename(r.From, p.Username, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ResolveUsername fetches the profile for a username, following renames. The
+// returned profile's Username will differ from username if the profile has
+// been renamed
+func ResolveUsername(store Profiles, username string) (*Profile, error) {
+	p, err := store.Load(username)
+	if err == nil {
+		return p, nil
+	} else if err != ErrNotFound {
+		return nil, err
+	}
+
+	err = store.Range(func(_ string, profile *Profile) (bool, error) {
+		for _, name := range profile.PreviousUsernames {
+			if strings.EqualFold(name, username) {
+				p = profile
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, ErrNotFound
+	}
+	return p, nil
+}
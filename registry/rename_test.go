@@ -0,0 +1,129 @@
+package registry
+
+import (
+	"encoding/base64"
+	"math/rand"
+	"testing"
+
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+)
+
+func TestRenameProfile(t *testing.T) {
+	ps := NewMemProfiles()
+	src := rand.New(rand.NewSource(0))
+	newKey := func() crypto.PrivKey {
+		pk, _, err := crypto.GenerateSecp256k1Key(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pk
+	}
+	register := func(username string, pk crypto.PrivKey) {
+		p, err := ProfileFromPrivateKey(&Profile{Username: username}, pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := RegisterProfile(ps, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	key, otherKey := newKey(), newKey()
+	register("b5", key)
+	register("other", otherKey)
+
+	rename, err := NewRename("b5", "b6", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged, err := NewRename("b5", "b6", otherKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged.ProfileID = rename.ProfileID
+	taken, err := NewRename("b5", "other", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed := *rename
+	replayed.To = "b7"
+	expired, err := NewRename("b5", "b6", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired.Timestamp = expired.Timestamp.Add(-RenameMaxAge * 2)
+	if expired.Signature, err = signRename(key, expired); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		description string
+		r           *Rename
+		err         string
+	}{
+		{"missing fields", &Rename{From: "b5"}, "to is required"},
+		{"unknown username", &Rename{From: "nope", To: "b6", ProfileID: rename.ProfileID, Timestamp: rename.Timestamp, Signature: rename.Signature}, ErrNotFound.Error()},
+		{"signed by another key", forged, "mismatched signature"},
+		{"replayed signature", &replayed, "mismatched signature"},
+		{"expired rename", expired, "rename has expired"},
+		{"taken username", taken, "username 'other' is taken"},
+	}
+	for _, c := range cases {
+		if _, err := RenameProfile(ps, c.r); err == nil || err.Error() != c.err {
+			t.Errorf("%s: error mismatch. expected: %q, got: %v", c.description, c.err, err)
+		}
+	}
+
+	p, err := RenameProfile(ps, rename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Username != "b6" || len(p.PreviousUsernames) != 1 || p.PreviousUsernames[0] != "b5" {
+		t.Errorf("expected profile renamed to b6 from b5, got username %q previous usernames: %v", p.Username, p.PreviousUsernames)
+	}
+	if _, err := ps.Load("b5"); err != ErrNotFound {
+		t.Errorf("expected old username to be removed, got: %v", err)
+	}
+
+	got, err := ResolveUsername(ps, "b5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Username != "b6" {
+		t.Errorf("expected old username to redirect to b6, got: %q", got.Username)
+	}
+	if _, err := ResolveUsername(ps, "unknown"); err != ErrNotFound {
+		t.Errorf("expected resolving an unknown username to be ErrNotFound, got: %v", err)
+	}
+
+	// previous usernames stay reserved for the profile that held them
+	squatter, err := ProfileFromPrivateKey(&Profile{Username: "b5"}, newKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterProfile(ps, squatter); err == nil {
+		t.Error("expected registering a previous username of another profile to fail")
+	}
+
+	// renaming back reclaims the name
+	back, err := NewRename("b6", "b5", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, err = RenameProfile(ps, back); err != nil {
+		t.Fatal(err)
+	}
+	if p.Username != "b5" || len(p.PreviousUsernames) != 1 || p.PreviousUsernames[0] != "b6" {
+		t.Errorf("expected profile renamed back to b5, got username %q previous usernames: %v", p.Username, p.PreviousUsernames)
+	}
+
+	// a rename can't be replayed once the profile has changed names again
+	if _, err := RenameProfile(ps, rename); err == nil || err.Error() != "rename has expired" {
+		t.Errorf("expected replaying a rename to fail, got: %v", err)
+	}
+}
+
+func signRename(pk crypto.PrivKey, r *Rename) (string, error) {
+	sig, err := pk.Sign(r.signingBytes())
+	return base64.StdEncoding.EncodeToString(sig), err
+}
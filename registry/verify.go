@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/multiformats/go-multihash"
 )
 
 // verify accepts base64 encoded keys & signatures to validate data
@@ -35,3 +36,16 @@ func verify(b64PubKey, b64Signature string, data []byte) error {
 
 	return nil
 }
+
+// profileIDFromPrivKey calculates the base58-encoded profileID of a private key
+func profileIDFromPrivKey(privKey crypto.PrivKey) (string, error) {
+	pubkeybytes, err := privKey.GetPublic().Bytes()
+	if err != nil {
+		return "", fmt.Errorf("error getting pubkey bytes: %s", err.Error())
+	}
+	mh, err := multihash.Sum(pubkeybytes, multihash.SHA2_256, 32)
+	if err != nil {
+		return "", fmt.Errorf("error summing pubkey: %s", err.Error())
+	}
+	return mh.B58String(), nil
+}
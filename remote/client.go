@@ -60,6 +60,9 @@ type Client interface {
 	// PushDataset synchronizes a dataset with a remote, synchronizing logbook
 	// data  and pulling the dataset version specified by ref.Path
 	PushDataset(ctx context.Context, ref dsref.Ref, remoteAddr string) error
+	// PushLogs synchronizes logbook data on a dataset with a remote without
+	// pushing version data
+	PushLogs(ctx context.Context, ref dsref.Ref, remoteAddr string) error
 	// PullDataset fetches & stores a dataset from a remote, synchronizing logbook
 	// data and pulling the dataset version data associated with ref.Path
 	PullDataset(ctx context.Context, ref *dsref.Ref, remoteAddr string) (*dataset.Dataset, error)
@@ -414,6 +417,14 @@ func (c *client) PushDataset(ctx context.Context, ref dsref.Ref, addr string) er
 	})
 }
 
+// PushLogs pushes logbook data on a dataset to a remote address
+func (c *client) PushLogs(ctx context.Context, ref dsref.Ref, remoteAddr string) error {
+	if c == nil {
+		return ErrNoRemoteClient
+	}
+	return c.pushLogs(ctx, ref, remoteAddr)
+}
+
 // pushLogs pushes logbook data to a remote address
 func (c *client) pushLogs(ctx context.Context, ref dsref.Ref, remoteAddr string) error {
 	log.Debugf("client.pushLogs ref=%q remoteAddr=%q", ref, remoteAddr)
@@ -736,10 +747,15 @@ func (c *client) signHTTPRequest(req *http.Request) error {
 	if err != nil {
 		return err
 	}
+	b64PubKey, err := encodePubKey(pk.GetPublic())
+	if err != nil {
+		return err
+	}
 
 	req.Header.Add("timestamp", now)
 	req.Header.Add("pid", peerID)
 	req.Header.Add("signature", b64Sig)
+	req.Header.Add("pubkey", b64PubKey)
 	req.Header.Add("qri-version", version.String)
 	return nil
 }
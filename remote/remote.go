@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -21,6 +22,7 @@ import (
 	"github.com/qri-io/qri/config"
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/identity"
+	"github.com/qri-io/qri/limits"
 	"github.com/qri-io/qri/logbook"
 	"github.com/qri-io/qri/logbook/logsync"
 	"github.com/qri-io/qri/logbook/oplog"
@@ -86,8 +88,22 @@ type Options struct {
 	Previews
 	// Policy defines the access control for the remote
 	Policy *access.Policy
+	// UsernameRedirect confirms author renames carried by pushed logs,
+	// usually by asking a registry for the current name of a username. Pushed
+	// renames aren't followed if UsernameRedirect is nil
+	UsernameRedirect dsref.UsernameRedirect
+	// QuotaDir is the directory push & pull quota usage is saved in. Usage is
+	// kept in memory if QuotaDir is empty
+	QuotaDir string
 }
 
+const (
+	// pushQuotaFilename is the file push quota usage is saved to
+	pushQuotaFilename = "remote_push_quota.json"
+	// pullQuotaFilename is the file pull quota usage is saved to
+	pullQuotaFilename = "remote_pull_quota.json"
+)
+
 // Remote receives requests from other qri nodes to perform actions on their
 // behalf
 type Remote struct {
@@ -105,6 +121,10 @@ type Remote struct {
 	// TODO (b5) - dsync needs to use timeouts
 	acceptTimeoutMs time.Duration
 
+	// daily bytes each profile can push & pull
+	pushQuota *limits.Quota
+	pullQuota *limits.Quota
+
 	datasetPushPreCheck   Hook
 	datasetPushFinalCheck Hook
 	datasetPushed         Hook
@@ -117,6 +137,8 @@ type Remote struct {
 
 	// policy defines the access control for the remote
 	policy *access.Policy
+	// usernameRedirect confirms pushed author renames
+	usernameRedirect dsref.UsernameRedirect
 }
 
 // OptPolicy adds a policy to the remote options
@@ -126,6 +148,22 @@ func OptPolicy(p *access.Policy) OptionsFunc {
 	}
 }
 
+// OptUsernameRedirect sets the lookup remotes use to confirm author renames
+// before moving references to an author's new name
+func OptUsernameRedirect(redirect dsref.UsernameRedirect) OptionsFunc {
+	return func(o *Options) {
+		o.UsernameRedirect = redirect
+	}
+}
+
+// OptQuotaDir saves push & pull quota usage in dir, so usage survives
+// restarts
+func OptQuotaDir(dir string) OptionsFunc {
+	return func(o *Options) {
+		o.QuotaDir = dir
+	}
+}
+
 // OptLoadPolicyFileIfExists checks for a policy at the given path and populates
 // the remote.Options.Policy if so
 func OptLoadPolicyFileIfExists(filename string) OptionsFunc {
@@ -176,11 +214,25 @@ func NewRemote(node *p2p.QriNode, cfg *config.Remote, localResolver dsref.Resolv
 		datasetPullPreCheck:   o.DatasetPullPreCheck,
 		datasetPulled:         o.DatasetPulled,
 		policy:                o.Policy,
+		usernameRedirect:      o.UsernameRedirect,
 
 		FeedPreCheck:    o.FeedPreCheck,
 		PreviewPreCheck: o.PreviewPreCheck,
 	}
 
+	if o.QuotaDir != "" {
+		var err error
+		if r.pushQuota, err = limits.NewFileQuota(cfg.DailyPushBytesMax, filepath.Join(o.QuotaDir, pushQuotaFilename)); err != nil {
+			return nil, err
+		}
+		if r.pullQuota, err = limits.NewFileQuota(cfg.DailyPullBytesMax, filepath.Join(o.QuotaDir, pullQuotaFilename)); err != nil {
+			return nil, err
+		}
+	} else {
+		r.pushQuota = limits.NewQuota(cfg.DailyPushBytesMax)
+		r.pullQuota = limits.NewQuota(cfg.DailyPullBytesMax)
+	}
+
 	if o.Feeds != nil {
 		r.Feeds = o.Feeds
 	} else {
@@ -228,8 +280,8 @@ func NewRemote(node *p2p.QriNode, cfg *config.Remote, localResolver dsref.Resolv
 	if book := r.logbook; book != nil {
 		r.logsync = logsync.New(book, func(lso *logsync.Options) {
 			lso.PushPreCheck = r.logPreCheckHook("PushPreCheck", "remote:push", o.LogPushPreCheck)
-			lso.PushFinalCheck = r.logHook("PushFinalCheck", o.LogPushFinalCheck)
-			lso.Pushed = r.logHook("Pushed", o.LogPushed)
+			lso.PushFinalCheck = r.logPushFinalCheck(o.LogPushFinalCheck)
+			lso.Pushed = r.logPushed(o.LogPushed)
 			lso.PullPreCheck = r.logPreCheckHook("PullPreCheck", "remote:pull", o.LogPullPreCheck)
 			lso.Pulled = r.logHook("Pulled", o.LogPulled)
 			lso.RemovePreCheck = r.logPreCheckHook("RemovePreCheck", "remote:remove", o.LogRemovePreCheck)
@@ -361,12 +413,8 @@ func (r *Remote) dsPushPreCheck(ctx context.Context, info dag.Info, meta map[str
 	// TODO(dlong): Customization for how to decide to accept the dataset.
 
 	// If size is -1, accept any size of dataset. Otherwise, check if the size is allowed.
+	totalSize := dagSize(info)
 	if r.acceptSizeMax != -1 {
-		var totalSize uint64
-		for _, s := range info.Sizes {
-			totalSize += s
-		}
-
 		if totalSize >= uint64(r.acceptSizeMax) {
 			return fmt.Errorf("dataset size too large")
 		}
@@ -380,9 +428,23 @@ func (r *Remote) dsPushPreCheck(ctx context.Context, info dag.Info, meta map[str
 		}
 	}
 
+	// charge the quota last, so pushes rejected by other checks don't count
+	// against it
+	if err := chargeQuota(r.pushQuota, meta, int64(totalSize)); err != nil {
+		return fmt.Errorf("pushing %s: %w", ref, err)
+	}
+
 	return nil
 }
 
+// dagSize sums the sizes of all blocks in a dag
+func dagSize(info dag.Info) (size uint64) {
+	for _, s := range info.Sizes {
+		size += s
+	}
+	return size
+}
+
 func (r *Remote) dsPushFinalCheck(ctx context.Context, info dag.Info, meta map[string]string) error {
 	if r.datasetPushFinalCheck != nil {
 		subj, ref, err := r.subjAndRefFromMeta(meta)
@@ -465,9 +527,30 @@ func (r *Remote) dsGetDagInfo(ctx context.Context, into dag.Info, meta map[strin
 			return err
 		}
 	}
+
+	// pulls are charged for the whole dag, clients that already have some
+	// blocks are charged for bytes they won't fetch
+	if err := chargeQuota(r.pullQuota, meta, int64(dagSize(into))); err != nil {
+		return fmt.Errorf("pulling %s: %w", ref, err)
+	}
 	return nil
 }
 
+// chargeQuota adds n bytes to the usage of the profile that signed meta.
+// Quotas are only charged to verified profiles, otherwise clients could use
+// up the quota of any profile by claiming its ID. Unverified requests are
+// rejected when a quota is set
+func chargeQuota(q *limits.Quota, meta map[string]string, n int64) error {
+	if q == nil {
+		return nil
+	}
+	pid, err := VerifiedProfileID(meta)
+	if err != nil {
+		return err
+	}
+	return q.Use(pid.String(), n)
+}
+
 func (r *Remote) subjAndRefFromMeta(meta map[string]string) (*profile.Profile, dsref.Ref, error) {
 	ref := dsref.Ref{
 		Username:  meta["username"],
@@ -521,6 +604,87 @@ func (r *Remote) logHook(name string, h Hook) logsync.Hook {
 	}
 }
 
+// logPushFinalCheck rejects pushes signed with keys that can't write to the
+// pushed log before calling a hook. Logs must be signed by an active key of
+// the log's author, or by a retired key re-sending ops the remote already
+// holds. Logs in an organization's namespace must be pushed by a member of the
+// organization
+func (r *Remote) logPushFinalCheck(h Hook) logsync.Hook {
+	hook := r.logHook("PushFinalCheck", h)
+	return func(ctx context.Context, author identity.Author, ref dsref.Ref, l *oplog.Log) error {
+		kid, err := identity.KeyIDFromPub(author.AuthorPubKey())
+		if err != nil {
+			return err
+		}
+		if err := r.logbook.CheckAuthorKey(ctx, kid, l); err != nil {
+			log.Debugf("remote.logPushFinalCheck ref=%q error=%q", ref, err)
+			return err
+		}
+		pid, err := r.logbook.ProfileIDForKey(ctx, kid)
+		if err != nil {
+			return err
+		}
+		if err := r.logbook.CheckOrgWriteAccess(ctx, pid, l); err != nil {
+			log.Debugf("remote.logPushFinalCheck ref=%q error=%q", ref, err)
+			return err
+		}
+		return hook(ctx, author, ref, l)
+	}
+}
+
+// logPushed follows author renames carried by a pushed log before calling a
+// hook, moving this remote's references to the author's current name so
+// references under both names keep resolving
+func (r *Remote) logPushed(h Hook) logsync.Hook {
+	hook := r.logHook("Pushed", h)
+	return func(ctx context.Context, author identity.Author, ref dsref.Ref, l *oplog.Log) error {
+		if r.node != nil && r.usernameRedirect != nil && l.Model() == logbook.AuthorModel && len(l.Ops) > 0 {
+			if err := r.followAuthorRename(ctx, author, l); err != nil {
+				log.Debugf("remote.logPushed follow rename error=%q", err)
+			}
+		}
+		return hook(ctx, author, ref, l)
+	}
+}
+
+// followAuthorRename moves references held by the profile an author log
+// belongs to from each of the log's previous names to its current name.
+// Renames are only followed for logs pushed by the profile they describe, and
+// only once usernameRedirect confirms the previous name now belongs to the
+// current one
+func (r *Remote) followAuthorRename(ctx context.Context, author identity.Author, l *oplog.Log) error {
+	kid, err := identity.KeyIDFromPub(author.AuthorPubKey())
+	if err != nil {
+		return err
+	}
+	pusherID, err := r.logbook.ProfileIDForKey(ctx, kid)
+	if err != nil {
+		return err
+	}
+	if pusherID != l.Ops[0].AuthorID {
+		return fmt.Errorf("author log for %q wasn't pushed by its author", l.Name())
+	}
+	pid, err := profile.IDB58Decode(pusherID)
+	if err != nil {
+		return err
+	}
+
+	current := l.Name()
+	for _, prev := range logbook.AuthorNames(l) {
+		if prev == current {
+			continue
+		}
+		if confirmed, err := r.usernameRedirect(ctx, prev); err != nil || confirmed != current {
+			log.Debugf("remote.followAuthorRename unconfirmed rename %q -> %q", prev, current)
+			continue
+		}
+		if err := base.RenameRepoRefs(r.node.Repo, pid, prev, current); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *Remote) logPreCheckHook(name string, action string, h Hook) logsync.Hook {
 	return func(ctx context.Context, author identity.Author, ref dsref.Ref, l *oplog.Log) error {
 		log.Debugf("remote.logPreCheckHook hook=%q ref=%q", name, ref)
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -20,6 +21,8 @@ import (
 	cfgtest "github.com/qri-io/qri/config/test"
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/identity"
+	"github.com/qri-io/qri/limits"
 	"github.com/qri-io/qri/logbook"
 	"github.com/qri-io/qri/p2p"
 	p2ptest "github.com/qri-io/qri/p2p/test"
@@ -394,6 +397,158 @@ func TestAccess(t *testing.T) {
 	}
 }
 
+func TestQuotas(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	aRef := writeWorldBankPopulation(tr.Ctx, t, tr.NodeA.Repo)
+	bRef := writeVideoViewStats(tr.Ctx, t, tr.NodeB.Repo)
+	cli := tr.NodeBClient(t)
+	rem := tr.NodeARemote(t)
+	rem.pushQuota = limits.NewQuota(1)
+	rem.pullQuota = limits.NewQuota(1)
+	server := tr.RemoteTestServer(rem)
+	defer server.Close()
+
+	if err := cli.PushDataset(tr.Ctx, bRef, server.URL); err == nil || !strings.Contains(err.Error(), limits.ErrQuotaExceeded.Error()) {
+		t.Errorf("expected push over quota to fail with %q, got: %v", limits.ErrQuotaExceeded, err)
+	}
+	if _, err := cli.PullDataset(tr.Ctx, &aRef, server.URL); err == nil || !strings.Contains(err.Error(), limits.ErrQuotaExceeded.Error()) {
+		t.Errorf("expected pull over quota to fail with %q, got: %v", limits.ErrQuotaExceeded, err)
+	}
+
+	rem.pushQuota = limits.NewQuota(1 << 20)
+	if err := cli.PushDataset(tr.Ctx, bRef, server.URL); err != nil {
+		t.Errorf("unexpected error pushing within quota: %s", err)
+	}
+}
+
+func TestOrgMembershipPushes(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	aBook, bBook := tr.NodeA.Repo.Logbook(), tr.NodeB.Repo.Logbook()
+	aID, err := identity.KeyIDFromPub(aBook.AuthorPubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	bID, err := identity.KeyIDFromPub(bBook.AuthorPubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	org, err := bBook.WriteOrgInit(tr.Ctx, "climate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bBook.WriteOrgMemberAdd(tr.Ctx, "climate", logbook.OrgMember{Username: "A", ProfileID: aID, Role: logbook.OrgRoleAdmin}); err != nil {
+		t.Fatal(err)
+	}
+	initID, err := bBook.WriteOrgDatasetInit(tr.Ctx, "climate", "temperatures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	save := func(body string) dsref.Ref {
+		ds := &dataset.Dataset{
+			Name:      "temperatures",
+			Peername:  "climate",
+			ProfileID: org.ProfileID,
+			Commit:    &dataset.Commit{Title: "update"},
+			Structure: &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray},
+		}
+		ds.SetBodyFile(qfs.NewMemfileBytes("body.json", []byte(body)))
+		head := dsref.Ref{Username: "climate", Name: "temperatures"}
+		if _, err := bBook.ResolveRef(tr.Ctx, &head); err != nil {
+			t.Fatal(err)
+		}
+		res, err := base.SaveDataset(tr.Ctx, tr.NodeB.Repo, tr.NodeB.Repo.Filesystem().DefaultWriteFS(), initID, head.Path, ds, base.SaveSwitches{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ref := dsref.ConvertDatasetToVersionInfo(res).SimpleRef()
+		ref.InitID = initID
+		return ref
+	}
+
+	cli := tr.NodeBClient(t)
+	rem := tr.NodeARemote(t)
+	server := tr.RemoteTestServer(rem)
+	defer server.Close()
+
+	if err := cli.PushDataset(tr.Ctx, save("[1]"), server.URL); err != nil {
+		t.Fatalf("expected org member push to succeed, got: %s", err)
+	}
+
+	// once the remote knows the org, its copy of membership applies
+	if err := aBook.WriteOrgMemberRemove(tr.Ctx, "climate", bID); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.PushDataset(tr.Ctx, save("[1,2]"), server.URL); err == nil || !strings.Contains(err.Error(), logbook.ErrAccessDenied.Error()) {
+		t.Errorf("expected push from a removed member to be denied, got: %v", err)
+	}
+}
+
+func TestAuthorRenamePushes(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	bRef := writeVideoViewStats(tr.Ctx, t, tr.NodeB.Repo)
+	bBook := tr.NodeB.Repo.Logbook()
+	prev := bBook.Username()
+
+	// stand in for a registry that has confirmed the rename
+	confirmed := map[string]string{}
+	redirect := func(ctx context.Context, username string) (string, error) {
+		if current, ok := confirmed[username]; ok {
+			return current, nil
+		}
+		return "", dsref.ErrRefNotFound
+	}
+
+	cli := tr.NodeBClient(t)
+	rem := tr.NodeARemote(t, OptUsernameRedirect(redirect))
+	server := tr.RemoteTestServer(rem)
+	defer server.Close()
+
+	if err := cli.PushDataset(tr.Ctx, bRef, server.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	// another profile's reference under the old username must stay put
+	aPro, err := tr.NodeA.Repo.Profile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	squatted := reporef.DatasetRef{Peername: prev, Name: "squatted", ProfileID: aPro.ID, Path: "/mem/QmSquatted"}
+	if err := tr.NodeA.Repo.PutRef(squatted); err != nil {
+		t.Fatal(err)
+	}
+	if err := base.ModifyRepoUsername(tr.Ctx, tr.NodeB.Repo, bBook, prev, "b_renamed"); err != nil {
+		t.Fatal(err)
+	}
+	bRef.Username = "b_renamed"
+	if err := cli.PushLogs(tr.Ctx, bRef, server.URL); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.NodeA.Repo.GetRef(reporef.DatasetRef{Peername: "b_renamed", Name: bRef.Name}); err == nil {
+		t.Errorf("expected an unconfirmed rename not to move references")
+	}
+
+	confirmed[prev] = "b_renamed"
+	if err := cli.PushLogs(tr.Ctx, bRef, server.URL); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.NodeA.Repo.GetRef(reporef.DatasetRef{Peername: prev, Name: "squatted"}); err != nil {
+		t.Errorf("expected references of other profiles to keep their username, got: %s", err)
+	}
+	if _, err := tr.NodeA.Repo.GetRef(reporef.DatasetRef{Peername: "b_renamed", Name: bRef.Name}); err != nil {
+		t.Errorf("expected remote to move references to the new username, got: %s", err)
+	}
+	if current, err := tr.NodeA.Repo.Logbook().ResolveUsername(tr.Ctx, prev); err != nil || current != "b_renamed" {
+		t.Errorf("expected remote to redirect %q to %q, got: %q, %v", prev, "b_renamed", current, err)
+	}
+}
+
 type testRunner struct {
 	Ctx          context.Context
 	NodeA, NodeB *p2p.QriNode
@@ -2,19 +2,31 @@ package remote
 
 import (
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	crypto "github.com/libp2p/go-libp2p-core/crypto"
 	"github.com/multiformats/go-multihash"
 	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/identity"
+	"github.com/qri-io/qri/repo/profile"
 )
 
 var (
 	// nowFunc is an ps function for getting timestamps
 	nowFunc = time.Now
+
+	// ErrUnverifiedRequest indicates a request isn't signed by the profile it
+	// claims to come from
+	ErrUnverifiedRequest = errors.New("request signature could not be verified")
 )
 
+// signatureMaxAge is how far a signed request's timestamp can be from the
+// current time before the signature is rejected
+const signatureMaxAge = 10 * time.Minute
+
 func sigParams(pk crypto.PrivKey, subjectUsername string, ref dsref.Ref) (map[string]string, error) {
 	pid, err := calcProfileID(pk)
 	if err != nil {
@@ -27,6 +39,10 @@ func sigParams(pk crypto.PrivKey, subjectUsername string, ref dsref.Ref) (map[st
 	if err != nil {
 		return nil, err
 	}
+	b64PubKey, err := encodePubKey(pk.GetPublic())
+	if err != nil {
+		return nil, err
+	}
 
 	return map[string]string{
 		"username":  ref.Username,
@@ -41,6 +57,7 @@ func sigParams(pk crypto.PrivKey, subjectUsername string, ref dsref.Ref) (map[st
 		"subject_username": subjectUsername,
 		"timestamp":        now,
 		"signature":        b64Sig,
+		"pubkey":           b64PubKey,
 	}, nil
 }
 
@@ -83,6 +100,46 @@ func VerifySigParams(pubkey crypto.PubKey, params map[string]string) (bool, erro
 	return pubkey.Verify([]byte(rss), sigBytes)
 }
 
+// VerifiedProfileID checks the signature of request params, returning the
+// profileID of the key that signed them. The signer's public key is sent in
+// the "pubkey" param & must hash to the "pid" param, so a profileID can only
+// be claimed by the holder of its key. The signature covers the "timestamp",
+// "pid" & "path" params
+func VerifiedProfileID(params map[string]string) (profile.ID, error) {
+	data, err := base64.StdEncoding.DecodeString(params["pubkey"])
+	if err != nil || len(data) == 0 {
+		return "", fmt.Errorf("%w: missing public key", ErrUnverifiedRequest)
+	}
+	pub, err := crypto.UnmarshalPublicKey(data)
+	if err != nil {
+		return "", fmt.Errorf("%w: decoding public key: %s", ErrUnverifiedRequest, err)
+	}
+	pid, err := identity.KeyIDFromPub(pub)
+	if err != nil {
+		return "", err
+	}
+	if pid != params["pid"] {
+		return "", fmt.Errorf("%w: public key doesn't match profileID %q", ErrUnverifiedRequest, params["pid"])
+	}
+
+	ts, err := strconv.ParseInt(params["timestamp"], 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid timestamp", ErrUnverifiedRequest)
+	}
+	if age := nowFunc().Sub(time.Unix(ts, 0)); age > signatureMaxAge || age < -signatureMaxAge {
+		return "", fmt.Errorf("%w: signature has expired", ErrUnverifiedRequest)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(params["signature"])
+	if err != nil {
+		return "", fmt.Errorf("%w: decoding signature: %s", ErrUnverifiedRequest, err)
+	}
+	if ok, err := pub.Verify([]byte(requestSigningString(params["timestamp"], pid, params["path"])), sig); err != nil || !ok {
+		return "", ErrUnverifiedRequest
+	}
+	return profile.IDB58Decode(pid)
+}
+
 func requestSigningString(timestamp, peerID, cidStr string) string {
 	return fmt.Sprintf("%s.%s.%s", timestamp, peerID, cidStr)
 }
@@ -108,3 +165,11 @@ func calcProfileID(privKey crypto.PrivKey) (string, error) {
 
 	return mh.B58String(), nil
 }
+
+func encodePubKey(pub crypto.PubKey) (string, error) {
+	data, err := pub.Bytes()
+	if err != nil {
+		return "", fmt.Errorf("error getting pubkey bytes: %s", err.Error())
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
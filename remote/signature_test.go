@@ -1,7 +1,9 @@
 package remote
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/qri-io/qri/config/test"
 	"github.com/qri-io/qri/dsref"
@@ -51,3 +53,53 @@ func TestVerifySigParams(t *testing.T) {
 		t.Errorf("case 'should not verify', expected verification to be false, but was true")
 	}
 }
+
+func TestVerifiedProfileID(t *testing.T) {
+	peerInfo0 := test.GetTestPeerInfo(0)
+	ref := dsref.Ref{Path: "foo", Username: "bar", Name: "baz"}
+	params, err := sigParams(peerInfo0.PrivKey, "bar", ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pid, err := VerifiedProfileID(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pid.String() != params["pid"] {
+		t.Errorf("expected profileID %q, got %q", params["pid"], pid)
+	}
+
+	// claiming another profile's ID with our own key
+	otherPid, err := calcProfileID(test.GetTestPeerInfo(1).PrivKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claimed := copyParams(params)
+	claimed["pid"] = otherPid
+	if _, err := VerifiedProfileID(claimed); !errors.Is(err, ErrUnverifiedRequest) {
+		t.Errorf("expected a claimed profileID to be unverified, got: %v", err)
+	}
+
+	// signatures only cover the signed path
+	tampered := copyParams(params)
+	tampered["path"] = "bar"
+	if _, err := VerifiedProfileID(tampered); !errors.Is(err, ErrUnverifiedRequest) {
+		t.Errorf("expected a tampered path to be unverified, got: %v", err)
+	}
+
+	prevNowFunc := nowFunc
+	nowFunc = func() time.Time { return prevNowFunc().Add(time.Hour) }
+	defer func() { nowFunc = prevNowFunc }()
+	if _, err := VerifiedProfileID(params); !errors.Is(err, ErrUnverifiedRequest) {
+		t.Errorf("expected an old signature to be unverified, got: %v", err)
+	}
+}
+
+func copyParams(params map[string]string) map[string]string {
+	res := make(map[string]string, len(params))
+	for k, v := range params {
+		res[k] = v
+	}
+	return res
+}
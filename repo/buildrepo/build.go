@@ -4,6 +4,8 @@ package buildrepo
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -79,7 +81,13 @@ func NewFilesystem(ctx context.Context, cfg *config.Config) (*muxfs.Mux, error)
 			if path, ok := fsCfg.Config["path"].(string); ok {
 				if !filepath.IsAbs(path) {
 					// resolve relative filepaths
-					cfg.Filesystems[i].Config["path"] = filepath.Join(qriPath, path)
+					path = filepath.Join(qriPath, path)
+					cfg.Filesystems[i].Config["path"] = path
+				}
+				if cfg.P2P != nil && cfg.P2P.PrivateSwarm {
+					if err := writeSwarmKey(path, cfg.P2P); err != nil {
+						return nil, err
+					}
 				}
 			}
 		}
@@ -100,6 +108,19 @@ func NewFilesystem(ctx context.Context, cfg *config.Config) (*muxfs.Mux, error)
 	return muxfs.New(ctx, cfg.Filesystems)
 }
 
+// writeSwarmKey puts the private swarm key where IPFS looks for it, so the
+// IPFS node only connects to peers in the swarm
+func writeSwarmKey(ipfsPath string, cfg *config.P2P) error {
+	if _, err := cfg.DecodeSwarmKey(); err != nil {
+		return err
+	}
+	if _, err := os.Stat(ipfsPath); os.IsNotExist(err) {
+		// IPFS repos are created during setup, the key is written on next load
+		return nil
+	}
+	return ioutil.WriteFile(filepath.Join(ipfsPath, "swarm.key"), []byte(cfg.SwarmKeyFile()), 0600)
+}
+
 func newLogbook(fs qfs.Filesystem, bus event.Bus, pro *profile.Profile, repoPath string) (book *logbook.Book, err error) {
 	logbookPath := filepath.Join(repoPath, "logbook.qfb")
 	return logbook.NewJournal(pro.PrivKey, pro.Peername, bus, fs, logbookPath)
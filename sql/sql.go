@@ -8,6 +8,7 @@ import (
 
 	"github.com/cube2222/octosql/app"
 	octosqlcfg "github.com/cube2222/octosql/config"
+	"github.com/cube2222/octosql/execution"
 	"github.com/cube2222/octosql/output"
 	csvoutput "github.com/cube2222/octosql/output/csv"
 	jsonoutput "github.com/cube2222/octosql/output/json"
@@ -42,6 +43,39 @@ func New(r repo.Repo, loadDataset dsref.ParseResolveLoad) *Service {
 
 // Exec runs an SQL query against a given dataset mapping
 func (svc *Service) Exec(ctx context.Context, w io.Writer, outFormat, query string) error {
+	var out output.Output
+	switch outFormat {
+	case "table":
+		out = table.NewOutput(w, false)
+	case "table_row_separated":
+		out = table.NewOutput(w, true)
+	case "json":
+		out = jsonoutput.NewOutput(w)
+	case "csv":
+		out = csvoutput.NewOutput(',', w)
+	case "tabbed":
+		out = csvoutput.NewOutput('\t', w)
+	default:
+		err := fmt.Errorf("invalid output type: %s", w)
+		log.Error(err)
+		return err
+	}
+
+	return svc.run(ctx, out, query)
+}
+
+// Query runs an SQL query, returning result column names & rows of values
+// in column order
+func (svc *Service) Query(ctx context.Context, query string) (columns []string, rows [][]interface{}, err error) {
+	out := &rowsOutput{}
+	if err = svc.run(ctx, out, query); err != nil {
+		return nil, nil, err
+	}
+	return out.columns, out.rows, nil
+}
+
+// run executes a query, writing results to out
+func (svc *Service) run(ctx context.Context, out output.Output, query string) error {
 	processedQuery, sources, err := preprocess.Query(query)
 	if err != nil {
 		log.Errorf("mapping query: %s", err)
@@ -75,24 +109,6 @@ func (svc *Service) Exec(ctx context.Context, w io.Writer, outFormat, query stri
 		return err
 	}
 
-	var out output.Output
-	switch outFormat {
-	case "table":
-		out = table.NewOutput(w, false)
-	case "table_row_separated":
-		out = table.NewOutput(w, true)
-	case "json":
-		out = jsonoutput.NewOutput(w)
-	case "csv":
-		out = csvoutput.NewOutput(',', w)
-	case "tabbed":
-		out = csvoutput.NewOutput('\t', w)
-	default:
-		err = fmt.Errorf("invalid output type: %s", w)
-		log.Error(err)
-		return err
-	}
-
 	app := app.NewApp(cfg, dataSourceRepository, out, false)
 
 	// Parse query
@@ -125,6 +141,32 @@ Error:
 	return unwrapErr(err)
 }
 
+// rowsOutput collects query results in memory
+type rowsOutput struct {
+	columns []string
+	rows    [][]interface{}
+}
+
+// WriteRecord implements the octosql output.Output interface
+func (o *rowsOutput) WriteRecord(record *execution.Record) error {
+	fields := record.Fields()
+	if o.columns == nil {
+		o.columns = make([]string, len(fields))
+		for i, field := range fields {
+			o.columns[i] = field.Name.String()
+		}
+	}
+	row := make([]interface{}, len(fields))
+	for i, field := range fields {
+		row[i] = record.Value(field.Name).ToRawValue()
+	}
+	o.rows = append(o.rows, row)
+	return nil
+}
+
+// Close implements the io.Closer interface
+func (o *rowsOutput) Close() error { return nil }
+
 // octosql uses the errors package, which doesn't support errors.Unwrap,
 // so we unwrap before returning
 func unwrapErr(err error) error {
@@ -0,0 +1,137 @@
+// Package assert implements a starlark module of assertions for testing
+// transform scripts, eg: load('assert.star', 'assert')
+package assert
+
+import (
+	"fmt"
+	"regexp"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+)
+
+// ModuleName defines the expected name for this module when used
+// in starlark's load() function, eg: load('assert.star', 'assert')
+const ModuleName = "assert.star"
+
+// LoadModule loads the assert module
+func LoadModule() (starlark.StringDict, error) {
+	return starlark.StringDict{
+		"assert": starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+			"eq":       starlark.NewBuiltin("eq", eq),
+			"ne":       starlark.NewBuiltin("ne", ne),
+			"true":     starlark.NewBuiltin("true", truth),
+			"contains": starlark.NewBuiltin("contains", contains),
+			"fails":    starlark.NewBuiltin("fails", fails),
+		}),
+	}, nil
+}
+
+// failure creates an assertion error, prefixed with an optional message
+func failure(b *starlark.Builtin, msg starlark.String, format string, args ...interface{}) error {
+	err := fmt.Sprintf(format, args...)
+	if msg != "" {
+		err = fmt.Sprintf("%s: %s", msg.GoString(), err)
+	}
+	return fmt.Errorf("assert.%s: %s", b.Name(), err)
+}
+
+// eq(actual, expected, msg?) fails if actual != expected
+func eq(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		x, y starlark.Value
+		msg  starlark.String
+	)
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "actual", &x, "expected", &y, "msg?", &msg); err != nil {
+		return nil, err
+	}
+	ok, err := starlark.Equal(x, y)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, failure(b, msg, "%s != %s", x, y)
+	}
+	return starlark.None, nil
+}
+
+// ne(actual, unexpected, msg?) fails if actual == unexpected
+func ne(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		x, y starlark.Value
+		msg  starlark.String
+	)
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "actual", &x, "unexpected", &y, "msg?", &msg); err != nil {
+		return nil, err
+	}
+	ok, err := starlark.Compare(syntax.NEQ, x, y)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, failure(b, msg, "%s == %s", x, y)
+	}
+	return starlark.None, nil
+}
+
+// true(cond, msg?) fails if cond isn't truthy
+func truth(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		cond starlark.Value
+		msg  starlark.String
+	)
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "cond", &cond, "msg?", &msg); err != nil {
+		return nil, err
+	}
+	if !cond.Truth() {
+		return nil, failure(b, msg, "%s is not true", cond)
+	}
+	return starlark.None, nil
+}
+
+// contains(container, item, msg?) fails if item isn't in container
+func contains(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		container, item starlark.Value
+		msg             starlark.String
+	)
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "container", &container, "item", &item, "msg?", &msg); err != nil {
+		return nil, err
+	}
+	ok, err := starlark.Binary(syntax.IN, item, container)
+	if err != nil {
+		return nil, err
+	}
+	if !ok.Truth() {
+		return nil, failure(b, msg, "%s not in %s", item, container)
+	}
+	return starlark.None, nil
+}
+
+// fails(fn, pattern) calls fn, failing if fn doesn't error with a message
+// that matches the regular expression pattern
+func fails(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		fn      starlark.Callable
+		pattern string
+	)
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "fn", &fn, "pattern", &pattern); err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("assert.fails: invalid pattern: %w", err)
+	}
+	if _, err := starlark.Call(thread, fn, nil, nil); err != nil {
+		msg := err.Error()
+		if ee, ok := err.(*starlark.EvalError); ok {
+			msg = ee.Msg
+		}
+		if !re.MatchString(msg) {
+			return nil, failure(b, "", "error %q doesn't match %q", msg, pattern)
+		}
+		return starlark.None, nil
+	}
+	return nil, failure(b, "", "%s didn't fail", fn.Name())
+}
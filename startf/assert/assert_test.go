@@ -0,0 +1,26 @@
+package assert
+
+import (
+	"fmt"
+	"testing"
+
+	"go.starlark.net/starlark"
+)
+
+func TestFile(t *testing.T) {
+	thread := &starlark.Thread{Load: func(thread *starlark.Thread, module string) (starlark.StringDict, error) {
+		if module == ModuleName {
+			return LoadModule()
+		}
+		return nil, fmt.Errorf("invalid module")
+	}}
+
+	_, err := starlark.ExecFile(thread, "testdata/test.star", nil, nil)
+	if err != nil {
+		if ee, ok := err.(*starlark.EvalError); ok {
+			t.Error(ee.Backtrace())
+		} else {
+			t.Error(err)
+		}
+	}
+}
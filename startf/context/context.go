@@ -2,6 +2,7 @@ package context
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/qri-io/starlib/util"
 	"go.starlark.net/starlark"
@@ -15,18 +16,68 @@ type Context struct {
 	values  starlark.StringDict
 	config  map[string]interface{}
 	secrets map[string]interface{}
+	// state persists between transform runs
+	state map[string]starlark.Value
+	// names of secrets read by the script
+	secretsUsed map[string]bool
 }
 
 // NewContext creates a new contex
 func NewContext(config, secrets map[string]interface{}) *Context {
 	return &Context{
-		results: starlark.StringDict{},
-		values:  starlark.StringDict{},
-		config:  config,
-		secrets: secrets,
+		results:     starlark.StringDict{},
+		values:      starlark.StringDict{},
+		config:      config,
+		secrets:     secrets,
+		state:       map[string]starlark.Value{},
+		secretsUsed: map[string]bool{},
 	}
 }
 
+// SecretsUsed lists the names of secrets the script has read in sorted order
+func (c *Context) SecretsUsed() []string {
+	if len(c.secretsUsed) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(c.secretsUsed))
+	for name := range c.secretsUsed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetState replaces the persisted state scripts read with get_state, usually
+// the state the previous run of a transform ended with
+func (c *Context) SetState(state map[string]interface{}) error {
+	c.state = map[string]starlark.Value{}
+	for key, val := range state {
+		v, err := util.Marshal(val)
+		if err != nil {
+			return fmt.Errorf("reading state %q: %w", key, err)
+		}
+		c.state[key] = v
+	}
+	return nil
+}
+
+// State returns the persisted state as of the end of the script, nil if no
+// state is set
+func (c *Context) State() (map[string]interface{}, error) {
+	if len(c.state) == 0 {
+		return nil, nil
+	}
+	state := map[string]interface{}{}
+	for key, val := range c.state {
+		v, err := util.Unmarshal(val)
+		if err != nil {
+			return nil, fmt.Errorf("saving state %q: %w", key, err)
+		}
+		state[key] = v
+	}
+	return state, nil
+}
+
 // Struct delivers this context as a starlark struct
 func (c *Context) Struct() *starlarkstruct.Struct {
 	dict := starlark.StringDict{
@@ -34,6 +85,8 @@ func (c *Context) Struct() *starlarkstruct.Struct {
 		"get":        starlark.NewBuiltin("get", c.getValue),
 		"get_config": starlark.NewBuiltin("get_config", c.GetConfig),
 		"get_secret": starlark.NewBuiltin("get_secret", c.GetSecret),
+		"get_state":  starlark.NewBuiltin("get_state", c.GetState),
+		"set_state":  starlark.NewBuiltin("set_state", c.SetStateValue),
 	}
 
 	for k, v := range c.results {
@@ -84,6 +137,9 @@ func (c *Context) GetSecret(thread *starlark.Thread, _ *starlark.Builtin, args s
 		return nil, err
 	}
 
+	if _, ok := c.secrets[string(key)]; ok {
+		c.secretsUsed[string(key)] = true
+	}
 	return util.Marshal(c.secrets[string(key)])
 }
 
@@ -101,3 +157,40 @@ func (c *Context) GetConfig(thread *starlark.Thread, _ *starlark.Builtin, args s
 
 	return util.Marshal(c.config[string(key)])
 }
+
+// GetState returns a value from the state persisted between transform runs,
+// or default if key isn't set
+func (c *Context) GetState(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		key starlark.String
+		def starlark.Value = starlark.None
+	)
+	if err := starlark.UnpackArgs("get_state", args, kwargs, "key", &key, "default?", &def); err != nil {
+		return nil, err
+	}
+	if v, ok := c.state[string(key)]; ok {
+		return v, nil
+	}
+	return def, nil
+}
+
+// SetStateValue sets a value in the state persisted between transform runs.
+// state values must be json-serializable, setting a value to None removes it
+func (c *Context) SetStateValue(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		key   starlark.String
+		value starlark.Value
+	)
+	if err := starlark.UnpackArgs("set_state", args, kwargs, "key", &key, "value", &value); err != nil {
+		return nil, err
+	}
+	if value == starlark.None {
+		delete(c.state, string(key))
+		return starlark.None, nil
+	}
+	if _, err := util.Unmarshal(value); err != nil {
+		return nil, fmt.Errorf("state value for %q can't be saved: %w", string(key), err)
+	}
+	c.state[string(key)] = value
+	return starlark.None, nil
+}
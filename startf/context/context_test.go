@@ -5,6 +5,7 @@ import (
 
 	"github.com/qri-io/starlib/testdata"
 	"go.starlark.net/starlark"
+	"go.starlark.net/starlarktest"
 )
 
 func TestContext(t *testing.T) {
@@ -75,3 +76,38 @@ func TestMissingSecrets(t *testing.T) {
 func newLoader() func(thread *starlark.Thread, module string) (starlark.StringDict, error) {
 	return testdata.NewLoader(nil, "context_is_global_no_module_name_exists")
 }
+
+func TestState(t *testing.T) {
+	thread := &starlark.Thread{Load: newLoader()}
+	starlarktest.SetReporter(thread, t)
+	ctx := NewContext(nil, nil)
+	if err := ctx.SetState(map[string]interface{}{"cursor": 10}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := starlark.ExecFile(thread, "state.star", `
+load('assert.star', 'assert')
+assert.eq(ctx.get_state("cursor"), 10)
+assert.eq(ctx.get_state("missing"), None)
+assert.eq(ctx.get_state("missing", 0), 0)
+ctx.set_state("cursor", 20)
+ctx.set_state("seen", ["a", "b"])
+ctx.set_state("missing", None)
+`, starlark.StringDict{"ctx": ctx.Struct()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := ctx.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]interface{}{"cursor": 20, "seen": []interface{}{"a", "b"}}
+	if len(state) != len(expect) || state["cursor"] != expect["cursor"] || len(state["seen"].([]interface{})) != 2 {
+		t.Errorf("state mismatch. expected: %v, got: %v", expect, state)
+	}
+
+	if _, err := ctx.SetStateValue(thread, nil, starlark.Tuple{starlark.String("fn"), starlark.NewBuiltin("fn", nil)}, nil); err == nil {
+		t.Error("expected setting a state value that can't be serialized to error")
+	}
+}
@@ -0,0 +1,103 @@
+package ds
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/starlib/util"
+	"go.starlark.net/starlark"
+)
+
+// BodyStream is a starlark iterable that reads body entries one at a time
+// instead of loading the entire body into memory. Array bodies yield values,
+// object bodies yield (key, value) tuples. A stream can only be iterated once
+type BodyStream struct {
+	r        dsio.EntryReader
+	isObject bool
+	consumed bool
+	err      error
+}
+
+// compile-time assertion that BodyStream is a starlark.Iterable
+var _ starlark.Iterable = (*BodyStream)(nil)
+
+// NewBodyStream creates a stream of the body of ds
+func NewBodyStream(ds *dataset.Dataset) (*BodyStream, error) {
+	if ds.Structure == nil {
+		return nil, fmt.Errorf("error: no structure for dataset")
+	}
+	mode, err := schemaScanMode(ds.Structure)
+	if err != nil {
+		return nil, err
+	}
+	r, err := dsio.NewEntryReader(ds.Structure, ds.BodyFile())
+	if err != nil {
+		return nil, fmt.Errorf("error allocating data reader: %s", err)
+	}
+	return &BodyStream{r: r, isObject: mode == smObject}, nil
+}
+
+// Err returns the first error encountered while reading the stream. starlark
+// iterators can't return errors, so callers must check Err after iteration
+func (s *BodyStream) Err() error { return s.err }
+
+// String implements the starlark.Value interface
+func (s *BodyStream) String() string { return "<body_stream>" }
+
+// Type implements the starlark.Value interface
+func (s *BodyStream) Type() string { return "body_stream" }
+
+// Freeze implements the starlark.Value interface. streams are read-only
+func (s *BodyStream) Freeze() {}
+
+// Truth implements the starlark.Value interface
+func (s *BodyStream) Truth() starlark.Bool { return starlark.True }
+
+// Hash implements the starlark.Value interface
+func (s *BodyStream) Hash() (uint32, error) {
+	return 0, fmt.Errorf("unhashable type: %s", s.Type())
+}
+
+// Iterate implements the starlark.Iterable interface
+func (s *BodyStream) Iterate() starlark.Iterator {
+	if s.consumed && s.err == nil {
+		s.err = fmt.Errorf("body stream can only be iterated once")
+	}
+	s.consumed = true
+	return &bodyStreamIterator{s}
+}
+
+type bodyStreamIterator struct {
+	s *BodyStream
+}
+
+// Next implements the starlark.Iterator interface
+func (it *bodyStreamIterator) Next(p *starlark.Value) bool {
+	s := it.s
+	if s.err != nil {
+		return false
+	}
+	ent, err := s.r.ReadEntry()
+	if err != nil {
+		if err.Error() != io.EOF.Error() {
+			s.err = err
+		}
+		return false
+	}
+
+	val, err := util.Marshal(ent.Value)
+	if err != nil {
+		s.err = err
+		return false
+	}
+	if s.isObject {
+		val = starlark.Tuple{starlark.String(ent.Key), val}
+	}
+	*p = val
+	return true
+}
+
+// Done implements the starlark.Iterator interface
+func (it *bodyStreamIterator) Done() {}
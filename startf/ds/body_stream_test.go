@@ -0,0 +1,69 @@
+package ds
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"go.starlark.net/starlark"
+)
+
+func TestStreamBody(t *testing.T) {
+	thread := &starlark.Thread{}
+
+	d := csvDataset()
+	stream, err := d.StreamBody(thread, nil, starlark.Tuple{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows []string
+	iter := stream.(starlark.Iterable).Iterate()
+	var v starlark.Value
+	for iter.Next(&v) {
+		rows = append(rows, v.String())
+	}
+	iter.Done()
+	if err := d.StreamErr(); err != nil {
+		t.Fatal(err)
+	}
+	expect := `[["foo", 1, "true"] ["bar", 2, "false"] ["bat", 3, "meh"]]`
+	if got := fmt.Sprintf("%v", rows); got != expect {
+		t.Errorf("stream mismatch.\nwant: %s\ngot:  %s", expect, got)
+	}
+
+	// streams can only be read once
+	stream.(starlark.Iterable).Iterate().Next(&v)
+	if d.StreamErr() == nil {
+		t.Error("expected iterating a stream twice to error")
+	}
+
+	// object bodies stream (key, value) pairs
+	obj := &dataset.Dataset{
+		Structure: &dataset.Structure{
+			Format: "json",
+			Schema: dataset.BaseSchemaObject,
+		},
+	}
+	obj.SetBodyFile(qfs.NewMemfileBytes("body.json", []byte(`{"a":1}`)))
+	stream, err = NewDataset(obj, nil).StreamBody(thread, nil, starlark.Tuple{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iter = stream.(starlark.Iterable).Iterate()
+	if !iter.Next(&v) {
+		t.Fatal("expected an entry")
+	}
+	if v.String() != `("a", 1)` {
+		t.Errorf("expected (key, value) tuple, got: %s", v)
+	}
+
+	// datasets without a body stream nothing
+	stream, err = NewDataset(&dataset.Dataset{}, nil).StreamBody(thread, nil, starlark.Tuple{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stream.(starlark.Sequence).Len() != 0 {
+		t.Errorf("expected empty stream, got: %s", stream)
+	}
+}
@@ -49,6 +49,7 @@ type Dataset struct {
 	bodyCache starlark.Iterable
 	check     MutateFieldCheck
 	modBody   bool
+	streams   []*BodyStream
 }
 
 // NewDataset creates a dataset object, intended to be called from go-land to prepare datasets
@@ -73,6 +74,16 @@ func (d *Dataset) IsBodyModified() bool {
 	return d.modBody
 }
 
+// StreamErr returns the first error encountered reading a body stream
+func (d *Dataset) StreamErr() error {
+	for _, s := range d.streams {
+		if err := s.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Methods exposes dataset methods as starlark values
 func (d *Dataset) Methods() *starlarkstruct.Struct {
 	return starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
@@ -82,6 +93,7 @@ func (d *Dataset) Methods() *starlarkstruct.Struct {
 		"set_structure": starlark.NewBuiltin("set_structure", d.SetStructure),
 		"get_body":      starlark.NewBuiltin("get_body", d.GetBody),
 		"set_body":      starlark.NewBuiltin("set_body", d.SetBody),
+		"stream_body":   starlark.NewBuiltin("stream_body", d.StreamBody),
 	})
 }
 
@@ -274,6 +286,25 @@ func (d *Dataset) GetBody(thread *starlark.Thread, _ *starlark.Builtin, args sta
 	return starlark.None, fmt.Errorf("value is not iterable")
 }
 
+// StreamBody returns the body of the dataset we're transforming as a stream of
+// entries, always reading the version being transformed, even after the body
+// is modified by set_body. Streams read the body file directly, so the body can
+// only be streamed once, and can't be read with get_body after streaming
+func (d *Dataset) StreamBody(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackArgs("stream_body", args, kwargs); err != nil {
+		return starlark.None, err
+	}
+	if d.read == nil || d.read.BodyFile() == nil {
+		return starlark.NewList(nil), nil
+	}
+	s, err := NewBodyStream(d.read)
+	if err != nil {
+		return starlark.None, err
+	}
+	d.streams = append(d.streams, s)
+	return s, nil
+}
+
 // SetBody assigns the dataset body. Future calls to GetBody will return this newly mutated body,
 // even if assigned value is the same as what was already there.
 func (d *Dataset) SetBody(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
@@ -0,0 +1,490 @@
+package startf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/startf/assert"
+	skyds "github.com/qri-io/qri/startf/ds"
+	"go.starlark.net/starlark"
+)
+
+// TestFixtures are the inputs a transform script is tested against, read from
+// a "[script].fixtures.json" file alongside the script
+type TestFixtures struct {
+	// transform configuration
+	Config map[string]interface{} `json:"config,omitempty"`
+	// secrets to execute with
+	Secrets map[string]string `json:"secrets,omitempty"`
+	// previous version of the dataset
+	Prev json.RawMessage `json:"prev,omitempty"`
+	// datasets load_dataset can load, keyed by reference
+	Datasets map[string]json.RawMessage `json:"datasets,omitempty"`
+	// recorded responses to http requests
+	HTTP []*HTTPFixture `json:"http,omitempty"`
+}
+
+// HTTPFixture is a recorded response to an http request
+type HTTPFixture struct {
+	Method string            `json:"method"`
+	URL    string            `json:"url"`
+	Status int               `json:"status"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   string            `json:"body"`
+}
+
+// TestCase is the result of a single transform test
+type TestCase struct {
+	Name  string `json:"name"`
+	Error string `json:"error,omitempty"`
+}
+
+// Passed reports if the test succeeded
+func (c TestCase) Passed() bool { return c.Error == "" }
+
+// TestResults is the outcome of testing a transform script
+type TestResults struct {
+	Script string     `json:"script"`
+	Cases  []TestCase `json:"cases"`
+	// output the script printed while running
+	Output string `json:"output,omitempty"`
+}
+
+// Failed counts failed test cases
+func (r *TestResults) Failed() (n int) {
+	for _, c := range r.Cases {
+		if !c.Passed() {
+			n++
+		}
+	}
+	return n
+}
+
+// FixturePaths returns the paths of the fixtures, expected dataset & test
+// file used to test the script at scriptPath
+func FixturePaths(scriptPath string) (fixtures, expected, testFile string) {
+	base := strings.TrimSuffix(scriptPath, ".star")
+	return base + ".fixtures.json", base + ".expected.json", base + "_test.star"
+}
+
+// RunTest executes the transform script at scriptPath against fixtures,
+// comparing the resulting dataset to the components in the expected file and
+// running every test_ function defined in the test file. Test functions are
+// called with the resulting dataset, and can load the assert module. When
+// record is true, http requests are made over the network and responses are
+// recorded to the fixtures file. opts are applied after fixture options
+func RunTest(ctx context.Context, scriptPath string, record bool, opts ...func(*ExecOpts)) (*TestResults, error) {
+	fixturesPath, expectedPath, testPath := FixturePaths(scriptPath)
+
+	script, err := ioutil.ReadFile(scriptPath)
+	if err != nil {
+		return nil, err
+	}
+	fx := &TestFixtures{}
+	if data, err := ioutil.ReadFile(fixturesPath); err == nil {
+		if err := json.Unmarshal(data, fx); err != nil {
+			return nil, fmt.Errorf("reading fixtures %q: %w", fixturesPath, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	expected, err := readOptionalFile(expectedPath)
+	if err != nil {
+		return nil, err
+	}
+	testScript, err := readOptionalFile(testPath)
+	if err != nil {
+		return nil, err
+	}
+	if expected == nil && testScript == nil {
+		return nil, fmt.Errorf("no tests for %s. create %s or %s", scriptPath, expectedPath, testPath)
+	}
+
+	var prev *dataset.Dataset
+	if fx.Prev != nil {
+		if prev, err = datasetFixture(fx.Prev); err != nil {
+			return nil, fmt.Errorf("reading prev fixture: %w", err)
+		}
+	}
+
+	next := &dataset.Dataset{
+		Transform: &dataset.Transform{Config: fx.Config},
+	}
+	next.Transform.SetScriptFile(qfs.NewMemfileBytes("transform.star", script))
+
+	var rt http.RoundTripper = &httpFixtureTransport{fixtures: fx.HTTP}
+	if record {
+		fx.HTTP = nil
+		rt = &httpRecordTransport{rt: http.DefaultTransport, fixtures: fx}
+	}
+
+	out := &bytes.Buffer{}
+	res := &TestResults{Script: scriptPath}
+	opts = append([]func(*ExecOpts){
+		SetErrWriter(out),
+		SetSecrets(fx.Secrets),
+		SetHTTPTransport(rt),
+		AddDatasetLoader(fx.loader),
+	}, opts...)
+
+	err = ExecScript(ctx, next, prev, opts...)
+	res.Output = out.String()
+	if err != nil {
+		res.Cases = append(res.Cases, TestCase{Name: "transform", Error: err.Error()})
+		return res, nil
+	}
+
+	if record {
+		if err := writeFixtures(fixturesPath, fx); err != nil {
+			return nil, err
+		}
+	}
+
+	var body []byte
+	if f := next.BodyFile(); f != nil {
+		if body, err = ioutil.ReadAll(f); err != nil {
+			return nil, err
+		}
+	}
+
+	if expected != nil {
+		tc := TestCase{Name: "expected"}
+		if err := compareExpected(expected, next, body); err != nil {
+			tc.Error = err.Error()
+		}
+		res.Cases = append(res.Cases, tc)
+	}
+
+	if testScript != nil {
+		cases, err := runTestFile(testPath, testScript, next, body, out)
+		if err != nil {
+			return nil, err
+		}
+		res.Cases = append(res.Cases, cases...)
+		res.Output = out.String()
+	}
+
+	return res, nil
+}
+
+func readOptionalFile(path string) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func writeFixtures(path string, fx *TestFixtures) error {
+	data, err := json.MarshalIndent(fx, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// datasetFixture reads a dataset fixture. Fixtures define their body inline,
+// in JSON
+func datasetFixture(data []byte) (*dataset.Dataset, error) {
+	ds := &dataset.Dataset{}
+	if err := json.Unmarshal(data, ds); err != nil {
+		return nil, err
+	}
+	if ds.Body == nil {
+		return ds, nil
+	}
+
+	body, err := json.Marshal(ds.Body)
+	if err != nil {
+		return nil, err
+	}
+	if ds.Structure == nil {
+		ds.Structure = &dataset.Structure{}
+	}
+	ds.Structure.Format = "json"
+	ds.Structure.FormatConfig = nil
+	if ds.Structure.Schema == nil {
+		ds.Structure.Schema = dataset.BaseSchemaArray
+		if _, ok := ds.Body.(map[string]interface{}); ok {
+			ds.Structure.Schema = dataset.BaseSchemaObject
+		}
+	}
+	ds.Body = nil
+	ds.SetBodyFile(qfs.NewMemfileBytes("body.json", body))
+	return ds, nil
+}
+
+// loader loads dataset fixtures by reference
+func (fx *TestFixtures) loader(ctx context.Context, refstr string) (*dataset.Dataset, error) {
+	data, ok := fx.Datasets[refstr]
+	if !ok {
+		return nil, fmt.Errorf("%w: no dataset fixture for %q", dsref.ErrRefNotFound, refstr)
+	}
+	return datasetFixture(data)
+}
+
+// httpFixtureTransport responds to requests with recorded fixtures. Requests
+// for the same method & url get recorded responses in order, repeating the
+// last response once all have been used
+type httpFixtureTransport struct {
+	lk       sync.Mutex
+	fixtures []*HTTPFixture
+	used     map[*HTTPFixture]bool
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (t *httpFixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.lk.Lock()
+	defer t.lk.Unlock()
+	if t.used == nil {
+		t.used = map[*HTTPFixture]bool{}
+	}
+
+	var match *HTTPFixture
+	for _, fx := range t.fixtures {
+		if fx.Method == req.Method && fx.URL == req.URL.String() {
+			match = fx
+			if !t.used[fx] {
+				break
+			}
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("no recorded response for %s %s", req.Method, req.URL)
+	}
+	t.used[match] = true
+
+	res := &http.Response{
+		Status:        fmt.Sprintf("%d %s", match.Status, http.StatusText(match.Status)),
+		StatusCode:    match.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{},
+		Body:          ioutil.NopCloser(strings.NewReader(match.Body)),
+		ContentLength: int64(len(match.Body)),
+		Request:       req,
+	}
+	for key, val := range match.Header {
+		res.Header.Set(key, val)
+	}
+	return res, nil
+}
+
+// httpRecordTransport makes requests with rt, recording responses as fixtures
+type httpRecordTransport struct {
+	lk       sync.Mutex
+	rt       http.RoundTripper
+	fixtures *TestFixtures
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (t *httpRecordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.rt.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	fx := &HTTPFixture{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Status: res.StatusCode,
+		Body:   string(body),
+	}
+	if ct := res.Header.Get("Content-Type"); ct != "" {
+		fx.Header = map[string]string{"Content-Type": ct}
+	}
+
+	t.lk.Lock()
+	t.fixtures.HTTP = append(t.fixtures.HTTP, fx)
+	t.lk.Unlock()
+	return res, nil
+}
+
+// compareExpected checks every component defined in expected matches ds.
+// objects in expected only need to define the fields to compare
+func compareExpected(expected []byte, ds *dataset.Dataset, body []byte) error {
+	var exp map[string]interface{}
+	if err := json.Unmarshal(expected, &exp); err != nil {
+		return fmt.Errorf("reading expected dataset: %w", err)
+	}
+
+	data, err := json.Marshal(ds)
+	if err != nil {
+		return err
+	}
+	var actual map[string]interface{}
+	if err := json.Unmarshal(data, &actual); err != nil {
+		return err
+	}
+	if body != nil {
+		if actual["body"], err = readTestBody(ds.Structure, body); err != nil {
+			return err
+		}
+	}
+
+	var diffs []string
+	keys := make([]string, 0, len(exp))
+	for key := range exp {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		diffs = append(diffs, matchExpected(key, exp[key], actual[key])...)
+	}
+	if len(diffs) > 0 {
+		return fmt.Errorf("dataset doesn't match expected:\n%s", strings.Join(diffs, "\n"))
+	}
+	return nil
+}
+
+// readTestBody decodes body entries into json-compatible values
+func readTestBody(st *dataset.Structure, body []byte) (interface{}, error) {
+	if st == nil {
+		return nil, fmt.Errorf("dataset has a body but no structure")
+	}
+	r, err := dsio.NewEntryReader(st, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var (
+		arr = []interface{}{}
+		obj map[string]interface{}
+	)
+	for {
+		ent, err := r.ReadEntry()
+		if err != nil {
+			if err.Error() == "EOF" {
+				break
+			}
+			return nil, err
+		}
+		if ent.Key != "" {
+			if obj == nil {
+				obj = map[string]interface{}{}
+			}
+			obj[ent.Key] = ent.Value
+		} else {
+			arr = append(arr, ent.Value)
+		}
+	}
+
+	var val interface{} = arr
+	if obj != nil {
+		val = obj
+	}
+	// round trip through json to normalize value types
+	data, err := json.Marshal(val)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &val)
+	return val, err
+}
+
+// matchExpected lists the differences between expected & actual values at
+// path. maps only compare keys present in expected
+func matchExpected(path string, expected, actual interface{}) (diffs []string) {
+	if exp, ok := expected.(map[string]interface{}); ok {
+		act, ok := actual.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected %s, got %s", path, jsonString(expected), jsonString(actual))}
+		}
+		keys := make([]string, 0, len(exp))
+		for key := range exp {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			diffs = append(diffs, matchExpected(path+"."+key, exp[key], act[key])...)
+		}
+		return diffs
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		return []string{fmt.Sprintf("%s: expected %s, got %s", path, jsonString(expected), jsonString(actual))}
+	}
+	return nil
+}
+
+func jsonString(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+// runTestFile executes a starlark test file, calling each function with a
+// "test_" prefix in name order with the dataset a transform produced
+func runTestFile(path string, script []byte, ds *dataset.Dataset, body []byte, out *bytes.Buffer) ([]TestCase, error) {
+	thread := &starlark.Thread{
+		Load: func(thread *starlark.Thread, module string) (starlark.StringDict, error) {
+			if module == assert.ModuleName {
+				return assert.LoadModule()
+			}
+			return DefaultModuleLoader(thread, module)
+		},
+		Print: func(thread *starlark.Thread, msg string) {
+			out.WriteString(msg + "\n")
+		},
+	}
+
+	globals, err := starlark.ExecFile(thread, path, script, nil)
+	if err != nil {
+		return nil, fmt.Errorf("loading test file: %w", err)
+	}
+
+	names := make([]string, 0, len(globals))
+	for name, val := range globals {
+		if _, ok := val.(*starlark.Function); ok && strings.HasPrefix(name, "test_") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	cases := make([]TestCase, 0, len(names))
+	for _, name := range names {
+		fn := globals[name].(*starlark.Function)
+		var args starlark.Tuple
+		if fn.NumParams() > 0 {
+			// give each test a fresh copy of the body
+			result := &dataset.Dataset{}
+			result.Assign(ds)
+			if body != nil {
+				result.SetBodyFile(qfs.NewMemfileBytes(ds.BodyFile().FileName(), body))
+			}
+			args = starlark.Tuple{skyds.NewDataset(result, nil).Methods()}
+		}
+
+		tc := TestCase{Name: name}
+		if _, err := starlark.Call(thread, fn, args, nil); err != nil {
+			tc.Error = err.Error()
+			if ee, ok := err.(*starlark.EvalError); ok {
+				tc.Error = ee.Backtrace()
+			}
+		}
+		cases = append(cases, tc)
+	}
+	return cases, nil
+}
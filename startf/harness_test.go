@@ -0,0 +1,135 @@
+package startf
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRunTest(t *testing.T) {
+	ctx := context.Background()
+
+	res, err := RunTest(ctx, "testdata/harness/numbers.star", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []TestCase{
+		{Name: "expected"},
+		{Name: "test_body"},
+		{Name: "test_meta"},
+	}
+	if diff := cmp.Diff(expect, res.Cases); diff != "" {
+		t.Errorf("cases mismatch (-want +got):\n%s\noutput:\n%s", diff, res.Output)
+	}
+
+	// failing expectations & assertions are reported per-case
+	dir, err := ioutil.TempDir("", "transform_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	copyFile(t, "testdata/harness/numbers.star", filepath.Join(dir, "numbers.star"))
+	copyFile(t, "testdata/harness/numbers.fixtures.json", filepath.Join(dir, "numbers.fixtures.json"))
+	writeFile(t, filepath.Join(dir, "numbers.expected.json"), `{"meta":{"title":"letters"},"body":[1,2]}`)
+	writeFile(t, filepath.Join(dir, "numbers_test.star"), `
+load("assert.star", "assert")
+
+def test_title(ds):
+  assert.eq(ds.get_meta()["title"], "letters")
+`)
+
+	res, err = RunTest(ctx, filepath.Join(dir, "numbers.star"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Failed() != 2 {
+		t.Fatalf("expected 2 failures, got: %#v", res.Cases)
+	}
+	expectErr := "dataset doesn't match expected:\nbody: expected [1,2], got [1,2,3]\nmeta.title: expected \"letters\", got \"numbers\""
+	if res.Cases[0].Error != expectErr {
+		t.Errorf("expected error mismatch.\nwant: %s\ngot:  %s", expectErr, res.Cases[0].Error)
+	}
+	if !strings.Contains(res.Cases[1].Error, `assert.eq: "numbers" != "letters"`) {
+		t.Errorf("expected assertion error, got: %s", res.Cases[1].Error)
+	}
+
+	// requests without a recorded response fail the transform
+	writeFile(t, filepath.Join(dir, "numbers.fixtures.json"), `{"datasets":{"me/more_numbers":{"body":[3]}}}`)
+	res, err = RunTest(ctx, filepath.Join(dir, "numbers.star"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Cases) != 1 || !strings.Contains(res.Cases[0].Error, "no recorded response for GET http://example.com/numbers.json") {
+		t.Errorf("expected missing fixture error, got: %#v", res.Cases)
+	}
+
+	if _, err := RunTest(ctx, "testdata/tf.star", false); err == nil {
+		t.Error("expected script without tests to error")
+	}
+}
+
+func TestRunTestRecord(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"numbers":[1,2]}`))
+	}))
+	defer s.Close()
+
+	dir, err := ioutil.TempDir("", "transform_test_record")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	script := filepath.Join(dir, "numbers.star")
+	writeFile(t, script, fmt.Sprintf(`
+load("http.star", "http")
+
+def download(ctx):
+  return http.get("%s/numbers.json").json()
+
+def transform(ds, ctx):
+  ds.set_body(ctx.download["numbers"])
+`, s.URL))
+	writeFile(t, filepath.Join(dir, "numbers.expected.json"), `{"body":[1,2]}`)
+
+	ctx := context.Background()
+	res, err := RunTest(ctx, script, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Failed() != 0 {
+		t.Fatalf("unexpected failures recording: %#v", res.Cases)
+	}
+
+	// recorded responses replay once the server is gone
+	s.Close()
+	res, err = RunTest(ctx, script, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Failed() != 0 {
+		t.Errorf("unexpected failures replaying: %#v", res.Cases)
+	}
+}
+
+func copyFile(t *testing.T, src, dst string) {
+	data, err := ioutil.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dst, string(data))
+}
+
+func writeFile(t *testing.T, path, data string) {
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
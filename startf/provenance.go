@@ -0,0 +1,214 @@
+package startf
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/qri-io/dataset"
+)
+
+// StarlarkVersion is the version of starlark transforms are run with
+var StarlarkVersion = moduleVersion("go.starlark.net")
+
+// moduleVersion looks up the version of a module dependency compiled into
+// this binary
+func moduleVersion(path string) string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path == path {
+				return dep.Version
+			}
+		}
+	}
+	return "unknown"
+}
+
+// Provenance records the inputs that contributed to a run of a transform
+type Provenance struct {
+	// version of qri the transform ran with
+	Qri string `json:"qri"`
+	// version of starlark the transform ran with
+	Starlark string `json:"starlark"`
+	// datasets loaded by the transform
+	Datasets []ProvenanceDataset `json:"datasets,omitempty"`
+	// urls fetched by the transform
+	URLs []ProvenanceURL `json:"urls,omitempty"`
+	// names of secrets the transform used. secret values are never recorded
+	Secrets []string `json:"secrets,omitempty"`
+}
+
+// ProvenanceDataset is a dataset version a transform loaded
+type ProvenanceDataset struct {
+	Ref  string `json:"ref"`
+	Path string `json:"path"`
+}
+
+// ProvenanceURL is a response to a request a transform made
+type ProvenanceURL struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Status int    `json:"status"`
+	// hex-encoded sha256 hash of the response body
+	SHA256 string `json:"sha256"`
+}
+
+// ReadProvenance reads the provenance recorded in a transform component, nil
+// if no provenance is recorded
+func ReadProvenance(tf *dataset.Transform) (*Provenance, error) {
+	if tf == nil || tf.Config[ProvenanceConfigKey] == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(tf.Config[ProvenanceConfigKey])
+	if err != nil {
+		return nil, err
+	}
+	p := &Provenance{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ResourceDatasets lists the datasets recorded in a transform's resources,
+// sorted by reference
+func ResourceDatasets(tf *dataset.Transform) []ProvenanceDataset {
+	if tf == nil || len(tf.Resources) == 0 {
+		return nil
+	}
+	dss := make([]ProvenanceDataset, 0, len(tf.Resources))
+	for _, res := range tf.Resources {
+		ds := ProvenanceDataset{Ref: res.Path}
+		if i := strings.Index(res.Path, "@"); i >= 0 {
+			ds.Ref, ds.Path = res.Path[:i], res.Path[i+1:]
+		}
+		dss = append(dss, ds)
+	}
+	sort.Slice(dss, func(i, j int) bool {
+		if dss[i].Ref == dss[j].Ref {
+			return dss[i].Path < dss[j].Path
+		}
+		return dss[i].Ref < dss[j].Ref
+	})
+	return dss
+}
+
+// redactedSecret replaces secret values in recorded urls
+const redactedSecret = "REDACTED"
+
+// provenanceRecorder collects urls a transform fetches
+type provenanceRecorder struct {
+	lk      sync.Mutex
+	urls    []ProvenanceURL
+	secrets []string
+}
+
+// newProvenanceRecorder creates a recorder that redacts the values of secrets
+// from recorded urls
+func newProvenanceRecorder(secrets map[string]interface{}) *provenanceRecorder {
+	p := &provenanceRecorder{}
+	for _, val := range secrets {
+		if str, ok := val.(string); ok && str != "" {
+			p.secrets = append(p.secrets, str)
+		}
+	}
+	// redact longer secrets first, so a secret that contains another is
+	// replaced whole
+	sort.Slice(p.secrets, func(i, j int) bool {
+		return len(p.secrets[i]) > len(p.secrets[j])
+	})
+	return p
+}
+
+// redactURL formats a url for the record, dropping credentials & replacing
+// the values of secrets passed to the run, which scripts often send as query
+// parameters
+func (p *provenanceRecorder) redactURL(u *url.URL) string {
+	cpy := *u
+	cpy.User = nil
+	str := cpy.String()
+	for _, secret := range p.secrets {
+		for _, s := range []string{secret, url.QueryEscape(secret), url.PathEscape(secret)} {
+			str = strings.ReplaceAll(str, s, redactedSecret)
+		}
+	}
+	return str
+}
+
+// roundTripper wraps rt, recording responses
+func (p *provenanceRecorder) roundTripper(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return provenanceRoundTripper{rt: rt, rec: p}
+}
+
+// provenance builds a provenance record for a transform run
+func (p *provenanceRecorder) provenance(tf *dataset.Transform, secrets []string) *Provenance {
+	p.lk.Lock()
+	defer p.lk.Unlock()
+	return &Provenance{
+		Qri:      Version,
+		Starlark: StarlarkVersion,
+		Datasets: ResourceDatasets(tf),
+		URLs:     p.urls,
+		Secrets:  secrets,
+	}
+}
+
+// provenanceRoundTripper records a hash of each response body
+type provenanceRoundTripper struct {
+	rt  http.RoundTripper
+	rec *provenanceRecorder
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (t provenanceRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.rt.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	sum := sha256.Sum256(body)
+	t.rec.lk.Lock()
+	t.rec.urls = append(t.rec.urls, ProvenanceURL{
+		Method: req.Method,
+		URL:    t.rec.redactURL(req.URL),
+		Status: res.StatusCode,
+		SHA256: hex.EncodeToString(sum[:]),
+	})
+	t.rec.lk.Unlock()
+	return res, nil
+}
+
+// saveProvenance writes a provenance record to the transform config
+func saveProvenance(tf *dataset.Transform, p *Provenance) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	// store as generic values, matching config read back from storage
+	var v map[string]interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if tf.Config == nil {
+		tf.Config = map[string]interface{}{}
+	}
+	tf.Config[ProvenanceConfigKey] = v
+	return nil
+}
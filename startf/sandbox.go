@@ -1,21 +1,45 @@
 package startf
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	starhttp "github.com/qri-io/starlib/http"
+	"go.starlark.net/starlark"
 )
 
 var (
 	httpGuard = &HTTPGuard{}
 	// ErrNtwkDisabled is returned whenever a network call is attempted but h.NetworkEnabled is false
 	ErrNtwkDisabled = fmt.Errorf("network use is disabled. http can only be used during download step")
+	// ErrHostNotAllowed is returned when a network call is made to a host that
+	// isn't in the list of allowed hosts
+	ErrHostNotAllowed = fmt.Errorf("host is not allowed")
+	// ErrStepLimit is returned when a script exceeds the maximum number of
+	// execution steps
+	ErrStepLimit = fmt.Errorf("transform exceeded the maximum number of execution steps")
+	// ErrTimeout is returned when a script runs longer than the execution timeout
+	ErrTimeout = fmt.Errorf("transform timed out")
+	// ErrBodyTooLarge is returned when a script sets a body larger than the
+	// maximum body size
+	ErrBodyTooLarge = fmt.Errorf("transform body exceeds the maximum body size")
+
+	// httpModuleLock guards swapping starlib http package settings while
+	// creating an http module
+	httpModuleLock sync.Mutex
 )
 
 // HTTPGuard protects network requests, only allowing when network is enabled
 type HTTPGuard struct {
 	NetworkEnabled bool
+	// AllowedHosts restricts requests to a list of hostnames. Entries that
+	// start with "*." match any subdomain. An empty list allows all hosts
+	AllowedHosts []string
 }
 
 // Allowed implements starlib/http RequestGuard
@@ -23,9 +47,27 @@ func (h *HTTPGuard) Allowed(req *http.Request) error {
 	if !h.NetworkEnabled {
 		return ErrNtwkDisabled
 	}
+	if len(h.AllowedHosts) > 0 && !h.hostAllowed(req.URL.Hostname()) {
+		return fmt.Errorf("%w: %s", ErrHostNotAllowed, req.URL.Hostname())
+	}
 	return nil
 }
 
+func (h *HTTPGuard) hostAllowed(host string) bool {
+	host = strings.ToLower(host)
+	for _, allowed := range h.AllowedHosts {
+		allowed = strings.ToLower(allowed)
+		if strings.HasPrefix(allowed, "*.") {
+			if strings.HasSuffix(host, allowed[1:]) {
+				return true
+			}
+		} else if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
 // EnableNtwk allows network calls
 func (h *HTTPGuard) EnableNtwk() {
 	h.NetworkEnabled = true
@@ -40,3 +82,100 @@ func init() {
 	// connect httpGuard instance to starlib http guard
 	starhttp.Guard = httpGuard
 }
+
+// newHTTPModule creates an http module that checks requests with guard,
+// including redirects. Requests are made with rt, falling back to the default
+// client transport if rt is nil, and are cancelled when ctx is done
+func newHTTPModule(ctx context.Context, guard *HTTPGuard, rt http.RoundTripper) (starlark.StringDict, error) {
+	httpModuleLock.Lock()
+	defer httpModuleLock.Unlock()
+
+	prevClient, prevGuard := starhttp.Client, starhttp.Guard
+	defer func() {
+		starhttp.Client, starhttp.Guard = prevClient, prevGuard
+	}()
+
+	if rt == nil {
+		rt = prevClient.Transport
+	}
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	starhttp.Client = &http.Client{
+		Transport: ctxRoundTripper{ctx: ctx, rt: rt},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return fmt.Errorf("stopped after 10 redirects")
+			}
+			return guard.Allowed(req)
+		},
+	}
+	starhttp.Guard = guard
+	return starhttp.LoadModule()
+}
+
+// ctxRoundTripper binds requests to a context
+type ctxRoundTripper struct {
+	ctx context.Context
+	rt  http.RoundTripper
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (c ctxRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return c.rt.RoundTrip(req.WithContext(c.ctx))
+}
+
+// sandbox enforces execution limits on a script's thread. Limits interrupt
+// the starlark interpreter, stopping scripts that loop without calling any
+// builtin function
+type sandbox struct {
+	ctx      context.Context
+	timeout  time.Duration
+	maxSteps uint64
+	thread   *starlark.Thread
+}
+
+// bind applies limits to a thread, cancelling the thread if the sandbox
+// context is done. The returned function stops watching the context and must
+// be called once the script finishes
+func (s *sandbox) bind(thread *starlark.Thread) (stop func()) {
+	s.thread = thread
+	if s.maxSteps > 0 {
+		thread.SetMaxExecutionSteps(s.maxSteps)
+	}
+	if s.ctx.Err() != nil {
+		thread.Cancel(s.ctx.Err().Error())
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-s.ctx.Done():
+			thread.Cancel(s.ctx.Err().Error())
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
+
+// err returns an error describing the limit a script exceeded, if any
+func (s *sandbox) err() error {
+	if s.maxSteps > 0 && s.thread != nil && s.thread.ExecutionSteps() >= s.maxSteps {
+		return fmt.Errorf("%w (%d)", ErrStepLimit, s.maxSteps)
+	}
+	if s.timeout > 0 && errors.Is(s.ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %s", ErrTimeout, s.timeout)
+	}
+	return nil
+}
+
+// isLimitErr checks if err was caused by a script exceeding a sandbox limit
+func isLimitErr(err error) bool {
+	for _, target := range []error{ErrStepLimit, ErrTimeout, ErrBodyTooLarge, ErrHostNotAllowed} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+// Package sql exposes qri's SQL engine to starlark transform scripts
+package sql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/repo"
+	qrisql "github.com/qri-io/qri/sql"
+	"github.com/qri-io/starlib/util"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// ModuleName defines the expected name for this module when used
+// in starlark's load() function, eg: load('sql.star', 'sql')
+const ModuleName = "sql.star"
+
+// NewModule creates a new sql module instance. Datasets referenced in queries
+// are loaded with loadDataset, giving queries the same access to datasets as
+// the load_dataset builtin. A nil loadDataset disables queries
+func NewModule(ctx context.Context, r repo.Repo, loadDataset dsref.ParseResolveLoad) *Module {
+	m := &Module{ctx: ctx}
+	if loadDataset != nil {
+		m.svc = qrisql.New(r, loadDataset)
+	}
+	return m
+}
+
+// Module encapsulates state for a sql starlark module
+type Module struct {
+	ctx context.Context
+	svc *qrisql.Service
+}
+
+// Namespace produces this module's exported namespace
+func (m *Module) Namespace() starlark.StringDict {
+	return starlark.StringDict{
+		"sql": m.Struct(),
+	}
+}
+
+// Struct returns this module's methods as a starlark Struct
+func (m *Module) Struct() *starlarkstruct.Struct {
+	return starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"exec": starlark.NewBuiltin("exec", m.Exec),
+	})
+}
+
+// Exec runs an SQL query, returning a list of rows. Each row is a dict keyed
+// by column name, in column order
+func (m *Module) Exec(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var query starlark.String
+	if err := starlark.UnpackArgs("exec", args, kwargs, "query", &query); err != nil {
+		return starlark.None, err
+	}
+	if m.svc == nil {
+		return starlark.None, fmt.Errorf("sql.exec requires load_dataset to be enabled")
+	}
+
+	columns, rows, err := m.svc.Query(m.ctx, query.GoString())
+	if err != nil {
+		return starlark.None, err
+	}
+
+	l := make([]starlark.Value, 0, len(rows))
+	for _, row := range rows {
+		d := starlark.NewDict(len(columns))
+		for i, col := range columns {
+			val, err := marshal(row[i])
+			if err != nil {
+				return starlark.None, err
+			}
+			if err := d.SetKey(starlark.String(col), val); err != nil {
+				return starlark.None, err
+			}
+		}
+		l = append(l, d)
+	}
+	return starlark.NewList(l), nil
+}
+
+// marshal converts a query result value to a starlark value. times & durations
+// have no starlark equivalent & are converted to strings
+func marshal(v interface{}) (starlark.Value, error) {
+	switch x := v.(type) {
+	case time.Time:
+		return starlark.String(x.Format(time.RFC3339)), nil
+	case time.Duration:
+		return starlark.String(x.String()), nil
+	case struct{}:
+		return starlark.None, nil
+	case []interface{}:
+		l := make([]starlark.Value, len(x))
+		for i, el := range x {
+			val, err := marshal(el)
+			if err != nil {
+				return nil, err
+			}
+			l[i] = val
+		}
+		return starlark.NewList(l), nil
+	}
+	return util.Marshal(v)
+}
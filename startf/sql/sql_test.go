@@ -0,0 +1,67 @@
+package sql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/dsref"
+	repotest "github.com/qri-io/qri/repo/test"
+	"github.com/qri-io/starlib/testdata"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarktest"
+)
+
+func TestExec(t *testing.T) {
+	ctx := context.Background()
+	r, err := repotest.NewTestRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := []string{}
+	loader := base.NewLocalDatasetLoader(r)
+	loadDataset := func(ctx context.Context, refstr string) (*dataset.Dataset, error) {
+		ref, err := dsref.Parse(refstr)
+		if err != nil {
+			return nil, err
+		}
+		source, err := r.ResolveRef(ctx, &ref)
+		if err != nil {
+			return nil, err
+		}
+		loaded = append(loaded, ref.Alias())
+		return loader.LoadDataset(ctx, ref, source)
+	}
+
+	m := NewModule(ctx, r, loadDataset)
+	assertLoader := testdata.NewLoader(nil, "")
+	thread := &starlark.Thread{Load: func(thread *starlark.Thread, module string) (starlark.StringDict, error) {
+		starlarktest.SetReporter(thread, t)
+		if module == ModuleName {
+			return m.Namespace(), nil
+		}
+		return assertLoader(thread, module)
+	}}
+
+	if _, err := starlark.ExecFile(thread, "testdata/test.star", nil, nil); err != nil {
+		if evalErr, ok := err.(*starlark.EvalError); ok {
+			t.Fatal(evalErr.Backtrace())
+		}
+		t.Fatal(err)
+	}
+	if len(loaded) == 0 || loaded[0] != "peer/movies" {
+		t.Errorf("expected queries to load datasets with the passed-in loader, loaded: %v", loaded)
+	}
+}
+
+func TestExecNoLoader(t *testing.T) {
+	m := NewModule(context.Background(), nil, nil)
+	exec := starlark.NewBuiltin("exec", m.Exec)
+	_, err := starlark.Call(&starlark.Thread{}, exec, starlark.Tuple{starlark.String("select * from peer/movies m")}, nil)
+	expect := "sql.exec requires load_dataset to be enabled"
+	if err == nil || err.Error() != expect {
+		t.Errorf("error mismatch. expected: %q, got: %v", expect, err)
+	}
+}
@@ -8,6 +8,9 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/qfs"
@@ -18,6 +21,7 @@ import (
 	skyqri "github.com/qri-io/qri/startf/qri"
 	"github.com/qri-io/qri/version"
 	"github.com/qri-io/starlib"
+	starhttp "github.com/qri-io/starlib/http"
 	"go.starlark.net/resolve"
 	"go.starlark.net/starlark"
 )
@@ -25,6 +29,24 @@ import (
 // Version is the version of qri that this transform was run with
 var Version = version.String
 
+const (
+	// ReservedConfigPrefix starts transform config keys qri writes when running
+	// a transform. Scripts can't read reserved keys with ctx.get_config, and
+	// reserved keys never collide with user config
+	ReservedConfigPrefix = "qri:"
+	// StateConfigKey is the transform config key scripts persist state to with
+	// ctx.set_state. state is read from the previous version's transform
+	StateConfigKey = ReservedConfigPrefix + "state"
+	// PrimaryKeyConfigKey is the transform config key that declares the column
+	// or columns that identify a body row. When set, the body a transform
+	// produces is merged into the previous version's body instead of replacing
+	// it. the value can be a string or a list of strings
+	PrimaryKeyConfigKey = "primary_key"
+	// ProvenanceConfigKey is the transform config key the inputs of a transform
+	// run are recorded to
+	ProvenanceConfigKey = ReservedConfigPrefix + "provenance"
+)
+
 // ExecOpts defines options for execution
 type ExecOpts struct {
 	// function to use for loading datasets
@@ -49,6 +71,16 @@ type ExecOpts struct {
 	ErrWriter io.Writer
 	// starlark module loader function
 	ModuleLoader ModuleLoader
+	// maximum number of steps a script may execute, 0 means no limit
+	MaxSteps uint64
+	// maximum duration a script may run for, 0 means no limit
+	Timeout time.Duration
+	// maximum size in bytes of a body set by the script, 0 means no limit
+	MaxBodySize int64
+	// hosts network modules may make requests to. empty allows any host
+	AllowedHosts []string
+	// transport network modules make requests with, nil uses the default
+	HTTPTransport http.RoundTripper
 }
 
 // AddDatasetLoader is required to enable the load_dataset starlark builtin
@@ -58,6 +90,17 @@ func AddDatasetLoader(prl dsref.ParseResolveLoad) func(o *ExecOpts) {
 	}
 }
 
+// AddModuleLoader sets the function ExecScript uses to resolve starlark
+// modules. Use this to make modules beyond the default set available to
+// scripts. The qri module is always available
+func AddModuleLoader(ml ModuleLoader) func(o *ExecOpts) {
+	return func(o *ExecOpts) {
+		if ml != nil {
+			o.ModuleLoader = ml
+		}
+	}
+}
+
 // AddQriRepo adds a qri repo to execution options, providing scripted access
 // to assets within the respoitory
 func AddQriRepo(repo repo.Repo) func(o *ExecOpts) {
@@ -98,6 +141,44 @@ func SetSecrets(secrets map[string]string) func(o *ExecOpts) {
 	}
 }
 
+// SetMaxSteps caps the number of steps a script can execute. Steps are
+// counted by the starlark interpreter, roughly one per bytecode instruction
+func SetMaxSteps(steps uint64) func(o *ExecOpts) {
+	return func(o *ExecOpts) {
+		o.MaxSteps = steps
+	}
+}
+
+// SetTimeout caps the time a script can run for
+func SetTimeout(d time.Duration) func(o *ExecOpts) {
+	return func(o *ExecOpts) {
+		o.Timeout = d
+	}
+}
+
+// SetMaxBodySize caps the size in bytes of a body a script can set
+func SetMaxBodySize(size int64) func(o *ExecOpts) {
+	return func(o *ExecOpts) {
+		o.MaxBodySize = size
+	}
+}
+
+// SetAllowedHosts restricts network requests to a list of hosts. Entries that
+// start with "*." allow any subdomain
+func SetAllowedHosts(hosts ...string) func(o *ExecOpts) {
+	return func(o *ExecOpts) {
+		o.AllowedHosts = hosts
+	}
+}
+
+// SetHTTPTransport sets the transport network modules make requests with,
+// which is useful for recording or mocking requests
+func SetHTTPTransport(rt http.RoundTripper) func(o *ExecOpts) {
+	return func(o *ExecOpts) {
+		o.HTTPTransport = rt
+	}
+}
+
 // DefaultExecOpts applies default options to an ExecOpts pointer
 func DefaultExecOpts(o *ExecOpts) {
 	o.AllowFloat = true
@@ -120,6 +201,11 @@ type transform struct {
 	bodyFile     qfs.File
 	stderr       io.Writer
 	moduleLoader ModuleLoader
+	sandbox      *sandbox
+	httpGuard    *HTTPGuard
+	httpRT       http.RoundTripper
+	provenance   *provenanceRecorder
+	maxBodySize  int64
 
 	download starlark.Iterable
 }
@@ -161,6 +247,12 @@ func ExecScript(ctx context.Context, next, prev *dataset.Dataset, opts ...func(o
 		starlark.Universe[key] = val
 	}
 
+	if o.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.Timeout)
+		defer cancel()
+	}
+
 	// set transform details
 	next.Transform.Syntax = "starlark"
 	next.Transform.SyntaxVersion = Version
@@ -182,9 +274,25 @@ func ExecScript(ctx context.Context, next, prev *dataset.Dataset, opts ...func(o
 		checkFunc:    o.MutateFieldCheck,
 		stderr:       o.ErrWriter,
 		moduleLoader: o.ModuleLoader,
+		sandbox: &sandbox{
+			ctx:      ctx,
+			timeout:  o.Timeout,
+			maxSteps: o.MaxSteps,
+		},
+		httpGuard:   &HTTPGuard{AllowedHosts: o.AllowedHosts},
+		httpRT:      o.HTTPTransport,
+		provenance:  newProvenanceRecorder(o.Secrets),
+		maxBodySize: o.MaxBodySize,
 	}
 
-	skyCtx := skyctx.NewContext(next.Transform.Config, o.Secrets)
+	skyCtx := skyctx.NewContext(userConfig(next.Transform.Config), o.Secrets)
+	if prev != nil && prev.Transform != nil {
+		if state, ok := prev.Transform.Config[StateConfigKey].(map[string]interface{}); ok {
+			if err = skyCtx.SetState(state); err != nil {
+				return err
+			}
+		}
+	}
 
 	thread := &starlark.Thread{
 		Load: t.ModuleLoader,
@@ -193,14 +301,13 @@ func ExecScript(ctx context.Context, next, prev *dataset.Dataset, opts ...func(o
 			_, _ = t.stderr.Write([]byte(msg))
 		},
 	}
+	stop := t.sandbox.bind(thread)
+	defer stop()
 
 	// execute the transformation
 	t.globals, err = starlark.ExecFile(thread, pipeScript.FileName(), pipeScript, t.locals())
 	if err != nil {
-		if evalErr, ok := err.(*starlark.EvalError); ok {
-			return fmt.Errorf(evalErr.Backtrace())
-		}
-		return err
+		return t.execErr(err)
 	}
 
 	funcs, err := t.specialFuncs()
@@ -212,23 +319,89 @@ func ExecScript(ctx context.Context, next, prev *dataset.Dataset, opts ...func(o
 		val, err := fn(t, thread, skyCtx)
 
 		if err != nil {
-			if evalErr, ok := err.(*starlark.EvalError); ok {
-				return fmt.Errorf(evalErr.Backtrace())
-			}
-			return err
+			return t.execErr(err)
 		}
 
 		skyCtx.SetResult(name, val)
 	}
 
-	err = callTransformFunc(t, thread, skyCtx)
-	if evalErr, ok := err.(*starlark.EvalError); ok {
-		return fmt.Errorf(evalErr.Backtrace())
-	}
+	err = t.execErr(callTransformFunc(t, thread, skyCtx))
 
 	// restore consumed script file
 	next.Transform.SetScriptFile(qfs.NewMemfileBytes("transform.star", buf.Bytes()))
 
+	if err != nil {
+		return err
+	}
+	if err = saveState(next.Transform, skyCtx); err != nil {
+		return err
+	}
+	return saveProvenance(next.Transform, t.provenance.provenance(next.Transform, skyCtx.SecretsUsed()))
+}
+
+// userConfig copies transform config without reserved keys
+func userConfig(config map[string]interface{}) map[string]interface{} {
+	if config == nil {
+		return nil
+	}
+	user := make(map[string]interface{}, len(config))
+	for key, val := range config {
+		if !strings.HasPrefix(key, ReservedConfigPrefix) {
+			user[key] = val
+		}
+	}
+	return user
+}
+
+// saveState writes context state to the transform config so the next run of
+// the transform can pick up where this one left off
+func saveState(tf *dataset.Transform, ctx *skyctx.Context) error {
+	state, err := ctx.State()
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		delete(tf.Config, StateConfigKey)
+		return nil
+	}
+	if tf.Config == nil {
+		tf.Config = map[string]interface{}{}
+	}
+	tf.Config[StateConfigKey] = state
+	return nil
+}
+
+// execErr converts starlark evaluation errors to errors with a backtrace,
+// writing errors caused by exceeding a sandbox limit to the script output
+func (t *transform) execErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	cause := err
+	evalErr, isEvalErr := err.(*starlark.EvalError)
+	if isEvalErr {
+		cause = evalErr.Unwrap()
+	}
+	// scripts cut short by a limit fail with a cancellation error, and work
+	// like network requests fails with context errors. report the limit
+	if !isLimitErr(cause) {
+		if limitErr := t.sandbox.err(); limitErr != nil {
+			cause = limitErr
+		}
+	}
+
+	if isEvalErr {
+		if cause != nil {
+			err = fmt.Errorf("%sError: %w", evalErr.CallStack, cause)
+		} else {
+			err = fmt.Errorf(evalErr.Backtrace())
+		}
+	} else {
+		err = cause
+	}
+	if isLimitErr(cause) {
+		t.print(fmt.Sprintf("🛑 %s\n", cause))
+	}
 	return err
 }
 
@@ -281,8 +454,8 @@ func (t *transform) specialFuncs() (defined map[string]specialFunc, err error) {
 type specialFunc func(t *transform, thread *starlark.Thread, ctx *skyctx.Context) (result starlark.Value, err error)
 
 func callDownloadFunc(t *transform, thread *starlark.Thread, ctx *skyctx.Context) (result starlark.Value, err error) {
-	httpGuard.EnableNtwk()
-	defer httpGuard.DisableNtwk()
+	t.httpGuard.EnableNtwk()
+	defer t.httpGuard.DisableNtwk()
 	t.print("📡 running download...\n")
 
 	var download *starlark.Function
@@ -311,6 +484,31 @@ func callTransformFunc(t *transform, thread *starlark.Thread, ctx *skyctx.Contex
 	if _, err = starlark.Call(thread, transform, starlark.Tuple{d.Methods(), ctx.Struct()}, nil); err != nil {
 		return err
 	}
+	if err = d.StreamErr(); err != nil {
+		return fmt.Errorf("reading body stream: %w", err)
+	}
+	if d.IsBodyModified() {
+		return t.checkBodySize()
+	}
+	return nil
+}
+
+// checkBodySize errors if the body the script set is larger than the maximum
+// body size
+func (t *transform) checkBodySize() error {
+	if t.maxBodySize <= 0 || t.next.BodyFile() == nil {
+		return nil
+	}
+	f := t.next.BodyFile()
+	data, err := ioutil.ReadAll(io.LimitReader(f, t.maxBodySize+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > t.maxBodySize {
+		return fmt.Errorf("%w (%d bytes)", ErrBodyTooLarge, t.maxBodySize)
+	}
+	// restore consumed body file
+	t.next.SetBodyFile(qfs.NewMemfileBytes(f.FileName(), data))
 	return nil
 }
 
@@ -330,6 +528,9 @@ func (t *transform) ModuleLoader(thread *starlark.Thread, module string) (dict s
 	if module == skyqri.ModuleName && t.skyqri != nil {
 		return t.skyqri.Namespace(), nil
 	}
+	if module == starhttp.ModuleName {
+		return newHTTPModule(t.ctx, t.httpGuard, t.provenance.roundTripper(t.httpRT))
+	}
 
 	if t.moduleLoader == nil {
 		return nil, fmt.Errorf("couldn't load module: %s", module)
@@ -354,19 +555,24 @@ func (t *transform) LoadDataset(thread *starlark.Thread, _ *starlark.Builtin, ar
 		return starlark.None, err
 	}
 
-	if t.next.Transform.Resources == nil {
-		t.next.Transform.Resources = map[string]*dataset.TransformResource{}
+	AddResource(t.next.Transform, ds)
+	return skyds.NewDataset(ds, nil).Methods(), nil
+}
+
+// AddResource records a dataset loaded during transform execution in the
+// transform's resources
+func AddResource(tf *dataset.Transform, ds *dataset.Dataset) {
+	if tf.Resources == nil {
+		tf.Resources = map[string]*dataset.TransformResource{}
 	}
 
-	t.next.Transform.Resources[ds.Path] = &dataset.TransformResource{
+	tf.Resources[ds.Path] = &dataset.TransformResource{
 		// TODO(b5) - this should be a method on dataset.Dataset
 		// we should add an ID field to dataset, set that to the InitID, and
 		// add fields to dataset.TransformResource that effectively make it the
 		// same data structure as dsref.Ref
 		Path: fmt.Sprintf("%s/%s@%s", ds.Peername, ds.Name, ds.Path),
 	}
-
-	return skyds.NewDataset(ds, nil).Methods(), nil
 }
 
 // MutatedComponentsFunc returns a function for checking if a field has been
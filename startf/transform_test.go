@@ -3,11 +3,14 @@ package startf
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/qri-io/dataset"
@@ -38,6 +41,7 @@ func TestOpts(t *testing.T) {
 	SetSecrets(map[string]string{"a": "b"})(o)
 	SetErrWriter(nil)(o)
 	AddQriRepo(nil)(o)
+	AddModuleLoader(nil)(o)
 	AddMutateFieldCheck(nil)(o)
 
 	expect := &ExecOpts{
@@ -120,6 +124,87 @@ func TestExecScript2(t *testing.T) {
 	}
 }
 
+func TestSandboxLimits(t *testing.T) {
+	ctx := context.Background()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"foo":["bar","baz","bat"]}`))
+	}))
+	defer s.Close()
+
+	loop := `
+def transform(ds, ctx):
+	total = 0
+	for i in range(100):
+		total += i
+	ds.set_body([total])
+`
+	// a loop that never calls a builtin function
+	spin := `
+def transform(ds, ctx):
+	rows = [0] * 1000
+	for a in rows:
+		for b in rows:
+			for c in rows:
+				pass
+`
+	cases := []struct {
+		description string
+		script      string
+		opt         func(o *ExecOpts)
+		err         error
+	}{
+		{"under step limit", loop, SetMaxSteps(10000), nil},
+		{"step limit", loop, SetMaxSteps(50), ErrStepLimit},
+		{"timeout", loop, SetTimeout(time.Nanosecond), ErrTimeout},
+		{"spin step limit", spin, SetMaxSteps(10000), ErrStepLimit},
+		{"spin timeout", spin, SetTimeout(50 * time.Millisecond), ErrTimeout},
+		{"under max body size", loop, SetMaxBodySize(100), nil},
+		{"max body size", loop, SetMaxBodySize(2), ErrBodyTooLarge},
+		{"allowed host", "testdata/fetch.star", SetAllowedHosts("127.0.0.1"), nil},
+		{"disallowed host", "testdata/fetch.star", SetAllowedHosts("*.example.com"), ErrHostNotAllowed},
+	}
+
+	for _, c := range cases {
+		ds := &dataset.Dataset{
+			Transform: &dataset.Transform{},
+		}
+		if strings.HasSuffix(c.script, ".star") {
+			ds.Transform.SetScriptFile(scriptFile(t, c.script))
+		} else {
+			ds.Transform.SetScriptFile(qfs.NewMemfileBytes("tf.star", []byte(c.script)))
+		}
+
+		stderr := &bytes.Buffer{}
+		err := ExecScript(ctx, ds, nil, c.opt, SetErrWriter(stderr), func(o *ExecOpts) {
+			o.Globals["test_server_url"] = starlark.String(s.URL)
+		})
+		if !errors.Is(err, c.err) {
+			t.Errorf("%s: error mismatch. expected: %v, got: %v", c.description, c.err, err)
+			continue
+		}
+		if c.err != nil && !strings.Contains(stderr.String(), "🛑 "+c.err.Error()) {
+			t.Errorf("%s: expected limit error in script output, got: %q", c.description, stderr.String())
+		}
+	}
+}
+
+func TestHTTPGuardAllowedHosts(t *testing.T) {
+	g := &HTTPGuard{NetworkEnabled: true, AllowedHosts: []string{"qri.io", "*.example.com"}}
+	cases := map[string]error{
+		"https://qri.io/data":         nil,
+		"https://QRI.io/data":         nil,
+		"https://api.example.com/x":   nil,
+		"https://api.qri.io/data":     ErrHostNotAllowed,
+		"https://example.com.evil.io": ErrHostNotAllowed,
+	}
+	for u, expect := range cases {
+		req := httptest.NewRequest("GET", u, nil)
+		if err := g.Allowed(req); !errors.Is(err, expect) {
+			t.Errorf("%s: error mismatch. expected: %v, got: %v", u, expect, err)
+		}
+	}
+}
+
 func TestScriptError(t *testing.T) {
 	ctx := context.Background()
 	script := `
@@ -156,6 +241,63 @@ func TestLoadDataset(t *testing.T) {
 	}
 }
 
+func TestProvenance(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+	defer s.Close()
+
+	prevVersion, prevStarlarkVersion := Version, StarlarkVersion
+	Version, StarlarkVersion = "test_version", "test_starlark_version"
+	defer func() { Version, StarlarkVersion = prevVersion, prevStarlarkVersion }()
+
+	ctx := context.Background()
+	ds := &dataset.Dataset{
+		Transform: &dataset.Transform{
+			Config: map[string]interface{}{"url": s.URL, "provenance": "user value"},
+		},
+	}
+	ds.Transform.SetScriptFile(scriptFile(t, "testdata/provenance.star"))
+
+	loader := func(ctx context.Context, refstr string) (*dataset.Dataset, error) {
+		return &dataset.Dataset{Peername: "peer", Name: "other", Path: "/mem/QmOther"}, nil
+	}
+	err := ExecScript(ctx, ds, nil,
+		AddDatasetLoader(loader),
+		SetSecrets(map[string]string{"api_key": "secret_value", "unused": "value"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadProvenance(ds.Transform)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := &Provenance{
+		Qri:      "test_version",
+		Starlark: "test_starlark_version",
+		Datasets: []ProvenanceDataset{{Ref: "peer/other", Path: "/mem/QmOther"}},
+		URLs: []ProvenanceURL{{
+			Method: "GET",
+			URL:    s.URL + "?key=REDACTED&page=2",
+			Status: 200,
+			// sha256 of "hello"
+			SHA256: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		}},
+		Secrets: []string{"api_key"},
+	}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("provenance mismatch (-want +got):\n%s", diff)
+	}
+	if strings.Contains(fmt.Sprintf("%v", ds.Transform.Config), "secret_value") {
+		t.Error("secret values must not be recorded")
+	}
+	if got := ds.Transform.Config["provenance"]; got != "user value" {
+		t.Errorf("expected recording provenance to keep user config. got: %v", got)
+	}
+}
+
 // TODO(b5) - we should think about moving this somewhere more general
 type repoLoader struct {
 	r repo.Repo
@@ -252,6 +394,70 @@ func TestGetMetaWithPrev(t *testing.T) {
 	}
 }
 
+func TestTransformState(t *testing.T) {
+	ctx := context.Background()
+	ds := &dataset.Dataset{
+		Transform: &dataset.Transform{},
+	}
+	ds.Transform.SetScriptFile(scriptFile(t, "testdata/state.star"))
+	if err := ExecScript(ctx, ds, nil); err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]interface{}{"cursor": 1, "seen": 0}
+	if diff := cmp.Diff(expect, ds.Transform.Config[StateConfigKey]); diff != "" {
+		t.Errorf("first run state mismatch (-want +got):\n%s", diff)
+	}
+
+	// the next run picks up state from the previous version
+	prev := ds
+	ds = &dataset.Dataset{
+		Transform: &dataset.Transform{},
+	}
+	ds.Transform.SetScriptFile(scriptFile(t, "testdata/state.star"))
+	if err := ExecScript(ctx, ds, prev); err != nil {
+		t.Fatal(err)
+	}
+	expect = map[string]interface{}{"cursor": 2, "seen": 1}
+	if diff := cmp.Diff(expect, ds.Transform.Config[StateConfigKey]); diff != "" {
+		t.Errorf("second run state mismatch (-want +got):\n%s", diff)
+	}
+	data, _ := ioutil.ReadAll(ds.BodyFile())
+	if string(data) != `[[2,"row 2"]]` {
+		t.Errorf("body mismatch. got: %s", data)
+	}
+}
+
+func TestTransformStateReservedConfig(t *testing.T) {
+	ctx := context.Background()
+	prev := &dataset.Dataset{
+		Transform: &dataset.Transform{
+			Config: map[string]interface{}{
+				"state":        map[string]interface{}{"cursor": 5},
+				StateConfigKey: map[string]interface{}{"cursor": 1},
+			},
+		},
+	}
+	ds := &dataset.Dataset{
+		Transform: &dataset.Transform{
+			Config: map[string]interface{}{"state": "user value"},
+		},
+	}
+	ds.Transform.SetScriptFile(scriptFile(t, "testdata/reserved_config.star"))
+	if err := ExecScript(ctx, ds, prev); err != nil {
+		t.Fatal(err)
+	}
+
+	// scripts read user config, and can't read state through config
+	data, _ := ioutil.ReadAll(ds.BodyFile())
+	if string(data) != `["user value",null,1]` {
+		t.Errorf("body mismatch. got: %s", data)
+	}
+	// saving state keeps user config
+	if got := ds.Transform.Config["state"]; got != "user value" {
+		t.Errorf("expected user config to be kept. got: %v", got)
+	}
+}
+
 func TestMutatedComponentsFunc(t *testing.T) {
 	ds := &dataset.Dataset{
 		Commit:    &dataset.Commit{},
@@ -0,0 +1,390 @@
+// Package upload stores files that arrive in chunks, so large files can be
+// sent over unreliable connections & resumed when a connection drops. Each
+// upload session is a directory holding a JSON description of the session &
+// the bytes received so far
+package upload
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	golog "github.com/ipfs/go-log"
+)
+
+var log = golog.Logger("upload")
+
+// DirName is the name of the upload directory within a repo directory
+const DirName = "uploads"
+
+// DefaultMaxAge is how long an upload can go without receiving a chunk
+// before it's considered abandoned & removed by a sweep
+const DefaultMaxAge = 24 * time.Hour
+
+// DefaultMaxSize is the largest upload in bytes a store accepts when no
+// maximum is given
+const DefaultMaxSize int64 = 10 << 30
+
+const (
+	sessionFilename = "session.json"
+	dataFilename    = "data"
+)
+
+var (
+	// ErrNotFound indicates an upload session doesn't exist
+	ErrNotFound = errors.New("upload not found")
+	// ErrOffsetMismatch indicates a chunk doesn't start where the last chunk
+	// ended. Clients should check the session offset & resume from there
+	ErrOffsetMismatch = errors.New("chunk offset doesn't match upload offset")
+	// ErrTooLarge indicates a chunk would write past the declared upload size
+	ErrTooLarge = errors.New("chunk exceeds upload size")
+	// ErrUploadTooLarge indicates an upload declares a size larger than the
+	// store accepts
+	ErrUploadTooLarge = errors.New("upload exceeds the maximum upload size")
+	// ErrChecksumMismatch indicates received bytes don't match a checksum
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	// ErrIncomplete indicates an upload hasn't received all of its bytes
+	ErrIncomplete = errors.New("upload is incomplete")
+)
+
+// Session describes an upload in progress
+type Session struct {
+	// ID identifies the upload
+	ID string `json:"id"`
+	// Filename is the name of the file being uploaded. The extension of the
+	// filename is kept, so formats can be detected from it
+	Filename string `json:"filename"`
+	// Size is the total number of bytes the upload will contain
+	Size int64 `json:"size"`
+	// SHA256 is the hex-encoded checksum of the complete file. optional
+	SHA256 string `json:"sha256,omitempty"`
+	// Offset is the number of bytes received
+	Offset int64 `json:"offset"`
+	// Created is when the upload was started
+	Created time.Time `json:"created"`
+	// Updated is when the last chunk was received
+	Updated time.Time `json:"updated"`
+}
+
+// Complete is true when all bytes of the upload have been received
+func (s Session) Complete() bool {
+	return s.Offset == s.Size
+}
+
+// Store keeps upload sessions in a directory
+type Store struct {
+	dir     string
+	maxSize int64
+
+	lk    sync.Mutex
+	locks map[string]*sessionLock
+}
+
+// sessionLock is a mutex shared by the callers working on a session. locks
+// are dropped from the store once no caller holds them, so the store only
+// keeps locks for sessions in use
+type sessionLock struct {
+	sync.Mutex
+	refs int
+}
+
+// NewStore creates a store in dir, creating the directory if it doesn't
+// exist. Sessions stored in dir by a previous store can be resumed. maxSize
+// is the largest upload in bytes the store accepts, 0 uses DefaultMaxSize
+func NewStore(dir string, maxSize int64) (*Store, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("creating upload directory: %w", err)
+	}
+	return &Store{dir: dir, maxSize: maxSize, locks: map[string]*sessionLock{}}, nil
+}
+
+// Create starts a new upload session for a file of size bytes. sha256 is the
+// hex-encoded checksum of the complete file, or empty to skip verification
+func (st *Store) Create(filename string, size int64, sha256 string) (*Session, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("upload size cannot be negative")
+	}
+	if size > st.maxSize {
+		return nil, fmt.Errorf("%w: %d bytes is larger than %d", ErrUploadTooLarge, size, st.maxSize)
+	}
+	if sha256 != "" {
+		if b, err := hex.DecodeString(sha256); err != nil || len(b) != 32 {
+			return nil, fmt.Errorf("invalid sha256 checksum %q", sha256)
+		}
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	s := &Session{
+		ID:       id,
+		Filename: filepath.Base(filename),
+		Size:     size,
+		SHA256:   strings.ToLower(sha256),
+		Created:  now,
+		Updated:  now,
+	}
+
+	if err := os.Mkdir(filepath.Join(st.dir, id), os.ModePerm); err != nil {
+		return nil, err
+	}
+	f, err := os.Create(st.dataPath(s))
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	if err := st.writeSession(s); err != nil {
+		return nil, err
+	}
+	log.Debugf("created upload %s size=%d", id, size)
+	return s, nil
+}
+
+// Get reads an upload session
+func (st *Store) Get(id string) (*Session, error) {
+	if !validID(id) {
+		return nil, ErrNotFound
+	}
+	data, err := ioutil.ReadFile(filepath.Join(st.dir, id, sessionFilename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	s := &Session{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("reading upload %s: %w", id, err)
+	}
+	return s, nil
+}
+
+// WriteChunk appends the bytes of r to an upload. offset must equal the
+// number of bytes already received. checksum is the hex-encoded sha256 of the
+// chunk, or empty to skip verification. Chunks that fail are discarded,
+// leaving the session as it was
+func (st *Store) WriteChunk(id string, offset int64, r io.Reader, checksum string) (*Session, error) {
+	unlock := st.lockSession(id)
+	defer unlock()
+
+	s, err := st.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if offset != s.Offset {
+		return s, ErrOffsetMismatch
+	}
+	// sessions created before the maximum was lowered don't get more bytes
+	if s.Size > st.maxSize {
+		return s, fmt.Errorf("%w: %d bytes is larger than %d", ErrUploadTooLarge, s.Size, st.maxSize)
+	}
+
+	f, err := os.OpenFile(st.dataPath(s), os.O_WRONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	h := sha256.New()
+	// read one byte past the remaining size to detect oversized chunks
+	n, err := io.Copy(io.MultiWriter(f, h), io.LimitReader(r, s.Size-offset+1))
+	if err == nil && offset+n > s.Size {
+		err = ErrTooLarge
+	}
+	if err == nil && checksum != "" && !strings.EqualFold(hex.EncodeToString(h.Sum(nil)), checksum) {
+		err = ErrChecksumMismatch
+	}
+	if err != nil {
+		if terr := f.Truncate(offset); terr != nil {
+			log.Errorf("discarding failed chunk of upload %s: %s", id, terr)
+		}
+		return s, err
+	}
+
+	s.Offset += n
+	s.Updated = time.Now().UTC()
+	if err := st.writeSession(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Finalize checks an upload is complete & matches its checksum, returning the
+// path to the uploaded file. The file remains in the store until the session
+// is removed
+func (st *Store) Finalize(id string) (string, error) {
+	unlock := st.lockSession(id)
+	defer unlock()
+
+	s, err := st.Get(id)
+	if err != nil {
+		return "", err
+	}
+	if !s.Complete() {
+		return "", fmt.Errorf("%w: received %d of %d bytes", ErrIncomplete, s.Offset, s.Size)
+	}
+
+	path := st.dataPath(s)
+	if s.SHA256 != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return "", err
+		}
+		if hex.EncodeToString(h.Sum(nil)) != s.SHA256 {
+			return "", ErrChecksumMismatch
+		}
+	}
+	return path, nil
+}
+
+// Remove deletes an upload session & any bytes it has received
+func (st *Store) Remove(id string) error {
+	unlock := st.lockSession(id)
+	defer unlock()
+
+	if _, err := st.Get(id); err != nil {
+		return err
+	}
+	return os.RemoveAll(filepath.Join(st.dir, id))
+}
+
+// RemoveExpired deletes sessions that haven't received a chunk in maxAge,
+// returning the number of sessions removed
+func (st *Store) RemoveExpired(maxAge time.Duration) (int, error) {
+	infos, err := ioutil.ReadDir(st.dir)
+	if err != nil {
+		return 0, err
+	}
+	cutoff := time.Now().Add(-maxAge)
+	removed := 0
+	for _, fi := range infos {
+		if !fi.IsDir() || !validID(fi.Name()) {
+			continue
+		}
+		ok, err := st.removeIfExpired(fi.Name(), cutoff)
+		if err != nil {
+			return removed, err
+		}
+		if ok {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func (st *Store) removeIfExpired(id string, cutoff time.Time) (bool, error) {
+	unlock := st.lockSession(id)
+	defer unlock()
+
+	s, err := st.Get(id)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	} else if err != nil {
+		// sessions that can't be read can't be resumed either
+		log.Debugf("removing unreadable upload %s: %s", id, err)
+	} else if !s.Updated.Before(cutoff) {
+		return false, nil
+	}
+	log.Debugf("removing expired upload %s", id)
+	return true, os.RemoveAll(filepath.Join(st.dir, id))
+}
+
+// Sweep removes expired sessions every interval until ctx is cancelled
+func (st *Store) Sweep(ctx context.Context, interval, maxAge time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		if _, err := st.RemoveExpired(maxAge); err != nil {
+			log.Errorf("removing expired uploads: %s", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// dataPath is the location of the uploaded bytes. the filename extension is
+// kept so readers can detect the file format
+func (st *Store) dataPath(s *Session) string {
+	return filepath.Join(st.dir, s.ID, dataFilename+filepath.Ext(s.Filename))
+}
+
+func (st *Store) writeSession(s *Session) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	// write to a temp file & rename so a crash can't leave a partial session
+	path := filepath.Join(st.dir, s.ID, sessionFilename)
+	if err := ioutil.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// lockSession serializes writes to a single session
+func (st *Store) lockSession(id string) (unlock func()) {
+	st.lk.Lock()
+	lk, ok := st.locks[id]
+	if !ok {
+		lk = &sessionLock{}
+		st.locks[id] = lk
+	}
+	lk.refs++
+	st.lk.Unlock()
+
+	lk.Lock()
+	return func() {
+		lk.Unlock()
+		st.lk.Lock()
+		if lk.refs--; lk.refs == 0 {
+			delete(st.locks, id)
+		}
+		st.lk.Unlock()
+	}
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// validID guards against ids that could address paths outside the store
+func validID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
@@ -0,0 +1,202 @@
+package upload
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "upload_store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	st, err := NewStore(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := "city,pop\ntoronto,40000000\nnew york,8500000\n"
+	sum := sha256.Sum256([]byte(content))
+	s, err := st.Create("cities.csv", int64(len(content)), hex.EncodeToString(sum[:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := st.Finalize(s.ID); !errors.Is(err, ErrIncomplete) {
+		t.Errorf("expected finalizing an empty upload to return ErrIncomplete, got: %v", err)
+	}
+
+	first, rest := content[:10], content[10:]
+	if s, err = st.WriteChunk(s.ID, 0, strings.NewReader(first), ""); err != nil {
+		t.Fatal(err)
+	}
+	if s.Offset != 10 {
+		t.Errorf("expected offset 10, got %d", s.Offset)
+	}
+
+	// a repeated chunk doesn't start at the current offset
+	if _, err := st.WriteChunk(s.ID, 0, strings.NewReader(first), ""); err != ErrOffsetMismatch {
+		t.Errorf("expected ErrOffsetMismatch, got: %v", err)
+	}
+	// chunks that fail their checksum are discarded
+	if _, err := st.WriteChunk(s.ID, 10, strings.NewReader(rest), "00"); err != ErrChecksumMismatch {
+		t.Errorf("expected ErrChecksumMismatch, got: %v", err)
+	}
+	if _, err := st.WriteChunk(s.ID, 10, strings.NewReader(rest+"extra"), ""); err != ErrTooLarge {
+		t.Errorf("expected ErrTooLarge, got: %v", err)
+	}
+
+	// sessions can be resumed by a new store
+	if st, err = NewStore(dir, 0); err != nil {
+		t.Fatal(err)
+	}
+	if s, err = st.Get(s.ID); err != nil {
+		t.Fatal(err)
+	}
+	if s.Offset != 10 {
+		t.Errorf("expected failed chunks to leave the offset at 10, got %d", s.Offset)
+	}
+
+	chunkSum := sha256.Sum256([]byte(rest))
+	if s, err = st.WriteChunk(s.ID, s.Offset, strings.NewReader(rest), hex.EncodeToString(chunkSum[:])); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Complete() {
+		t.Errorf("expected upload to be complete. got offset %d of %d", s.Offset, s.Size)
+	}
+
+	path, err := st.Finalize(s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Ext(path) != ".csv" {
+		t.Errorf("expected upload path to keep the .csv extension, got: %s", path)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != content {
+		t.Errorf("upload content mismatch. got: %q", data)
+	}
+
+	if err := st.Remove(s.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.Get(s.ID); err != ErrNotFound {
+		t.Errorf("expected removed upload to be ErrNotFound, got: %v", err)
+	}
+	if _, err := st.Get("../../etc"); err != ErrNotFound {
+		t.Errorf("expected invalid id to be ErrNotFound, got: %v", err)
+	}
+}
+
+func TestMaxSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "upload_max_size")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	st, err := NewStore(dir, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := st.Create("big.csv", 11, ""); !errors.Is(err, ErrUploadTooLarge) {
+		t.Errorf("expected ErrUploadTooLarge, got: %v", err)
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected a rejected upload not to create a session, got %d entries", len(entries))
+	}
+
+	s, err := st.Create("ok.csv", 10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a store with a lower maximum doesn't accept chunks for existing sessions
+	if st, err = NewStore(dir, 5); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.WriteChunk(s.ID, 0, strings.NewReader("city"), ""); !errors.Is(err, ErrUploadTooLarge) {
+		t.Errorf("expected ErrUploadTooLarge, got: %v", err)
+	}
+}
+
+func TestFinalizeChecksumMismatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "upload_finalize_checksum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	st, err := NewStore(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte("expected"))
+	s, err := st.Create("body.json", 6, hex.EncodeToString(sum[:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.WriteChunk(s.ID, 0, strings.NewReader("actual"), ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.Finalize(s.ID); err != ErrChecksumMismatch {
+		t.Errorf("expected ErrChecksumMismatch, got: %v", err)
+	}
+}
+
+func TestRemoveExpired(t *testing.T) {
+	dir, err := ioutil.TempDir("", "upload_remove_expired")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	st, err := NewStore(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale, err := st.Create("stale.csv", 10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.Updated = stale.Updated.Add(-2 * DefaultMaxAge)
+	if err := st.writeSession(stale); err != nil {
+		t.Fatal(err)
+	}
+	fresh, err := st.Create("fresh.csv", 10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.WriteChunk(fresh.ID, 0, strings.NewReader("city"), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := st.RemoveExpired(DefaultMaxAge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 {
+		t.Errorf("expected 1 expired upload to be removed, got %d", removed)
+	}
+	if _, err := st.Get(stale.ID); err != ErrNotFound {
+		t.Errorf("expected expired upload to be ErrNotFound, got: %v", err)
+	}
+	if _, err := st.Get(fresh.ID); err != nil {
+		t.Errorf("expected fresh upload to remain, got: %v", err)
+	}
+	if len(st.locks) != 0 {
+		t.Errorf("expected session locks to be released, got %d", len(st.locks))
+	}
+}
@@ -54,7 +54,10 @@ func NewFilesysWatcher(ctx context.Context, bus event.Bus) (*FilesysWatcher, err
 		bus:     bus,
 	}
 
-	bus.Subscribe(w.eventHandler,
+	// adding a watch touches the filesystem, handle link events off the
+	// publisher's goroutine
+	bus.SubscribeAsync(w.eventHandler,
+		event.AsyncOptions{},
 		event.ETFSICreateLinkEvent,
 	)
 
@@ -89,16 +92,14 @@ func NewFilesysWatcher(ctx context.Context, bus event.Bus) (*FilesysWatcher, err
 func (w *FilesysWatcher) eventHandler(ctx context.Context, t event.Type, payload interface{}) error {
 	switch t {
 	case event.ETFSICreateLinkEvent:
-		go func() {
-			if fce, ok := payload.(event.FSICreateLinkEvent); ok {
-				log.Debugf("received link event. adding watcher for path: %s", fce.FSIPath)
-				w.Watch(EventPath{
-					Path:     fce.FSIPath,
-					Username: fce.Username,
-					Dsname:   fce.Dsname,
-				})
-			}
-		}()
+		if fce, ok := payload.(event.FSICreateLinkEvent); ok {
+			log.Debugf("received link event. adding watcher for path: %s", fce.FSIPath)
+			w.Watch(EventPath{
+				Path:     fce.FSIPath,
+				Username: fce.Username,
+				Dsname:   fce.Dsname,
+			})
+		}
 	}
 
 	return nil
@@ -0,0 +1,503 @@
+// Package webhook delivers event bus events to HTTP endpoints. A Dispatcher
+// subscribes to the bus and POSTs a signed JSON payload to each configured
+// webhook that matches an event, retrying failed deliveries with exponential
+// backoff and recording the outcome of every delivery in a log
+package webhook
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	golog "github.com/ipfs/go-log"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/event"
+)
+
+var log = golog.Logger("webhook")
+
+const (
+	// ETTest is the event type of payloads sent by Dispatcher.Test
+	ETTest = event.Type("webhook:Test")
+
+	// SignatureHeader carries the HMAC-SHA256 signature of the request body,
+	// in the form "sha256=<hex digest>". only set when a webhook has a secret
+	SignatureHeader = "X-Qri-Signature"
+	// EventHeader carries the event type of the payload
+	EventHeader = "X-Qri-Event"
+	// DeliveryHeader carries the unique identifier of the payload. retried
+	// deliveries reuse the same identifier
+	DeliveryHeader = "X-Qri-Delivery"
+)
+
+var (
+	// DefaultEvents is the set of events delivered to webhooks that don't
+	// specify a list of events
+	DefaultEvents = []event.Type{
+		event.ETDatasetNameInit,
+		event.ETDatasetCommitChange,
+		event.ETDatasetDeleteAll,
+		event.ETDatasetRename,
+		event.ETRemoteClientPushDatasetCompleted,
+		event.ETRemoteClientPullDatasetCompleted,
+		event.ETRemoteClientRemoveDatasetCompleted,
+	}
+	// MaxAttempts is the number of times a delivery is tried before giving up
+	MaxAttempts = 5
+	// Backoff is the time to wait before the first retry. the wait doubles
+	// with each subsequent retry
+	Backoff = time.Second
+	// Timeout bounds each delivery request
+	Timeout = 10 * time.Second
+	// MaxLogDeliveries is the number of deliveries the delivery log keeps.
+	// Log files are trimmed back to this many deliveries once they grow past
+	// twice as many
+	MaxLogDeliveries = 1000
+)
+
+// Payload is the JSON body POSTed to webhooks
+type Payload struct {
+	ID        string      `json:"id"`
+	Type      event.Type  `json:"type"`
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+// Delivery records the outcome of sending a payload to a webhook
+type Delivery struct {
+	ID         string     `json:"id"`
+	URL        string     `json:"url"`
+	Type       event.Type `json:"type"`
+	Timestamp  time.Time  `json:"timestamp"`
+	Attempts   int        `json:"attempts"`
+	StatusCode int        `json:"statusCode,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// Succeeded returns true if the webhook accepted the delivery
+func (d Delivery) Succeeded() bool {
+	return d.Error == ""
+}
+
+// Sign creates a signature for a payload body using a webhook secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature created by Sign, for use by webhook receivers
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// Dispatcher delivers events to webhooks
+type Dispatcher struct {
+	ctx     context.Context
+	bus     event.Bus
+	client  *http.Client
+	logPath string
+
+	lk    sync.Mutex
+	hooks config.Webhooks
+	sub   event.Subscription
+	// closed is set once the dispatcher's context is cancelled, after which
+	// no deliveries are started
+	closed bool
+
+	logLk      sync.Mutex
+	deliveries []Delivery
+	// logLines counts deliveries in the log file, -1 until the file is read
+	logLines int
+
+	wg     sync.WaitGroup
+	doneCh chan struct{}
+}
+
+// NewDispatcher creates a dispatcher that delivers events published on bus to
+// hooks. Deliveries are appended to the file at logPath as lines of JSON. if
+// logPath is empty, the most recent deliveries are kept in memory. The
+// dispatcher stops delivering when ctx is cancelled
+func NewDispatcher(ctx context.Context, bus event.Bus, hooks config.Webhooks, logPath string) *Dispatcher {
+	d := &Dispatcher{
+		ctx:      ctx,
+		bus:      bus,
+		client:   &http.Client{Timeout: Timeout},
+		logPath:  logPath,
+		logLines: -1,
+		doneCh:   make(chan struct{}),
+	}
+	d.SetHooks(hooks)
+
+	go func() {
+		<-ctx.Done()
+		// deliveries are only added to the wait group while the dispatcher is
+		// open, so marking it closed first keeps Add from racing with Wait
+		d.lk.Lock()
+		d.closed = true
+		d.lk.Unlock()
+		d.wg.Wait()
+		close(d.doneCh)
+	}()
+
+	return d
+}
+
+// Done returns a channel that closes once the dispatcher's context is
+// cancelled and all in-flight deliveries have finished
+func (d *Dispatcher) Done() <-chan struct{} {
+	return d.doneCh
+}
+
+// SetHooks replaces the list of webhooks the dispatcher delivers to
+func (d *Dispatcher) SetHooks(hooks config.Webhooks) {
+	d.lk.Lock()
+	defer d.lk.Unlock()
+	d.hooks = hooks.Copy()
+
+	if d.sub != nil {
+		d.sub.Unsubscribe()
+		d.sub = nil
+	}
+
+	seen := map[event.Type]bool{}
+	var topics []event.Type
+	for _, hook := range d.hooks {
+		for _, t := range hookEvents(hook) {
+			if !seen[t] {
+				seen[t] = true
+				topics = append(topics, t)
+			}
+		}
+	}
+	if len(topics) > 0 {
+		d.sub = d.bus.Subscribe(d.handleEvent, topics...)
+	}
+}
+
+// Hooks returns a copy of the webhooks the dispatcher delivers to
+func (d *Dispatcher) Hooks() config.Webhooks {
+	d.lk.Lock()
+	defer d.lk.Unlock()
+	return d.hooks.Copy()
+}
+
+func (d *Dispatcher) handleEvent(_ context.Context, t event.Type, payload interface{}) error {
+	// deliveries outlive the publisher's context, which is often scoped to a
+	// single request
+	if d.ctx.Err() != nil {
+		return nil
+	}
+
+	p, err := newPayload(t, payload)
+	if err != nil {
+		log.Errorf("creating webhook payload: %s", err)
+		return nil
+	}
+
+	ds := event.DatasetName(payload)
+	d.lk.Lock()
+	if d.closed {
+		d.lk.Unlock()
+		return nil
+	}
+	var hooks config.Webhooks
+	for _, hook := range d.hooks {
+		if matches(hook, t, ds) {
+			hooks = append(hooks, hook.Copy())
+		}
+	}
+	d.wg.Add(len(hooks))
+	d.lk.Unlock()
+
+	for _, hook := range hooks {
+		go func(hook *config.Webhook) {
+			defer d.wg.Done()
+			d.record(d.deliver(d.ctx, hook, p, MaxAttempts))
+		}(hook)
+	}
+	return nil
+}
+
+// Test sends a test payload to each configured webhook, or only the webhook
+// with a matching URL if url is not empty. Test deliveries are attempted once
+func (d *Dispatcher) Test(ctx context.Context, url string) ([]Delivery, error) {
+	var hooks config.Webhooks
+	for _, hook := range d.Hooks() {
+		if url == "" || hook.URL == url {
+			hooks = append(hooks, hook)
+		}
+	}
+	if len(hooks) == 0 {
+		if url != "" {
+			return nil, fmt.Errorf("no webhook is configured for url %q", url)
+		}
+		return nil, fmt.Errorf("no webhooks are configured")
+	}
+
+	p, err := newPayload(ETTest, map[string]string{"message": "test delivery from qri"})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Delivery, 0, len(hooks))
+	for _, hook := range hooks {
+		del := d.deliver(ctx, hook, p, 1)
+		d.record(del)
+		res = append(res, del)
+	}
+	return res, nil
+}
+
+// deliver POSTs a payload to a webhook, retrying up to attempts times
+func (d *Dispatcher) deliver(ctx context.Context, hook *config.Webhook, p *Payload, attempts int) Delivery {
+	del := Delivery{
+		ID:        p.ID,
+		URL:       hook.URL,
+		Type:      p.Type,
+		Timestamp: time.Now(),
+	}
+
+	body, err := json.Marshal(p)
+	if err != nil {
+		del.Error = err.Error()
+		return del
+	}
+
+	wait := Backoff
+	for {
+		del.Attempts++
+		retry := false
+		del.StatusCode, retry, err = d.post(ctx, hook, p, body)
+		if err == nil {
+			del.Error = ""
+			return del
+		}
+		del.Error = err.Error()
+		log.Debugf("delivering %s to %s, attempt %d: %s", p.ID, hook.URL, del.Attempts, err)
+
+		if !retry || del.Attempts >= attempts {
+			return del
+		}
+
+		select {
+		case <-time.After(wait):
+			wait *= 2
+		case <-ctx.Done():
+			del.Error = ctx.Err().Error()
+			return del
+		}
+	}
+}
+
+// post makes a single delivery attempt, reporting if a failed attempt should
+// be retried
+func (d *Dispatcher) post(ctx context.Context, hook *config.Webhook, p *Payload, body []byte) (status int, retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(p.Type))
+	req.Header.Set(DeliveryHeader, p.ID)
+	if hook.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(hook.Secret, body))
+	}
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, true, err
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return res.StatusCode, false, nil
+	}
+	// server errors & rate limiting are worth retrying, other client errors
+	// won't go away on their own
+	retry = res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests
+	return res.StatusCode, retry, fmt.Errorf("webhook responded with status %d", res.StatusCode)
+}
+
+// record adds a delivery to the log
+func (d *Dispatcher) record(del Delivery) {
+	d.logLk.Lock()
+	defer d.logLk.Unlock()
+
+	if d.logPath == "" {
+		d.deliveries = append(d.deliveries, del)
+		if len(d.deliveries) > MaxLogDeliveries {
+			d.deliveries = d.deliveries[len(d.deliveries)-MaxLogDeliveries:]
+		}
+		return
+	}
+
+	data, err := json.Marshal(del)
+	if err != nil {
+		log.Errorf("encoding webhook delivery: %s", err)
+		return
+	}
+	f, err := os.OpenFile(d.logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Errorf("opening webhook delivery log: %s", err)
+		return
+	}
+	_, err = f.Write(append(data, '\n'))
+	f.Close()
+	if err != nil {
+		log.Errorf("writing webhook delivery log: %s", err)
+		return
+	}
+
+	if d.logLines < 0 {
+		dels, err := d.readLog()
+		if err != nil {
+			log.Errorf("reading webhook delivery log: %s", err)
+			return
+		}
+		d.logLines = len(dels)
+	} else {
+		d.logLines++
+	}
+	if d.logLines > 2*MaxLogDeliveries {
+		if err := d.trimLog(); err != nil {
+			log.Errorf("trimming webhook delivery log: %s", err)
+		}
+	}
+}
+
+// readLog reads all deliveries in the log file. must be called with the log
+// lock held
+func (d *Dispatcher) readLog() ([]Delivery, error) {
+	f, err := os.Open(d.logPath)
+	if os.IsNotExist(err) {
+		return []Delivery{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var dels []Delivery
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		del := Delivery{}
+		if err := json.Unmarshal(sc.Bytes(), &del); err != nil {
+			return nil, fmt.Errorf("reading webhook delivery log: %w", err)
+		}
+		dels = append(dels, del)
+	}
+	return dels, sc.Err()
+}
+
+// trimLog rewrites the log file with only the most recent MaxLogDeliveries
+// deliveries. must be called with the log lock held
+func (d *Dispatcher) trimLog() error {
+	dels, err := d.readLog()
+	if err != nil {
+		return err
+	}
+	if len(dels) > MaxLogDeliveries {
+		dels = dels[len(dels)-MaxLogDeliveries:]
+	}
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	for _, del := range dels {
+		if err := enc.Encode(del); err != nil {
+			return err
+		}
+	}
+	// write to a temp file & rename so a crash can't lose the log
+	if err := ioutil.WriteFile(d.logPath+".tmp", buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(d.logPath+".tmp", d.logPath); err != nil {
+		return err
+	}
+	d.logLines = len(dels)
+	return nil
+}
+
+// Deliveries returns up to limit of the most recent deliveries, oldest first.
+// a limit less than one returns all logged deliveries
+func (d *Dispatcher) Deliveries(limit int) ([]Delivery, error) {
+	d.logLk.Lock()
+	defer d.logLk.Unlock()
+
+	var dels []Delivery
+	if d.logPath == "" {
+		dels = make([]Delivery, len(d.deliveries))
+		copy(dels, d.deliveries)
+	} else {
+		var err error
+		if dels, err = d.readLog(); err != nil {
+			return nil, err
+		}
+	}
+
+	if limit > 0 && len(dels) > limit {
+		dels = dels[len(dels)-limit:]
+	}
+	return dels, nil
+}
+
+func newPayload(t event.Type, data interface{}) (*Payload, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	return &Payload{
+		ID:        hex.EncodeToString(id),
+		Type:      t,
+		Timestamp: time.Now(),
+		Data:      data,
+	}, nil
+}
+
+// hookEvents returns the event types a webhook should receive
+func hookEvents(hook *config.Webhook) []event.Type {
+	if len(hook.Events) == 0 {
+		return DefaultEvents
+	}
+	types := make([]event.Type, len(hook.Events))
+	for i, t := range hook.Events {
+		types[i] = event.Type(t)
+	}
+	return types
+}
+
+// matches returns true if a webhook should receive an event about dataset ds
+func matches(hook *config.Webhook, t event.Type, ds string) bool {
+	found := false
+	for _, et := range hookEvents(hook) {
+		if et == t {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false
+	}
+	if len(hook.Datasets) == 0 {
+		return true
+	}
+	for _, name := range hook.Datasets {
+		if name == ds {
+			return true
+		}
+	}
+	return false
+}
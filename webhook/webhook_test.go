@@ -0,0 +1,242 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/event"
+)
+
+type receiver struct {
+	lk       sync.Mutex
+	statuses []int
+	payloads []Payload
+	headers  []http.Header
+	bodies   [][]byte
+	got      chan struct{}
+}
+
+func newReceiver(statuses ...int) (*receiver, *httptest.Server) {
+	r := &receiver{statuses: statuses, got: make(chan struct{}, 10)}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		p := Payload{}
+		json.Unmarshal(body, &p)
+
+		r.lk.Lock()
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+		r.payloads = append(r.payloads, p)
+		r.headers = append(r.headers, req.Header)
+		r.bodies = append(r.bodies, body)
+		r.lk.Unlock()
+
+		w.WriteHeader(status)
+		r.got <- struct{}{}
+	}))
+	return r, s
+}
+
+func (r *receiver) wait(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-r.got:
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for delivery %d", i+1)
+		}
+	}
+}
+
+func TestDispatcherDelivery(t *testing.T) {
+	prevBackoff := Backoff
+	Backoff = time.Millisecond
+	defer func() { Backoff = prevBackoff }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rec, s := newReceiver(http.StatusInternalServerError, http.StatusOK)
+	defer s.Close()
+	filtered, fs := newReceiver()
+	defer fs.Close()
+
+	bus := event.NewBus(ctx)
+	dir, err := ioutil.TempDir("", "webhook_dispatcher_delivery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	logPath := filepath.Join(dir, "webhooks.log")
+	d := NewDispatcher(ctx, bus, config.Webhooks{
+		{URL: s.URL, Secret: "shh"},
+		{URL: fs.URL, Events: []string{string(event.ETDatasetDeleteAll)}, Datasets: []string{"peer/other"}},
+	}, logPath)
+
+	change := event.DsChange{Username: "peer", PrettyName: "ds", HeadRef: "/mem/QmHead"}
+	if err := bus.Publish(ctx, event.ETDatasetCommitChange, change); err != nil {
+		t.Fatal(err)
+	}
+	// first attempt fails with a server error, the retry succeeds
+	rec.wait(t, 2)
+
+	rec.lk.Lock()
+	p := rec.payloads[1]
+	if p.Type != event.ETDatasetCommitChange {
+		t.Errorf("payload type mismatch. want %q, got %q", event.ETDatasetCommitChange, p.Type)
+	}
+	if rec.payloads[0].ID != p.ID {
+		t.Errorf("expected retries to reuse the payload id")
+	}
+	h := rec.headers[1]
+	if h.Get(EventHeader) != string(event.ETDatasetCommitChange) {
+		t.Errorf("event header mismatch. got %q", h.Get(EventHeader))
+	}
+	if h.Get(DeliveryHeader) != p.ID {
+		t.Errorf("delivery header mismatch. want %q, got %q", p.ID, h.Get(DeliveryHeader))
+	}
+	if !Verify("shh", rec.bodies[1], h.Get(SignatureHeader)) {
+		t.Errorf("signature %q doesn't verify", h.Get(SignatureHeader))
+	}
+	rec.lk.Unlock()
+
+	select {
+	case <-filtered.got:
+		t.Errorf("filtered webhook shouldn't receive events for other datasets")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// deliveries are recorded after the final attempt returns
+	var dels []Delivery
+	for i := 0; i < 100; i++ {
+		var err error
+		if dels, err = d.Deliveries(0); err != nil {
+			t.Fatal(err)
+		}
+		if len(dels) == 1 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if len(dels) != 1 {
+		t.Fatalf("expected 1 logged delivery, got %d", len(dels))
+	}
+	if !dels[0].Succeeded() || dels[0].Attempts != 2 || dels[0].StatusCode != http.StatusOK {
+		t.Errorf("unexpected delivery: %#v", dels[0])
+	}
+
+	if err := bus.Publish(ctx, event.ETDatasetDeleteAll, event.DsChange{Username: "peer", PrettyName: "other"}); err != nil {
+		t.Fatal(err)
+	}
+	filtered.wait(t, 1)
+	rec.wait(t, 1)
+}
+
+func TestDispatcherGivesUp(t *testing.T) {
+	prevBackoff, prevAttempts := Backoff, MaxAttempts
+	Backoff, MaxAttempts = time.Millisecond, 3
+	defer func() { Backoff, MaxAttempts = prevBackoff, prevAttempts }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	rec, s := newReceiver(http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadRequest)
+	defer s.Close()
+	bus := event.NewBus(ctx)
+	d := NewDispatcher(ctx, bus, config.Webhooks{{URL: s.URL}}, "")
+
+	bus.Publish(ctx, event.ETDatasetRename, event.DsChange{})
+	rec.wait(t, 3)
+	bus.Publish(ctx, event.ETDatasetRename, event.DsChange{})
+	rec.wait(t, 1)
+
+	cancel()
+	<-d.Done()
+
+	dels, err := d.Deliveries(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dels) != 2 {
+		t.Fatalf("expected 2 logged deliveries, got %d", len(dels))
+	}
+	for i, expectAttempts := range []int{3, 1} {
+		if dels[i].Succeeded() {
+			t.Errorf("delivery %d: expected failure", i)
+		}
+		if dels[i].Attempts != expectAttempts {
+			t.Errorf("delivery %d: expected %d attempts, got %d", i, expectAttempts, dels[i].Attempts)
+		}
+	}
+}
+
+func TestDispatcherTest(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rec, s := newReceiver()
+	defer s.Close()
+	dir, err := ioutil.TempDir("", "webhook_dispatcher_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	d := NewDispatcher(ctx, event.NilBus, config.Webhooks{{URL: s.URL}}, filepath.Join(dir, "webhooks.log"))
+
+	if _, err := d.Test(ctx, "https://example.com/missing"); err == nil {
+		t.Error("expected testing an unconfigured url to error")
+	}
+
+	dels, err := d.Test(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.wait(t, 1)
+	if len(dels) != 1 || !dels[0].Succeeded() || dels[0].Type != ETTest {
+		t.Errorf("unexpected test deliveries: %#v", dels)
+	}
+
+	logged, err := d.Deliveries(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logged) != 1 || logged[0].ID != dels[0].ID {
+		t.Errorf("expected test delivery to be logged. got: %#v", logged)
+	}
+}
+
+func TestDeliveryLogTrimmed(t *testing.T) {
+	prevMax := MaxLogDeliveries
+	MaxLogDeliveries = 2
+	defer func() { MaxLogDeliveries = prevMax }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dir, err := ioutil.TempDir("", "webhook_delivery_log_trimmed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	d := NewDispatcher(ctx, event.NilBus, nil, filepath.Join(dir, "webhooks.log"))
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		d.record(Delivery{ID: id})
+	}
+
+	dels, err := d.Deliveries(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dels) != 2 || dels[0].ID != "d" || dels[1].ID != "e" {
+		t.Errorf("expected log to be trimmed to the 2 most recent deliveries, got: %#v", dels)
+	}
+}